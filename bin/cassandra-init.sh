@@ -3,4 +3,7 @@
 cqlsh -f /0chain/sql/zerochain_keyspace.sql cassandra
 cqlsh -f /0chain/sql/magic_block_map.sql cassandra
 cqlsh -f /0chain/sql/txn_summary.sql cassandra
+cqlsh -f /0chain/sql/txn_client_history.sql cassandra
+# upgrade of an existing table, fails harmlessly if it's already upgraded
+cqlsh -f /0chain/sql/txn_client_history_partial.sql cassandra
 echo "cassandra initialized"
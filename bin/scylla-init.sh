@@ -2,4 +2,7 @@
 /0chain/bin/wait-for-service.sh -t 0 scylla:9042 -- echo "scylla started"
 cqlsh -f /0chain/sql/zerochain_keyspace.sql scylla
 cqlsh -f /0chain/sql/txn_summary.sql scylla
+cqlsh -f /0chain/sql/txn_client_history.sql scylla
+# upgrade of an existing table, fails harmlessly if it's already upgraded
+cqlsh -f /0chain/sql/txn_client_history_partial.sql scylla
 echo "scylla initialized"
@@ -161,6 +161,12 @@ type Block struct {
 	RunningTxnCount       int64           `json:"running_txn_count"`
 	UniqueBlockExtensions map[string]bool `json:"-"`
 	*MagicBlock           `json:"magic_block,omitempty"`
+
+	// txnTransfers is recipients of transfers and mints of the block
+	// transactions by transaction hash; it's known only for blocks with
+	// state computed by this node
+	txnTransfers      map[string][]string
+	txnTransfersMutex sync.RWMutex
 }
 
 // NewBlock - create a new empty block
@@ -203,6 +209,11 @@ func (b *Block) GetEntityMetadata() datastore.EntityMetadata {
 	return blockEntityMetadata
 }
 
+// GetRoundNumber returns round of the block.
+func (b *Block) GetRoundNumber() int64 {
+	return b.Round
+}
+
 /*ComputeProperties - Entity implementation */
 func (b *Block) ComputeProperties() {
 	if datastore.IsEmpty(b.ChainID) {
@@ -436,12 +447,26 @@ func (b *Block) getHashData() string {
 	merkleRoot := mt.GetRoot()
 	rmt := b.GetReceiptsMerkleTree()
 	rMerkleRoot := rmt.GetRoot()
-	hashData := b.MinerID + ":" + b.PrevHash + ":" + common.TimeToString(b.CreationDate) + ":" + strconv.FormatInt(b.Round, 10) + ":" + strconv.FormatInt(b.GetRoundRandomSeed(), 10) + ":" + merkleRoot + ":" + rMerkleRoot
+	var mbHash string
 	if b.MagicBlock != nil {
 		if b.MagicBlock.Hash == "" {
 			b.MagicBlock.Hash = b.MagicBlock.GetHash()
 		}
-		hashData += ":" + b.MagicBlock.Hash
+		mbHash = b.MagicBlock.Hash
+	}
+	return HeaderHashData(b.MinerID, b.PrevHash, b.CreationDate, b.Round,
+		b.GetRoundRandomSeed(), merkleRoot, rMerkleRoot, mbHash)
+}
+
+// HeaderHashData returns the data hash of a block is computed of. The magic
+// block hash is empty for a block without a magic block.
+func HeaderHashData(minerID, prevHash string, creationDate common.Timestamp,
+	round, roundRandomSeed int64, merkleRoot, receiptMerkleRoot,
+	magicBlockHash string) string {
+
+	hashData := minerID + ":" + prevHash + ":" + common.TimeToString(creationDate) + ":" + strconv.FormatInt(round, 10) + ":" + strconv.FormatInt(roundRandomSeed, 10) + ":" + merkleRoot + ":" + receiptMerkleRoot
+	if magicBlockHash != "" {
+		hashData += ":" + magicBlockHash
 	}
 	return hashData
 }
@@ -594,6 +619,25 @@ func (b *Block) GetTransaction(hash string) *transaction.Transaction {
 	return nil
 }
 
+// AddTxnTransfers records recipients of transfers and mints made by given
+// transaction during the block state computation.
+func (b *Block) AddTxnTransfers(hash string, recipients []string) {
+	b.txnTransfersMutex.Lock()
+	defer b.txnTransfersMutex.Unlock()
+	if b.txnTransfers == nil {
+		b.txnTransfers = make(map[string][]string)
+	}
+	b.txnTransfers[hash] = recipients
+}
+
+// GetTxnTransfers returns recipients of transfers and mints made by given
+// transaction, if the block state has been computed by this node.
+func (b *Block) GetTxnTransfers(hash string) []string {
+	b.txnTransfersMutex.RLock()
+	defer b.txnTransfersMutex.RUnlock()
+	return b.txnTransfers[hash]
+}
+
 //SetBlockNotarized - set the block as notarized
 func (b *Block) SetBlockNotarized() {
 	b.ticketsMutex.Lock()
@@ -26,6 +26,11 @@ type MagicBlock struct {
 	T                      int                 `json:"t"`
 	K                      int                 `json:"k"`
 	N                      int                 `json:"n"`
+	// Features is schedule of protocol features activations.
+	Features *ProtocolFeatures `json:"features,omitempty"`
+	// MinersStake is snapshot of miners total stakes taken from the miner
+	// SC delegate pools at the moment the magic block created.
+	MinersStake map[string]int64 `json:"miners_stake,omitempty"`
 }
 
 func NewMagicBlock() *MagicBlock {
@@ -87,6 +92,21 @@ func (mb *MagicBlock) GetHashBytes() []byte {
 	}
 	data = append(data, []byte(strconv.Itoa(mb.T))...)
 	data = append(data, []byte(strconv.Itoa(mb.N))...)
+	// features info, omitted when empty to keep hashes of magic blocks
+	// created before the features registry
+	if mb.Features.Size() > 0 {
+		data = append(data, mb.Features.hashData()...)
+	}
+	// stakes info, omitted when empty for the same reason
+	var stakeKeys = make([]string, 0, len(mb.MinersStake))
+	for k := range mb.MinersStake {
+		stakeKeys = append(stakeKeys, k)
+	}
+	sort.Strings(stakeKeys)
+	for _, k := range stakeKeys {
+		data = append(data, []byte(k)...)
+		data = append(data, []byte(strconv.FormatInt(mb.MinersStake[k], 10))...)
+	}
 	return encryption.RawHash(data)
 }
 
@@ -100,6 +120,15 @@ func (mb *MagicBlock) IsActiveNode(id string, round int64) bool {
 	return mb.Sharders.HasNode(id) && mb.StartingRound <= round
 }
 
+// IsFeatureActive returns true if given protocol feature is scheduled by the
+// magic block and is active at given round.
+func (mb *MagicBlock) IsFeatureActive(name string, round int64) bool {
+	if mb == nil {
+		return false
+	}
+	return mb.Features.IsActive(name, round)
+}
+
 func (mb *MagicBlock) VerifyMinersSignatures(b *Block) bool {
 	for _, bvt := range b.GetVerificationTickets() {
 		var sender = mb.Miners.GetNode(bvt.VerifierID)
@@ -139,6 +168,15 @@ func (mb *MagicBlock) Clone() *MagicBlock {
 	if mb.Sharders != nil {
 		clone.Sharders = mb.Sharders.Clone()
 	}
+	if mb.Features != nil {
+		clone.Features = mb.Features.Clone()
+	}
+	if mb.MinersStake != nil {
+		clone.MinersStake = make(map[string]int64, len(mb.MinersStake))
+		for k, v := range mb.MinersStake {
+			clone.MinersStake[k] = v
+		}
+	}
 
 	return clone
 }
@@ -0,0 +1,159 @@
+package block
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+var (
+	knownFeaturesMutex sync.RWMutex
+	// knownFeatures is set of protocol features the node's code is able to
+	// handle. A feature scheduled on chain but missing here means the node
+	// binary is outdated and must be upgraded before the activation round.
+	knownFeatures = make(map[string]struct{})
+)
+
+// RegisterProtocolFeature marks given feature as known by this node binary.
+// Packages branching on a feature should register it from their init.
+func RegisterProtocolFeature(name string) {
+	knownFeaturesMutex.Lock()
+	defer knownFeaturesMutex.Unlock()
+	knownFeatures[name] = struct{}{}
+}
+
+// IsKnownProtocolFeature returns true if given feature registered by this
+// node binary.
+func IsKnownProtocolFeature(name string) bool {
+	knownFeaturesMutex.RLock()
+	defer knownFeaturesMutex.RUnlock()
+	_, ok := knownFeatures[name]
+	return ok
+}
+
+// ProtocolFeature is a named protocol change activated at given round.
+type ProtocolFeature struct {
+	Name            string `json:"name"`
+	ActivationRound int64  `json:"activation_round"`
+}
+
+// Encode implements util.Serializable interface.
+func (pf *ProtocolFeature) Encode() []byte {
+	buff, _ := json.Marshal(pf)
+	return buff
+}
+
+// Decode implements util.Serializable interface.
+func (pf *ProtocolFeature) Decode(input []byte) error {
+	return json.Unmarshal(input, pf)
+}
+
+// ProtocolFeatures is registry of scheduled protocol features. It's
+// stored in miner SC state and is copied to every new magic block.
+type ProtocolFeatures struct {
+	Features map[string]*ProtocolFeature `json:"features"`
+}
+
+// NewProtocolFeatures returns new empty registry.
+func NewProtocolFeatures() *ProtocolFeatures {
+	return &ProtocolFeatures{Features: make(map[string]*ProtocolFeature)}
+}
+
+// Encode implements util.Serializable interface.
+func (pfs *ProtocolFeatures) Encode() []byte {
+	buff, _ := json.Marshal(pfs)
+	return buff
+}
+
+// Decode implements util.Serializable interface.
+func (pfs *ProtocolFeatures) Decode(input []byte) error {
+	return json.Unmarshal(input, pfs)
+}
+
+func (pfs *ProtocolFeatures) GetHash() string {
+	return util.ToHex(pfs.GetHashBytes())
+}
+
+func (pfs *ProtocolFeatures) GetHashBytes() []byte {
+	return encryption.RawHash(pfs.hashData())
+}
+
+// hashData returns deterministic representation of the registry used for
+// the registry and magic block hashes.
+func (pfs *ProtocolFeatures) hashData() (data []byte) {
+	for _, name := range pfs.Names() {
+		data = append(data, []byte(name)...)
+		data = append(data,
+			[]byte(strconv.FormatInt(pfs.Features[name].ActivationRound, 10))...)
+	}
+	return
+}
+
+// Size returns number of scheduled features, nil registry is empty.
+func (pfs *ProtocolFeatures) Size() int {
+	if pfs == nil {
+		return 0
+	}
+	return len(pfs.Features)
+}
+
+// Names returns sorted names of all scheduled features.
+func (pfs *ProtocolFeatures) Names() (names []string) {
+	if pfs == nil {
+		return
+	}
+	names = make([]string, 0, len(pfs.Features))
+	for name := range pfs.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// IsActive returns true if given feature is scheduled and its activation
+// round is reached at given round.
+func (pfs *ProtocolFeatures) IsActive(name string, round int64) bool {
+	if pfs == nil {
+		return false
+	}
+	pf, ok := pfs.Features[name]
+	return ok && pf.ActivationRound <= round
+}
+
+// Unknown returns sorted names of scheduled features which are not known by
+// this node binary.
+func (pfs *ProtocolFeatures) Unknown() (names []string) {
+	for _, name := range pfs.Names() {
+		if !IsKnownProtocolFeature(name) {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// UnknownActive returns sorted names of features active at given round and
+// not known by this node binary.
+func (pfs *ProtocolFeatures) UnknownActive(round int64) (names []string) {
+	for _, name := range pfs.Unknown() {
+		if pfs.IsActive(name, round) {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// Clone returns a clone of ProtocolFeatures instance.
+func (pfs *ProtocolFeatures) Clone() *ProtocolFeatures {
+	clone := &ProtocolFeatures{
+		Features: make(map[string]*ProtocolFeature, len(pfs.Features)),
+	}
+	for k, v := range pfs.Features {
+		nv := *v
+		clone.Features[k] = &nv
+	}
+	return clone
+}
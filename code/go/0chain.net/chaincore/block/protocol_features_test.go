@@ -0,0 +1,65 @@
+package block
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/node"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocolFeatures_IsActive(t *testing.T) {
+	pfs := NewProtocolFeatures()
+	pfs.Features["f1"] = &ProtocolFeature{Name: "f1", ActivationRound: 100}
+
+	assert.False(t, pfs.IsActive("f1", 99))
+	assert.True(t, pfs.IsActive("f1", 100))
+	assert.True(t, pfs.IsActive("f1", 101))
+	assert.False(t, pfs.IsActive("f2", 101))
+
+	var nilPfs *ProtocolFeatures
+	assert.False(t, nilPfs.IsActive("f1", 100))
+	assert.Zero(t, nilPfs.Size())
+}
+
+func TestProtocolFeatures_Unknown(t *testing.T) {
+	RegisterProtocolFeature("known_feature")
+
+	pfs := NewProtocolFeatures()
+	pfs.Features["known_feature"] = &ProtocolFeature{Name: "known_feature", ActivationRound: 10}
+	pfs.Features["unknown_b"] = &ProtocolFeature{Name: "unknown_b", ActivationRound: 10}
+	pfs.Features["unknown_a"] = &ProtocolFeature{Name: "unknown_a", ActivationRound: 20}
+
+	assert.Equal(t, []string{"unknown_a", "unknown_b"}, pfs.Unknown())
+	assert.Empty(t, pfs.UnknownActive(9))
+	assert.Equal(t, []string{"unknown_b"}, pfs.UnknownActive(10))
+	assert.Equal(t, []string{"unknown_a", "unknown_b"}, pfs.UnknownActive(20))
+}
+
+func TestProtocolFeatures_EncodeDecode(t *testing.T) {
+	pfs := NewProtocolFeatures()
+	pfs.Features["f1"] = &ProtocolFeature{Name: "f1", ActivationRound: 100}
+
+	got := NewProtocolFeatures()
+	require.NoError(t, got.Decode(pfs.Encode()))
+	assert.Equal(t, pfs, got)
+	assert.Equal(t, pfs.GetHash(), got.GetHash())
+	assert.Equal(t, pfs, pfs.Clone())
+}
+
+func TestMagicBlock_GetHashBytesFeatures(t *testing.T) {
+	mb := NewMagicBlock()
+	mb.Miners = node.NewPool(node.NodeTypeMiner)
+	mb.Sharders = node.NewPool(node.NodeTypeSharder)
+
+	withoutFeatures := mb.GetHash()
+	mb.Features = NewProtocolFeatures()
+	assert.Equal(t, withoutFeatures, mb.GetHash(),
+		"empty features must not change magic block hash")
+
+	mb.Features.Features["f1"] = &ProtocolFeature{Name: "f1", ActivationRound: 100}
+	assert.NotEqual(t, withoutFeatures, mb.GetHash())
+	assert.True(t, mb.IsFeatureActive("f1", 100))
+	assert.True(t, mb.Clone().IsFeatureActive("f1", 100))
+}
@@ -44,6 +44,11 @@ func (bvt *BlockVerificationTicket) GetKey() datastore.Key {
 	return datastore.ToKey(bvt.BlockID)
 }
 
+// GetRoundNumber returns round of the block.
+func (bvt *BlockVerificationTicket) GetRoundNumber() int64 {
+	return bvt.Round
+}
+
 /*Validate - implementing the interface */
 func (bvt *BlockVerificationTicket) Validate(ctx context.Context) error {
 	if datastore.IsEmpty(bvt.VerifierID) {
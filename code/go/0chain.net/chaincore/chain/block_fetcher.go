@@ -4,12 +4,14 @@ import (
 	"context"
 	"net/url"
 	"strconv"
+	"time"
 
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/config"
 	"0chain.net/chaincore/node"
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
+	"0chain.net/core/journal"
 
 	"0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -273,7 +275,11 @@ func (bf *BlockFetcher) fetchFromMiners(ctx context.Context,
 
 	defer bf.release(limit)
 
-	var nb, err = chainer.getNotarizedBlockFromMiners(ctx, bfr.hash)
+	var (
+		start   = time.Now()
+		nb, err = chainer.getNotarizedBlockFromMiners(ctx, bfr.hash)
+	)
+	recordFetch(bfr, "miners", start, nb, err)
 	if err != nil {
 		bf.gotError(ctx, got, bfr.hash, err)
 		return
@@ -288,11 +294,13 @@ func (bf *BlockFetcher) fetchFromSharders(ctx context.Context,
 
 	defer bf.release(limit)
 
+	var start = time.Now()
 	var fb, err = chainer.getFinalizedBlockFromSharders(ctx, &LFBTicket{
 		LFBHash:   bfr.hash,      //
 		Round:     bfr.round,     //
 		SharderID: bfr.sharderID, // if set
 	})
+	recordFetch(bfr, "sharders", start, fb, err)
 	if err != nil {
 		bf.gotError(ctx, got, bfr.hash, err)
 		return
@@ -301,6 +309,24 @@ func (bf *BlockFetcher) fetchFromSharders(ctx context.Context,
 	bf.gotBlock(ctx, got, fb)
 }
 
+// recordFetch records result of the block fetch request in the journal
+func recordFetch(bfr *blockFetchRequest, from string, start time.Time,
+	b *block.Block, err error) {
+
+	var e = &journal.Event{Type: journal.SyncFetch, Round: bfr.round,
+		Block: bfr.hash,
+		Details: map[string]interface{}{
+			"from":        from,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}}
+	if err != nil {
+		e.Error = err.Error()
+	} else {
+		e.Round = b.Round
+	}
+	journal.Record(e)
+}
+
 //
 // Common interfaces used by the block fetcher.
 //
@@ -1,6 +1,7 @@
 package chain
 
 import (
+	"fmt"
 	"time"
 
 	"0chain.net/core/datastore"
@@ -13,6 +14,47 @@ const (
 	BlockProposalWaitDynamic = iota
 )
 
+// ConsensusThresholdMode - Set in 0chain.yaml
+type ConsensusThresholdMode int8
+
+// Consensus threshold modes. A block is notarized or finalized if
+// threshold_by_count percent of miners, threshold_by_stake percent of miners
+// stake or both of them are reached.
+const (
+	ThresholdModeCount ConsensusThresholdMode = iota
+	ThresholdModeStake
+	ThresholdModeBoth
+)
+
+// parseThresholdMode returns the consensus threshold mode of its name, the
+// count mode if the name is not set.
+func parseThresholdMode(mode string) (ConsensusThresholdMode, error) {
+	switch mode {
+	case "", "count":
+		return ThresholdModeCount, nil
+	case "stake":
+		return ThresholdModeStake, nil
+	case "both":
+		return ThresholdModeBoth, nil
+	}
+	return 0, fmt.Errorf("unknown threshold_mode %q, expected count, stake"+
+		" or both", mode)
+}
+
+// validateThresholds checks the stake threshold is a percent in (0, 100]
+// if the threshold mode uses it.
+func (c *Config) validateThresholds() error {
+	if c.ThresholdMode == ThresholdModeCount {
+		return nil
+	}
+	if c.ThresholdByStake <= 0 || c.ThresholdByStake > 100 {
+		return fmt.Errorf("threshold_by_stake must be a percent in (0, 100]"+
+			" for the stake and both threshold modes, got %d",
+			c.ThresholdByStake)
+	}
+	return nil
+}
+
 // HealthCheckScan - Set in 0chain.yaml
 type HealthCheckScan int
 
@@ -58,7 +100,7 @@ type Config struct {
 	GeneratorsPercent     float64       `json:"generators_percent"`      // Percentage of all miners
 	NumReplicators        int           `json:"num_replicators"`         // Number of sharders that can store the block
 	ThresholdByCount      int           `json:"threshold_by_count"`      // Threshold count for a block to be notarized
-	ThresholdByStake      int           `json:"threshold_by_stake"`      // Stake threshold for a block to be notarized
+	ThresholdByStake      int           `json:"threshold_by_stake"`      // Percent of the magic block miners stake for a block to be notarized
 	ValidationBatchSize   int           `json:"validation_size"`         // Batch size of txns for crypto verification
 	TxnMaxPayload         int           `json:"transaction_max_payload"` // Max payload allowed in the transaction
 	PruneStateBelowCount  int           `json:"prune_state_below_count"` // Prune state below these many rounds
@@ -66,6 +108,8 @@ type Config struct {
 	BlocksToSharder       int           `json:"blocks_to_sharder"`       // send finalized or notarized blocks to sharder
 	VerificationTicketsTo int           `json:"verification_tickets_to"` // send verification tickets to generator or all miners
 
+	ThresholdMode ConsensusThresholdMode `json:"threshold_mode"` // count, stake or both thresholds should be reached
+
 	HealthShowCounters bool `json:"health_show_counters"` // display detail counters
 	// Health Check switches
 	HCCycleScan [2]HealthCheckCycleScan
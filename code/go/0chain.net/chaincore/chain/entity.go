@@ -87,6 +87,9 @@ type Chain struct {
 
 	//Chain config goes into this object
 	*Config
+	// localConfigMutex guards the node-local settings of the Config that
+	// can be reloaded at runtime
+	localConfigMutex sync.RWMutex
 
 	MagicBlockStorage round.RoundStorage `json:"-"`
 
@@ -125,9 +128,6 @@ type Chain struct {
 
 	BlockChain *ring.Ring `json:"-"`
 
-	minersStake map[datastore.Key]int
-	stakeMutex  *sync.Mutex
-
 	nodePoolScorer node.PoolScorer
 
 	GenerateTimeout int `json:"-"`
@@ -266,6 +266,18 @@ func (c *Chain) GetMagicBlockNoOffset(round int64) *block.MagicBlock {
 	return entity.(*block.MagicBlock)
 }
 
+// IsFeatureActive returns true if given protocol feature is active at given
+// round according to the magic block of the round.
+func (c *Chain) IsFeatureActive(name string, round int64) bool {
+	return c.GetMagicBlock(round).IsFeatureActive(name, round)
+}
+
+// UnknownActiveFeatures returns protocol features active at given round and
+// not known by this node. A node can't produce valid blocks in this case.
+func (c *Chain) UnknownActiveFeatures(round int64) []string {
+	return c.GetMagicBlock(round).Features.UnknownActive(round)
+}
+
 func (c *Chain) GetPrevMagicBlock(r int64) *block.MagicBlock {
 
 	r = mbRoundOffset(r)
@@ -349,6 +361,15 @@ func NewChainFromConfig() *Chain {
 	chain.NumReplicators = viper.GetInt("server_chain.block.replicators")
 	chain.ThresholdByCount = viper.GetInt("server_chain.block.consensus.threshold_by_count")
 	chain.ThresholdByStake = viper.GetInt("server_chain.block.consensus.threshold_by_stake")
+	var err error
+	chain.ThresholdMode, err = parseThresholdMode(
+		viper.GetString("server_chain.block.consensus.threshold_mode"))
+	if err == nil {
+		err = chain.validateThresholds()
+	}
+	if err != nil {
+		logging.Logger.Panic("invalid consensus thresholds", zap.Error(err))
+	}
 	chain.OwnerID = viper.GetString("server_chain.owner")
 	chain.ValidationBatchSize = viper.GetInt("server_chain.block.validation.batch_size")
 	chain.RoundRange = viper.GetInt64("server_chain.round_range")
@@ -396,13 +417,6 @@ func NewChainFromConfig() *Chain {
 
 	chain.HealthShowCounters = viper.GetBool("server_chain.health_check.show_counters")
 
-	chain.BlockProposalMaxWaitTime = viper.GetDuration("server_chain.block.proposal.max_wait_time") * time.Millisecond
-	waitMode := viper.GetString("server_chain.block.proposal.wait_mode")
-	if waitMode == "static" {
-		chain.BlockProposalWaitMode = BlockProposalWaitStatic
-	} else if waitMode == "dynamic" {
-		chain.BlockProposalWaitMode = BlockProposalWaitDynamic
-	}
 	chain.ReuseTransactions = viper.GetBool("server_chain.block.reuse_txns")
 	chain.SetSignatureScheme(viper.GetString("server_chain.client.signature_scheme"))
 
@@ -412,13 +426,74 @@ func NewChainFromConfig() *Chain {
 	if chain.SmartContractTimeout == 0 {
 		chain.SmartContractTimeout = DefaultSmartContractTimeout
 	}
-	chain.RoundTimeoutSofttoMin = viper.GetInt("server_chain.round_timeouts.softto_min")
-	chain.RoundTimeoutSofttoMult = viper.GetInt("server_chain.round_timeouts.softto_mult")
-	chain.RoundRestartMult = viper.GetInt("server_chain.round_timeouts.round_restart_mult")
+	chain.readLocalConfig()
+	config.RegisterReloadable(chain.readLocalConfig,
+		"server_chain.block.proposal", "server_chain.round_timeouts")
 
 	return chain
 }
 
+// readLocalConfig reads the node-local settings that can be reloaded at
+// runtime, the settings are read through the getters
+func (c *Chain) readLocalConfig() error {
+	maxWaitTime := viper.GetDuration("server_chain.block.proposal.max_wait_time") * time.Millisecond
+	waitMode := c.BlockProposalWaitMode
+	switch viper.GetString("server_chain.block.proposal.wait_mode") {
+	case "static":
+		waitMode = BlockProposalWaitStatic
+	case "dynamic":
+		waitMode = BlockProposalWaitDynamic
+	}
+
+	c.localConfigMutex.Lock()
+	defer c.localConfigMutex.Unlock()
+	c.BlockProposalMaxWaitTime = maxWaitTime
+	c.BlockProposalWaitMode = waitMode
+	c.RoundTimeoutSofttoMin = viper.GetInt("server_chain.round_timeouts.softto_min")
+	c.RoundTimeoutSofttoMult = viper.GetInt("server_chain.round_timeouts.softto_mult")
+	c.RoundRestartMult = viper.GetInt("server_chain.round_timeouts.round_restart_mult")
+	return nil
+}
+
+// GetBlockProposalMaxWaitTime returns max time to wait for the block
+// proposals.
+func (c *Chain) GetBlockProposalMaxWaitTime() time.Duration {
+	c.localConfigMutex.RLock()
+	defer c.localConfigMutex.RUnlock()
+	return c.BlockProposalMaxWaitTime
+}
+
+// GetBlockProposalWaitMode returns whether the wait time for the block
+// proposals is static or dynamic.
+func (c *Chain) GetBlockProposalWaitMode() int8 {
+	c.localConfigMutex.RLock()
+	defer c.localConfigMutex.RUnlock()
+	return c.BlockProposalWaitMode
+}
+
+// GetRoundTimeoutSofttoMin returns min soft timeout of a round in
+// milliseconds.
+func (c *Chain) GetRoundTimeoutSofttoMin() int {
+	c.localConfigMutex.RLock()
+	defer c.localConfigMutex.RUnlock()
+	return c.RoundTimeoutSofttoMin
+}
+
+// GetRoundTimeoutSofttoMult returns multiplier of the mean network time for
+// the soft timeout of a round.
+func (c *Chain) GetRoundTimeoutSofttoMult() int {
+	c.localConfigMutex.RLock()
+	defer c.localConfigMutex.RUnlock()
+	return c.RoundTimeoutSofttoMult
+}
+
+// GetRoundRestartMult returns number of the soft timeouts restarting a round.
+func (c *Chain) GetRoundRestartMult() int {
+	c.localConfigMutex.RLock()
+	defer c.localConfigMutex.RUnlock()
+	return c.RoundRestartMult
+}
+
 /*Provider - entity provider for chain object */
 func Provider() datastore.Entity {
 	c := &Chain{}
@@ -435,7 +510,6 @@ func Provider() datastore.Entity {
 	c.retry_wait_mutex = &sync.Mutex{}
 	c.genTimeoutMutex = &sync.Mutex{}
 	c.stateMutex = &sync.RWMutex{}
-	c.stakeMutex = &sync.Mutex{}
 	c.InitializeCreationDate()
 	c.nodePoolScorer = node.NewHashPoolScorer(encryption.NewXORHashScorer())
 
@@ -471,7 +545,6 @@ func (c *Chain) Initialize() {
 	c.clientStateDeserializer = &state.Deserializer{}
 	c.stateDB = stateDB
 	c.BlockChain = ring.New(10000)
-	c.minersStake = make(map[datastore.Key]int)
 	c.magicBlockStartingRounds = make(map[int64]*block.Block)
 	c.MagicBlockStorage = round.NewRoundStartingStorage()
 }
@@ -529,12 +602,21 @@ func (c *Chain) getInitialState(tokens state.Balance) util.Serializable {
 	return balance
 }
 
-/*setupInitialState - setup the initial state based on configuration */
-func (c *Chain) setupInitialState(initStates *state.InitStates) util.MerklePatriciaTrieI {
-	pmt := util.NewMerklePatriciaTrie(c.stateDB, util.Sequence(0))
+// NewInitialState creates the initial state of the given init states in the
+// given node db.
+func (c *Chain) NewInitialState(initStates *state.InitStates,
+	ndb util.NodeDB) util.MerklePatriciaTrieI {
+
+	pmt := util.NewMerklePatriciaTrie(ndb, util.Sequence(0))
 	for _, v := range initStates.States {
 		pmt.Insert(util.Path(v.ID), c.getInitialState(v.Tokens))
 	}
+	return pmt
+}
+
+/*setupInitialState - setup the initial state based on configuration */
+func (c *Chain) setupInitialState(initStates *state.InitStates) util.MerklePatriciaTrieI {
+	pmt := c.NewInitialState(initStates, c.stateDB)
 	if err := pmt.SaveChanges(context.Background(), stateDB, false); err != nil {
 		logging.Logger.Error("chain.stateDB save changes failed", zap.Error(err))
 	}
@@ -953,14 +1035,56 @@ func (c *Chain) ChainHasTransaction(ctx context.Context, b *block.Block, txn *tr
 	return false, ErrInsufficientChain
 }
 
-func (c *Chain) updateMiningStake(minerID datastore.Key, stake int) {
-	c.stakeMutex.Lock()
-	defer c.stakeMutex.Unlock()
-	c.minersStake[minerID] = stake
+// reachedStakeThreshold returns true if given miners of the magic block have
+// at least threshold_by_stake percent of the magic block miners stake. The
+// stakes are taken from snapshot of the magic block itself, thus, rounds of
+// previous magic block are checked against their own stakes after a view
+// change. Miners out of the magic block and duplicates are ignored. If there
+// is no stake snapshot in the magic block, the stake threshold is never
+// reached.
+func (c *Chain) reachedStakeThreshold(mb *block.MagicBlock,
+	minerIDs []datastore.Key) (reached bool, stake, total int64) {
+
+	for _, id := range mb.Miners.Keys() {
+		total += mb.MinersStake[id]
+	}
+
+	var seen = make(map[datastore.Key]struct{}, len(minerIDs))
+	for _, id := range minerIDs {
+		if _, ok := seen[id]; ok || !mb.Miners.HasNode(id) {
+			continue
+		}
+		seen[id] = struct{}{}
+		stake += mb.MinersStake[id]
+	}
+
+	reached = total > 0 && c.ThresholdByStake > 0 &&
+		stake*100 >= total*int64(c.ThresholdByStake)
+	return
 }
 
-func (c *Chain) getMiningStake(minerID datastore.Key) int {
-	return c.minersStake[minerID]
+// reachedThreshold checks given miners of the magic block against
+// consensus thresholds regarding configured threshold mode.
+func (c *Chain) reachedThreshold(mb *block.MagicBlock,
+	minerIDs []datastore.Key) bool {
+
+	var byCount = len(minerIDs) >= c.GetNotarizationThresholdCount(mb.Miners.Size())
+	if c.ThresholdMode == ThresholdModeCount {
+		return byCount
+	}
+
+	var byStake, _, total = c.reachedStakeThreshold(mb, minerIDs)
+	if total == 0 {
+		logging.Logger.Warn("reached threshold -- missing miners stake,"+
+			" fallback to threshold by count",
+			zap.Int64("mb_sr", mb.StartingRound))
+		return byCount
+	}
+
+	if c.ThresholdMode == ThresholdModeStake {
+		return byStake
+	}
+	return byCount && byStake
 }
 
 //InitializeMinerPool - initialize the miners after their configuration is read
@@ -1375,6 +1499,13 @@ func (c *Chain) UpdateMagicBlock(newMagicBlock *block.MagicBlock) error {
 			fmt.Sprintf("magic block's previous magic block hash (%v) doesn't equal latest finalized magic block id (%v)", newMagicBlock.PreviousMagicBlockHash, lfmb.MagicBlockHash))
 	}
 
+	if unknown := newMagicBlock.Features.Unknown(); len(unknown) > 0 {
+		logging.Logger.Error("update magic block -- unknown protocol features"+
+			" scheduled, the node should be upgraded before activation",
+			zap.Strings("features", unknown),
+			zap.Int64("mb_starting_round", newMagicBlock.StartingRound))
+	}
+
 	// initialize magicblock nodepools
 	c.UpdateNodesFromMagicBlock(newMagicBlock)
 
@@ -1414,6 +1545,7 @@ func (c *Chain) UpdateNodesFromMagicBlock(newMagicBlock *block.MagicBlock) {
 	c.GetNodesPreviousInfo(newMagicBlock)
 
 	node.DeregisterNodes(keep)
+	node.PrunePeers(keep)
 
 	// reset the monitor
 	ResetStatusMonitor(newMagicBlock.StartingRound)
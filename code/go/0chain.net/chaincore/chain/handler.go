@@ -22,6 +22,7 @@ import (
 	"0chain.net/chaincore/round"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/metric"
+	"0chain.net/core/openapi"
 	"go.uber.org/zap"
 
 	"0chain.net/core/build"
@@ -62,6 +63,58 @@ func SetupHandlers() {
 	http.HandleFunc("/_diagnostics/state_dump", common.UserRateLimit(StateDumpHandler))
 
 	http.HandleFunc("/v1/block/get/latest_finalized_ticket", common.N2NRateLimit(common.ToJSONResponse(LFBTicketHandler)))
+
+	http.HandleFunc("/v1/openapi.json", common.UserRateLimit(common.ToJSONResponse(OpenAPIHandler)))
+
+	if node.Self.Underlying().Type == node.NodeTypeMiner {
+		openapi.Register(&openapi.Endpoint{Path: "/v1/block/get",
+			Summary: "block by hash", Params: blockParams{}, Response: BlockResponse{}})
+	}
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/chain/get", Summary: "chain",
+			Params: idParams{}, Response: Chain{}},
+		&openapi.Endpoint{Method: http.MethodPost, Path: "/v1/chain/put",
+			Summary: "update chain", Body: Chain{}, Response: Chain{}},
+		&openapi.Endpoint{Path: "/v1/block/get/latest_finalized",
+			Summary: "latest finalized block", Response: block.BlockSummary{}},
+		&openapi.Endpoint{Path: "/v1/block/get/latest_finalized_magic_block_summary",
+			Summary: "latest finalized magic block summary", Response: block.BlockSummary{}},
+		&openapi.Endpoint{Path: "/v1/block/get/latest_finalized_magic_block",
+			Summary: "latest finalized magic block", Response: block.Block{}},
+		&openapi.Endpoint{Path: "/v1/block/get/recent_finalized",
+			Summary: "recent finalized blocks", Response: []*block.BlockSummary{}},
+		&openapi.Endpoint{Path: "/v1/block/get/fee_stats",
+			Summary: "transaction fee statistics", Response: transaction.TransactionFeeStats{}},
+		&openapi.Endpoint{Method: http.MethodPost,
+			Path:    "/v1/block/get/latest_finalized_ticket",
+			Summary: "latest finalized block ticket of a sharder",
+			Body:    LFBTicket{}},
+		&openapi.Endpoint{Method: http.MethodPost, Path: "/v1/transaction/put",
+			Summary: "submit transaction", Body: transaction.Transaction{},
+			Response: transaction.Transaction{}},
+		&openapi.Endpoint{Path: "/v1/openapi.json",
+			Summary: "OpenAPI document of the node", Response: openapi.Document{}},
+	)
+}
+
+// idParams are query parameters of an entity handler
+type idParams struct {
+	ID string `param:"id,required"`
+}
+
+// blockParams are query parameters of the block handler, the content is
+// comma separated list of 'full', 'header' and 'merkle_tree'
+type blockParams struct {
+	Block   string `param:"block,required"`
+	Content string `param:"content"`
+}
+
+/*BlockResponse - the block response, only the requested content parts
+* are present */
+type BlockResponse struct {
+	Block      *block.Block        `json:"block,omitempty"`
+	Header     *block.BlockSummary `json:"header,omitempty"`
+	MerkleTree []string            `json:"merkle_tree,omitempty"`
 }
 
 func DiagnosticsNodesHandler(w http.ResponseWriter, r *http.Request) {
@@ -78,7 +131,15 @@ func DiagnosticsNodesHandler(w http.ResponseWriter, r *http.Request) {
 
 /*GetChainHandler - given an id returns the chain information */
 func GetChainHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	return datastore.GetEntityHandler(ctx, r, chainEntityMetadata, "id")
+	var p idParams
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
+	entity := chainEntityMetadata.Instance()
+	if err := entity.Read(ctx, datastore.ToKey(p.ID)); err != nil {
+		return nil, err
+	}
+	return entity, nil
 }
 
 func LatestBlockFeeStatsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
@@ -106,13 +167,15 @@ func (c *Chain) GetShardersHandler(w http.ResponseWriter, r *http.Request) {
 
 /*GetBlockHandler - get the block from local cache */
 func GetBlockHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	hash := r.FormValue("block")
-	content := r.FormValue("content")
-	if content == "" {
-		content = "header"
+	var p blockParams
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
+	if p.Content == "" {
+		p.Content = "header"
 	}
-	parts := strings.Split(content, ",")
-	b, err := GetServerChain().GetBlock(ctx, hash)
+	parts := strings.Split(p.Content, ",")
+	b, err := GetServerChain().GetBlock(ctx, p.Block)
 	if err != nil {
 		return nil, err
 	}
@@ -383,24 +446,15 @@ func (c *Chain) infraHealthInATable(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</td>")
 	fmt.Fprintf(w, "</tr>")
 	if snt := node.Self.Underlying().Type; snt == node.NodeTypeMiner {
-		txn, ok := transaction.Provider().(*transaction.Transaction)
-		if ok {
-			transactionEntityMetadata := txn.GetEntityMetadata()
-			collectionName := txn.GetCollectionName()
-			ctx := common.GetRootContext()
-			cctx := memorystore.WithEntityConnection(ctx, transactionEntityMetadata)
-			defer memorystore.Close(cctx)
-			mstore, ok := transactionEntityMetadata.GetStore().(*memorystore.Store)
-			if ok {
-				fmt.Fprintf(w, "<tr class='active'>")
-				fmt.Fprintf(w, "<td>")
-				fmt.Fprintf(w, "Redis Collection")
-				fmt.Fprintf(w, "</td>")
-				fmt.Fprintf(w, "<td class='number'>")
-				fmt.Fprintf(w, "%v", mstore.GetCollectionSize(cctx, transactionEntityMetadata, collectionName))
-				fmt.Fprintf(w, "</td>")
-				fmt.Fprintf(w, "</tr>")
-			}
+		if size, ok := txnPoolSize(); ok {
+			fmt.Fprintf(w, "<tr class='active'>")
+			fmt.Fprintf(w, "<td>")
+			fmt.Fprintf(w, "Redis Collection")
+			fmt.Fprintf(w, "</td>")
+			fmt.Fprintf(w, "<td class='number'>")
+			fmt.Fprintf(w, "%v", size)
+			fmt.Fprintf(w, "</td>")
+			fmt.Fprintf(w, "</tr>")
 		}
 
 		var lfb = c.GetLatestFinalizedBlock()
@@ -415,21 +469,9 @@ func (c *Chain) infraHealthInATable(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "</tr>")
 
 	} else if snt == node.NodeTypeSharder {
-		var (
-			lfb       = c.GetLatestFinalizedBlock()
-			seri, err = c.GetBlockStateNode(lfb, minersc.PhaseKey)
-
-			phase    minersc.Phase = minersc.Unknown
-			restarts int64         = -1
-
-			pn minersc.PhaseNode
-		)
-
-		if err == nil {
-			if err = pn.Decode(seri.Encode()); err == nil {
-				phase = pn.Phase
-				restarts = pn.Restarts
-			}
+		var phase, restarts, ok = c.lfbPhase()
+		if !ok {
+			phase, restarts = minersc.Unknown, -1
 		}
 
 		fmt.Fprintf(w, "<tr class='active'>")
@@ -635,6 +677,7 @@ func DiagnosticsHomepageHandler(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "<li><a href='_diagnostics/round_info'>/_diagnostics/round_info</a>")
 	}
 	fmt.Fprintf(w, "<li><a href='_diagnostics/dkg_process'>/_diagnostics/dkg_process</a></li>")
+	fmt.Fprintf(w, "<li><a href='v1/diagnostics/journal'>/v1/diagnostics/journal</a></li>")
 	fmt.Fprintf(w, "</td>")
 
 	fmt.Fprintf(w, "<td valign='top'>")
@@ -0,0 +1,168 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"0chain.net/chaincore/config"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/logging"
+	"0chain.net/core/memorystore"
+	"0chain.net/core/metric"
+	"0chain.net/core/quota"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract/minersc"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"go.uber.org/zap"
+)
+
+// fetchStatTimeout limits waiting for the block fetcher statistic.
+const fetchStatTimeout = time.Second
+
+// MetricsHandler writes the go-metrics registry and the chain gauges
+// in the Prometheus text exposition format.
+func (c *Chain) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metric.PrometheusContentType)
+	var pw = metric.NewPrometheusWriter(w)
+	c.WriteMetrics(r.Context(), pw)
+	pw.Registry(metrics.DefaultRegistry)
+	if dropped := pw.Dropped(); len(dropped) > 0 {
+		logging.Logger.Debug("metrics - dropped duplicate names",
+			zap.Strings("names", dropped))
+	}
+}
+
+// WriteMetrics writes the chain gauges.
+func (c *Chain) WriteMetrics(ctx context.Context, pw *metric.PrometheusWriter) {
+	pw.Gauge("chain_current_round", "Current round.",
+		float64(c.GetCurrentRound()))
+	if lfb := c.GetLatestFinalizedBlock(); lfb != nil {
+		pw.Gauge("chain_lfb_round", "Latest finalized block round.",
+			float64(lfb.Round))
+	}
+
+	if size, ok := txnPoolSize(); ok {
+		pw.Gauge("chain_txn_pool_size", "Number of transactions in the pool.",
+			float64(size))
+	}
+
+	var failures []metric.Sample
+	for _, n := range node.CopyNodes() {
+		if node.Self.IsEqual(n) {
+			continue
+		}
+		failures = append(failures, metric.Sample{
+			Labels: metric.Labels{
+				"id":   n.ID,
+				"peer": n.GetPseudoName(),
+				"type": n.GetNodeTypeName(),
+			},
+			Value: float64(n.GetSendErrors()),
+		})
+	}
+	pw.GaugeVec("chain_n2n_send_failures", "Failed n2n sends to the peer.",
+		failures)
+
+	if ps := c.GetPruneStats(); ps != nil {
+		writePruneStats(pw, ps)
+	}
+
+	if q := quota.Get(); q != nil {
+		q.WriteMetrics(pw)
+	}
+
+	if config.DevConfiguration.ViewChange {
+		if phase, restarts, ok := c.lfbPhase(); ok {
+			pw.Gauge("chain_dkg_phase", "Current DKG phase: "+phasesHelp(),
+				float64(phase))
+			pw.Gauge("chain_dkg_restarts", "DKG restarts.", float64(restarts))
+		}
+	}
+
+	var cctx, cancel = context.WithTimeout(ctx, fetchStatTimeout)
+	defer cancel()
+	if fqs := c.FetchStat(cctx); cctx.Err() == nil {
+		pw.Gauge("chain_block_fetch_miners",
+			"Current block fetch requests to miners.", float64(fqs.Miners))
+		pw.Gauge("chain_block_fetch_sharders",
+			"Current block fetch requests to sharders.", float64(fqs.Sharders))
+	}
+}
+
+func writePruneStats(pw *metric.PrometheusWriter, ps *util.PruneStats) {
+	pw.GaugeVec("chain_prune_stage", "Current state pruning stage.",
+		[]metric.Sample{{Labels: metric.Labels{"stage": ps.Stage}, Value: 1}})
+	pw.Gauge("chain_prune_version", "State is pruned below the round.",
+		float64(ps.Version))
+	pw.Gauge("chain_prune_total_nodes", "State nodes visited.",
+		float64(ps.Total))
+	pw.Gauge("chain_prune_leaf_nodes", "Leaf state nodes visited.",
+		float64(ps.Leaves))
+	pw.Gauge("chain_prune_below_version_nodes",
+		"State nodes below the pruned round.", float64(ps.BelowVersion))
+	pw.Gauge("chain_prune_deleted_nodes", "Deleted state nodes.",
+		float64(ps.Deleted))
+	pw.Gauge("chain_prune_missing_nodes", "Missing state nodes.",
+		float64(ps.MissingNodes))
+	pw.Gauge("chain_prune_update_seconds", "Time of the update stage.",
+		ps.UpdateTime.Seconds())
+	pw.Gauge("chain_prune_delete_seconds", "Time of the delete stage.",
+		ps.DeleteTime.Seconds())
+}
+
+// lfbPhase returns DKG phase and restarts of the latest finalized block state.
+func (c *Chain) lfbPhase() (phase minersc.Phase, restarts int64, ok bool) {
+	var lfb = c.GetLatestFinalizedBlock()
+	if lfb == nil {
+		return
+	}
+	var seri, err = c.GetBlockStateNode(lfb, minersc.PhaseKey)
+	if err != nil {
+		return
+	}
+	var pn minersc.PhaseNode
+	if err = pn.Decode(seri.Encode()); err != nil {
+		return
+	}
+	return pn.Phase, pn.Restarts, true
+}
+
+// phasesHelp lists values of all the DKG phases in order of the view change
+// with the Complain and Justify phases, whose values follow the Wait one.
+func phasesHelp() string {
+	var phases []string
+	for ph := minersc.Start; ; {
+		phases = append(phases, fmt.Sprintf("%d=%s", ph, ph))
+		if ph = minersc.NextPhase(ph, true); ph == minersc.Start {
+			break
+		}
+	}
+	return strings.Join(phases, ", ")
+}
+
+// txnPoolSize returns size of the transactions pool of a miner.
+func txnPoolSize() (size int64, ok bool) {
+	if node.Self.Underlying().Type != node.NodeTypeMiner {
+		return
+	}
+	txn, ok := transaction.Provider().(*transaction.Transaction)
+	if !ok {
+		return
+	}
+	var (
+		meta   = txn.GetEntityMetadata()
+		cctx   = memorystore.WithEntityConnection(common.GetRootContext(), meta)
+		mstore *memorystore.Store
+	)
+	defer memorystore.Close(cctx)
+	if mstore, ok = meta.GetStore().(*memorystore.Store); !ok {
+		return
+	}
+	return mstore.GetCollectionSize(cctx, meta, txn.GetCollectionName()), true
+}
@@ -0,0 +1,12 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhasesHelp(t *testing.T) {
+	require.Equal(t, "0=start, 1=contribute, 2=share, 5=complain, "+
+		"6=justify, 3=publish, 4=wait", phasesHelp())
+}
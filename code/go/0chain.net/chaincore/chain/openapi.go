@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"0chain.net/chaincore/smartcontract"
+	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/core/common"
+	"0chain.net/core/openapi"
+)
+
+// APIVersion is version of the REST API described by the OpenAPI document.
+const APIVersion = "1.0.0"
+
+/*OpenAPIHandler - provides the OpenAPI document of the REST API of the node,
+* including the REST handlers of the smart contracts */
+func OpenAPIHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var endpoints = append(openapi.Registered(), scRestEndpoints()...)
+	return openapi.NewDocument(openapi.Info{
+		Title:       "0chain node API",
+		Description: "REST API of the miners and sharders",
+		Version:     APIVersion,
+	}, common.ErrorResponse{}, endpoints), nil
+}
+
+// scRestEndpoints returns the REST handlers of the smart contracts sorted
+// by the smart contract address and the handler path
+func scRestEndpoints() (endpoints []*openapi.Endpoint) {
+	var addresses = make([]string, 0, len(smartcontract.ContractMap))
+	for address := range smartcontract.ContractMap {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		var (
+			sc    = smartcontract.ContractMap[address]
+			name  = sc.GetName()
+			descs map[string]*sci.RestEndpoint
+			paths = make([]string, 0, len(sc.GetRestPoints()))
+		)
+		if rd, ok := sc.(sci.RestDescriber); ok {
+			descs = rd.GetRestEndpoints()
+		}
+		for path := range sc.GetRestPoints() {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			var ep = &openapi.Endpoint{
+				Path:        "/v1/screst/" + address + path,
+				OperationID: name + "_" + openapi.OperationID(path),
+				Tags:        []string{name},
+			}
+			if desc, ok := descs[path]; ok && desc != nil {
+				ep.Summary = desc.Summary
+				ep.Params = desc.Params
+				ep.Response = desc.Response
+			}
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return
+}
@@ -9,8 +9,12 @@ import (
 	"0chain.net/chaincore/node"
 
 	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/rpc"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/journal"
 	"0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	"go.uber.org/zap"
 )
 
@@ -126,38 +130,27 @@ func (c *Chain) reachedNotarization(round int64,
 		mb        = c.GetMagicBlock(round)
 		num       = mb.Miners.Size()
 		threshold = c.GetNotarizationThresholdCount(num)
+		verifiers = make([]datastore.Key, 0, len(bvt))
 	)
 
-	if c.ThresholdByCount > 0 {
-		var numSignatures = len(bvt)
-		if numSignatures < threshold {
-			logging.Logger.Info("not reached notarization",
-				zap.Int64("mb_sr", mb.StartingRound),
-				zap.Int("active_miners", num),
-				zap.Int("threshold", threshold),
-				zap.Int("num_signatures", numSignatures),
-				zap.Int64("current_round", c.GetCurrentRound()),
-				zap.Int64("round", round))
-			return false
-		}
-	}
-	if c.ThresholdByStake > 0 {
-		verifiersStake := 0
-		for _, ticket := range bvt {
-			verifiersStake += c.getMiningStake(ticket.VerifierID)
-		}
-		if verifiersStake < c.ThresholdByStake {
-			logging.Logger.Info("not reached notarization - stake < threshold stake",
-				zap.Int64("mb_sr", mb.StartingRound),
-				zap.Int("verify stake", verifiersStake),
-				zap.Int("threshold", c.ThresholdByStake),
-				zap.Int("active_miners", num),
-				zap.Int("num_signatures", len(bvt)),
-				zap.Int("signature threshold", threshold),
-				zap.Int64("current_round", c.GetCurrentRound()),
-				zap.Int64("round", round))
-			return false
-		}
+	for _, ticket := range bvt {
+		verifiers = append(verifiers, ticket.VerifierID)
+	}
+
+	if !c.reachedThreshold(mb, verifiers) {
+		var _, stake, total = c.reachedStakeThreshold(mb, verifiers)
+		logging.Logger.Info("not reached notarization",
+			zap.Int64("mb_sr", mb.StartingRound),
+			zap.Int("active_miners", num),
+			zap.Int("threshold", threshold),
+			zap.Int("num_signatures", len(bvt)),
+			zap.Int64("verify_stake", stake),
+			zap.Int64("total_stake", total),
+			zap.Int("threshold_by_stake", c.ThresholdByStake),
+			zap.Int8("threshold_mode", int8(c.ThresholdMode)),
+			zap.Int64("current_round", c.GetCurrentRound()),
+			zap.Int64("round", round))
+		return false
 	}
 
 	logging.Logger.Info("Reached notarization!!!",
@@ -224,6 +217,10 @@ func (c *Chain) MergeVerificationTickets(ctx context.Context, b *block.Block, vt
 }
 
 func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockStateHandler) {
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.finalize", fb.Round, fb.Hash)
+	defer span.Finish()
+
 	logging.Logger.Info("finalize block", zap.Int64("round", fb.Round), zap.Int64("current_round", c.GetCurrentRound()),
 		zap.Int64("lf_round", c.GetLatestFinalizedBlock().Round), zap.String("hash", fb.Hash),
 		zap.Int("round_rank", fb.RoundRank), zap.Int8("state", fb.GetBlockState()))
@@ -274,13 +271,22 @@ func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockSta
 
 	ssFTs = time.Now()
 	c.UpdateChainInfo(fb)
+	var fe = &journal.Event{Type: journal.BlockFinalized, Round: fb.Round,
+		Block: fb.Hash, Node: fb.MinerID,
+		Details: map[string]interface{}{
+			"txns":       len(fb.Txns),
+			"round_rank": fb.RoundRank,
+		}}
 	if err := c.SaveChanges(ctx, fb); err != nil {
 		logging.Logger.Error("Finaliz block save changes failed",
 			zap.Error(err),
 			zap.Int64("round", fb.Round),
 			zap.String("hash", fb.Hash))
+		fe.Error = err.Error()
+		journal.Record(fe)
 		return
 	}
+	journal.Record(fe)
 	c.rebaseState(fb)
 	c.updateFeeStats(fb)
 
@@ -295,6 +301,7 @@ func (c *Chain) finalizeBlock(ctx context.Context, fb *block.Block, bsh BlockSta
 		}
 	}
 	go bsh.UpdateFinalizedBlock(ctx, fb)
+	rpc.PublishFinalizedBlock(fb)
 	c.BlockChain.Value = fb.GetSummary()
 	c.BlockChain = c.BlockChain.Next()
 
@@ -331,10 +338,11 @@ func (c *Chain) IsFinalizedDeterministically(b *block.Block) bool {
 	if c.GetLatestFinalizedBlock().Round < b.Round {
 		return false
 	}
-	if len(b.UniqueBlockExtensions)*100 >= mb.Miners.Size()*c.ThresholdByCount {
-		return true
+	var extensions = make([]datastore.Key, 0, len(b.UniqueBlockExtensions))
+	for minerID := range b.UniqueBlockExtensions {
+		extensions = append(extensions, minerID)
 	}
-	return false
+	return c.reachedThreshold(mb, extensions)
 }
 
 // GetLocalPreviousBlock returns previous block for the block. Without a network
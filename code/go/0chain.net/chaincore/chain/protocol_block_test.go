@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/core/datastore"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStakeTestChain creates chain with magic block of 4 miners where first
+// miner has 70% of total stake and the rest have 10% each.
+func newStakeTestChain(t *testing.T, mode ConsensusThresholdMode) (
+	c *Chain, miners []datastore.Key) {
+
+	c = Provider().(*Chain)
+	c.ThresholdByCount = 66
+	c.ThresholdByStake = 66
+	c.ThresholdMode = mode
+
+	var mb = block.NewMagicBlock()
+	mb.StartingRound = 1
+	mb.Miners = node.NewPool(node.NodeTypeMiner)
+	mb.Sharders = node.NewPool(node.NodeTypeSharder)
+	mb.MinersStake = make(map[string]int64)
+
+	miners = []datastore.Key{"m1", "m2", "m3", "m4"}
+	for i, id := range miners {
+		var n = &node.Node{}
+		n.ID = id
+		n.SetIndex = i
+		mb.Miners.AddNode(n)
+		mb.MinersStake[id] = 10
+	}
+	mb.MinersStake["m1"] = 70
+	mb.Miners.ComputeProperties()
+
+	c.SetMagicBlock(mb)
+	return
+}
+
+func verificationTickets(ids ...datastore.Key) (
+	bvt []*block.VerificationTicket) {
+
+	for _, id := range ids {
+		bvt = append(bvt, &block.VerificationTicket{VerifierID: id})
+	}
+	return
+}
+
+func TestChain_reachedNotarization(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       ConsensusThresholdMode
+		stakeHeavy bool // m1 only
+		countHeavy bool // m2, m3, m4
+		all        bool
+	}{
+		{name: "count", mode: ThresholdModeCount,
+			stakeHeavy: false, countHeavy: true, all: true},
+		{name: "stake", mode: ThresholdModeStake,
+			stakeHeavy: true, countHeavy: false, all: true},
+		{name: "both", mode: ThresholdModeBoth,
+			stakeHeavy: false, countHeavy: false, all: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, miners := newStakeTestChain(t, tt.mode)
+			require.Equal(t, tt.stakeHeavy,
+				c.reachedNotarization(1, verificationTickets(miners[0])),
+				"stake-heavy minority")
+			require.Equal(t, tt.countHeavy,
+				c.reachedNotarization(1, verificationTickets(miners[1:]...)),
+				"count-heavy minority")
+			require.Equal(t, tt.all,
+				c.reachedNotarization(1, verificationTickets(miners...)),
+				"all miners")
+		})
+	}
+}
+
+func TestChain_reachedStakeThreshold(t *testing.T) {
+	c, miners := newStakeTestChain(t, ThresholdModeStake)
+	var mb = c.GetMagicBlock(1)
+
+	// duplicates and unknown miners are ignored
+	reached, stake, total := c.reachedStakeThreshold(mb,
+		[]datastore.Key{miners[1], miners[1], miners[2], "unknown"})
+	require.False(t, reached)
+	require.EqualValues(t, 20, stake)
+	require.EqualValues(t, 100, total)
+
+	// missing stake snapshot falls back to threshold by count
+	mb.MinersStake = nil
+	require.False(t, c.reachedNotarization(1, verificationTickets(miners[0])))
+	require.True(t, c.reachedNotarization(1, verificationTickets(miners[1:]...)))
+}
+
+func TestChain_reachedNotarizationAfterViewChange(t *testing.T) {
+	c, miners := newStakeTestChain(t, ThresholdModeStake)
+
+	// next magic block moves the stake to the last miner
+	var next = c.GetMagicBlock(1).Clone()
+	next.StartingRound = 101
+	next.MinersStake = map[string]int64{"m1": 10, "m2": 10, "m3": 10,
+		"m4": 70}
+	c.SetMagicBlock(next)
+
+	require.True(t, c.reachedNotarization(50, verificationTickets(miners[0])),
+		"previous magic block round uses its own stakes")
+	require.False(t, c.reachedNotarization(50, verificationTickets(miners[3])))
+	require.False(t, c.reachedNotarization(200, verificationTickets(miners[0])))
+	require.True(t, c.reachedNotarization(200, verificationTickets(miners[3])))
+}
+
+func TestChain_IsFinalizedDeterministicallyByStake(t *testing.T) {
+	c, miners := newStakeTestChain(t, ThresholdModeStake)
+
+	var b = block.NewBlock("", 1)
+	c.LatestFinalizedBlock = b
+
+	b.UniqueBlockExtensions = map[string]bool{miners[1]: true, miners[2]: true,
+		miners[3]: true}
+	require.False(t, c.IsFinalizedDeterministically(b))
+
+	b.UniqueBlockExtensions = map[string]bool{miners[0]: true}
+	require.True(t, c.IsFinalizedDeterministically(b))
+}
+
+func TestChain_reachedNotarizationByStake(t *testing.T) {
+	c, miners := newStakeTestChain(t, ThresholdModeStake)
+
+	for _, tt := range []struct {
+		name    string
+		signers []datastore.Key
+		want    bool
+	}{
+		{name: "no stake", signers: nil, want: false},
+		{name: "partial stake", signers: miners[1:], want: false},
+		{name: "threshold stake", signers: miners[:1], want: true},
+		{name: "full stake", signers: miners, want: true},
+	} {
+		require.Equal(t, tt.want,
+			c.reachedNotarization(1, verificationTickets(tt.signers...)),
+			tt.name)
+	}
+
+	// a zero stake threshold never notarizes
+	c.ThresholdByStake = 0
+	require.False(t, c.reachedNotarization(1, verificationTickets()))
+	require.False(t, c.reachedNotarization(1, verificationTickets(miners...)))
+}
+
+func TestConfig_thresholds(t *testing.T) {
+	for name, want := range map[string]ConsensusThresholdMode{
+		"count": ThresholdModeCount,
+		"stake": ThresholdModeStake,
+		"both":  ThresholdModeBoth,
+	} {
+		mode, err := parseThresholdMode(name)
+		require.NoError(t, err)
+		require.Equal(t, want, mode)
+	}
+	mode, err := parseThresholdMode("")
+	require.NoError(t, err)
+	require.Equal(t, ThresholdModeCount, mode, "default")
+	_, err = parseThresholdMode("stakes")
+	require.Error(t, err)
+
+	for _, tt := range []struct {
+		mode  ConsensusThresholdMode
+		stake int
+		ok    bool
+	}{
+		{ThresholdModeCount, 0, true},
+		{ThresholdModeStake, 0, false},
+		{ThresholdModeStake, 101, false},
+		{ThresholdModeStake, 66, true},
+		{ThresholdModeBoth, 0, false},
+		{ThresholdModeBoth, 100, true},
+	} {
+		var c = &Config{ThresholdMode: tt.mode, ThresholdByStake: tt.stake}
+		require.Equal(t, tt.ok, c.validateThresholds() == nil,
+			"mode %d, stake %d", tt.mode, tt.stake)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	"0chain.net/core/util"
 	"0chain.net/smartcontract/minersc"
 	metrics "github.com/rcrowley/go-metrics"
@@ -62,7 +63,15 @@ func (c *Chain) ComputeOrSyncState(ctx context.Context, b *block.Block) error {
 	return nil
 }
 
-func (c *Chain) computeState(ctx context.Context, b *block.Block) error {
+func (c *Chain) computeState(ctx context.Context, b *block.Block) (err error) {
+	if !b.IsStateComputed() {
+		var span *tracing.Span
+		ctx, span = tracing.StartSpan(ctx, "state.compute", b.Round, b.Hash)
+		defer func() {
+			span.SetError(err)
+			span.Finish()
+		}()
+	}
 	return b.ComputeState(ctx, c)
 }
 
@@ -198,6 +207,9 @@ func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transactio
 		return fmt.Errorf("invalid transaction type: %v", txn.TransactionType)
 	}
 
+	// recipients of the transaction transfers, excluding the fee below
+	var recipients = getTransferRecipients(sctx)
+
 	if config.DevConfiguration.IsFeeEnabled {
 		err = sctx.AddTransfer(state.NewTransfer(txn.ClientID, minersc.ADDRESS,
 			state.Balance(txn.Fee)))
@@ -261,10 +273,36 @@ func (c *Chain) updateState(ctx context.Context, b *block.Block, txn *transactio
 		}
 	}
 
+	if len(recipients) > 0 {
+		b.AddTxnTransfers(txn.Hash, recipients)
+	}
+
 	txn.Status = transaction.TxnSuccess
 	return
 }
 
+// getTransferRecipients returns unique recipients of transfers, signed
+// transfers and mints of given state context.
+func getTransferRecipients(sctx bcstate.StateContextI) (recipients []string) {
+	var seen = make(map[string]bool)
+	var add = func(id datastore.Key) {
+		if !seen[id] {
+			seen[id] = true
+			recipients = append(recipients, id)
+		}
+	}
+	for _, t := range sctx.GetTransfers() {
+		add(t.ToClientID)
+	}
+	for _, t := range sctx.GetSignedTransfers() {
+		add(t.ToClientID)
+	}
+	for _, m := range sctx.GetMints() {
+		add(m.ToClientID)
+	}
+	return
+}
+
 /*
 * transferAmount - transfers balance from one account to another
 *   when there is an error getting the state of the from or to account (other than no value), the error is simply returned back
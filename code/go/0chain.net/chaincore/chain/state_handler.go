@@ -11,10 +11,12 @@ import (
 	"strings"
 
 	"0chain.net/chaincore/smartcontract"
+	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 
 	"0chain.net/core/common"
 	"0chain.net/core/encryption"
+	"0chain.net/core/openapi"
 	"0chain.net/core/util"
 )
 
@@ -26,12 +28,36 @@ func SetupStateHandlers() {
 	http.HandleFunc("/v1/scstats/", common.UserRateLimit(c.GetSCStats))
 	http.HandleFunc("/v1/screst/", common.UserRateLimit(c.HandleSCRest))
 	http.HandleFunc("/_smart_contract_stats", common.UserRateLimit(c.SCStats))
+
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/client/get/balance",
+			Summary: "balance of the client", Params: balanceParams{},
+			Response: state.State{}},
+		&openapi.Endpoint{Path: "/v1/scstate/get",
+			Summary: "value of the smart contract state key",
+			Params:  scStateParams{}},
+		&openapi.Endpoint{Path: "/v1/scstats/{sc_address}",
+			Summary:     "statistics page of the smart contract",
+			ContentType: "text/html"},
+	)
+}
+
+// balanceParams are query parameters of the balance handler
+type balanceParams struct {
+	ClientID string `param:"client_id"`
+}
+
+// scStateParams are query parameters of the smart contract state handler
+type scStateParams struct {
+	SCAddress string `param:"sc_address"`
+	Key       string `param:"key"`
 }
 
 func (c *Chain) HandleSCRest(w http.ResponseWriter, r *http.Request) {
 	scRestRE := regexp.MustCompile(`/v1/screst/(.*)`)
 	pathParams := scRestRE.FindStringSubmatch(r.URL.Path)
 	if len(pathParams) < 2 {
+		common.Respond(w, r, nil, common.NewErrBadRequest("invalid Rest API path"))
 		return
 	}
 
@@ -50,7 +76,7 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 	scRestRE := regexp.MustCompile(`/v1/screst/(.*)?/(.*)`)
 	pathParams := scRestRE.FindStringSubmatch(r.URL.Path)
 	if len(pathParams) < 3 {
-		return nil, common.NewError("invalid_path", "Invalid Rest API path")
+		return nil, common.NewErrBadRequest("invalid Rest API path")
 	}
 
 	scAddress := pathParams[1]
@@ -74,8 +100,11 @@ func (c *Chain) GetSCRestOutput(ctx context.Context, r *http.Request) (interface
 }
 
 func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interface{}, error) {
-	scAddress := r.FormValue("sc_address")
-	key := r.FormValue("key")
+	var p scStateParams
+	if err := common.DecodeParams(r.URL.Query(), &p); err != nil {
+		return nil, err
+	}
+	scAddress, key := p.SCAddress, p.Key
 	lfb := c.GetLatestFinalizedBlock()
 	if lfb == nil {
 		return nil, common.NewError("failed to get sc state", "finalized block doesn't exist")
@@ -102,12 +131,15 @@ func (c *Chain) GetNodeFromSCState(ctx context.Context, r *http.Request) (interf
 
 /*GetBalanceHandler - get the balance of a client */
 func (c *Chain) GetBalanceHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	clientID := r.FormValue("client_id")
+	var p balanceParams
+	if err := common.DecodeParams(r.URL.Query(), &p); err != nil {
+		return nil, err
+	}
 	lfb := c.GetLatestFinalizedBlock()
 	if lfb == nil {
 		return nil, common.ErrTemporaryFailure
 	}
-	state, err := c.GetState(lfb, clientID)
+	state, err := c.GetState(lfb, p.ClientID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,6 +189,7 @@ func (c *Chain) GetSCRestPoints(w http.ResponseWriter, r *http.Request) {
 	key := pathParams[1]
 	scInt, ok := smartcontract.ContractMap[key]
 	if !ok {
+		common.Respond(w, r, nil, common.NewError("invalid_sc", "Invalid Smart contract address"))
 		return
 	}
 	PrintCSS(w)
@@ -1,6 +1,7 @@
 package chain_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -24,6 +25,7 @@ import (
 	"0chain.net/core/encryption"
 	"0chain.net/core/logging"
 	"0chain.net/core/memorystore"
+	"0chain.net/core/openapi"
 	"0chain.net/core/util"
 	"0chain.net/core/viper"
 	"0chain.net/smartcontract/faucetsc"
@@ -1801,7 +1803,8 @@ func TestGetSCRestOutput(t *testing.T) {
 			body := w.Body.String()
 			sc := smartcontract.ContractMap[test.address]
 			if test.empty {
-				require.EqualValues(t, body, "")
+				require.EqualValues(t, http.StatusBadRequest, w.Code)
+				require.JSONEq(t, `{"code":"invalid_sc","error":"invalid_sc: Invalid Smart contract address"}`, body)
 				return
 			}
 			restPoints := sc.GetRestPoints()
@@ -1809,3 +1812,32 @@ func TestGetSCRestOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenAPIHandler(t *testing.T) {
+	resp, err := chain.OpenAPIHandler(context.Background(),
+		httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+	require.NoError(t, err)
+	_, err = json.Marshal(resp)
+	require.NoError(t, err)
+
+	doc, ok := resp.(*openapi.Document)
+	require.True(t, ok)
+	assert.Equal(t, chain.APIVersion, doc.Info.Version)
+
+	op := doc.Paths["/v1/screst/"+minersc.ADDRESS+"/nodePoolStat"]
+	require.NotNil(t, op)
+	require.NotNil(t, op.Get)
+	assert.Equal(t, "miner_nodePoolStat", op.Get.OperationID)
+	assert.Equal(t, []string{"miner"}, op.Get.Tags)
+	require.Len(t, op.Get.Parameters, 2)
+	assert.Equal(t, "id", op.Get.Parameters[0].Name)
+	assert.Equal(t, "pool_id", op.Get.Parameters[1].Name)
+	assert.Equal(t, "#/components/schemas/smartcontractinterface.DelegatePool",
+		op.Get.Responses["200"].Content["application/json"].Schema.Ref)
+
+	for address, sc := range smartcontract.ContractMap {
+		for path := range sc.GetRestPoints() {
+			assert.NotNil(t, doc.Paths["/v1/screst/"+address+path], path)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/memorystore"
+	"0chain.net/core/openapi"
 )
 
 /*SetupHandlers sets up the necessary API end points */
@@ -21,9 +22,29 @@ func SetupHandlers() {
 				memorystore.WithConnectionEntityJSONHandler(
 					PutClient, clientEntityMetadata),
 				clientEntityMetadata)))
+
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/client/get", Summary: "client by ID",
+			Params: idParams{}, Response: Client{}},
+		&openapi.Endpoint{Method: http.MethodPost, Path: "/v1/client/put",
+			Summary: "register client", Body: Client{}, Response: Client{}},
+	)
+}
+
+// idParams are query parameters of the client handler
+type idParams struct {
+	ID string `param:"id,required"`
 }
 
 /*GetClientHandler - given an id returns the client information */
 func GetClientHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	return datastore.GetEntityHandler(ctx, r, clientEntityMetadata, "id")
+	var p idParams
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
+	entity := clientEntityMetadata.Instance()
+	if err := entity.Read(ctx, datastore.ToKey(p.ID)); err != nil {
+		return nil, err
+	}
+	return entity, nil
 }
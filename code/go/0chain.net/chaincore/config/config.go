@@ -21,11 +21,14 @@ func SetupDefaultConfig() {
 	viper.SetDefault("network.timeout.small_message", 500)
 	viper.SetDefault("network.timeout.large_message", 1000)
 	viper.SetDefault("network.large_message_th_size", 10240)
+	viper.SetDefault("network.max_observers", 16)
+	viper.SetDefault("network.n2n_transport", "http")
 	viper.SetDefault("server_chain.messages.verification_tickets_to", "generator")
 	viper.SetDefault("server_chain.round_range", 10000000)
 	viper.SetDefault("server_chain.transaction.payload.max_size", 32)
 	viper.SetDefault("server_chain.state.prune_below_count", 100)
 	viper.SetDefault("server_chain.block.consensus.threshold_by_count", 66)
+	viper.SetDefault("server_chain.block.consensus.threshold_mode", "count")
 	viper.SetDefault("server_chain.block.generation.timeout", 37)
 	viper.SetDefault("server_chain.state.sync.timeout", 10)
 	viper.SetDefault("server_chain.stuck.check_interval", 10)
@@ -65,6 +68,14 @@ func SetupDefaultConfig() {
 	viper.SetDefault("server_chain.health_check.proximity_scan.repeat_interval_mins", 60)
 	viper.SetDefault("server_chain.health_check.deep_scan.report_status_mins", 15)
 
+	// Replication audit.
+	viper.SetDefault("server_chain.health_check.replication_audit.enabled", false)
+	viper.SetDefault("server_chain.health_check.replication_audit.window", 100000)
+	viper.SetDefault("server_chain.health_check.replication_audit.settle_rounds", 100)
+	viper.SetDefault("server_chain.health_check.replication_audit.sample_size", 100)
+	viper.SetDefault("server_chain.health_check.replication_audit.repeat_interval_mins", 30)
+	viper.SetDefault("server_chain.health_check.replication_audit.repair", true)
+
 	// LFB tickets.
 	viper.SetDefault("server_chain.lfb_ticket.rebroadcast_timeout", time.Second*16)
 	viper.SetDefault("server_chain.lfb_ticket.ahead", 2)
@@ -81,6 +92,9 @@ func SetupConfig() {
 	if err := viper.ReadConfigFile(file); err != nil {
 		panic(fmt.Errorf("fatal error config file: %s", err))
 	}
+	if err := trackConfigFile("0chain.yaml", file, viper.GetViper(), false); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
 	setupDevConfig()
 }
 
@@ -94,16 +108,23 @@ func SetupDefaultSmartContractConfig() {
 	SmartContractConfig.SetDefault("smart_contracts.interestpoolsc.lock_period", "2160h")
 	SmartContractConfig.SetDefault("smart_contracts.interestpoolsc.interest_rate", 0.01)
 
+	SmartContractConfig.SetDefault("smart_contracts.minersc.complain_rounds", 25)
+	SmartContractConfig.SetDefault("smart_contracts.minersc.justify_rounds", 25)
+
 	SmartContractConfig.SetDefault("smart_contracts.storagesc.challenge_enabled", true)
 	SmartContractConfig.SetDefault("smart_contracts.storagesc.challenge_rate_per_mb_min", 1)
 }
 
 // SetupSmartContractConfig setups the smart contracts configuration system.
 func SetupSmartContractConfig() {
+	SetupDefaultSmartContractConfig()
 	file := filepath.Join(".", "config", "sc.yaml")
 	if err := SmartContractConfig.ReadConfigFile(file); err != nil {
 		panic(fmt.Errorf("fatal error config file: %s", err))
 	}
+	if err := trackConfigFile("sc.yaml", file, SmartContractConfig, true); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
 }
 
 // ReadConfig reads a configuration from given file path.
@@ -1,26 +1,103 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 
 	"gopkg.in/yaml.v2"
 
+	"0chain.net/core/common"
+	"0chain.net/core/openapi"
 	"0chain.net/core/viper"
 )
 
+// ReloadKeyHeader is the header of the reload.admin_key.
+const ReloadKeyHeader = "X-Admin-Key"
+
 /*SetupHandlers - setup config related handlers */
 func SetupHandlers() {
 	http.HandleFunc("/v1/config/get", GetConfigHandler)
+	http.HandleFunc("/v1/config/reload", common.UserRateLimit(common.ToJSONResponse(ReloadHandler)))
+
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/config/reload",
+			OperationID: "v1_config_reload_diff",
+			Summary:     "changes of the configuration files, not applied",
+			Response:    ReloadResult{}},
+		&openapi.Endpoint{Method: http.MethodPost, Path: "/v1/config/reload",
+			Summary: "reload the node-local configurations", Response: ReloadResult{}},
+	)
 }
 
-/*GetConfigHandler - display configuration */
+/*GetConfigHandler - display configuration, values of the sensitive keys are
+* masked */
 func GetConfigHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain;charset=UTF-8")
-	c := viper.AllSettings()
+	c := maskSettings(viper.AllSettings())
 	bs, err := yaml.Marshal(c)
 	if err != nil {
 		fmt.Fprintf(w, err.Error())
 	}
 	fmt.Fprintf(w, "%v", string(bs))
 }
+
+// maskSettings returns copy of the nested settings with values of the
+// sensitive keys masked
+func maskSettings(settings map[string]interface{}) map[string]interface{} {
+	var masked = make(map[string]interface{}, len(settings))
+	for key, val := range settings {
+		if isSensitive(key) {
+			masked[key] = maskedValue
+			continue
+		}
+		masked[key] = maskValue(val)
+	}
+	return masked
+}
+
+func maskValue(val interface{}) interface{} {
+	switch tv := val.(type) {
+	case map[string]interface{}:
+		return maskSettings(tv)
+	case map[interface{}]interface{}:
+		var settings = make(map[string]interface{}, len(tv))
+		for k, v := range tv {
+			settings[fmt.Sprint(k)] = v
+		}
+		return maskSettings(settings)
+	case []interface{}:
+		var list = make([]interface{}, 0, len(tv))
+		for _, v := range tv {
+			list = append(list, maskValue(v))
+		}
+		return list
+	}
+	return val
+}
+
+/*ReloadHandler - show (GET) or apply (POST) the changes of the configuration
+* files, only the clients of the reload.allowed_networks can use it, with the
+* reload.admin_key in the X-Admin-Key header if the key is set. The client
+* address is the address of the connection, behind a reverse proxy on the
+* same host all the clients are the loopback ones, the key is required then */
+func ReloadHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !reloadAllowed(ip, r.Header.Get(ReloadKeyHeader)) {
+		return nil, common.NewError(common.ErrForbiddenCode,
+			"configuration reload is not allowed from "+host)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		return Reload(true)
+	case http.MethodPost:
+		return Reload(false)
+	}
+	return nil, common.NewErrBadRequest("method must be GET or POST")
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/viper"
+)
+
+func TestGetConfigHandler(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		path  = filepath.Join(dir, "0chain.yaml")
+		empty = filepath.Join(dir, "empty.yaml")
+	)
+	require.NoError(t, ioutil.WriteFile(empty, nil, 0644))
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+network:
+  user_handlers:
+    quota:
+      enabled: true
+      api_keys:
+        - key: "quota-secret"
+          name: explorer
+      endpoints:
+        - path: /v1/screst/
+          cost: 5
+minio:
+  secret_access_key: "minio-secret"
+reload:
+  allowed_networks: ["127.0.0.0/8"]
+  admin_key: "reload-secret"
+`), 0644))
+	require.NoError(t, viper.ReadConfigFile(path))
+	t.Cleanup(func() {
+		// the reload tests read the global configurations too
+		require.NoError(t, viper.ReadConfigFile(empty))
+	})
+
+	var w = httptest.NewRecorder()
+	GetConfigHandler(w, httptest.NewRequest(http.MethodGet, "/v1/config/get",
+		nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body = w.Body.String()
+	assert.NotContains(t, body, "quota-secret")
+	assert.NotContains(t, body, "minio-secret")
+	assert.NotContains(t, body, "reload-secret", "the admin key is masked")
+	assert.Contains(t, body, `admin_key: '***'`)
+	assert.Contains(t, body, `api_keys: '***'`)
+	assert.Contains(t, body, "path: /v1/screst/")
+	assert.Contains(t, body, "enabled: true")
+}
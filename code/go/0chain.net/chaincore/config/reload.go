@@ -0,0 +1,467 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/core/common"
+	"0chain.net/core/logging"
+	"0chain.net/core/viper"
+)
+
+// Statuses of a changed key of the configurations.
+const (
+	// ReloadableKey is changed at runtime.
+	ReloadableKey = "reloadable"
+	// ConsensusCriticalKey must be the same on all the nodes, it can't be
+	// changed at runtime.
+	ConsensusCriticalKey = "consensus_critical"
+	// RestartRequiredKey is read at start of the node only.
+	RestartRequiredKey = "restart_required"
+)
+
+// maskedValue replaces the values of the sensitive keys in the changes
+const maskedValue = "***"
+
+// consensusCritical are prefixes of the keys of the 0chain.yaml that must be
+// the same on all the nodes, all the keys of the sc.yaml are critical too
+var consensusCritical = []string{
+	"server_chain.id",
+	"server_chain.owner",
+	"server_chain.decimals",
+	"server_chain.tokens",
+	"server_chain.genesis_block",
+	"server_chain.block",
+	"server_chain.round_range",
+	"server_chain.transaction",
+	"server_chain.client",
+	"server_chain.messages",
+	"server_chain.state",
+	"server_chain.smart_contract",
+	"server_chain.view_change",
+	"server_chain.dkg",
+	"development",
+	"network.magic_block_file",
+	"network.genesis_dkg",
+}
+
+// sensitive are substrings of the last part of the keys whose values are
+// not shown in the changes and in the configurations
+var sensitive = []string{"secret", "password", "private", "access_key",
+	"api_keys", "admin_key"}
+
+// ConfigChange is a changed key of a configuration file.
+type ConfigChange struct {
+	File string `json:"file"`
+	Key  string `json:"key"`
+	// Old value of the key, nil if the key is added.
+	Old interface{} `json:"old"`
+	// New value of the key, nil if the key is removed.
+	New    interface{} `json:"new"`
+	Status string      `json:"status"`
+}
+
+// ReloadResult is the changes of the configuration files. Either all the
+// changes are applied, or none of them.
+type ReloadResult struct {
+	Changes []*ConfigChange `json:"changes"`
+	// Applied is true if the changes are applied.
+	Applied bool `json:"applied"`
+	// DryRun is true if the changes are only shown.
+	DryRun bool `json:"dry_run"`
+	// Error is reason of the rejection of the changes.
+	Error string `json:"error,omitempty"`
+}
+
+// reloadable keys of given prefixes
+type reloadable struct {
+	prefixes []string
+	apply    func() error
+}
+
+// configFile is a configuration file that can be reloaded
+type configFile struct {
+	name     string
+	path     string
+	v        *viper.Viper           // the configuration used by the node
+	critical bool                   // all the keys are consensus critical
+	blob     []byte                 // content of the file loaded last
+	settings map[string]interface{} // keys of the file loaded last
+}
+
+var (
+	reloadMu    sync.Mutex
+	reloadables = make(map[string]*reloadable) // prefix -> reloadable
+	configFiles []*configFile
+
+	// networks allowed to use the reload endpoint
+	reloadNetworks []*net.IPNet
+	// key required by the reload endpoint in addition, if not empty
+	reloadAdminKey string
+)
+
+// SetupReload reads the 'reload' section of the configurations, registers
+// the reloadable logging level and rate limits and starts watching the
+// configuration files if enabled.
+func SetupReload(ctx context.Context) error {
+	var networks []*net.IPNet
+	for _, cidr := range viper.GetStringSlice("reload.allowed_networks") {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("config: reload.allowed_networks: %v", err)
+		}
+		networks = append(networks, n)
+	}
+
+	RegisterReloadable(func() error {
+		return logging.SetLevel(viper.GetString("logging.level"))
+	}, "logging.level")
+	RegisterReloadable(func() error {
+		common.ConfigRateLimits()
+		return nil
+	}, "network.user_handlers.rate_limit", "network.n2n_handlers.rate_limit")
+	RegisterReloadable(nil, "server_chain.lfb_ticket", "async_blocks_fetching")
+
+	reloadMu.Lock()
+	reloadNetworks = networks
+	reloadAdminKey = viper.GetString("reload.admin_key")
+	reloadMu.Unlock()
+
+	if interval := viper.GetDuration("reload.watch_interval"); interval > 0 {
+		go WatchConfig(ctx, interval)
+	}
+	return nil
+}
+
+// reloadAllowed reports whether the IP is allowed to use the reload endpoint
+// with given admin key
+func reloadAllowed(ip net.IP, key string) bool {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if reloadAdminKey != "" &&
+		subtle.ConstantTimeCompare([]byte(key), []byte(reloadAdminKey)) != 1 {
+		return false
+	}
+	for _, n := range reloadNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterReloadable registers the keys of the node-local settings that can
+// be changed at runtime, those are the keys of given prefixes. The apply is
+// called once the configuration has the new values of the keys, it may be
+// nil if the values are read from the configuration on use. A failed apply
+// reverts all the changes. Registration of a prefix replaces its previous
+// registration.
+func RegisterReloadable(apply func() error, prefixes ...string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	var r = &reloadable{prefixes: prefixes, apply: apply}
+	for _, prefix := range prefixes {
+		reloadables[prefix] = r
+	}
+}
+
+// trackConfigFile makes the configuration file loaded into given
+// configuration reloadable
+func trackConfigFile(name, path string, v *viper.Viper, critical bool) error {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	settings, err := parseConfig(path, blob)
+	if err != nil {
+		return err
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	for _, f := range configFiles {
+		if f.name == name {
+			f.path, f.v, f.critical = path, v, critical
+			f.blob, f.settings = blob, settings
+			return nil
+		}
+	}
+	configFiles = append(configFiles, &configFile{name: name, path: path,
+		v: v, critical: critical, blob: blob, settings: settings})
+	return nil
+}
+
+// Reload reads the configuration files again and applies their changes if
+// all the changed keys are reloadable. Nothing is applied for a dry run.
+func Reload(dryRun bool) (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	type update struct {
+		f        *configFile
+		blob     []byte
+		settings map[string]interface{}
+	}
+	var (
+		res      = &ReloadResult{Changes: []*ConfigChange{}, DryRun: dryRun}
+		updates  []*update
+		rejected = make(map[string][]string) // status -> keys
+		applies  []*reloadable
+		seen     = make(map[*reloadable]bool)
+	)
+	for _, f := range configFiles {
+		blob, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+		settings, err := parseConfig(f.path, blob)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %v", f.name, err)
+		}
+		var changes = diffSettings(f.name, f.settings, settings)
+		if len(changes) == 0 {
+			continue
+		}
+		for _, c := range changes {
+			var r *reloadable
+			c.Status, r = keyStatus(c.Key, f.critical)
+			if c.Status != ReloadableKey {
+				rejected[c.Status] = append(rejected[c.Status], c.Key)
+				continue
+			}
+			if r.apply != nil && !seen[r] {
+				seen[r] = true
+				applies = append(applies, r)
+			}
+		}
+		res.Changes = append(res.Changes, changes...)
+		updates = append(updates, &update{f: f, blob: blob, settings: settings})
+	}
+
+	if len(rejected) > 0 {
+		var reasons []string
+		if keys := rejected[ConsensusCriticalKey]; len(keys) > 0 {
+			reasons = append(reasons, "consensus critical keys can't change "+
+				"at runtime: "+strings.Join(keys, ", "))
+		}
+		if keys := rejected[RestartRequiredKey]; len(keys) > 0 {
+			reasons = append(reasons, "keys requiring restart of the node: "+
+				strings.Join(keys, ", "))
+		}
+		res.Error = strings.Join(reasons, "; ")
+		return res, nil
+	}
+	if dryRun || len(updates) == 0 {
+		return res, nil
+	}
+
+	for _, u := range updates {
+		if err := u.f.v.ReadConfig(bytes.NewReader(u.blob)); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range applies {
+		if err := r.apply(); err != nil {
+			// revert all the changes
+			for _, u := range updates {
+				if rerr := u.f.v.ReadConfig(bytes.NewReader(u.f.blob)); rerr != nil {
+					logging.Logger.Error("config reload - revert",
+						zap.String("file", u.f.name), zap.Error(rerr))
+				}
+			}
+			for _, r := range applies {
+				if rerr := r.apply(); rerr != nil {
+					logging.Logger.Error("config reload - revert",
+						zap.Strings("keys", r.prefixes), zap.Error(rerr))
+				}
+			}
+			res.Error = fmt.Sprintf("%s: %v", strings.Join(r.prefixes, ", "), err)
+			return res, nil
+		}
+	}
+	for _, u := range updates {
+		u.f.blob, u.f.settings = u.blob, u.settings
+	}
+	res.Applied = true
+	return res, nil
+}
+
+// keyStatus of a changed key and its registration if it's reloadable, the
+// most specific prefix of the key is used
+func keyStatus(key string, critical bool) (string, *reloadable) {
+	if critical {
+		return ConsensusCriticalKey, nil
+	}
+	var (
+		match string
+		r     *reloadable
+	)
+	for prefix, rl := range reloadables {
+		if hasKeyPrefix(key, prefix) && len(prefix) > len(match) {
+			match, r = prefix, rl
+		}
+	}
+	if r != nil {
+		return ReloadableKey, r
+	}
+	for _, prefix := range consensusCritical {
+		if hasKeyPrefix(key, prefix) {
+			return ConsensusCriticalKey, nil
+		}
+	}
+	return RestartRequiredKey, nil
+}
+
+// hasKeyPrefix reports whether the key is the prefix or a key of its section
+func hasKeyPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+".")
+}
+
+// parseConfig returns all the keys of the configuration file with their
+// values
+func parseConfig(path string, blob []byte) (map[string]interface{}, error) {
+	var v = viper.New()
+	v.Instance().SetConfigType(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err := v.ReadConfig(bytes.NewReader(blob)); err != nil {
+		return nil, err
+	}
+	var settings = make(map[string]interface{})
+	for _, key := range v.AllKeys() {
+		settings[key] = v.Get(key)
+	}
+	return settings, nil
+}
+
+// diffSettings returns the changed keys sorted by the key
+func diffSettings(file string, old, new map[string]interface{}) []*ConfigChange {
+	var changes []*ConfigChange
+	for key, nv := range new {
+		if ov, ok := old[key]; !ok || !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, newConfigChange(file, key, ov, nv))
+		}
+	}
+	for key, ov := range old {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, newConfigChange(file, key, ov, nil))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func newConfigChange(file, key string, old, new interface{}) *ConfigChange {
+	var c = &ConfigChange{File: file, Key: key, Old: jsonValue(old),
+		New: jsonValue(new)}
+	if isSensitive(key[strings.LastIndexByte(key, '.')+1:]) {
+		if c.Old != nil {
+			c.Old = maskedValue
+		}
+		if c.New != nil {
+			c.New = maskedValue
+		}
+	}
+	return c
+}
+
+// isSensitive reports whether value of the key of given name is hidden
+func isSensitive(name string) bool {
+	for _, s := range sensitive {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue converts the nested YAML maps of the value to the JSON objects
+func jsonValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case map[interface{}]interface{}:
+		var m = make(map[string]interface{}, len(tv))
+		for k, e := range tv {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case map[string]interface{}:
+		var m = make(map[string]interface{}, len(tv))
+		for k, e := range tv {
+			m[k] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		var s = make([]interface{}, len(tv))
+		for i, e := range tv {
+			s[i] = jsonValue(e)
+		}
+		return s
+	}
+	return v
+}
+
+// WatchConfig reloads the configuration files when they change, the files
+// are checked every given interval.
+func WatchConfig(ctx context.Context, interval time.Duration) {
+	var (
+		ticker = time.NewTicker(interval)
+		sums   = configSums()
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var current = configSums()
+		if reflect.DeepEqual(sums, current) {
+			continue
+		}
+		sums = current
+
+		res, err := Reload(false)
+		switch {
+		case err != nil:
+			logging.Logger.Error("config reload", zap.Error(err))
+		case res.Error != "":
+			logging.Logger.Warn("config reload - rejected",
+				zap.String("error", res.Error))
+		case res.Applied:
+			logging.Logger.Info("config reload - applied",
+				zap.Int("changes", len(res.Changes)))
+		}
+	}
+}
+
+// configSums returns checksums of the configuration files, an unreadable
+// file has no checksum
+func configSums() map[string][sha256.Size]byte {
+	reloadMu.Lock()
+	var paths = make(map[string]string, len(configFiles))
+	for _, f := range configFiles {
+		paths[f.name] = f.path
+	}
+	reloadMu.Unlock()
+
+	var sums = make(map[string][sha256.Size]byte, len(paths))
+	for name, path := range paths {
+		if blob, err := ioutil.ReadFile(path); err == nil {
+			sums[name] = sha256.Sum256(blob)
+		}
+	}
+	return sums
+}
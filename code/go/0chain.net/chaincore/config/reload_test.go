@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/common"
+	"0chain.net/core/viper"
+)
+
+const testConfig = `
+logging:
+  level: info
+server_chain:
+  block:
+    max_block_size: 100
+    proposal:
+      max_wait_time: 180
+network:
+  user_handlers:
+    quota:
+      api_keys:
+        - key: secret
+`
+
+// setupTestReload tracks the configuration files of a test, it returns
+// the configurations and writer of the files
+func setupTestReload(t *testing.T) (node, sc *viper.Viper,
+	write func(name, content string)) {
+
+	var dir = t.TempDir()
+	write = func(name, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name),
+			[]byte(content), 0644))
+	}
+	write("0chain.yaml", testConfig)
+	write("sc.yaml", "smart_contracts:\n  minersc:\n    max_n: 7\n")
+
+	reloadMu.Lock()
+	configFiles, reloadables = nil, make(map[string]*reloadable)
+	reloadMu.Unlock()
+	t.Cleanup(func() {
+		reloadMu.Lock()
+		configFiles, reloadables = nil, make(map[string]*reloadable)
+		reloadNetworks, reloadAdminKey = nil, ""
+		reloadMu.Unlock()
+	})
+
+	node, sc = viper.New(), viper.New()
+	for _, f := range []struct {
+		name     string
+		v        *viper.Viper
+		critical bool
+	}{{"0chain.yaml", node, false}, {"sc.yaml", sc, true}} {
+		var path = filepath.Join(dir, f.name)
+		require.NoError(t, f.v.ReadConfigFile(path))
+		require.NoError(t, trackConfigFile(f.name, path, f.v, f.critical))
+	}
+	return node, sc, write
+}
+
+func TestReload(t *testing.T) {
+	var (
+		node, _, write = setupTestReload(t)
+		levels         []string
+	)
+	RegisterReloadable(func() error {
+		levels = append(levels, node.GetString("logging.level"))
+		return nil
+	}, "logging.level")
+	RegisterReloadable(nil, "server_chain.block.proposal")
+
+	res, err := Reload(false)
+	require.NoError(t, err)
+	assert.Equal(t, &ReloadResult{Changes: []*ConfigChange{}}, res)
+
+	write("0chain.yaml", `
+logging:
+  level: debug
+server_chain:
+  block:
+    max_block_size: 100
+    proposal:
+      max_wait_time: 250
+network:
+  user_handlers:
+    quota:
+      api_keys:
+        - key: secret
+`)
+	var changes = []*ConfigChange{
+		{File: "0chain.yaml", Key: "logging.level", Old: "info", New: "debug",
+			Status: ReloadableKey},
+		{File: "0chain.yaml", Key: "server_chain.block.proposal.max_wait_time",
+			Old: 180, New: 250, Status: ReloadableKey},
+	}
+	res, err = Reload(true)
+	require.NoError(t, err)
+	assert.Equal(t, &ReloadResult{Changes: changes, DryRun: true}, res)
+	assert.Equal(t, "info", node.GetString("logging.level"), "dry run")
+
+	res, err = Reload(false)
+	require.NoError(t, err)
+	assert.Equal(t, &ReloadResult{Changes: changes, Applied: true}, res)
+	assert.Equal(t, "debug", node.GetString("logging.level"))
+	assert.Equal(t, 250, node.GetInt("server_chain.block.proposal.max_wait_time"))
+	assert.Equal(t, []string{"debug"}, levels)
+
+	res, err = Reload(false)
+	require.NoError(t, err)
+	assert.Empty(t, res.Changes, "the changes are applied once")
+}
+
+func TestReload_rejected(t *testing.T) {
+	var node, sc, write = setupTestReload(t)
+	RegisterReloadable(nil, "logging.level")
+
+	write("0chain.yaml", `
+logging:
+  level: debug
+server_chain:
+  block:
+    max_block_size: 200
+    proposal:
+      max_wait_time: 180
+network:
+  user_handlers:
+    quota:
+      api_keys:
+        - key: other
+`)
+	write("sc.yaml", "smart_contracts:\n  minersc:\n    max_n: 8\n")
+
+	res, err := Reload(false)
+	require.NoError(t, err)
+	assert.False(t, res.Applied)
+	assert.Equal(t, []*ConfigChange{
+		{File: "0chain.yaml", Key: "logging.level", Old: "info", New: "debug",
+			Status: ReloadableKey},
+		{File: "0chain.yaml", Key: "network.user_handlers.quota.api_keys",
+			Old: maskedValue, New: maskedValue, Status: RestartRequiredKey},
+		{File: "0chain.yaml", Key: "server_chain.block.max_block_size",
+			Old: 100, New: 200, Status: ConsensusCriticalKey},
+		{File: "sc.yaml", Key: "smart_contracts.minersc.max_n",
+			Old: 7, New: 8, Status: ConsensusCriticalKey},
+	}, res.Changes)
+	assert.Equal(t, "consensus critical keys can't change at runtime: "+
+		"server_chain.block.max_block_size, smart_contracts.minersc.max_n; "+
+		"keys requiring restart of the node: "+
+		"network.user_handlers.quota.api_keys", res.Error)
+
+	assert.Equal(t, "info", node.GetString("logging.level"),
+		"nothing is applied")
+	assert.Equal(t, 7, sc.GetInt("smart_contracts.minersc.max_n"))
+}
+
+func TestReload_revert(t *testing.T) {
+	var (
+		node, _, write = setupTestReload(t)
+		applied        []int
+	)
+	RegisterReloadable(func() error {
+		applied = append(applied,
+			node.GetInt("server_chain.block.proposal.max_wait_time"))
+		return nil
+	}, "server_chain.block.proposal")
+	RegisterReloadable(func() error {
+		if level := node.GetString("logging.level"); level != "info" {
+			return errors.New("invalid level " + level)
+		}
+		return nil
+	}, "logging.level")
+
+	write("0chain.yaml", `
+logging:
+  level: invalid
+server_chain:
+  block:
+    max_block_size: 100
+    proposal:
+      max_wait_time: 250
+network:
+  user_handlers:
+    quota:
+      api_keys:
+        - key: secret
+`)
+	res, err := Reload(false)
+	require.NoError(t, err)
+	assert.False(t, res.Applied)
+	assert.Equal(t, "logging.level: invalid level invalid", res.Error)
+	assert.Equal(t, "info", node.GetString("logging.level"))
+	assert.Equal(t, 180, node.GetInt("server_chain.block.proposal.max_wait_time"))
+	assert.Equal(t, 180, applied[len(applied)-1], "reverted setting")
+}
+
+func TestReloadHandler(t *testing.T) {
+	var _, _, write = setupTestReload(t)
+	RegisterReloadable(nil, "logging.level")
+	require.NoError(t, SetupReload(context.Background()))
+	reloadMu.Lock()
+	_, n, _ := net.ParseCIDR("10.0.0.0/8")
+	reloadNetworks = []*net.IPNet{n}
+	reloadMu.Unlock()
+
+	write("0chain.yaml", testConfig+"\nunknown: 1\n")
+
+	var handler = common.ToJSONResponse(ReloadHandler)
+	var w = httptest.NewRecorder()
+	var r = httptest.NewRequest(http.MethodPost, "/v1/config/reload", nil)
+	r.RemoteAddr = "192.168.0.1:1234"
+	handler(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"forbidden"`)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/v1/config/reload", nil)
+	r.RemoteAddr = "10.1.2.3:1234"
+	handler(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"changes":[{"file":"0chain.yaml","key":"unknown",`+
+		`"old":null,"new":1,"status":"restart_required"}],"applied":false,`+
+		`"dry_run":true,"error":"keys requiring restart of the node: unknown"}`,
+		w.Body.String())
+
+	// the admin key is required in addition to the network
+	reloadMu.Lock()
+	reloadAdminKey = "admin"
+	reloadMu.Unlock()
+	for key, code := range map[string]int{
+		"":      http.StatusForbidden,
+		"wrong": http.StatusForbidden,
+		"admin": http.StatusOK,
+	} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/v1/config/reload", nil)
+		r.RemoteAddr = "10.1.2.3:1234"
+		r.Header.Set(ReloadKeyHeader, key)
+		handler(w, r)
+		assert.Equal(t, code, w.Code, key)
+	}
+}
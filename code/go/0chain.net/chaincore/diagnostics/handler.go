@@ -6,6 +6,7 @@ import (
 
 	"0chain.net/chaincore/chain"
 	"0chain.net/core/common"
+	"0chain.net/core/journal"
 	"0chain.net/core/logging"
 	"0chain.net/core/util"
 	metrics "github.com/rcrowley/go-metrics"
@@ -22,6 +23,8 @@ func SetupHandlers() {
 	http.HandleFunc("/_diagnostics/n2n/info", common.UserRateLimit(sc.N2NStatsWriter))
 	http.HandleFunc("/_diagnostics/miner_stats", common.UserRateLimit(sc.MinerStatsHandler))
 	http.HandleFunc("/_diagnostics/block_chain", common.UserRateLimit(sc.WIPBlockChainHandler))
+	http.HandleFunc("/metrics", common.UserRateLimit(sc.MetricsHandler))
+	http.HandleFunc("/v1/diagnostics/journal", common.UserRateLimit(common.ToJSONResponse(journal.Handler)))
 }
 
 /*GetStatistics - write the statistics of the given timer */
@@ -66,10 +69,10 @@ func WriteConfiguration(w http.ResponseWriter, c *chain.Chain) {
 	fmt.Fprintf(w, "<tr><td class='tname'>Block Size</td><td>%v - %v</td></tr>", c.MinBlockSize, c.BlockSize)
 	fmt.Fprintf(w, "<tr><td class='tname'>Network Latency (Delta)</td><td>%v</td></tr>", chain.DELTA)
 	proposalMode := "dynamic"
-	if c.BlockProposalWaitMode == chain.BlockProposalWaitStatic {
+	if c.GetBlockProposalWaitMode() == chain.BlockProposalWaitStatic {
 		proposalMode = "static"
 	}
-	fmt.Fprintf(w, "<tr><td class='tname'>Block Proposal Wait Time</td><td>%v (%v)</td>", c.BlockProposalMaxWaitTime, proposalMode)
+	fmt.Fprintf(w, "<tr><td class='tname'>Block Proposal Wait Time</td><td>%v (%v)</td>", c.GetBlockProposalMaxWaitTime(), proposalMode)
 
 	fmt.Fprintf(w, "<tr><td class='tname'>Validation Batch Size</td><td>%d</td>", c.ValidationBatchSize)
 	fmt.Fprintf(w, "</table>")
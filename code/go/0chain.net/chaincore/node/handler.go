@@ -18,6 +18,8 @@ func SetupHandlers() {
 	http.HandleFunc("/_nh/whoami", common.UserRateLimit(WhoAmIHandler))
 	http.HandleFunc("/_nh/status", common.UserRateLimit(StatusHandler))
 	http.HandleFunc("/_nh/getpoolmembers", common.UserRateLimit(common.ToJSONResponse(GetPoolMembersHandler)))
+	http.HandleFunc("/_nh/peers", common.UserRateLimit(common.ToJSONResponse(PeersHandler)))
+	http.HandleFunc("/_nh/observe", common.N2NRateLimit(common.ToJSONResponse(ObserveHandler)))
 }
 
 //WhoAmIHandler - who am i?
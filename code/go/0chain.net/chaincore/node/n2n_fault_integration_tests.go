@@ -0,0 +1,57 @@
+// +build integration_tests
+
+package node
+
+import (
+	"time"
+
+	crpc "0chain.net/conductor/conductrpc"
+)
+
+// messageFault returns delay of the message and whether to drop it by the
+// network faults of the conductor.
+func messageFault(from, to, uri string) (time.Duration, bool) {
+	var state = crpc.Client().State()
+	if state == nil {
+		return 0, false // not polled yet
+	}
+	return state.MessageFault(from, to, uri)
+}
+
+// applySendFault delays the message sent to the receiver by the network
+// faults of the conductor. It returns false if the message is dropped.
+func applySendFault(receiver *Node, uri string) bool {
+	var delay, drop = messageFault(Self.Underlying().GetKey(),
+		receiver.GetKey(), uri)
+	if drop {
+		return false
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return true
+}
+
+// applyServeFault delays response to the request of the sender by the
+// network faults of the conductor. It returns false if the request is
+// dropped.
+func applyServeFault(sender *Node, uri string) bool {
+	var delay, drop = messageFault(sender.GetKey(),
+		Self.Underlying().GetKey(), uri)
+	if drop {
+		return false
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return true
+}
+
+// isPartitioned returns true if the sender is partitioned from this node by
+// the conductor. Sent messages are dropped by the sender already, it's
+// the receiver side check.
+func isPartitioned(sender *Node) bool {
+	var state = crpc.Client().State()
+	return state != nil &&
+		state.IsPartitioned(sender.GetKey(), Self.Underlying().GetKey())
+}
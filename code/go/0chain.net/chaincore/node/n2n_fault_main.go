@@ -0,0 +1,18 @@
+// +build !integration_tests
+
+package node
+
+// applySendFault is a no-op, it never drops a message.
+func applySendFault(receiver *Node, uri string) bool {
+	return true
+}
+
+// applyServeFault is a no-op, it never drops a request.
+func applyServeFault(sender *Node, uri string) bool {
+	return true
+}
+
+// isPartitioned is always false.
+func isPartitioned(sender *Node) bool {
+	return false
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net"
 	"net/http"
 	"net/http/httptrace"
 	"time"
@@ -52,6 +51,7 @@ var pullDataRequestor EntityRequestor
 func SetupN2NHandlers() {
 	http.HandleFunc("/v1/_n2n/entity/post", common.N2NRateLimit(ToN2NReceiveEntityHandler(datastore.PrintEntityHandler, nil)))
 	http.HandleFunc(pullURL, common.N2NRateLimit(ToN2NSendEntityHandler(PushToPullHandler)))
+	http.HandleFunc(streamURL, common.N2NRateLimit(StreamHandler))
 	options := &SendOptions{Timeout: TimeoutLargeMessage, CODEC: CODEC_MSGPACK, Compress: true}
 	pullDataRequestor = RequestEntityHandler(pullURL, options, nil)
 }
@@ -107,27 +107,9 @@ type ReceiveOptions struct {
 	MessageFilter MessageFilterI
 }
 
-var httpClient *http.Client
-
 var n2nTrace = &httptrace.ClientTrace{}
 
 func init() {
-	var transport *http.Transport
-	transport = &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-			DualStack: true,
-		}).DialContext,
-		MaxIdleConns:          1000,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		MaxIdleConnsPerHost:   5,
-	}
-	httpClient = &http.Client{Transport: transport}
-
 	n2nTrace.GotConn = func(connInfo httptrace.GotConnInfo) {
 		fmt.Printf("GOT conn: %+v\n", connInfo)
 	}
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	"go.uber.org/zap"
 )
 
@@ -133,8 +135,9 @@ func (n *Node) RequestEntityFromNode(ctx context.Context, requestor EntityReques
 	}
 }
 
-/*SetRequestHeaders - sets the send request headers*/
-func SetRequestHeaders(req *http.Request, options *SendOptions, entityMetadata datastore.EntityMetadata) bool {
+/*SetRequestHeaders - sets the send request headers, the trace context is set
+* for the round of the 'round' parameter, if any */
+func SetRequestHeaders(req *http.Request, options *SendOptions, entityMetadata datastore.EntityMetadata, params *url.Values) bool {
 	SetHeaders(req)
 	if options.InitialNodeID != "" {
 		req.Header.Set(HeaderInitialNodeID, options.InitialNodeID)
@@ -148,11 +151,17 @@ func SetRequestHeaders(req *http.Request, options *SendOptions, entityMetadata d
 	} else {
 		req.Header.Set(HeaderRequestCODEC, CodecMsgpack)
 	}
+	if params != nil {
+		if rn, err := strconv.ParseInt(params.Get("round"), 10, 64); err == nil {
+			tracing.Inject(req.Header, rn)
+		}
+	}
 	return true
 }
 
 //RequestEntityHandler - a handler that requests an entity and uses it
 func RequestEntityHandler(uri string, options *SendOptions, entityMetadata datastore.EntityMetadata) EntityRequestor {
+	registerMessageType(uri)
 	return func(params *url.Values, handler datastore.JSONEntityReqResponderF) SendHandler {
 		return func(provider *Node) bool {
 			timer := provider.GetTimer(uri)
@@ -177,7 +186,7 @@ func RequestEntityHandler(uri string, options *SendOptions, entityMetadata datas
 			if entityMetadata != nil {
 				eName = entityMetadata.GetName()
 			}
-			SetRequestHeaders(req, options, entityMetadata)
+			SetRequestHeaders(req, options, entityMetadata, params)
 			ctx, cancel := context.WithCancel(context.TODO())
 			req = req.WithContext(ctx)
 			// Keep the number of messages to a node bounded
@@ -197,7 +206,7 @@ func RequestEntityHandler(uri string, options *SendOptions, entityMetadata datas
 				selfNode = Self.Underlying()
 				selfNode.SetLastActiveTime(ts)
 				selfNode.InduceDelay(provider)
-				resp, err = httpClient.Do(req)
+				resp, err = doPeerRequest(provider, req)
 			}()
 
 			duration := time.Since(ts)
@@ -287,8 +296,15 @@ func ToN2NSendEntityHandler(handler common.JSONResponderF) common.ReqRespHandler
 		if !validateRequest(sender, r) {
 			return
 		}
+		if !applyServeFault(sender, r.URL.Path) {
+			http.Error(w, "dropped", http.StatusServiceUnavailable)
+			return
+		}
 		sender.AddReceived(1)
 		ctx := context.TODO()
+		if sc, ok := tracing.Extract(r.Header); ok {
+			ctx = tracing.WithRemoteParent(ctx, sc)
+		}
 		ts := time.Now()
 		data, err := handler(ctx, r)
 		if err != nil {
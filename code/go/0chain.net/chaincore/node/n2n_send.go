@@ -13,6 +13,7 @@ import (
 	"0chain.net/core/datastore"
 	"0chain.net/core/encryption"
 	"0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	metrics "github.com/rcrowley/go-metrics"
 	"go.uber.org/zap"
 )
@@ -44,9 +45,9 @@ func (np *Pool) SendTo(handler SendHandler, to string) (bool, error) {
 	return handler(recepient), nil
 }
 
-/*SendOne - send message to a single node in the pool */
+/*SendOne - send message to a single node in the pool, healthiest peers first */
 func (np *Pool) SendOne(handler SendHandler) *Node {
-	nodes := np.shuffleNodesLock(false)
+	nodes := np.healthiestNodes()
 	return np.sendOne(handler, nodes)
 }
 
@@ -108,7 +109,7 @@ func (np *Pool) sendTo(numNodes int, nodes []*Node, handler SendHandler) []*Node
 			}
 		}()
 	}
-	for _, node := range nodes {
+	for _, node := range backedOffLast(nodes) {
 		if Self.IsEqual(node) {
 			continue
 		}
@@ -147,7 +148,7 @@ func (np *Pool) sendTo(numNodes int, nodes []*Node, handler SendHandler) []*Node
 }
 
 func (np *Pool) sendOne(handler SendHandler, nodes []*Node) *Node {
-	for _, node := range nodes {
+	for _, node := range backedOffLast(nodes) {
 		if node.GetStatus() == NodeStatusInactive {
 			continue
 		}
@@ -183,6 +184,7 @@ func SendEntityHandler(uri string, options *SendOptions) EntitySendHandler {
 	if options.Timeout > 0 {
 		timeout = options.Timeout
 	}
+	registerMessageType(uri)
 	return func(entity datastore.Entity) SendHandler {
 		data := getResponseData(options, entity).Bytes()
 		toPull := options.Pull
@@ -193,6 +195,9 @@ func SendEntityHandler(uri string, options *SendOptions) EntitySendHandler {
 			pushDataCache.Add(key, pdce)
 		}
 		return func(receiver *Node) bool {
+			if !applySendFault(receiver, uri) {
+				return false
+			}
 			timer := receiver.GetTimer(uri)
 			url := receiver.GetN2NURLBase() + uri
 			var buffer *bytes.Buffer
@@ -231,7 +236,7 @@ func SendEntityHandler(uri string, options *SendOptions) EntitySendHandler {
 				selfNode.SetLastActiveTime(ts)
 				selfNode.InduceDelay(receiver)
 				//req = req.WithContext(httptrace.WithClientTrace(req.Context(), n2nTrace))
-				resp, err = httpClient.Do(req)
+				resp, err = doPeerRequest(receiver, req)
 			}()
 
 			logging.N2n.Info("sending", zap.Int("from", selfNode.SetIndex), zap.Int("to", receiver.SetIndex), zap.String("handler", uri), zap.Duration("duration", time.Since(ts)), zap.String("entity", entity.GetEntityMetadata().GetName()), zap.Any("id", entity.GetKey()))
@@ -289,6 +294,9 @@ func SetSendHeaders(req *http.Request, entity datastore.Entity, options *SendOpt
 		req.Header.Set(HeaderRequestMaxRelayLength, strconv.FormatInt(options.MaxRelayLength, 10))
 	}
 	req.Header.Set(HeaderRequestRelayLength, strconv.FormatInt(options.CurrentRelayLength, 10))
+	if re, ok := entity.(tracing.Rounder); ok {
+		tracing.Inject(req.Header, re.GetRoundNumber())
+	}
 	return true
 }
 
@@ -369,6 +377,10 @@ func ToN2NReceiveEntityHandler(handler datastore.JSONEntityReqResponderF, option
 		if !validateSendRequest(sender, r) {
 			return
 		}
+		if isPartitioned(sender) {
+			http.Error(w, "partitioned", http.StatusServiceUnavailable)
+			return
+		}
 		entityName := r.Header.Get(HeaderRequestEntityName)
 		entityID := r.Header.Get(HeaderRequestEntityID)
 		entityMetadata := datastore.GetEntityMetadata(entityName)
@@ -389,6 +401,9 @@ func ToN2NReceiveEntityHandler(handler datastore.JSONEntityReqResponderF, option
 		} else {
 			ctx = WithNode(ctx, sender)
 		}
+		if sc, ok := tracing.Extract(r.Header); ok {
+			ctx = tracing.WithRemoteParent(ctx, sc)
+		}
 		entity, err := getRequestEntity(r, entityMetadata)
 		if err != nil {
 			if err == NoDataErr {
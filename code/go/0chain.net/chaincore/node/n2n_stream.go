@@ -0,0 +1,815 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+/*
+The stream transport is a framed binary alternative to posting every n2n
+message as its own HTTP request. A node keeps single outgoing connection per
+peer. The connection is upgraded from HTTP on the n2n port and authenticated
+by both sides. Every n2n request is a stream of frames multiplexed over the
+connection. Request and response bodies are sent by chunks, thus, a large
+block doesn't hold small messages sent along with it. A stream whose reader
+doesn't keep up with its body is reset instead of blocking other streams of
+the connection, and number of concurrent streams of a connection is limited.
+
+The transport is a http.RoundTripper on the sending side and dispatches
+received streams to the registered HTTP handlers on the receiving side.
+Thus, the existing n2n senders, requestors and handlers work unchanged
+behind it.
+*/
+
+const (
+	// N2NTransportHTTP sends every n2n message as a HTTP request.
+	N2NTransportHTTP = "http"
+	// N2NTransportStream multiplexes n2n messages over single framed binary
+	// connection per peer.
+	N2NTransportStream = "stream"
+)
+
+// N2NTransport is transport used for outgoing n2n messages.
+var N2NTransport = N2NTransportHTTP
+
+// SetN2NTransport - set the transport used for outgoing n2n messages
+func SetN2NTransport(transport string) {
+	switch transport {
+	case N2NTransportStream:
+		N2NTransport = N2NTransportStream
+	case N2NTransportHTTP, "":
+		N2NTransport = N2NTransportHTTP
+	default:
+		logging.Logger.Error("unknown n2n transport, using http",
+			zap.String("transport", transport))
+		N2NTransport = N2NTransportHTTP
+	}
+}
+
+const (
+	streamURL      = "/v1/_n2n/stream"
+	streamProtocol = "0chain-n2n/1"
+
+	// HeaderStreamData is signed data of the stream connection upgrade.
+	HeaderStreamData = "X-Stream-Data"
+
+	streamDialTimeout  = 5 * time.Second
+	streamWriteTimeout = 10 * time.Second
+	// streamRetryInterval is time the HTTP transport is used after failed
+	// stream connection to a peer
+	streamRetryInterval = 30 * time.Second
+)
+
+const (
+	frameRequest  byte = iota + 1 // request headers, opens a stream
+	frameResponse                 // response headers
+	frameData                     // chunk of request or response body
+	frameEnd                      // end of request or response body
+	frameReset                    // stream aborted
+)
+
+const (
+	frameHeaderSize = 9 // type (1) + stream (4) + payload length (4)
+	// maxFrameData is max size of body chunk sent in single frame
+	maxFrameData = 64 * 1024
+	// maxFramePayload is max accepted frame payload
+	maxFramePayload = 1 << 20
+	// maxStreamBuffer is max size of received and not yet read body of a
+	// stream, the stream is reset once its reader falls behind that far
+	maxStreamBuffer = 8 << 20
+	// maxConnStreams is max number of concurrent streams of a connection,
+	// requests above it are reset by the receiving side and sent over HTTP
+	// by the sending side
+	maxConnStreams = 64
+)
+
+var (
+	errStreamReset  = common.NewError("stream_reset", "stream reset by peer")
+	errStreamClosed = common.NewError("stream_closed", "stream connection closed")
+	errStreamBuffer = common.NewError("stream_buffer_exceeded",
+		"stream body not read in time")
+	errStreamsLimit = common.NewError("streams_limit",
+		"too many concurrent streams")
+)
+
+var (
+	messageTypesMutex sync.RWMutex
+	// messageTypes maps endpoints of the EntitySendHandler and the
+	// EntityRequestor to compact message types used by the stream transport
+	messageTypes = make(map[string]uint32)
+)
+
+// registerMessageType registers given n2n endpoint as a stream message type.
+func registerMessageType(uri string) uint32 {
+	messageTypesMutex.Lock()
+	defer messageTypesMutex.Unlock()
+	if mt, ok := messageTypes[uri]; ok {
+		return mt
+	}
+	var mt = uint32(len(messageTypes) + 1)
+	messageTypes[uri] = mt
+	return mt
+}
+
+// getMessageType returns message type of given endpoint, or zero if the
+// endpoint is not registered.
+func getMessageType(uri string) uint32 {
+	messageTypesMutex.RLock()
+	defer messageTypesMutex.RUnlock()
+	return messageTypes[uri]
+}
+
+type frame struct {
+	typ     byte
+	stream  uint32
+	payload []byte
+}
+
+func readFrame(r io.Reader) (*frame, error) {
+	var head [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	var (
+		f    = &frame{typ: head[0], stream: binary.BigEndian.Uint32(head[1:5])}
+		size = binary.BigEndian.Uint32(head[5:9])
+	)
+	if size > maxFramePayload {
+		return nil, fmt.Errorf("frame payload too large: %d", size)
+	}
+	f.payload = make([]byte, size)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func writeFrame(w io.Writer, typ byte, stream uint32, payload []byte) error {
+	var head [frameHeaderSize]byte
+	head[0] = typ
+	binary.BigEndian.PutUint32(head[1:5], stream)
+	binary.BigEndian.PutUint32(head[5:9], uint32(len(payload)))
+	if _, err := w.Write(head[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// frameEncoder encodes headers frames payload.
+type frameEncoder struct {
+	bytes.Buffer
+}
+
+func (fe *frameEncoder) putUvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	fe.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (fe *frameEncoder) putString(s string) {
+	fe.putUvarint(uint64(len(s)))
+	fe.WriteString(s)
+}
+
+func (fe *frameEncoder) putHeader(h http.Header) {
+	var count int
+	for _, vs := range h {
+		count += len(vs)
+	}
+	fe.putUvarint(uint64(count))
+	for k, vs := range h {
+		for _, v := range vs {
+			fe.putString(k)
+			fe.putString(v)
+		}
+	}
+}
+
+// frameDecoder decodes headers frames payload, first error is sticky.
+type frameDecoder struct {
+	buf []byte
+	err error
+}
+
+var errFrameMalformed = errors.New("malformed frame")
+
+func (fd *frameDecoder) uvarint() uint64 {
+	if fd.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(fd.buf)
+	if n <= 0 {
+		fd.err = errFrameMalformed
+		return 0
+	}
+	fd.buf = fd.buf[n:]
+	return v
+}
+
+func (fd *frameDecoder) string() string {
+	var size = fd.uvarint()
+	if fd.err != nil {
+		return ""
+	}
+	if uint64(len(fd.buf)) < size {
+		fd.err = errFrameMalformed
+		return ""
+	}
+	var s = string(fd.buf[:size])
+	fd.buf = fd.buf[size:]
+	return s
+}
+
+func (fd *frameDecoder) header() http.Header {
+	var (
+		count = fd.uvarint()
+		h     = make(http.Header)
+	)
+	for i := uint64(0); i < count && fd.err == nil; i++ {
+		var k, v = fd.string(), fd.string()
+		h[k] = append(h[k], v)
+	}
+	return h
+}
+
+type requestHeaders struct {
+	messageType uint32
+	path        string
+	method      string
+	query       string
+	header      http.Header
+}
+
+type responseHeaders struct {
+	status int
+	header http.Header
+}
+
+// streamBody is incoming body of a stream: request body on the receiving
+// side and response body on the sending side.
+type streamBody struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	buf      bytes.Buffer
+	err      error
+	closed   bool
+	finished bool
+	onClose  func(finished bool)
+}
+
+func newStreamBody(onClose func(finished bool)) *streamBody {
+	var sb = &streamBody{onClose: onClose}
+	sb.cond = sync.NewCond(&sb.mutex)
+	return sb
+}
+
+// write appends received data to the body. It returns false if the unread
+// data exceeds maxStreamBuffer, the data is dropped in this case.
+func (sb *streamBody) write(p []byte) bool {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	if sb.err != nil || sb.closed {
+		return true
+	}
+	if sb.buf.Len()+len(p) > maxStreamBuffer {
+		return false
+	}
+	sb.buf.Write(p)
+	sb.cond.Broadcast()
+	return true
+}
+
+// finish ends the body with given error, io.EOF for complete body.
+func (sb *streamBody) finish(err error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	if sb.err == nil {
+		sb.err = err
+		sb.finished = err == io.EOF
+		sb.cond.Broadcast()
+	}
+}
+
+func (sb *streamBody) Read(p []byte) (int, error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	for sb.buf.Len() == 0 && sb.err == nil && !sb.closed {
+		sb.cond.Wait()
+	}
+	if sb.buf.Len() > 0 {
+		return sb.buf.Read(p)
+	}
+	if sb.closed {
+		return 0, errStreamClosed
+	}
+	return 0, sb.err
+}
+
+func (sb *streamBody) Close() error {
+	sb.mutex.Lock()
+	if sb.closed {
+		sb.mutex.Unlock()
+		return nil
+	}
+	sb.closed = true
+	sb.buf.Reset()
+	sb.cond.Broadcast()
+	var finished = sb.finished
+	sb.mutex.Unlock()
+
+	if sb.onClose != nil {
+		sb.onClose(finished)
+	}
+	return nil
+}
+
+type stream struct {
+	id       uint32
+	body     *streamBody
+	response chan *responseHeaders // sending side
+	cancel   context.CancelFunc    // receiving side
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (st *stream) close() {
+	st.doneOnce.Do(func() { close(st.done) })
+}
+
+// streamConn is an authenticated connection multiplexing n2n streams.
+type streamConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	peer *Node
+	// server is true for the receiving side of the connection
+	server bool
+
+	writeMutex sync.Mutex
+	w          *bufio.Writer
+	// announced message types, sending side
+	announced map[uint32]bool
+
+	mutex   sync.Mutex
+	streams map[uint32]*stream
+	nextID  uint32
+	// message types announced by the peer, receiving side
+	types map[uint32]string
+	// serving stream handlers, receiving side
+	serving chan struct{}
+	closed  chan struct{}
+	err     error
+}
+
+func newStreamConn(conn net.Conn, r *bufio.Reader, peer *Node,
+	server bool) *streamConn {
+
+	return &streamConn{
+		conn:      conn,
+		r:         r,
+		peer:      peer,
+		server:    server,
+		w:         bufio.NewWriter(conn),
+		announced: make(map[uint32]bool),
+		streams:   make(map[uint32]*stream),
+		types:     make(map[uint32]string),
+		serving:   make(chan struct{}, maxConnStreams),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (sc *streamConn) isClosed() bool {
+	select {
+	case <-sc.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the connection and fails all its streams.
+func (sc *streamConn) close(err error) {
+	sc.mutex.Lock()
+	if sc.isClosed() {
+		sc.mutex.Unlock()
+		return
+	}
+	sc.err = err
+	close(sc.closed)
+	var streams = sc.streams
+	sc.streams = make(map[uint32]*stream)
+	sc.mutex.Unlock()
+
+	sc.conn.Close()
+	for _, st := range streams {
+		st.body.finish(errStreamClosed)
+		if st.cancel != nil {
+			st.cancel()
+		}
+		st.close()
+	}
+	logging.N2n.Debug("stream connection closed",
+		zap.String("peer", sc.peer.GetKey()), zap.Bool("server", sc.server),
+		zap.Error(err))
+}
+
+func (sc *streamConn) writeFrame(typ byte, id uint32, payload []byte) error {
+	sc.writeMutex.Lock()
+	defer sc.writeMutex.Unlock()
+	return sc.writeFrameLocked(typ, id, payload)
+}
+
+func (sc *streamConn) writeFrameLocked(typ byte, id uint32,
+	payload []byte) (err error) {
+
+	if sc.isClosed() {
+		return errStreamClosed
+	}
+	sc.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err = writeFrame(sc.w, typ, id, payload); err == nil {
+		err = sc.w.Flush()
+	}
+	if err != nil {
+		go sc.close(err)
+	}
+	return
+}
+
+// writeBody sends given body as data frames followed by end frame.
+func (sc *streamConn) writeBody(id uint32, body io.Reader) error {
+	if body != nil {
+		var buf = make([]byte, maxFrameData)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				if err := sc.writeFrame(frameData, id, buf[:n]); err != nil {
+					return err
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				sc.writeFrame(frameReset, id, nil)
+				return err
+			}
+		}
+	}
+	return sc.writeFrame(frameEnd, id, nil)
+}
+
+func (sc *streamConn) getStream(id uint32) *stream {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	return sc.streams[id]
+}
+
+func (sc *streamConn) removeStream(id uint32) {
+	sc.mutex.Lock()
+	var st, ok = sc.streams[id]
+	delete(sc.streams, id)
+	sc.mutex.Unlock()
+	if ok {
+		st.close()
+	}
+}
+
+// resetStream aborts the stream locally and resets it on the peer side. The
+// reset frame is sent asynchronously, the read loop doesn't wait for writes.
+func (sc *streamConn) resetStream(st *stream, err error) {
+	st.body.finish(err)
+	if st.cancel != nil {
+		st.cancel()
+	}
+	sc.removeStream(st.id)
+	go sc.writeFrame(frameReset, st.id, nil)
+}
+
+// readLoop reads frames of the connection until it's closed.
+func (sc *streamConn) readLoop() {
+	for {
+		f, err := readFrame(sc.r)
+		if err != nil {
+			sc.close(err)
+			return
+		}
+		if err = sc.handleFrame(f); err != nil {
+			sc.close(err)
+			return
+		}
+	}
+}
+
+func (sc *streamConn) handleFrame(f *frame) error {
+	switch f.typ {
+	case frameRequest:
+		if !sc.server {
+			return fmt.Errorf("unexpected request frame")
+		}
+		return sc.handleRequest(f)
+	case frameResponse:
+		if sc.server {
+			return fmt.Errorf("unexpected response frame")
+		}
+		var (
+			fd = &frameDecoder{buf: f.payload}
+			rh = &responseHeaders{status: int(fd.uvarint()), header: fd.header()}
+		)
+		if fd.err != nil {
+			return fd.err
+		}
+		if st := sc.getStream(f.stream); st != nil {
+			select {
+			case st.response <- rh:
+			default:
+			}
+		}
+	case frameData:
+		if st := sc.getStream(f.stream); st != nil && !st.body.write(f.payload) {
+			logging.N2n.Error("stream body buffer exceeded",
+				zap.String("peer", sc.peer.GetKey()),
+				zap.Bool("server", sc.server), zap.Uint32("stream", f.stream))
+			sc.resetStream(st, errStreamBuffer)
+		}
+	case frameEnd:
+		if st := sc.getStream(f.stream); st != nil {
+			st.body.finish(io.EOF)
+		}
+	case frameReset:
+		if st := sc.getStream(f.stream); st != nil {
+			st.body.finish(errStreamReset)
+			if st.cancel != nil {
+				st.cancel()
+			}
+			sc.removeStream(f.stream)
+		}
+	default:
+		return fmt.Errorf("unknown frame type: %d", f.typ)
+	}
+	return nil
+}
+
+// roundTrip sends given request as a new stream and waits for the response
+// headers. The response body is streamed.
+func (sc *streamConn) roundTrip(req *http.Request) (*http.Response, error) {
+	sc.mutex.Lock()
+	if sc.isClosed() {
+		sc.mutex.Unlock()
+		return nil, errStreamClosed
+	}
+	if len(sc.streams) >= maxConnStreams {
+		sc.mutex.Unlock()
+		return nil, errStreamsLimit
+	}
+	sc.nextID++
+	var st = &stream{
+		id:       sc.nextID,
+		response: make(chan *responseHeaders, 1),
+		done:     make(chan struct{}),
+	}
+	st.body = newStreamBody(func(finished bool) {
+		if !finished {
+			sc.writeFrame(frameReset, st.id, nil)
+		}
+		sc.removeStream(st.id)
+	})
+	sc.streams[st.id] = st
+	sc.mutex.Unlock()
+
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	if err := sc.writeRequest(st.id, req); err != nil {
+		sc.removeStream(st.id)
+		return nil, err
+	}
+	if err := sc.writeBody(st.id, req.Body); err != nil {
+		sc.removeStream(st.id)
+		return nil, err
+	}
+
+	var ctx = req.Context()
+	select {
+	case rh := <-st.response:
+		go func() {
+			select {
+			case <-ctx.Done():
+				st.body.finish(ctx.Err())
+				st.body.Close()
+			case <-st.done:
+			}
+		}()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", rh.status, http.StatusText(rh.status)),
+			StatusCode:    rh.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        rh.header,
+			Body:          st.body,
+			ContentLength: -1,
+			Request:       req,
+		}, nil
+	case <-st.done:
+		return nil, errStreamReset
+	case <-sc.closed:
+		return nil, errStreamClosed
+	case <-ctx.Done():
+		st.body.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// writeRequest sends request headers frame. Path of a registered message
+// type is sent only once per connection, next requests refer to it by the
+// type only.
+func (sc *streamConn) writeRequest(id uint32, req *http.Request) error {
+	sc.writeMutex.Lock()
+	defer sc.writeMutex.Unlock()
+
+	var (
+		fe   frameEncoder
+		path = req.URL.Path
+		mt   = getMessageType(path)
+	)
+	fe.putUvarint(uint64(mt))
+	if mt != 0 && sc.announced[mt] {
+		fe.putString("")
+	} else {
+		fe.putString(path)
+	}
+	fe.putString(req.Method)
+	fe.putString(req.URL.RawQuery)
+	fe.putHeader(req.Header)
+
+	if err := sc.writeFrameLocked(frameRequest, id, fe.Bytes()); err != nil {
+		return err
+	}
+	if mt != 0 {
+		sc.announced[mt] = true
+	}
+	return nil
+}
+
+// StreamTransport is a http.RoundTripper sending requests to a peer over
+// single stream connection. It falls back to the HTTP transport while the
+// stream connection can't be established, e.g. the peer doesn't support it.
+type StreamTransport struct {
+	nodeID   string
+	fallback http.RoundTripper
+
+	mutex   sync.Mutex
+	conn    *streamConn
+	retryAt time.Time
+}
+
+// NewStreamTransport returns stream transport to given node.
+func NewStreamTransport(nodeID string,
+	fallback http.RoundTripper) *StreamTransport {
+
+	return &StreamTransport{nodeID: nodeID, fallback: fallback}
+}
+
+// RoundTrip - implement interface
+func (st *StreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sc, err := st.getConn(req.URL.Host)
+	if err != nil {
+		return st.fallback.RoundTrip(req)
+	}
+	resp, err := sc.roundTrip(req)
+	if err == errStreamsLimit {
+		return st.fallback.RoundTrip(req) // nothing is sent over the stream
+	}
+	return resp, err
+}
+
+// Close closes the stream connection.
+func (st *StreamTransport) Close() {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	if st.conn != nil {
+		st.conn.close(errStreamClosed)
+		st.conn = nil
+	}
+}
+
+func (st *StreamTransport) getConn(host string) (*streamConn, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.conn != nil && !st.conn.isClosed() {
+		return st.conn, nil
+	}
+	if time.Now().Before(st.retryAt) {
+		return nil, errStreamClosed
+	}
+	sc, err := dialStream(host, st.nodeID)
+	if err != nil {
+		logging.N2n.Debug("stream connection failed, using http",
+			zap.String("to", st.nodeID), zap.String("host", host),
+			zap.Error(err))
+		st.retryAt = time.Now().Add(streamRetryInterval)
+		return nil, err
+	}
+	st.conn = sc
+	return sc, nil
+}
+
+// dialStream connects to given node and upgrades the connection to stream
+// one. Both sides sign their node ID and current time, the response is
+// bound to the request hash.
+func dialStream(host, nodeID string) (*streamConn, error) {
+	var peer = GetNode(nodeID)
+	if peer == nil {
+		return nil, ErrNodeNotFound
+	}
+	data, hash, signature, err := Self.TimeStampSignature()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://"+host+streamURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	SetHeaders(req)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", streamProtocol)
+	req.Header.Set(HeaderStreamData, data)
+	req.Header.Set(HeaderRequestHash, hash)
+	req.Header.Set(HeaderNodeRequestSignature, signature)
+
+	conn, err := net.DialTimeout("tcp", host, streamDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(streamDialTimeout))
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	var r = bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("stream upgrade rejected: %s", resp.Status)
+	}
+	if resp.Header.Get(HeaderNodeID) != nodeID {
+		conn.Close()
+		return nil, errors.New("stream upgrade: unexpected node")
+	}
+	var sdata = resp.Header.Get(HeaderStreamData)
+	if !strings.HasSuffix(sdata, ":"+hash) {
+		conn.Close()
+		return nil, errors.New("stream upgrade: response not bound to request")
+	}
+	err = validateStreamSignature(peer, sdata, resp.Header.Get(HeaderRequestHash),
+		resp.Header.Get(HeaderNodeRequestSignature))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	var sc = newStreamConn(conn, r, peer, false)
+	go sc.readLoop()
+	logging.N2n.Info("stream connection established",
+		zap.String("to", nodeID), zap.String("host", host))
+	return sc, nil
+}
+
+// validateStreamSignature validates signed data of a stream upgrade request
+// or response.
+func validateStreamSignature(n *Node, data, hash, signature string) error {
+	if !strings.HasPrefix(data, n.GetKey()+":") {
+		return errors.New("stream upgrade: data doesn't match the node")
+	}
+	if ok, err := ValidateSignatureTime(data); !ok {
+		return fmt.Errorf("stream upgrade: invalid time: %v", err)
+	}
+	if hash != encryption.Hash(data) {
+		return errors.New("stream upgrade: invalid hash")
+	}
+	if ok, err := n.Verify(signature, hash); !ok || err != nil {
+		return errors.New("stream upgrade: invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// StreamHandler upgrades n2n connection of a registered node to the stream
+// transport and serves its streams until the connection is closed.
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Upgrade") != streamProtocol {
+		http.Error(w, "unsupported upgrade protocol", http.StatusBadRequest)
+		return
+	}
+	var (
+		nodeID = r.Header.Get(HeaderNodeID)
+		sender = GetNode(nodeID)
+	)
+	if sender == nil {
+		logging.N2n.Error("stream upgrade - request from unrecognized node",
+			zap.String("from", nodeID))
+		http.Error(w, "unknown node", http.StatusForbidden)
+		return
+	}
+	if !validateChain(sender, r) {
+		http.Error(w, "invalid chain", http.StatusForbidden)
+		return
+	}
+	var hash = r.Header.Get(HeaderRequestHash)
+	err := validateStreamSignature(sender, r.Header.Get(HeaderStreamData), hash,
+		r.Header.Get(HeaderNodeRequestSignature))
+	if err != nil {
+		logging.N2n.Error("stream upgrade", zap.String("from", nodeID),
+			zap.Error(err))
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var (
+		self  = Self.Underlying().GetKey()
+		sdata = fmt.Sprintf("%v:%v:%v", self, common.Now(), hash)
+		shash = encryption.Hash(sdata)
+	)
+	signature, err := Self.Sign(shash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "stream upgrade is not supported",
+			http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		logging.N2n.Error("stream upgrade - hijack", zap.Error(err))
+		return
+	}
+	// reset deadlines of the HTTP server
+	conn.SetDeadline(time.Time{})
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Connection: Upgrade\r\nUpgrade: %s\r\n%s: %s\r\n%s: %s\r\n"+
+		"%s: %s\r\n%s: %s\r\n\r\n", streamProtocol,
+		HeaderNodeID, self, HeaderStreamData, sdata,
+		HeaderRequestHash, shash, HeaderNodeRequestSignature, signature)
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	logging.N2n.Info("stream connection accepted", zap.String("from", nodeID),
+		zap.Int("set_index", sender.SetIndex))
+	var sc = newStreamConn(conn, rw.Reader, sender, true)
+	sc.readLoop()
+}
+
+// isStreamPath returns true for endpoints allowed to be requested over the
+// stream transport.
+func isStreamPath(path string) bool {
+	return strings.HasPrefix(path, "/v1/_") || strings.HasPrefix(path, "/_nh/")
+}
+
+// handleRequest opens a stream for request headers frame and serves it.
+func (sc *streamConn) handleRequest(f *frame) error {
+	var (
+		fd = &frameDecoder{buf: f.payload}
+		rh = &requestHeaders{messageType: uint32(fd.uvarint())}
+	)
+	rh.path = fd.string()
+	rh.method = fd.string()
+	rh.query = fd.string()
+	rh.header = fd.header()
+	if fd.err != nil {
+		return fd.err
+	}
+
+	sc.mutex.Lock()
+	if rh.messageType != 0 {
+		if rh.path != "" {
+			sc.types[rh.messageType] = rh.path
+		} else {
+			rh.path = sc.types[rh.messageType]
+		}
+	}
+	if _, ok := sc.streams[f.stream]; ok || sc.isClosed() {
+		sc.mutex.Unlock()
+		return fmt.Errorf("duplicate stream: %d", f.stream)
+	}
+	// a handler keeps its slot until it returns, even if the stream is
+	// reset by the peer
+	select {
+	case sc.serving <- struct{}{}:
+	default:
+		sc.mutex.Unlock()
+		logging.N2n.Error("stream request rejected - too many streams",
+			zap.String("from", sc.peer.GetKey()), zap.String("handler", rh.path))
+		go sc.writeFrame(frameReset, f.stream, nil)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var st = &stream{id: f.stream, cancel: cancel, done: make(chan struct{})}
+	st.body = newStreamBody(nil)
+	sc.streams[st.id] = st
+	sc.mutex.Unlock()
+
+	go sc.serveStream(ctx, st, rh)
+	return nil
+}
+
+// serveStream dispatches the stream request to the registered HTTP handler.
+func (sc *streamConn) serveStream(ctx context.Context, st *stream,
+	rh *requestHeaders) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			logging.N2n.Error("stream handler panic", zap.Any("error", r),
+				zap.String("handler", rh.path))
+			sc.writeFrame(frameReset, st.id, nil)
+		}
+		st.cancel()
+		st.body.Close()
+		sc.removeStream(st.id)
+		<-sc.serving
+	}()
+
+	if rh.path == "" || !isStreamPath(rh.path) ||
+		rh.header.Get(HeaderNodeID) != sc.peer.GetKey() {
+
+		logging.N2n.Error("stream request rejected",
+			zap.String("from", sc.peer.GetKey()),
+			zap.Uint32("message_type", rh.messageType),
+			zap.String("handler", rh.path))
+		sc.writeFrame(frameReset, st.id, nil)
+		return
+	}
+
+	var uri = rh.path
+	if rh.query != "" {
+		uri += "?" + rh.query
+	}
+	req, err := http.NewRequestWithContext(ctx, rh.method, uri, st.body)
+	if err != nil {
+		sc.writeFrame(frameReset, st.id, nil)
+		return
+	}
+	req.Header = rh.header
+	req.RequestURI = uri
+	req.RemoteAddr = sc.conn.RemoteAddr().String()
+	req.Host = sc.conn.LocalAddr().String()
+	req.ContentLength = -1
+
+	var w = &streamResponseWriter{conn: sc, id: st.id, header: make(http.Header)}
+	http.DefaultServeMux.ServeHTTP(w, req)
+	w.finish()
+}
+
+// streamResponseWriter is http.ResponseWriter sending response of a stream.
+type streamResponseWriter struct {
+	conn        *streamConn
+	id          uint32
+	header      http.Header
+	wroteHeader bool
+	err         error
+}
+
+// Header - implement interface
+func (w *streamResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader - implement interface
+func (w *streamResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	var fe frameEncoder
+	fe.putUvarint(uint64(status))
+	fe.putHeader(w.header)
+	w.err = w.conn.writeFrame(frameResponse, w.id, fe.Bytes())
+}
+
+// Write - implement interface
+func (w *streamResponseWriter) Write(p []byte) (n int, err error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	for len(p) > 0 && w.err == nil {
+		var chunk = p
+		if len(chunk) > maxFrameData {
+			chunk = chunk[:maxFrameData]
+		}
+		if w.err = w.conn.writeFrame(frameData, w.id, chunk); w.err == nil {
+			n += len(chunk)
+			p = p[len(chunk):]
+		}
+	}
+	return n, w.err
+}
+
+// Flush - implement interface, every frame is flushed once written
+func (w *streamResponseWriter) Flush() {}
+
+func (w *streamResponseWriter) finish() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.err == nil {
+		w.conn.writeFrame(frameEnd, w.id, nil)
+	}
+}
@@ -0,0 +1,229 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"0chain.net/chaincore/client"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/require"
+)
+
+const streamTestPath = "/v1/_n2n/stream_test/echo"
+
+func init() {
+	// echoes request body with query and node id, repeated by the 'repeat'
+	http.HandleFunc(streamTestPath, func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var repeat = 1
+		fmt.Sscan(r.URL.Query().Get("repeat"), &repeat)
+		w.Header().Set(HeaderNodeID, r.Header.Get(HeaderNodeID))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		for i := 0; i < repeat; i++ {
+			w.Write(body)
+		}
+	})
+}
+
+func setupStreamSelf(t *testing.T) (restore func()) {
+	client.SetClientSignatureScheme("ed25519")
+	var scheme = encryption.NewED25519Scheme()
+	require.NoError(t, scheme.GenerateKeys())
+
+	var n = Provider()
+	n.Type = NodeTypeMiner
+	n.SetPublicKey(scheme.GetPublicKey())
+	RegisterNode(n)
+
+	var prev = Self
+	Self = &SelfNode{Node: n}
+	Self.SetSignatureScheme(scheme)
+	return func() {
+		Self = prev
+		nodesMutex.Lock()
+		delete(nodes, n.GetKey())
+		nodesMutex.Unlock()
+	}
+}
+
+func TestFrameHeaders(t *testing.T) {
+	var (
+		fe frameEncoder
+		h  = http.Header{"X-A": {"1", "2"}, "X-B": {""}}
+	)
+	fe.putUvarint(300)
+	fe.putString("/v1/_m2m/block/verify")
+	fe.putHeader(h)
+
+	var fd = &frameDecoder{buf: fe.Bytes()}
+	require.EqualValues(t, 300, fd.uvarint())
+	require.Equal(t, "/v1/_m2m/block/verify", fd.string())
+	require.Equal(t, h, fd.header())
+	require.NoError(t, fd.err)
+
+	fd = &frameDecoder{buf: fe.Bytes()[:4]}
+	fd.uvarint()
+	fd.string()
+	require.Equal(t, errFrameMalformed, fd.err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, frameData, 7, []byte("data")))
+	f, err := readFrame(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &frame{typ: frameData, stream: 7, payload: []byte("data")}, f)
+}
+
+func TestStreamTransport(t *testing.T) {
+	defer setupStreamSelf(t)()
+	registerMessageType(streamTestPath)
+
+	var server = httptest.NewServer(http.HandlerFunc(StreamHandler))
+	defer server.Close()
+
+	var (
+		transport = NewStreamTransport(Self.Underlying().GetKey(),
+			http.DefaultTransport)
+		hc = &http.Client{Transport: transport}
+	)
+	defer transport.Close()
+
+	var post = func(body string, repeat int) string {
+		var url = fmt.Sprintf("%s%s?repeat=%d", server.URL, streamTestPath,
+			repeat)
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		require.NoError(t, err)
+		SetHeaders(req)
+		resp, err := hc.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, Self.Underlying().GetKey(), resp.Header.Get(HeaderNodeID))
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	require.Equal(t, "hello", post("hello", 1))
+	require.NotNil(t, transport.conn, "stream connection is used")
+	var conn = transport.conn
+	require.Equal(t, "again", post("again", 1)) // announced message type
+
+	// multiplexed large bodies
+	var (
+		wg    sync.WaitGroup
+		large = strings.Repeat("0123456789", 3*maxFrameData/10)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var body = fmt.Sprintf("%d:%s", i, large)
+			require.Equal(t, strings.Repeat(body, 3), post(body, 3))
+		}(i)
+	}
+	wg.Wait()
+	require.True(t, conn == transport.conn, "single connection per peer")
+
+	conn.mutex.Lock()
+	require.Empty(t, conn.streams, "all streams closed")
+	conn.mutex.Unlock()
+}
+
+func TestStreamTransportFallback(t *testing.T) {
+	defer setupStreamSelf(t)()
+
+	// the peer doesn't support stream transport
+	var server = httptest.NewServer(http.DefaultServeMux)
+	defer server.Close()
+
+	var transport = NewStreamTransport(Self.Underlying().GetKey(),
+		http.DefaultTransport)
+	req, err := http.NewRequest(http.MethodPost, server.URL+streamTestPath,
+		strings.NewReader("http"))
+	require.NoError(t, err)
+	SetHeaders(req)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "http", string(data))
+	require.Nil(t, transport.conn)
+}
+
+func TestStreamHandlerRejectsUnknownNode(t *testing.T) {
+	defer setupStreamSelf(t)()
+
+	var server = httptest.NewServer(http.HandlerFunc(StreamHandler))
+	defer server.Close()
+
+	var host = strings.TrimPrefix(server.URL, "http://")
+	_, err := dialStream(host, "unknown")
+	require.Equal(t, ErrNodeNotFound, err)
+
+	// the connected peer is not the expected node
+	var other = Provider()
+	var scheme = encryption.NewED25519Scheme()
+	require.NoError(t, scheme.GenerateKeys())
+	other.SetPublicKey(scheme.GetPublicKey())
+	RegisterNode(other)
+	defer func() {
+		nodesMutex.Lock()
+		delete(nodes, other.GetKey())
+		nodesMutex.Unlock()
+	}()
+	_, err = dialStream(host, other.GetKey())
+	require.Error(t, err)
+}
+
+func TestStreamBodyLimit(t *testing.T) {
+	var sb = newStreamBody(nil)
+	require.True(t, sb.write(make([]byte, maxStreamBuffer-1)))
+	require.False(t, sb.write([]byte("xx")), "unread data over the limit")
+
+	var buf = make([]byte, maxFrameData)
+	n, err := sb.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, maxFrameData, n)
+	require.True(t, sb.write([]byte("xx")), "the reader caught up")
+}
+
+func TestStreamConnStreamsLimit(t *testing.T) {
+	var (
+		local, remote = net.Pipe()
+		peer          = Provider()
+		sc            = newStreamConn(local, bufio.NewReader(local), peer, true)
+	)
+	defer sc.close(errStreamClosed)
+	defer remote.Close()
+
+	for i := 0; i < maxConnStreams; i++ {
+		sc.serving <- struct{}{} // busy handlers
+	}
+	var fe frameEncoder
+	fe.putUvarint(0)
+	fe.putString(streamTestPath)
+	fe.putString(http.MethodPost)
+	fe.putString("")
+	fe.putHeader(http.Header{})
+	require.NoError(t, sc.handleFrame(&frame{typ: frameRequest, stream: 1,
+		payload: fe.Bytes()}))
+
+	f, err := readFrame(remote)
+	require.NoError(t, err)
+	require.Equal(t, &frame{typ: frameReset, stream: 1, payload: []byte{}}, f)
+	require.Nil(t, sc.getStream(1), "the stream is not served")
+}
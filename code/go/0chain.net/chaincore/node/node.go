@@ -102,9 +102,11 @@ var (
 	NodeTypeMiner   int8 = 0
 	NodeTypeSharder int8 = 1
 	NodeTypeBlobber int8 = 2
+	// NodeTypeObserver is a read-only subscriber to blocks propagation.
+	NodeTypeObserver int8 = 3
 )
 
-var NodeTypeNames = common.CreateLookups("m", "Miner", "s", "Sharder", "b", "Blobber")
+var NodeTypeNames = common.CreateLookups("m", "Miner", "s", "Sharder", "b", "Blobber", "o", "Observer")
 
 /*Node - a struct holding the node information */
 type Node struct {
@@ -577,6 +579,9 @@ func ReadConfig() {
 	SetTimeoutLargeMessage(viper.GetDuration("network.timeout.large_message") * time.Millisecond)
 	SetMaxConcurrentRequests(viper.GetInt("network.max_concurrent_requests"))
 	SetLargeMessageThresholdSize(viper.GetInt("network.large_message_th_size"))
+	SetMaxObservers(viper.GetInt("network.max_observers"))
+	SetObserverKeys(viper.GetStringSlice("network.observer_keys"))
+	SetN2NTransport(viper.GetString("network.n2n_transport"))
 }
 
 //SetID - set the id of the node
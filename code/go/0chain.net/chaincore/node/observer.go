@@ -0,0 +1,243 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// ObserverTTL is time an observer is subscribed for after its last
+// registration. Observers should re-register periodically.
+const ObserverTTL = 5 * time.Minute
+
+var (
+	observersMutex sync.RWMutex
+	// observers are non-validator nodes subscribed to blocks propagation of
+	// this node; they are never registered as nodes, thus, messages from
+	// them are not accepted (read-only)
+	observers = make(map[string]*observer)
+	// MaxObservers is max number of observers subscribed to this node,
+	// zero disables observers
+	MaxObservers = 16
+	// observerKeys are public keys of nodes allowed to subscribe, nobody
+	// can subscribe if it's empty
+	observerKeys = make(map[string]struct{})
+	// verifyObserverHost checks that the host of a new observer is run by
+	// the owner of its key; it's replaced in tests
+	verifyObserverHost = requestObserverWhoAmI
+)
+
+type observer struct {
+	node    *Node
+	expires time.Time
+}
+
+// SetMaxObservers - set the max number of observers
+func SetMaxObservers(maxObservers int) {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+	MaxObservers = maxObservers
+}
+
+// SetObserverKeys sets public keys of nodes allowed to be observers.
+func SetObserverKeys(keys []string) {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+	observerKeys = make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		observerKeys[k] = struct{}{}
+	}
+}
+
+func isObserverKey(publicKey string) bool {
+	observersMutex.RLock()
+	defer observersMutex.RUnlock()
+	_, ok := observerKeys[publicKey]
+	return ok
+}
+
+// isSubscribed returns true if given observer is subscribed with the same
+// address, its host is already verified then.
+func isSubscribed(n *Node) bool {
+	observersMutex.RLock()
+	defer observersMutex.RUnlock()
+	o, ok := observers[n.GetKey()]
+	return ok && time.Now().Before(o.expires) &&
+		o.node.GetN2NURLBase() == n.GetN2NURLBase()
+}
+
+// requestObserverWhoAmI requests the whoami of given observer and checks
+// the observer responds with its own identity.
+func requestObserverWhoAmI(n *Node) error {
+	var client = http.Client{Timeout: TimeoutSmallMessage}
+	resp, err := client.Get(n.GetN2NURLBase() + "/_nh/whoami")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	var line []byte
+	if line, err = ioutil.ReadAll(io.LimitReader(resp.Body, 4096)); err != nil {
+		return err
+	}
+	whoami, err := Read(strings.TrimSpace(string(line)))
+	if err != nil {
+		return err
+	}
+	if whoami.GetKey() != n.GetKey() || whoami.PublicKey != n.PublicKey {
+		return errors.New("the host is run by another node")
+	}
+	return nil
+}
+
+// RegisterObserver subscribes given observer to blocks propagation or
+// extends its subscription.
+func RegisterObserver(n *Node) error {
+	observersMutex.Lock()
+	defer observersMutex.Unlock()
+
+	var now = time.Now()
+	for id, o := range observers {
+		if now.After(o.expires) {
+			delete(observers, id)
+		}
+	}
+
+	if _, ok := observers[n.GetKey()]; !ok && len(observers) >= MaxObservers {
+		return common.NewErrorf("register_observer",
+			"max observers reached: %d", MaxObservers)
+	}
+
+	observers[n.GetKey()] = &observer{node: n, expires: now.Add(ObserverTTL)}
+	return nil
+}
+
+// GetObservers returns all observers with not expired subscription.
+func GetObservers() (list []*Node) {
+	observersMutex.RLock()
+	defer observersMutex.RUnlock()
+
+	var now = time.Now()
+	for _, o := range observers {
+		if now.Before(o.expires) {
+			list = append(list, o.node)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GetKey() < list[j].GetKey()
+	})
+	return
+}
+
+// SendToObservers sends a message to all subscribed observers.
+func SendToObservers(handler SendHandler) []*Node {
+	var list = GetObservers()
+	if len(list) == 0 {
+		return nil
+	}
+	var np = NewPool(NodeTypeObserver)
+	return np.sendTo(len(list), list, handler)
+}
+
+// ObserveHandler subscribes an observer to blocks propagation. Only nodes
+// with configured public keys can subscribe. The observer signs current
+// timestamp the same way nodes do for the status handler, and its whoami
+// must return the same identity to prove it owns the given host.
+func ObserveHandler(ctx context.Context, r *http.Request) (
+	interface{}, error) {
+
+	var (
+		publicKey = r.FormValue("public_key")
+		n2nHost   = r.FormValue("n2n_host")
+		path      = r.FormValue("path")
+		data      = r.FormValue("data")
+		hash      = r.FormValue("hash")
+		signature = r.FormValue("signature")
+	)
+
+	if publicKey == "" || n2nHost == "" || data == "" || hash == "" ||
+		signature == "" {
+		return nil, common.NewError("observe", "missing fields")
+	}
+
+	if !isObserverKey(publicKey) {
+		return nil, common.NewError("observe", "unknown observer key")
+	}
+
+	port, err := strconv.Atoi(r.FormValue("port"))
+	if err != nil || port <= 0 {
+		return nil, common.NewError("observe", "invalid port")
+	}
+
+	var n = Provider()
+	n.SetPublicKey(publicKey)
+	n.N2NHost = n2nHost
+	n.Host = n2nHost
+	n.Port = port
+	n.Path = path
+	n.Type = NodeTypeObserver
+	n.Status = NodeStatusActive
+	n.ComputeProperties()
+
+	if GetNode(n.GetKey()) != nil {
+		return nil, common.NewError("observe",
+			"registered node can't be an observer")
+	}
+
+	if !strings.HasPrefix(data, n.GetKey()+":") {
+		return nil, common.NewError("observe", "data doesn't match the key")
+	}
+	if ok, err := ValidateSignatureTime(data); !ok {
+		return nil, common.NewErrorf("observe", "invalid time: %v", err)
+	}
+	if hash != encryption.Hash(data) {
+		return nil, common.NewError("observe", "invalid hash")
+	}
+	if ok, err := n.Verify(signature, hash); !ok || err != nil {
+		return nil, common.NewError("observe", "invalid signature")
+	}
+	if !isSubscribed(n) {
+		if err = verifyObserverHost(n); err != nil {
+			return nil, common.NewErrorf("observe",
+				"can't verify observer host: %v", err)
+		}
+	}
+
+	if err = RegisterObserver(n); err != nil {
+		return nil, err
+	}
+
+	logging.N2n.Info("observer subscribed", zap.String("id", n.GetKey()),
+		zap.String("n2n_host", n.N2NHost), zap.Int("port", n.Port))
+	return Self.Underlying().GetInfo(), nil
+}
+
+// PeersInfo is response of the peers diagnostic handler.
+type PeersInfo struct {
+	Peers     []*PeerInfo `json:"peers"`
+	Observers []*PeerInfo `json:"observers"`
+}
+
+// PeersHandler returns health and latency of peers and subscribed observers.
+func PeersHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var pi = &PeersInfo{Peers: GetPeersInfo()}
+	for _, n := range GetObservers() {
+		pi.Observers = append(pi.Observers, GetPeer(n.GetKey()).Info(n))
+	}
+	return pi, nil
+}
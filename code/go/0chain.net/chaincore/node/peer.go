@@ -0,0 +1,344 @@
+package node
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"0chain.net/core/logging"
+	metrics "github.com/rcrowley/go-metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// peerBackoffBase is back off period after first failed send to a peer,
+	// it's doubled for every next consecutive failure
+	peerBackoffBase = 500 * time.Millisecond
+	// peerBackoffMax is max back off period
+	peerBackoffMax = 30 * time.Second
+	// peerLatencyRef is latency that halves latency factor of a peer score
+	peerLatencyRef = 100 * time.Millisecond
+	// peerLatencyWeight is weight of last measured latency in the moving
+	// average of a peer latency
+	peerLatencyWeight = 0.2
+	// peerHealthBuckets is number of equal ranges the peers score is split
+	// into; peers of the same range are equally healthy
+	peerHealthBuckets = 10
+)
+
+var (
+	peersMutex sync.RWMutex
+	// peers keeps n2n state of remote nodes by node ID; it survives magic
+	// block changes where the Node instances are recreated
+	peers = make(map[string]*Peer)
+	// n2nRoundTripper, if set, replaces transport of the peers clients
+	n2nRoundTripper http.RoundTripper
+	// peerBackedOffSends counts sends to the peers in back off
+	peerBackedOffSends = metrics.GetOrRegisterCounter("n2n_backed_off_sends", nil)
+)
+
+// SetN2NRoundTripper sets transport of n2n clients of all peers, nil
+// restores the configured one. It's used to run nodes over a simulated
+// network in tests. Existing peers statistics are reset.
+func SetN2NRoundTripper(rt http.RoundTripper) {
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+	n2nRoundTripper = rt
+	peers = make(map[string]*Peer)
+}
+
+// Peer is n2n state of a remote node: persistent keep-alive HTTP client,
+// or stream transport client, and send health and latency statistics.
+type Peer struct {
+	mutex sync.RWMutex
+
+	client *http.Client
+
+	successes           int64
+	failures            int64
+	consecutiveFailures int
+	latency             time.Duration // moving average
+	backoffUntil        time.Time
+	lastSuccess         time.Time
+	lastFailure         time.Time
+}
+
+func newPeerClient(id string) *http.Client {
+	if n2nRoundTripper != nil {
+		return &http.Client{Transport: n2nRoundTripper}
+	}
+	var transport = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          16,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       5 * time.Minute,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if N2NTransport == N2NTransportStream {
+		return &http.Client{Transport: NewStreamTransport(id, transport)}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// GetPeer returns n2n state of given node, creating it if missing.
+func GetPeer(id string) *Peer {
+	peersMutex.RLock()
+	p, ok := peers[id]
+	peersMutex.RUnlock()
+	if ok {
+		return p
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+	if p, ok = peers[id]; !ok {
+		p = &Peer{client: newPeerClient(id)}
+		peers[id] = p
+	}
+	return p
+}
+
+// PrunePeers removes n2n state of all nodes not from given list and not
+// subscribed observers, and closes their persistent connections. It's called
+// on magic block change to drop nodes left the network.
+func PrunePeers(keep map[string]struct{}) {
+	var subscribed = make(map[string]struct{})
+	for _, o := range GetObservers() {
+		subscribed[o.GetKey()] = struct{}{}
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	for id, p := range peers {
+		if _, ok := keep[id]; ok {
+			continue
+		}
+		if _, ok := subscribed[id]; ok {
+			continue
+		}
+		if st, ok := p.client.Transport.(*StreamTransport); ok {
+			st.Close()
+		}
+		p.client.CloseIdleConnections()
+		delete(peers, id)
+	}
+}
+
+// Client returns HTTP client with persistent connections to the peer.
+func (p *Peer) Client() *http.Client {
+	return p.client
+}
+
+// RecordSuccess updates the peer statistics with successful send.
+func (p *Peer) RecordSuccess(latency time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.successes++
+	p.consecutiveFailures = 0
+	p.backoffUntil = time.Time{}
+	p.lastSuccess = time.Now()
+	if p.latency == 0 {
+		p.latency = latency
+	} else {
+		p.latency = time.Duration(peerLatencyWeight*float64(latency) +
+			(1-peerLatencyWeight)*float64(p.latency))
+	}
+}
+
+// RecordFailure updates the peer statistics with failed send and puts the
+// peer to back off.
+func (p *Peer) RecordFailure() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.failures++
+	p.consecutiveFailures++
+	p.lastFailure = time.Now()
+
+	var backoff = peerBackoffBase
+	for i := 1; i < p.consecutiveFailures && backoff < peerBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > peerBackoffMax {
+		backoff = peerBackoffMax
+	}
+	p.backoffUntil = p.lastFailure.Add(backoff)
+}
+
+// IsBackedOff returns true if the peer should be tried after the others.
+func (p *Peer) IsBackedOff() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return time.Now().Before(p.backoffUntil)
+}
+
+// Latency returns moving average of the peer latency.
+func (p *Peer) Latency() time.Duration {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.latency
+}
+
+// Score returns health score of the peer in (0; 1] range. It's product of
+// smoothed success ratio and latency factor.
+func (p *Peer) Score() float64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.score()
+}
+
+func (p *Peer) score() float64 {
+	var (
+		health  = float64(p.successes+1) / float64(p.successes+p.failures+2)
+		latency = 1 / (1 + float64(p.latency)/float64(peerLatencyRef))
+	)
+	return health * latency
+}
+
+// PeerInfo is diagnostic information of a peer.
+type PeerInfo struct {
+	ID                  string    `json:"id"`
+	Type                string    `json:"type"`
+	SetIndex            int       `json:"set_index"`
+	Host                string    `json:"host"`
+	Port                int       `json:"port"`
+	Active              bool      `json:"active"`
+	Score               float64   `json:"score"`
+	LatencyMs           float64   `json:"latency_ms"`
+	Successes           int64     `json:"successes"`
+	Failures            int64     `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	BackoffMs           int64     `json:"backoff_ms"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastFailure         time.Time `json:"last_failure"`
+}
+
+// Info returns diagnostic information of the peer for given node.
+func (p *Peer) Info(n *Node) *PeerInfo {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var pi = &PeerInfo{
+		ID:                  n.GetKey(),
+		Type:                n.GetNodeTypeName(),
+		SetIndex:            n.SetIndex,
+		Host:                n.N2NHost,
+		Port:                n.Port,
+		Active:              n.IsActive(),
+		Score:               p.score(),
+		LatencyMs:           float64(p.latency) / float64(time.Millisecond),
+		Successes:           p.successes,
+		Failures:            p.failures,
+		ConsecutiveFailures: p.consecutiveFailures,
+		LastSuccess:         p.lastSuccess,
+		LastFailure:         p.lastFailure,
+	}
+	if left := time.Until(p.backoffUntil); left > 0 {
+		pi.BackoffMs = int64(left / time.Millisecond)
+	}
+	return pi
+}
+
+// GetPeersInfo returns diagnostic information of all registered nodes
+// except this one.
+func GetPeersInfo() (infos []*PeerInfo) {
+	for _, n := range CopyNodes() {
+		if Self.IsEqual(n) {
+			continue
+		}
+		infos = append(infos, GetPeer(n.GetKey()).Info(n))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Type == infos[j].Type {
+			return infos[i].SetIndex < infos[j].SetIndex
+		}
+		return infos[i].Type < infos[j].Type
+	})
+	return
+}
+
+// doPeerRequest sends given request to given node using persistent
+// connections of its peer and updates the peer statistics.
+func doPeerRequest(n *Node, req *http.Request) (*http.Response, error) {
+	var (
+		peer  = GetPeer(n.GetKey())
+		start = time.Now()
+	)
+	resp, err := peer.Client().Do(req)
+	if err != nil {
+		peer.RecordFailure()
+		logging.N2n.Debug("peer send failed", zap.String("to", n.GetKey()),
+			zap.Error(err))
+		return nil, err
+	}
+	// the peer is reachable, but can't serve requests
+	if resp.StatusCode >= http.StatusInternalServerError {
+		peer.RecordFailure()
+		logging.N2n.Debug("peer send failed", zap.String("to", n.GetKey()),
+			zap.Int("status", resp.StatusCode))
+		return resp, nil
+	}
+	peer.RecordSuccess(time.Since(start))
+	return resp, nil
+}
+
+// isBackedOff returns true if the node should be tried after the others.
+func (n *Node) isBackedOff() bool {
+	return GetPeer(n.GetKey()).IsBackedOff()
+}
+
+// backedOffLast returns the nodes with the backed off peers moved to the end
+// keeping the order otherwise. The backed off peers are not skipped, a single
+// failure must not drop consensus messages to a peer, they are tried after
+// the others and counted.
+func backedOffLast(nodes []*Node) []*Node {
+	var (
+		sorted    = make([]*Node, 0, len(nodes))
+		backedOff []*Node
+	)
+	for _, n := range nodes {
+		if n.isBackedOff() {
+			backedOff = append(backedOff, n)
+			continue
+		}
+		sorted = append(sorted, n)
+	}
+	if len(backedOff) > 0 {
+		peerBackedOffSends.Inc(int64(len(backedOff)))
+		logging.N2n.Debug("send to backed off peers",
+			zap.Int("backed_off", len(backedOff)))
+	}
+	return append(sorted, backedOff...)
+}
+
+// healthBucket returns health range of the peer, greater is healthier.
+func (p *Peer) healthBucket() int {
+	return int(p.Score() * peerHealthBuckets)
+}
+
+// healthiestNodes returns nodes of the pool ordered by their peers health,
+// equally healthy nodes are shuffled to spread the load among them.
+func (np *Pool) healthiestNodes() (nodes []*Node) {
+	nodes = np.CopyNodes()
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+	var buckets = make(map[*Node]int, len(nodes))
+	for _, n := range nodes {
+		buckets[n] = GetPeer(n.GetKey()).healthBucket()
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return buckets[nodes[i]] > buckets[nodes[j]]
+	})
+	return
+}
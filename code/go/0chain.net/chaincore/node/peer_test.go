@@ -0,0 +1,281 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/client"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerBackoff(t *testing.T) {
+	var p = GetPeer("peer_backoff")
+	require.True(t, p == GetPeer("peer_backoff"), "same peer")
+	require.False(t, p.IsBackedOff())
+
+	var prev time.Time
+	for i := 0; i < 10; i++ {
+		p.RecordFailure()
+		require.True(t, p.IsBackedOff())
+		require.True(t, p.backoffUntil.Sub(p.lastFailure) <= peerBackoffMax)
+		require.True(t, p.backoffUntil.After(prev))
+		prev = p.backoffUntil
+	}
+	require.Equal(t, peerBackoffMax, p.backoffUntil.Sub(p.lastFailure))
+
+	p.RecordSuccess(10 * time.Millisecond)
+	require.False(t, p.IsBackedOff())
+	require.Equal(t, 10*time.Millisecond, p.Latency())
+
+	var info = p.Info(&Node{Type: NodeTypeMiner})
+	require.EqualValues(t, 1, info.Successes)
+	require.EqualValues(t, 10, info.Failures)
+	require.Zero(t, info.ConsecutiveFailures)
+	require.Zero(t, info.BackoffMs)
+}
+
+func TestPeerSendOrder(t *testing.T) {
+	var np = NewPool(NodeTypeSharder)
+	for i := 0; i < 3; i++ {
+		var nd = Provider()
+		nd.Type = NodeTypeSharder
+		nd.ID = fmt.Sprintf("peer_scorer_%d", i)
+		np.AddNode(nd)
+	}
+	np.ComputeProperties()
+
+	// 0 - slow, 1 - failing, 2 - fast
+	GetPeer("peer_scorer_0").RecordSuccess(time.Second)
+	GetPeer("peer_scorer_1").RecordFailure()
+	GetPeer("peer_scorer_1").RecordFailure()
+	GetPeer("peer_scorer_2").RecordSuccess(time.Millisecond)
+
+	var nodes = np.healthiestNodes()
+	require.Equal(t, "peer_scorer_2", nodes[0].GetKey())
+	nodes = []*Node{np.GetNode("peer_scorer_0"), np.GetNode("peer_scorer_1"),
+		np.GetNode("peer_scorer_2")}
+
+	// backed off peer is tried last, but tried
+	var (
+		sent  []string
+		count = peerBackedOffSends.Count()
+	)
+	np.sendOne(func(n *Node) bool {
+		sent = append(sent, n.GetKey())
+		return false
+	}, []*Node{nodes[2], nodes[1], nodes[0]})
+	require.Equal(t, []string{"peer_scorer_2", "peer_scorer_0",
+		"peer_scorer_1"}, sent)
+	require.EqualValues(t, count+1, peerBackedOffSends.Count())
+
+	var mu sync.Mutex
+	sent = nil
+	np.sendTo(3, []*Node{nodes[2], nodes[1], nodes[0]}, func(n *Node) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, n.GetKey())
+		return true
+	})
+	require.Len(t, sent, 3, "broadcast reaches the backed off peer")
+}
+
+func TestPeerHealthiestNodes(t *testing.T) {
+	var np = NewPool(NodeTypeSharder)
+	for i := 0; i < 5; i++ {
+		var nd = Provider()
+		nd.Type = NodeTypeSharder
+		nd.ID = fmt.Sprintf("peer_healthiest_%d", i)
+		np.AddNode(nd)
+	}
+	np.ComputeProperties()
+	GetPeer("peer_healthiest_4").RecordFailure()
+
+	var first = make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		var nodes = np.healthiestNodes()
+		require.Len(t, nodes, 5)
+		require.Equal(t, "peer_healthiest_4", nodes[4].GetKey())
+		first[nodes[0].GetKey()] = true
+	}
+	require.True(t, len(first) > 1, "equally healthy peers are shuffled")
+}
+
+func TestDoPeerRequest(t *testing.T) {
+	var status = http.StatusOK
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	defer srv.Close()
+
+	var n = Provider()
+	n.ID = "peer_do_request"
+	var do = func() {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := doPeerRequest(n, req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	do()
+	var p = GetPeer(n.ID)
+	require.EqualValues(t, 1, p.Info(n).Successes)
+
+	status = http.StatusServiceUnavailable
+	do()
+	require.EqualValues(t, 1, p.Info(n).Failures)
+	require.True(t, p.IsBackedOff())
+
+	PrunePeers(map[string]struct{}{})
+	peersMutex.RLock()
+	_, ok := peers[n.ID]
+	peersMutex.RUnlock()
+	require.False(t, ok, "pruned")
+}
+
+func TestRegisterObserver(t *testing.T) {
+	defer SetMaxObservers(MaxObservers)
+	SetMaxObservers(1)
+
+	var o1, o2 = Provider(), Provider()
+	o1.ID, o2.ID = "observer_1", "observer_2"
+
+	require.NoError(t, RegisterObserver(o1))
+	require.NoError(t, RegisterObserver(o1), "refresh")
+	require.Error(t, RegisterObserver(o2), "limit")
+	require.Len(t, GetObservers(), 1)
+
+	// expired
+	observersMutex.Lock()
+	observers[o1.ID].expires = time.Now().Add(-time.Second)
+	observersMutex.Unlock()
+	require.Empty(t, GetObservers())
+	require.NoError(t, RegisterObserver(o2))
+	require.Len(t, GetObservers(), 1)
+	require.Equal(t, o2.ID, GetObservers()[0].ID)
+
+	observersMutex.Lock()
+	delete(observers, o2.ID)
+	observersMutex.Unlock()
+}
+
+func TestObserveHandler(t *testing.T) {
+	client.SetClientSignatureScheme("ed25519")
+	if Self == nil || Self.Node == nil {
+		Self = &SelfNode{}
+		Self.Node = Provider()
+	}
+
+	var scheme = encryption.NewED25519Scheme()
+	require.NoError(t, scheme.GenerateKeys())
+
+	var (
+		id   = encryption.Hash(mustDecodeHex(t, scheme.GetPublicKey()))
+		data = fmt.Sprintf("%v:%v", id, common.Now())
+		hash = encryption.Hash(data)
+	)
+	signature, err := scheme.Sign(hash)
+	require.NoError(t, err)
+
+	var observe = func(values url.Values) error {
+		req, err := http.NewRequest(http.MethodPost, "/_nh/observe",
+			strings.NewReader(values.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err = ObserveHandler(context.Background(), req)
+		return err
+	}
+
+	var verified []string
+	defer func(verify func(*Node) error) { verifyObserverHost = verify }(
+		verifyObserverHost)
+	verifyObserverHost = func(n *Node) error {
+		verified = append(verified, n.GetKey())
+		if n.Port != 7171 {
+			return errors.New("not owned")
+		}
+		return nil
+	}
+
+	var values = url.Values{
+		"public_key": {scheme.GetPublicKey()},
+		"n2n_host":   {"127.0.0.1"},
+		"port":       {"7171"},
+		"data":       {data},
+		"hash":       {hash},
+		"signature":  {signature},
+	}
+
+	require.Error(t, observe(values), "unknown key")
+	defer SetObserverKeys(nil)
+	SetObserverKeys([]string{scheme.GetPublicKey()})
+
+	var bad = url.Values{}
+	for k, v := range values {
+		bad[k] = v
+	}
+	bad.Set("port", "7172")
+	require.Error(t, observe(bad), "foreign host")
+
+	bad.Set("port", "7171")
+	bad.Set("signature", signature[2:]+"00")
+	require.Error(t, observe(bad), "invalid signature")
+
+	bad.Set("signature", signature)
+	bad.Set("data", "another:"+strings.Split(data, ":")[1])
+	require.Error(t, observe(bad), "foreign data")
+
+	require.NoError(t, observe(values))
+	require.NoError(t, observe(values), "refresh")
+	require.Equal(t, []string{id, id}, verified,
+		"the host is verified on subscription only")
+
+	var found bool
+	for _, o := range GetObservers() {
+		if o.GetKey() == id {
+			found = true
+			require.Equal(t, NodeTypeObserver, o.Type)
+		}
+	}
+	require.True(t, found)
+
+	observersMutex.Lock()
+	delete(observers, id)
+	observersMutex.Unlock()
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	var n = Provider()
+	n.SetPublicKey(s)
+	require.NotEmpty(t, n.PublicKeyBytes)
+	return n.PublicKeyBytes
+}
+
+type testRoundTripper struct{}
+
+func (testRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, common.NewError("test", "test transport")
+}
+
+func TestSetN2NRoundTripper(t *testing.T) {
+	var rt = testRoundTripper{}
+	SetN2NRoundTripper(rt)
+	var p = GetPeer("peer_round_tripper")
+	require.Equal(t, rt, p.Client().Transport)
+
+	SetN2NRoundTripper(nil)
+	require.False(t, p == GetPeer("peer_round_tripper"), "peers reset")
+	require.NotEqual(t, rt, GetPeer("peer_round_tripper").Client().Transport)
+}
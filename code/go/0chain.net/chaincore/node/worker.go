@@ -128,7 +128,7 @@ func (np *Pool) statusMonitor(ctx context.Context, startRound int64) {
 			reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 			req = req.WithContext(reqCtx)
-			resp, err := httpClient.Do(req)
+			resp, err := doPeerRequest(nd, req)
 			if err != nil {
 				nd.AddErrorCount(1) // ++
 				var nodeInActive bool
@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"0chain.net/core/logging"
+	"0chain.net/core/viper"
+)
+
+// Config of the gateway.
+type Config struct {
+	// Enabled turns the gateway on.
+	Enabled bool
+	// PortOffset is offset of the port of the gateway from the port of the
+	// node, the gateway is disabled if it's zero.
+	PortOffset int
+	// StreamBuffer is max number of the finalized blocks buffered for a
+	// stream, the stream is closed when the buffer is full.
+	StreamBuffer int
+	// MaxStreams is max number of concurrent streams of the finalized
+	// blocks, gRPC and JSON-RPC ones.
+	MaxStreams int
+	// ReadHeaderTimeout, ReadTimeout and IdleTimeout of the connections of
+	// the gateway. There's no write timeout, it would close the streams.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	IdleTimeout       time.Duration
+}
+
+// Defaults of the gateway configurations.
+const (
+	DefaultStreamBuffer      = 64
+	DefaultMaxStreams        = 256
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+var (
+	mu     sync.Mutex
+	server *http.Server
+)
+
+// ReadConfig reads the 'rpc' section of the configurations and starts the
+// gateway of the node with given port.
+func ReadConfig(ctx context.Context, port int) error {
+	return Setup(ctx, &Config{
+		Enabled:           viper.GetBool("rpc.enabled"),
+		PortOffset:        viper.GetInt("rpc.port_offset"),
+		StreamBuffer:      viper.GetInt("rpc.stream_buffer"),
+		MaxStreams:        viper.GetInt("rpc.max_streams"),
+		ReadHeaderTimeout: viper.GetDuration("rpc.read_header_timeout"),
+		ReadTimeout:       viper.GetDuration("rpc.read_timeout"),
+		IdleTimeout:       viper.GetDuration("rpc.idle_timeout"),
+	}, port)
+}
+
+// Setup starts the gateway of the node with given port. The gateway is
+// stopped when given context is done. Previous gateway, if any, is stopped.
+func Setup(ctx context.Context, c *Config, port int) error {
+	if c.StreamBuffer <= 0 {
+		c.StreamBuffer = DefaultStreamBuffer
+	}
+	if c.MaxStreams <= 0 {
+		c.MaxStreams = DefaultMaxStreams
+	}
+	if c.ReadHeaderTimeout <= 0 {
+		c.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = DefaultReadTimeout
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if server != nil {
+		server.Close()
+		server = nil
+	}
+	setFeed(NewFeed(c.StreamBuffer, c.MaxStreams))
+	if !c.Enabled || c.PortOffset == 0 {
+		return nil
+	}
+
+	var addr = fmt.Sprintf(":%d", port+c.PortOffset)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	var srv = &http.Server{
+		Handler:           NewHandler(NewService(http.DefaultServeMux)),
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		IdleTimeout:       c.IdleTimeout,
+	}
+	server = srv
+
+	go func() {
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			logging.Logger.Error("rpc gateway", zap.Error(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		if server == srv {
+			server.Close()
+			server = nil
+		}
+	}()
+	logging.Logger.Info("rpc gateway started", zap.String("address", addr))
+	return nil
+}
+
+// NewHandler returns handler of the gRPC and the JSON-RPC requests to the
+// service. The gRPC is served over the HTTP/2 without TLS, the JSON-RPC is
+// served over the HTTP/1.1 and the HTTP/2.
+func NewHandler(s *Service) http.Handler {
+	var (
+		gs      = NewGRPCServer(s)
+		jsonRPC = NewJSONRPCHandler(s)
+	)
+	return h2c.NewHandler(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor == 2 && strings.HasPrefix(
+				r.Header.Get("Content-Type"), "application/grpc") {
+				gs.ServeHTTP(w, r)
+				return
+			}
+			jsonRPC.ServeHTTP(w, r)
+		}), &http2.Server{})
+}
@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"sync"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/rpc/rpcpb"
+)
+
+// Feed of the finalized blocks. A subscriber is dropped when its buffer is
+// full, so a slow subscriber never blocks the finalization.
+type Feed struct {
+	mu     sync.Mutex
+	buffer int
+	max    int
+	subs   map[chan *rpcpb.BlockSummary]struct{}
+}
+
+// NewFeed returns feed buffering given number of blocks per subscriber and
+// keeping up to max subscribers, zero max is unlimited.
+func NewFeed(buffer, max int) *Feed {
+	if buffer <= 0 {
+		buffer = 1
+	}
+	return &Feed{
+		buffer: buffer,
+		max:    max,
+		subs:   make(map[chan *rpcpb.BlockSummary]struct{}),
+	}
+}
+
+// Subscribe to the feed. The returned channel is closed when the subscriber
+// is dropped or cancelled. The cancel function must be called when the
+// subscriber is not needed anymore. It fails if the feed has max
+// subscribers already.
+func (f *Feed) Subscribe() (c <-chan *rpcpb.BlockSummary, cancel func(),
+	err error) {
+
+	var sc = make(chan *rpcpb.BlockSummary, f.buffer)
+	f.mu.Lock()
+	if f.max > 0 && len(f.subs) >= f.max {
+		f.mu.Unlock()
+		return nil, nil, errTooManyStreams
+	}
+	f.subs[sc] = struct{}{}
+	f.mu.Unlock()
+	return sc, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subs[sc]; ok {
+			delete(f.subs, sc)
+			close(sc)
+		}
+	}, nil
+}
+
+// Len returns number of the subscribers.
+func (f *Feed) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+// Publish the block summary to the subscribers. The summary must not be
+// changed after.
+func (f *Feed) Publish(bs *rpcpb.BlockSummary) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sc := range f.subs {
+		select {
+		case sc <- bs:
+		default:
+			delete(f.subs, sc)
+			close(sc)
+		}
+	}
+}
+
+var (
+	feedMu sync.RWMutex
+	feed   = NewFeed(64, 0)
+)
+
+// GetFeed returns the feed of the finalized blocks of the node.
+func GetFeed() *Feed {
+	feedMu.RLock()
+	defer feedMu.RUnlock()
+	return feed
+}
+
+func setFeed(f *Feed) {
+	feedMu.Lock()
+	defer feedMu.Unlock()
+	feed = f
+}
+
+// PublishFinalizedBlock publishes the block to the subscribers of the
+// finalized blocks, if any.
+func PublishFinalizedBlock(b *block.Block) {
+	var f = GetFeed()
+	if f.Len() == 0 {
+		return
+	}
+	f.Publish(blockSummary(b))
+}
+
+func blockSummary(b *block.Block) *rpcpb.BlockSummary {
+	return &rpcpb.BlockSummary{
+		Version:               b.Version,
+		CreationDate:          int64(b.CreationDate),
+		Hash:                  b.Hash,
+		MinerId:               b.MinerID,
+		Round:                 b.Round,
+		RoundRandomSeed:       b.GetRoundRandomSeed(),
+		MerkleTreeRoot:        b.GetMerkleTree().GetRoot(),
+		StateHash:             b.ClientStateHash,
+		ReceiptMerkleTreeRoot: b.GetReceiptsMerkleTree().GetRoot(),
+		NumTxns:               int32(len(b.Txns)),
+	}
+}
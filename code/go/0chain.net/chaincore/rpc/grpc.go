@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"0chain.net/chaincore/rpc/rpcpb"
+)
+
+// ErrorCodeKey is the trailer metadata key of the code of the error of a
+// gRPC call, the code is the code of the error response of the REST API.
+const ErrorCodeKey = "x-error-code"
+
+// NewGRPCServer returns gRPC server of the service.
+func NewGRPCServer(s *Service) *grpc.Server {
+	var gs = grpc.NewServer(
+		grpc.UnaryInterceptor(unaryErrors),
+		grpc.StreamInterceptor(streamErrors),
+	)
+	rpcpb.RegisterNodeServer(gs, s)
+	return gs
+}
+
+func unaryErrors(ctx context.Context, req interface{},
+	_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return resp, nil
+}
+
+func streamErrors(srv interface{}, ss grpc.ServerStream,
+	_ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	if err := handler(srv, ss); err != nil {
+		return grpcError(ss.Context(), err)
+	}
+	return nil
+}
+
+// grpcError converts error of a call to the gRPC status error, the code of
+// the error is sent in the trailer
+func grpcError(ctx context.Context, err error) error {
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeKey, rpcErr.Code))
+	return status.Error(grpcCode(rpcErr.Status), rpcErr.Msg)
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch {
+	case httpStatus == http.StatusBadRequest:
+		return codes.InvalidArgument
+	case httpStatus == http.StatusNotFound:
+		return codes.NotFound
+	case httpStatus == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case httpStatus == http.StatusMethodNotAllowed:
+		return codes.Unimplemented
+	case httpStatus >= http.StatusInternalServerError:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,378 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"0chain.net/chaincore/rpc/rpcpb"
+	"0chain.net/core/common"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+	// JSONRPCNotFound is the code of the 'resource_not_found' errors.
+	JSONRPCNotFound = -32001
+	// JSONRPCTooManyRequests is the code of the 'too_many_requests' errors.
+	JSONRPCTooManyRequests = -32002
+)
+
+// JSON-RPC methods streaming the finalized blocks.
+const (
+	// StreamFinalizedBlocksMethod subscribes to the finalized blocks. The
+	// blocks are sent as FinalizedBlockMethod notifications, one per line,
+	// the response of the call ends the stream.
+	StreamFinalizedBlocksMethod = "streamFinalizedBlocks"
+	// FinalizedBlockMethod is the method of the notifications of the
+	// finalized blocks.
+	FinalizedBlockMethod = "finalizedBlock"
+)
+
+const (
+	// maxJSONRPCBody is max size of the body of a JSON-RPC request
+	maxJSONRPCBody = 4 << 20
+	// maxJSONRPCBatch is max number of requests of a JSON-RPC batch
+	maxJSONRPCBatch = 100
+)
+
+// JSONRPCError is the error object of a JSON-RPC response.
+type JSONRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type jsonRPCRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// isNotification of the request, which is not responded
+func (req *jsonRPCRequest) isNotification() bool {
+	return len(req.ID) == 0
+}
+
+type jsonRPCResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
+type jsonRPCNotification struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// jsonRPCMethod calls the method with given params
+type jsonRPCMethod func(ctx context.Context, params json.RawMessage) (
+	proto.Message, error)
+
+// JSONRPCHandler serves the Node service over the JSON-RPC 2.0. The params
+// and the results are the messages of the service in the protobuf JSON
+// mapping, the methods are named as the service methods in lower camel case.
+type JSONRPCHandler struct {
+	methods map[string]jsonRPCMethod
+}
+
+// NewJSONRPCHandler returns JSON-RPC handler of the service.
+func NewJSONRPCHandler(s *Service) *JSONRPCHandler {
+	return &JSONRPCHandler{methods: map[string]jsonRPCMethod{
+		"submitTransaction": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.Transaction
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.SubmitTransaction(ctx, &req)
+		},
+		"getTransactionStatus": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.GetTransactionStatusRequest
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.GetTransactionStatus(ctx, &req)
+		},
+		"getBalance": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.GetBalanceRequest
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.GetBalance(ctx, &req)
+		},
+		"getBlock": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.GetBlockRequest
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.GetBlock(ctx, &req)
+		},
+		"getMagicBlock": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.GetMagicBlockRequest
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.GetMagicBlock(ctx, &req)
+		},
+		"callSCRest": func(ctx context.Context,
+			params json.RawMessage) (proto.Message, error) {
+			var req rpcpb.CallSCRestRequest
+			if err := decodeJSONRPCParams(params, &req); err != nil {
+				return nil, err
+			}
+			return s.CallSCRest(ctx, &req)
+		},
+	}}
+}
+
+// ServeHTTP serves a JSON-RPC request, or a batch of the requests.
+func (h *JSONRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		common.RespondError(w, http.StatusMethodNotAllowed,
+			common.NewErrBadRequest("JSON-RPC requests must be POST"))
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body,
+		maxJSONRPCBody))
+	if err != nil {
+		writeJSON(w, newJSONRPCError(nil, JSONRPCParseError, err.Error()))
+		return
+	}
+	var ctx = withCaller(r.Context(), r)
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		var req jsonRPCRequest
+		if err = json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, newJSONRPCError(nil, JSONRPCParseError, err.Error()))
+			return
+		}
+		if req.Method == StreamFinalizedBlocksMethod {
+			h.stream(ctx, w, &req)
+			return
+		}
+		if resp := h.call(ctx, &req); resp != nil {
+			writeJSON(w, resp)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var batch []json.RawMessage
+	if err = json.Unmarshal(body, &batch); err != nil {
+		writeJSON(w, newJSONRPCError(nil, JSONRPCParseError, err.Error()))
+		return
+	}
+	if len(batch) == 0 {
+		writeJSON(w, newJSONRPCError(nil, JSONRPCInvalidRequest,
+			"empty batch"))
+		return
+	}
+	if len(batch) > maxJSONRPCBatch {
+		writeJSON(w, newJSONRPCError(nil, JSONRPCInvalidRequest,
+			fmt.Sprintf("too many requests in batch, max %d", maxJSONRPCBatch)))
+		return
+	}
+	var resps = make([]*jsonRPCResponse, 0, len(batch))
+	for _, raw := range batch {
+		var req jsonRPCRequest
+		if err = json.Unmarshal(raw, &req); err != nil {
+			resps = append(resps, newJSONRPCError(nil, JSONRPCInvalidRequest,
+				err.Error()))
+			continue
+		}
+		if req.Method == StreamFinalizedBlocksMethod {
+			resps = append(resps, newJSONRPCError(req.ID,
+				JSONRPCInvalidRequest, "streams can't be batched"))
+			continue
+		}
+		if resp := h.call(ctx, &req); resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	if len(resps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, resps)
+}
+
+// call the method of the request, the response is nil for a notification
+func (h *JSONRPCHandler) call(ctx context.Context,
+	req *jsonRPCRequest) *jsonRPCResponse {
+
+	var resp = h.do(ctx, req)
+	if req.isNotification() {
+		return nil
+	}
+	return resp
+}
+
+func (h *JSONRPCHandler) do(ctx context.Context,
+	req *jsonRPCRequest) *jsonRPCResponse {
+
+	if req.Version != "2.0" || req.Method == "" {
+		return newJSONRPCError(req.ID, JSONRPCInvalidRequest,
+			"not a JSON-RPC 2.0 request")
+	}
+	var method, ok = h.methods[req.Method]
+	if !ok {
+		return newJSONRPCError(req.ID, JSONRPCMethodNotFound,
+			"method not found: "+req.Method)
+	}
+	result, err := method(ctx, req.Params)
+	if err != nil {
+		return &jsonRPCResponse{Version: "2.0", ID: req.ID,
+			Error: toJSONRPCError(err)}
+	}
+	data, err := marshal(result)
+	if err != nil {
+		return newJSONRPCError(req.ID, JSONRPCInternalError, err.Error())
+	}
+	return &jsonRPCResponse{Version: "2.0", ID: req.ID, Result: data}
+}
+
+// stream the finalized blocks as the notifications, one per line, until
+// the client disconnects or doesn't keep up with the finalization
+func (h *JSONRPCHandler) stream(ctx context.Context, w http.ResponseWriter,
+	req *jsonRPCRequest) {
+
+	if req.Version != "2.0" {
+		writeJSON(w, newJSONRPCError(req.ID, JSONRPCInvalidRequest,
+			"not a JSON-RPC 2.0 request"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, newJSONRPCError(req.ID, JSONRPCInternalError,
+			"streaming is not supported"))
+		return
+	}
+
+	c, cancel, err := GetFeed().Subscribe()
+	if err != nil {
+		writeJSON(w, &jsonRPCResponse{Version: "2.0", ID: req.ID,
+			Error: toJSONRPCError(err)})
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var enc = json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case bs, ok := <-c:
+			if !ok {
+				if !req.isNotification() {
+					enc.Encode(&jsonRPCResponse{Version: "2.0", ID: req.ID,
+						Error: toJSONRPCError(errTooSlow)})
+				}
+				return
+			}
+			params, err := marshal(bs)
+			if err != nil {
+				return
+			}
+			if err = enc.Encode(&jsonRPCNotification{Version: "2.0",
+				Method: FinalizedBlockMethod, Params: params}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func decodeJSONRPCParams(params json.RawMessage, req proto.Message) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := protojson.Unmarshal(params, req); err != nil {
+		return &JSONRPCError{Code: JSONRPCInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (err *JSONRPCError) Error() string {
+	return err.Message
+}
+
+// marshal the message in the protobuf JSON mapping, the output is compacted
+// since the protojson output is not stable
+func marshal(m proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func newJSONRPCError(id json.RawMessage, code int,
+	msg string) *jsonRPCResponse {
+
+	return &jsonRPCResponse{Version: "2.0", ID: id,
+		Error: &JSONRPCError{Code: code, Message: msg}}
+}
+
+// toJSONRPCError converts error of a call to the JSON-RPC error, the code
+// of the error response of the REST API is the 'code' of the data
+func toJSONRPCError(err error) *JSONRPCError {
+	var jsonErr *JSONRPCError
+	if errors.As(err, &jsonErr) {
+		return jsonErr
+	}
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) {
+		return &JSONRPCError{Code: JSONRPCInternalError, Message: err.Error(),
+			Data: map[string]string{"code": common.ErrInternalCode}}
+	}
+	var code int
+	switch {
+	case rpcErr.Status == http.StatusNotFound:
+		code = JSONRPCNotFound
+	case rpcErr.Status == http.StatusTooManyRequests:
+		code = JSONRPCTooManyRequests
+	case rpcErr.Status >= http.StatusInternalServerError:
+		code = JSONRPCInternalError
+	default:
+		code = JSONRPCInvalidParams
+	}
+	return &JSONRPCError{Code: code, Message: rpcErr.Msg,
+		Data: map[string]string{"code": rpcErr.Code}}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,335 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"0chain.net/chaincore/rpc/rpcpb"
+	"0chain.net/core/common"
+	"0chain.net/core/quota"
+)
+
+// newTestMux returns mux of the REST handlers used by the tests
+func newTestMux() *http.ServeMux {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/v1/client/get/balance", common.ToJSONResponse(
+		func(ctx context.Context, r *http.Request) (interface{}, error) {
+			switch r.FormValue("client_id") {
+			case "":
+				return nil, common.NewErrBadRequest("missing client_id")
+			case "unknown":
+				return nil, common.NewErrNoResource("client not found")
+			}
+			return map[string]interface{}{
+				"txn": "txn-hash", "round": 10, "balance": 1e10,
+			}, nil
+		}))
+	mux.HandleFunc("/v1/transaction/put", common.ToJSONResponse(
+		func(ctx context.Context, r *http.Request) (interface{}, error) {
+			var txn map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&txn); err != nil {
+				return nil, common.NewErrBadRequest(err.Error())
+			}
+			txn["hash"] = "txn-hash"
+			return map[string]interface{}{"async": true, "entity": txn}, nil
+		}))
+	mux.HandleFunc("/v1/screst/sc-address/getConfig", common.ToJSONResponse(
+		func(ctx context.Context, r *http.Request) (interface{}, error) {
+			return map[string]interface{}{"key": r.FormValue("key")}, nil
+		}))
+	return mux
+}
+
+func TestService_GetBalance(t *testing.T) {
+	var s = NewService(newTestMux())
+
+	balance, err := s.GetBalance(context.Background(),
+		&rpcpb.GetBalanceRequest{ClientId: "client"})
+	require.NoError(t, err)
+	assert.Equal(t, "txn-hash", balance.Txn)
+	assert.EqualValues(t, 10, balance.Round)
+	assert.EqualValues(t, 1e10, balance.Balance)
+
+	_, err = s.GetBalance(context.Background(),
+		&rpcpb.GetBalanceRequest{ClientId: "unknown"})
+	require.Error(t, err)
+	assert.Equal(t, &Error{
+		Status: http.StatusNotFound,
+		Code:   common.ErrNoResourceCode,
+		Msg:    "resource_not_found: client not found",
+	}, err)
+}
+
+func TestService_SubmitTransaction(t *testing.T) {
+	var s = NewService(newTestMux())
+
+	txn, err := s.SubmitTransaction(context.Background(), &rpcpb.Transaction{
+		ClientId:         "client",
+		TransactionValue: 100,
+		CreationDate:     1600000000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "txn-hash", txn.Hash)
+	assert.Equal(t, "client", txn.ClientId)
+	assert.EqualValues(t, 100, txn.TransactionValue)
+	assert.EqualValues(t, 1600000000, txn.CreationDate)
+}
+
+func TestService_CallSCRest(t *testing.T) {
+	var s = NewService(newTestMux())
+
+	resp, err := s.CallSCRest(context.Background(), &rpcpb.CallSCRestRequest{
+		ScAddress: "sc-address",
+		Path:      "getConfig",
+		Params:    map[string]string{"key": "value"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"key": "value"},
+		resp.Result.AsInterface())
+
+	_, err = s.CallSCRest(context.Background(), &rpcpb.CallSCRestRequest{
+		ScAddress: "sc-address",
+		Path:      "/unknown",
+	})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, err.(*Error).Status)
+	assert.Equal(t, common.ErrNoResourceCode, err.(*Error).Code)
+}
+
+func TestFeed(t *testing.T) {
+	var (
+		f            = NewFeed(2, 3)
+		c, _, _      = f.Subscribe()
+		slow, _, _   = f.Subscribe()
+		d, cancel, _ = f.Subscribe()
+	)
+	_, _, err := f.Subscribe()
+	assert.Equal(t, errTooManyStreams, err)
+	cancel()
+	_, ok := <-d
+	assert.False(t, ok, "cancelled subscriber")
+	assert.Equal(t, 2, f.Len())
+
+	for r := int64(1); r <= 2; r++ {
+		f.Publish(&rpcpb.BlockSummary{Round: r})
+		assert.EqualValues(t, r, (<-c).Round)
+	}
+	f.Publish(&rpcpb.BlockSummary{Round: 3})
+	assert.Equal(t, 1, f.Len(), "slow subscriber must be dropped")
+
+	var rounds []int64
+	for bs := range slow {
+		rounds = append(rounds, bs.Round)
+	}
+	assert.Equal(t, []int64{1, 2}, rounds)
+
+	_, _, err = f.Subscribe()
+	assert.NoError(t, err)
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *Feed) {
+	var f = NewFeed(10, 1)
+	setFeed(f)
+	var srv = httptest.NewServer(NewHandler(NewService(newTestMux())))
+	t.Cleanup(func() {
+		srv.Close()
+		setFeed(NewFeed(64, 0))
+	})
+	return srv, f
+}
+
+func postJSONRPC(t *testing.T, url, body string) (int, string) {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestJSONRPCHandler(t *testing.T) {
+	var srv, _ = newTestServer(t)
+
+	var tests = []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "call",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"client_id":"c"}}`,
+			status: http.StatusOK,
+			want:   `{"jsonrpc":"2.0","id":1,"result":{"txn":"txn-hash","round":"10","balance":"10000000000"}}`,
+		},
+		{
+			name:   "handler error",
+			body:   `{"jsonrpc":"2.0","id":"a","method":"getBalance","params":{"client_id":"unknown"}}`,
+			status: http.StatusOK,
+			want:   `{"jsonrpc":"2.0","id":"a","error":{"code":-32001,"message":"resource_not_found: client not found","data":{"code":"resource_not_found"}}}`,
+		},
+		{
+			name:   "invalid params",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"unknown":1}}`,
+			status: http.StatusOK,
+			want:   `"code":-32602`,
+		},
+		{
+			name:   "method not found",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"unknown"}`,
+			status: http.StatusOK,
+			want:   `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found: unknown"}}`,
+		},
+		{
+			name:   "parse error",
+			body:   `{"jsonrpc":`,
+			status: http.StatusOK,
+			want:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,`,
+		},
+		{
+			name:   "notification",
+			body:   `{"jsonrpc":"2.0","method":"getBalance","params":{"client_id":"c"}}`,
+			status: http.StatusNoContent,
+			want:   ``,
+		},
+		{
+			name: "batch",
+			body: `[{"jsonrpc":"2.0","id":1,"method":"callSCRest","params":{"sc_address":"sc-address","path":"/getConfig","params":{"key":"v"}}},` +
+				`{"jsonrpc":"2.0","method":"getBalance"},` +
+				`{"jsonrpc":"2.0","id":2,"method":"getBalance"}]`,
+			status: http.StatusOK,
+			want: `[{"jsonrpc":"2.0","id":1,"result":{"result":{"key":"v"}}},` +
+				`{"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"invalid_request: missing client_id","data":{"code":"invalid_request"}}}]`,
+		},
+	}
+
+	var batch = make([]string, maxJSONRPCBatch+1)
+	for i := range batch {
+		batch[i] = `{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"client_id":"c"}}`
+	}
+	tests = append(tests, struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		name:   "too long batch",
+		body:   "[" + strings.Join(batch, ",") + "]",
+		status: http.StatusOK,
+		want:   `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"too many requests in batch, max 100"}}`,
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status, body = postJSONRPC(t, srv.URL, tt.body)
+			assert.Equal(t, tt.status, status)
+			assert.Contains(t, body, tt.want)
+		})
+	}
+}
+
+func TestJSONRPCHandler_streamFinalizedBlocks(t *testing.T) {
+	var srv, f = newTestServer(t)
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"streamFinalizedBlocks"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, func() bool { return f.Len() == 1 }, time.Second,
+		10*time.Millisecond)
+
+	f.Publish(&rpcpb.BlockSummary{Hash: "b1", Round: 1})
+	var line, _ = bufio.NewReader(resp.Body).ReadString('\n')
+	assert.Contains(t, line,
+		`{"jsonrpc":"2.0","method":"finalizedBlock","params":{"version":"","creation_date":"0","hash":"b1","miner_id":"","round":"1",`)
+
+	// the test feed allows one stream only
+	var _, body = postJSONRPC(t, srv.URL,
+		`{"jsonrpc":"2.0","id":2,"method":"streamFinalizedBlocks"}`)
+	assert.Contains(t, body, `{"jsonrpc":"2.0","id":2,"error":{"code":-32002,`)
+}
+
+func TestGRPC(t *testing.T) {
+	var srv, f = newTestServer(t)
+
+	conn, err := grpc.Dial(strings.TrimPrefix(srv.URL, "http://"),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var (
+		client  = rpcpb.NewNodeClient(conn)
+		ctx     = context.Background()
+		trailer metadata.MD
+	)
+	balance, err := client.GetBalance(ctx,
+		&rpcpb.GetBalanceRequest{ClientId: "c"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1e10, balance.Balance)
+
+	_, err = client.GetBalance(ctx, &rpcpb.GetBalanceRequest{},
+		grpc.Trailer(&trailer))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []string{common.ErrBadRequestCode},
+		trailer.Get(ErrorCodeKey))
+
+	stream, err := client.StreamFinalizedBlocks(ctx,
+		&rpcpb.StreamFinalizedBlocksRequest{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return f.Len() == 1 }, time.Second,
+		10*time.Millisecond)
+	f.Publish(&rpcpb.BlockSummary{Hash: "b1", Round: 1})
+	bs, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "b1", bs.Hash)
+}
+
+func TestService_caller(t *testing.T) {
+	var (
+		mux     = http.NewServeMux()
+		apiKeys = make(chan string, 2)
+	)
+	mux.HandleFunc("/v1/client/get/balance", common.ToJSONResponse(
+		func(ctx context.Context, r *http.Request) (interface{}, error) {
+			apiKeys <- r.Header.Get(quota.APIKeyHeader)
+			assert.Contains(t, r.RemoteAddr, "127.0.0.1:")
+			return map[string]interface{}{}, nil
+		}))
+	var srv = httptest.NewServer(NewHandler(NewService(mux)))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"getBalance"}`))
+	require.NoError(t, err)
+	req.Header.Set(quota.APIKeyHeader, "json-key")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "json-key", <-apiKeys)
+
+	conn, err := grpc.Dial(strings.TrimPrefix(srv.URL, "http://"),
+		grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	var ctx = metadata.AppendToOutgoingContext(context.Background(),
+		quota.APIKeyHeader, "grpc-key")
+	_, err = rpcpb.NewNodeClient(conn).GetBalance(ctx,
+		&rpcpb.GetBalanceRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "grpc-key", <-apiKeys)
+}
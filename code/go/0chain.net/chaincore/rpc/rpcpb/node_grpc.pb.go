@@ -0,0 +1,367 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package rpcpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// NodeClient is the client API for Node service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NodeClient interface {
+	// SubmitTransaction puts the signed transaction to the transactions pool,
+	// miners only.
+	SubmitTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Transaction, error)
+	// GetTransactionStatus returns confirmation of the transaction, sharders
+	// only.
+	GetTransactionStatus(ctx context.Context, in *GetTransactionStatusRequest, opts ...grpc.CallOption) (*TransactionStatus, error)
+	// GetBalance returns balance of the client in the state of the latest
+	// finalized block.
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error)
+	// GetBlock returns the block by its hash, or by its round on sharders.
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
+	// GetMagicBlock returns the magic block by its number on sharders, the
+	// latest finalized magic block on miners.
+	GetMagicBlock(ctx context.Context, in *GetMagicBlockRequest, opts ...grpc.CallOption) (*GetMagicBlockResponse, error)
+	// CallSCRest calls the REST handler of the smart contract.
+	CallSCRest(ctx context.Context, in *CallSCRestRequest, opts ...grpc.CallOption) (*CallSCRestResponse, error)
+	// StreamFinalizedBlocks streams the blocks finalized by the node.
+	StreamFinalizedBlocks(ctx context.Context, in *StreamFinalizedBlocksRequest, opts ...grpc.CallOption) (Node_StreamFinalizedBlocksClient, error)
+}
+
+type nodeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNodeClient(cc grpc.ClientConnInterface) NodeClient {
+	return &nodeClient{cc}
+}
+
+func (c *nodeClient) SubmitTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/SubmitTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetTransactionStatus(ctx context.Context, in *GetTransactionStatusRequest, opts ...grpc.CallOption) (*TransactionStatus, error) {
+	out := new(TransactionStatus)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/GetTransactionStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error) {
+	out := new(GetBlockResponse)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetMagicBlock(ctx context.Context, in *GetMagicBlockRequest, opts ...grpc.CallOption) (*GetMagicBlockResponse, error) {
+	out := new(GetMagicBlockResponse)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/GetMagicBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) CallSCRest(ctx context.Context, in *CallSCRestRequest, opts ...grpc.CallOption) (*CallSCRestResponse, error) {
+	out := new(CallSCRestResponse)
+	err := c.cc.Invoke(ctx, "/zchain.rpc.Node/CallSCRest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) StreamFinalizedBlocks(ctx context.Context, in *StreamFinalizedBlocksRequest, opts ...grpc.CallOption) (Node_StreamFinalizedBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Node_ServiceDesc.Streams[0], "/zchain.rpc.Node/StreamFinalizedBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nodeStreamFinalizedBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Node_StreamFinalizedBlocksClient interface {
+	Recv() (*BlockSummary, error)
+	grpc.ClientStream
+}
+
+type nodeStreamFinalizedBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *nodeStreamFinalizedBlocksClient) Recv() (*BlockSummary, error) {
+	m := new(BlockSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NodeServer is the server API for Node service.
+// All implementations must embed UnimplementedNodeServer
+// for forward compatibility
+type NodeServer interface {
+	// SubmitTransaction puts the signed transaction to the transactions pool,
+	// miners only.
+	SubmitTransaction(context.Context, *Transaction) (*Transaction, error)
+	// GetTransactionStatus returns confirmation of the transaction, sharders
+	// only.
+	GetTransactionStatus(context.Context, *GetTransactionStatusRequest) (*TransactionStatus, error)
+	// GetBalance returns balance of the client in the state of the latest
+	// finalized block.
+	GetBalance(context.Context, *GetBalanceRequest) (*Balance, error)
+	// GetBlock returns the block by its hash, or by its round on sharders.
+	GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error)
+	// GetMagicBlock returns the magic block by its number on sharders, the
+	// latest finalized magic block on miners.
+	GetMagicBlock(context.Context, *GetMagicBlockRequest) (*GetMagicBlockResponse, error)
+	// CallSCRest calls the REST handler of the smart contract.
+	CallSCRest(context.Context, *CallSCRestRequest) (*CallSCRestResponse, error)
+	// StreamFinalizedBlocks streams the blocks finalized by the node.
+	StreamFinalizedBlocks(*StreamFinalizedBlocksRequest, Node_StreamFinalizedBlocksServer) error
+	mustEmbedUnimplementedNodeServer()
+}
+
+// UnimplementedNodeServer must be embedded to have forward compatible implementations.
+type UnimplementedNodeServer struct {
+}
+
+func (UnimplementedNodeServer) SubmitTransaction(context.Context, *Transaction) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTransaction not implemented")
+}
+func (UnimplementedNodeServer) GetTransactionStatus(context.Context, *GetTransactionStatusRequest) (*TransactionStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionStatus not implemented")
+}
+func (UnimplementedNodeServer) GetBalance(context.Context, *GetBalanceRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedNodeServer) GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedNodeServer) GetMagicBlock(context.Context, *GetMagicBlockRequest) (*GetMagicBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMagicBlock not implemented")
+}
+func (UnimplementedNodeServer) CallSCRest(context.Context, *CallSCRestRequest) (*CallSCRestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CallSCRest not implemented")
+}
+func (UnimplementedNodeServer) StreamFinalizedBlocks(*StreamFinalizedBlocksRequest, Node_StreamFinalizedBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFinalizedBlocks not implemented")
+}
+func (UnimplementedNodeServer) mustEmbedUnimplementedNodeServer() {}
+
+// UnsafeNodeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NodeServer will
+// result in compilation errors.
+type UnsafeNodeServer interface {
+	mustEmbedUnimplementedNodeServer()
+}
+
+func RegisterNodeServer(s grpc.ServiceRegistrar, srv NodeServer) {
+	s.RegisterService(&Node_ServiceDesc, srv)
+}
+
+func _Node_SubmitTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).SubmitTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/SubmitTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).SubmitTransaction(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetTransactionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetTransactionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/GetTransactionStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetTransactionStatus(ctx, req.(*GetTransactionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetMagicBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMagicBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetMagicBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/GetMagicBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetMagicBlock(ctx, req.(*GetMagicBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_CallSCRest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallSCRestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).CallSCRest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zchain.rpc.Node/CallSCRest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).CallSCRest(ctx, req.(*CallSCRestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_StreamFinalizedBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFinalizedBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServer).StreamFinalizedBlocks(m, &nodeStreamFinalizedBlocksServer{stream})
+}
+
+type Node_StreamFinalizedBlocksServer interface {
+	Send(*BlockSummary) error
+	grpc.ServerStream
+}
+
+type nodeStreamFinalizedBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *nodeStreamFinalizedBlocksServer) Send(m *BlockSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Node_ServiceDesc is the grpc.ServiceDesc for Node service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Node_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "zchain.rpc.Node",
+	HandlerType: (*NodeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitTransaction",
+			Handler:    _Node_SubmitTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransactionStatus",
+			Handler:    _Node_GetTransactionStatus_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Node_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Node_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetMagicBlock",
+			Handler:    _Node_GetMagicBlock_Handler,
+		},
+		{
+			MethodName: "CallSCRest",
+			Handler:    _Node_CallSCRest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFinalizedBlocks",
+			Handler:       _Node_StreamFinalizedBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "node.proto",
+}
@@ -0,0 +1,363 @@
+// Package rpc provides the gRPC and the JSON-RPC 2.0 gateway of the client
+// facing API of a node. The calls are served by the REST handlers of the
+// node, so the gateway and the REST API share the validation, the rate
+// limits and the errors.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"0chain.net/chaincore/rpc/rpcpb"
+	"0chain.net/core/common"
+	"0chain.net/core/quota"
+)
+
+// Error of a call, it's the error response of the REST handler.
+type Error struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Code is the code of the error.
+	Code string
+	// Msg is the message of the error.
+	Msg string
+}
+
+func (err *Error) Error() string {
+	return err.Msg
+}
+
+// Service implements the Node gRPC service calling the REST handlers of
+// the node.
+type Service struct {
+	rpcpb.UnimplementedNodeServer
+	handler http.Handler
+}
+
+// NewService returns service calling the REST handlers registered in given
+// handler, usually the http.DefaultServeMux.
+func NewService(handler http.Handler) *Service {
+	return &Service{handler: handler}
+}
+
+// SubmitTransaction puts the transaction to the transactions pool.
+func (s *Service) SubmitTransaction(ctx context.Context,
+	txn *rpcpb.Transaction) (*rpcpb.Transaction, error) {
+
+	// the REST API expects numbers, so it's not the protojson
+	body, err := json.Marshal(txn)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Entity json.RawMessage `json:"entity"`
+	}
+	if err = s.callJSON(ctx, http.MethodPost, "/v1/transaction/put", nil,
+		body, &resp); err != nil {
+		return nil, err
+	}
+	var out rpcpb.Transaction
+	if err = unmarshal(resp.Entity, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTransactionStatus returns confirmation of the transaction.
+func (s *Service) GetTransactionStatus(ctx context.Context,
+	req *rpcpb.GetTransactionStatusRequest) (*rpcpb.TransactionStatus, error) {
+
+	var q = url.Values{"hash": {req.Hash}}
+	if req.WaitFor != "" {
+		q.Set("wait_for", req.WaitFor)
+	}
+	if req.Timeout != 0 {
+		q.Set("timeout", strconv.FormatInt(req.Timeout, 10))
+	}
+	var out rpcpb.TransactionStatus
+	if err := s.call(ctx, "/v1/transaction/get/confirmation", q,
+		&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetBalance returns balance of the client.
+func (s *Service) GetBalance(ctx context.Context,
+	req *rpcpb.GetBalanceRequest) (*rpcpb.Balance, error) {
+
+	var out rpcpb.Balance
+	if err := s.call(ctx, "/v1/client/get/balance",
+		url.Values{"client_id": {req.ClientId}}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetBlock returns the block by its hash or round.
+func (s *Service) GetBlock(ctx context.Context,
+	req *rpcpb.GetBlockRequest) (*rpcpb.GetBlockResponse, error) {
+
+	var q = url.Values{}
+	if req.Hash != "" {
+		q.Set("block", req.Hash)
+	} else if req.Round != 0 {
+		q.Set("round", strconv.FormatInt(req.Round, 10))
+	}
+	if len(req.Content) > 0 {
+		q.Set("content", strings.Join(req.Content, ","))
+	}
+	var out rpcpb.GetBlockResponse
+	if err := s.call(ctx, "/v1/block/get", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMagicBlock returns the magic block by its number, or the latest
+// finalized one if the number is not set.
+func (s *Service) GetMagicBlock(ctx context.Context,
+	req *rpcpb.GetMagicBlockRequest) (*rpcpb.GetMagicBlockResponse, error) {
+
+	var (
+		out rpcpb.GetMagicBlockResponse
+		err error
+	)
+	if req.MagicBlockNumber > 0 {
+		err = s.call(ctx, "/v1/block/magic/get", url.Values{
+			"magic_block_number": {strconv.FormatInt(req.MagicBlockNumber, 10)},
+		}, &out)
+	} else {
+		err = s.call(ctx, "/v1/block/get/latest_finalized_magic_block", nil,
+			&out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CallSCRest calls the REST handler of the smart contract.
+func (s *Service) CallSCRest(ctx context.Context,
+	req *rpcpb.CallSCRestRequest) (*rpcpb.CallSCRestResponse, error) {
+
+	if req.ScAddress == "" || strings.Contains(req.ScAddress, "/") {
+		return nil, &Error{
+			Status: http.StatusBadRequest,
+			Code:   common.ErrBadRequestCode,
+			Msg:    "invalid smart contract address",
+		}
+	}
+	var path = req.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	var q = url.Values{}
+	for k, v := range req.Params {
+		q.Set(k, v)
+	}
+	var out structpb.Value
+	if err := s.call(ctx, "/v1/screst/"+req.ScAddress+path, q,
+		&out); err != nil {
+		return nil, err
+	}
+	return &rpcpb.CallSCRestResponse{Result: &out}, nil
+}
+
+// StreamFinalizedBlocks streams the blocks finalized by the node. The stream
+// is closed with the ResourceExhausted status if the client doesn't keep up
+// with the finalization.
+func (s *Service) StreamFinalizedBlocks(_ *rpcpb.StreamFinalizedBlocksRequest,
+	stream rpcpb.Node_StreamFinalizedBlocksServer) error {
+
+	c, cancel, err := GetFeed().Subscribe()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case bs, ok := <-c:
+			if !ok {
+				return errTooSlow
+			}
+			if err := stream.Send(bs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var (
+	errTooSlow = &Error{
+		Status: http.StatusTooManyRequests,
+		Code:   common.ErrTooManyRequestsCode,
+		Msg:    "the stream is closed, finalized blocks are not consumed in time",
+	}
+	errTooManyStreams = &Error{
+		Status: http.StatusTooManyRequests,
+		Code:   common.ErrTooManyRequestsCode,
+		Msg:    "too many streams of finalized blocks",
+	}
+)
+
+// call the GET REST handler and unmarshal the response to the message
+func (s *Service) call(ctx context.Context, path string, query url.Values,
+	out proto.Message) error {
+
+	var resp json.RawMessage
+	if err := s.callJSON(ctx, http.MethodGet, path, query, nil,
+		&resp); err != nil {
+		return err
+	}
+	return unmarshal(resp, out)
+}
+
+// callJSON calls the REST handler and decodes its JSON response
+func (s *Service) callJSON(ctx context.Context, method, path string,
+	query url.Values, body []byte, out interface{}) error {
+
+	var u = path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, method, u,
+		bytes.NewReader(body))
+	if err != nil {
+		return &Error{
+			Status: http.StatusBadRequest,
+			Code:   common.ErrBadRequestCode,
+			Msg:    err.Error(),
+		}
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	setCaller(ctx, r)
+
+	var w = newRecorder()
+	s.handler.ServeHTTP(w, r)
+	if w.status != http.StatusOK {
+		return w.error()
+	}
+	if err = json.Unmarshal(w.body.Bytes(), out); err != nil {
+		return fmt.Errorf("decoding response of %s: %v", path, err)
+	}
+	return nil
+}
+
+func unmarshal(data []byte, out proto.Message) error {
+	var opts = protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err := opts.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %v", err)
+	}
+	return nil
+}
+
+type callerKey struct{}
+
+// caller of the service, the REST handlers use its address and headers for
+// the rate limits and the quotas
+type caller struct {
+	addr   string
+	header http.Header
+}
+
+// withCaller sets the caller of the JSON-RPC request
+func withCaller(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, callerKey{}, &caller{
+		addr:   r.RemoteAddr,
+		header: r.Header,
+	})
+}
+
+// setCaller sets the address and the client identifying headers of the
+// caller to the request to the REST handler
+func setCaller(ctx context.Context, r *http.Request) {
+	if c, ok := ctx.Value(callerKey{}).(*caller); ok {
+		r.RemoteAddr = c.addr
+		for _, h := range quota.Headers {
+			if v := c.header.Get(h); v != "" {
+				r.Header.Set(h, v)
+			}
+		}
+		return
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, h := range quota.Headers {
+			if v := md.Get(h); len(v) > 0 {
+				r.Header.Set(h, v[0])
+			}
+		}
+	}
+}
+
+// recorder is the http.ResponseWriter keeping the response of a REST
+// handler
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	return rec.body.Write(p)
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+// error of the response, the code is derived from the status if the body
+// is not the error envelope
+func (rec *recorder) error() *Error {
+	var (
+		er  common.ErrorResponse
+		err = &Error{Status: rec.status}
+	)
+	if json.Unmarshal(rec.body.Bytes(), &er) == nil && er.Code != "" {
+		err.Code, err.Msg = er.Code, er.Error
+		return err
+	}
+	err.Msg = strings.TrimSpace(rec.body.String())
+	if err.Msg == "" {
+		err.Msg = http.StatusText(rec.status)
+	}
+	switch {
+	case rec.status == http.StatusNotFound:
+		err.Code = common.ErrNoResourceCode
+	case rec.status == http.StatusTooManyRequests:
+		err.Code = common.ErrTooManyRequestsCode
+	case rec.status >= http.StatusInternalServerError:
+		err.Code = common.ErrInternalCode
+	default:
+		err.Code = common.ErrBadRequestCode
+	}
+	return err
+}
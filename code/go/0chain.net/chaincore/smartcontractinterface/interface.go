@@ -16,13 +16,15 @@ type SmartContractRestHandler func(ctx context.Context, params url.Values, balan
 type SmartContract struct {
 	ID                          string
 	RestHandlers                map[string]SmartContractRestHandler
+	RestEndpoints               map[string]*RestEndpoint
 	SmartContractExecutionStats map[string]interface{}
 }
 
 func NewSC(id string) *SmartContract {
 	restHandlers := make(map[string]SmartContractRestHandler)
+	restEndpoints := make(map[string]*RestEndpoint)
 	scExecStats := make(map[string]interface{})
-	return &SmartContract{ID: id, RestHandlers: restHandlers, SmartContractExecutionStats: scExecStats}
+	return &SmartContract{ID: id, RestHandlers: restHandlers, RestEndpoints: restEndpoints, SmartContractExecutionStats: scExecStats}
 }
 
 type SmartContractTransactionData struct {
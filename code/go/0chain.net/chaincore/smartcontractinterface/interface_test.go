@@ -24,6 +24,7 @@ func TestNewSC(t *testing.T) {
 			want: &SmartContract{
 				ID:                          id,
 				RestHandlers:                make(map[string]SmartContractRestHandler),
+				RestEndpoints:               make(map[string]*RestEndpoint),
 				SmartContractExecutionStats: make(map[string]interface{}),
 			},
 		},
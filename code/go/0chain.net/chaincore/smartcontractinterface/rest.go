@@ -0,0 +1,33 @@
+package smartcontractinterface
+
+/*RestEndpoint - description of a REST handler of a smart contract, types of
+* its query parameters and of its response used by the OpenAPI document */
+type RestEndpoint struct {
+	Summary string
+	// Params is a struct of the query parameters with the 'param' field
+	// tags decoded by the common.DecodeParams, nil if none.
+	Params interface{}
+	// Response is a value of type of the response.
+	Response interface{}
+}
+
+/*RestDescriber - a smart contract describing its REST handlers */
+type RestDescriber interface {
+	GetRestEndpoints() map[string]*RestEndpoint
+}
+
+/*SetRestHandler - set the REST handler of the path with its description */
+func (sc *SmartContract) SetRestHandler(path string,
+	handler SmartContractRestHandler, endpoint *RestEndpoint) {
+
+	sc.RestHandlers[path] = handler
+	if sc.RestEndpoints == nil {
+		sc.RestEndpoints = make(map[string]*RestEndpoint)
+	}
+	sc.RestEndpoints[path] = endpoint
+}
+
+/*GetRestEndpoints - descriptions of the REST handlers by their paths */
+func (sc *SmartContract) GetRestEndpoints() map[string]*RestEndpoint {
+	return sc.RestEndpoints
+}
@@ -9,12 +9,21 @@ import (
 	"0chain.net/core/datastore"
 	"0chain.net/core/logging"
 	"0chain.net/core/memorystore"
+	"0chain.net/core/openapi"
 	"go.uber.org/zap"
 )
 
 /*SetupHandlers sets up the necessary API end points */
 func SetupHandlers() {
 	http.HandleFunc("/v1/transaction/get", common.UserRateLimit(common.ToJSONResponse(memorystore.WithConnectionHandler(GetTransaction))))
+
+	openapi.Register(&openapi.Endpoint{Path: "/v1/transaction/get",
+		Summary: "transaction by hash", Params: hashParams{}, Response: Transaction{}})
+}
+
+// hashParams are query parameters of the transaction handler
+type hashParams struct {
+	Hash string `param:"hash,required"`
 }
 
 /*GetTransaction - given an id returns the transaction information */
@@ -3,10 +3,20 @@ package transaction
 import (
 	"context"
 
+	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/util"
 )
 
+// Finality levels of a transaction confirmation, from the weakest one. A
+// dropped transaction was in a notarized block that has not been finalized.
+const (
+	FinalityNotarized     = "notarized"
+	FinalityFinalized     = "finalized"
+	FinalityDeterministic = "deterministic"
+	FinalityDropped       = "dropped"
+)
+
 /*Confirmation - a data structure that provides the confirmation that a transaction is included into the block chain */
 type Confirmation struct {
 	Version           string       `json:"version"`
@@ -23,6 +33,34 @@ type Confirmation struct {
 	MerkleTreePath        *util.MTPath  `json:"merkle_tree_path"`
 	ReceiptMerkleTreeRoot string        `json:"receipt_merkle_tree_root"`
 	ReceiptMerkleTreePath *util.MTPath  `json:"receipt_merkle_tree_path"`
+	// Finality of the block of the transaction.
+	Finality string `json:"finality,omitempty"`
+	// Depth is number of finalized blocks after the block of the transaction.
+	Depth int64 `json:"depth"`
+	// BlockHeader of the block of the transaction, if requested.
+	BlockHeader *BlockHeader `json:"block_header,omitempty"`
+}
+
+// BlockHeader is the data the block hash is computed of along with the
+// verification tickets notarizing the block. It proves the merkle roots of
+// a confirmation belong to a notarized block.
+type BlockHeader struct {
+	Hash                  string           `json:"hash"`
+	MinerID               string           `json:"miner_id"`
+	PrevHash              string           `json:"prev_hash"`
+	CreationDate          common.Timestamp `json:"creation_date"`
+	Round                 int64            `json:"round"`
+	RoundRandomSeed       int64            `json:"round_random_seed"`
+	MerkleTreeRoot        string           `json:"merkle_tree_root"`
+	ReceiptMerkleTreeRoot string           `json:"receipt_merkle_tree_root"`
+	MagicBlockHash        string           `json:"magic_block_hash,omitempty"`
+	VerificationTickets   []*BlockTicket   `json:"verification_tickets"`
+}
+
+// BlockTicket is a verification ticket of the block of a block header.
+type BlockTicket struct {
+	VerifierID string `json:"verifier_id"`
+	Signature  string `json:"signature"`
 }
 
 var transactionConfirmationEntityMetadata *datastore.EntityMetadataImpl
@@ -47,7 +85,7 @@ func (c *Confirmation) ComputeProperties() {
 
 }
 
-//Validate - implement entity interface
+// Validate - implement entity interface
 func (c *Confirmation) Validate(ctx context.Context) error {
 	return nil
 }
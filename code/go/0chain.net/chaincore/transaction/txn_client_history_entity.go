@@ -0,0 +1,86 @@
+package transaction
+
+import (
+	"context"
+
+	"0chain.net/core/datastore"
+)
+
+// Roles of a client in a transaction of the client history.
+const (
+	TxnRoleSender    = "sender"    // the transaction client
+	TxnRoleRecipient = "recipient" // the transaction to_client_id
+	TxnRoleTransfer  = "transfer"  // received a transfer or a mint of the SC
+)
+
+/*TxnClientHistory - an entry of the per client transactions index */
+type TxnClientHistory struct {
+	ClientID string   `json:"client_id"`
+	Round    int64    `json:"round"`
+	Hash     string   `json:"hash"`
+	Roles    []string `json:"roles"`
+	// Partial is set if recipients of SC transfers of the transaction are
+	// unknown, the block state wasn't computed by the indexing sharder.
+	Partial bool `json:"partial,omitempty"`
+}
+
+var txnClientHistoryEntityMetadata *datastore.EntityMetadataImpl
+
+//TxnClientHistoryProvider - factory method
+func TxnClientHistoryProvider() datastore.Entity {
+	return &TxnClientHistory{}
+}
+
+//GetEntityMetadata - implement interface
+func (t *TxnClientHistory) GetEntityMetadata() datastore.EntityMetadata {
+	return txnClientHistoryEntityMetadata
+}
+
+//GetKey - implement interface
+func (t *TxnClientHistory) GetKey() datastore.Key {
+	return datastore.ToKey(t.ClientID)
+}
+
+//SetKey - implement interface
+func (t *TxnClientHistory) SetKey(key datastore.Key) {
+	t.ClientID = datastore.ToString(key)
+}
+
+//ComputeProperties - implement interface
+func (t *TxnClientHistory) ComputeProperties() {
+}
+
+//Validate - implement interface
+func (t *TxnClientHistory) Validate(ctx context.Context) error {
+	return nil
+}
+
+/*Read - store read */
+func (t *TxnClientHistory) Read(ctx context.Context, key datastore.Key) error {
+	return t.GetEntityMetadata().GetStore().Read(ctx, key, t)
+}
+
+/*GetScore - score for write*/
+func (t *TxnClientHistory) GetScore() int64 {
+	return t.Round
+}
+
+/*Write - store read */
+func (t *TxnClientHistory) Write(ctx context.Context) error {
+	return t.GetEntityMetadata().GetStore().Write(ctx, t)
+}
+
+/*Delete - store read */
+func (t *TxnClientHistory) Delete(ctx context.Context) error {
+	return t.GetEntityMetadata().GetStore().Delete(ctx, t)
+}
+
+/*SetupTxnClientHistoryEntity - setup the txn client history entity */
+func SetupTxnClientHistoryEntity(store datastore.Store) {
+	txnClientHistoryEntityMetadata = datastore.MetadataProvider()
+	txnClientHistoryEntityMetadata.Name = "txn_client_history"
+	txnClientHistoryEntityMetadata.Provider = TxnClientHistoryProvider
+	txnClientHistoryEntityMetadata.Store = store
+	txnClientHistoryEntityMetadata.IDColumnName = "client_id"
+	datastore.RegisterEntityMetadata("txn_client_history", txnClientHistoryEntityMetadata)
+}
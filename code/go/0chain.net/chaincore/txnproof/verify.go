@@ -0,0 +1,231 @@
+// Package txnproof verifies transaction confirmations off chain. A
+// confirmation requested with the block header proves the transaction and
+// its output are in a block notarized by miners of a known magic block,
+// without trusting the sharder returned it.
+package txnproof
+
+import (
+	"math"
+	"sort"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// Verifier of transaction confirmations against known magic blocks.
+type Verifier struct {
+	// magicBlocks ordered by starting round
+	magicBlocks      []*block.MagicBlock
+	signatureScheme  string
+	thresholdByCount int
+	thresholdByStake int
+}
+
+// NewVerifier returns verifier of confirmations of transactions of blocks
+// notarized by miners of the given magic blocks. A block is verified with
+// the magic block of its round, that's the one with the starting round not
+// after the block round and before the starting round of the next one. The
+// latest magic block is used for all rounds after its start. The signature
+// scheme is the one of the chain (bls0chain or ed25519), the thresholds are
+// percent of the miners count and of the miners stake required to notarize a
+// block, zero threshold is not checked. Like the chain, the threshold by
+// count is used if a magic block has no miners stake.
+func NewVerifier(mbs []*block.MagicBlock, signatureScheme string,
+	thresholdByCount, thresholdByStake int) (*Verifier, error) {
+
+	if len(mbs) == 0 {
+		return nil, common.NewError("invalid_magic_block",
+			"missing magic blocks")
+	}
+	for _, mb := range mbs {
+		if mb == nil || mb.Miners == nil {
+			return nil, common.NewError("invalid_magic_block",
+				"missing magic block or its miners")
+		}
+	}
+	if !encryption.IsValidSignatureScheme(signatureScheme) {
+		return nil, common.NewErrorf("invalid_signature_scheme",
+			"unknown signature scheme: %s", signatureScheme)
+	}
+	if thresholdByCount < 0 || thresholdByCount > 100 ||
+		thresholdByStake < 0 || thresholdByStake > 100 ||
+		thresholdByCount+thresholdByStake == 0 {
+		return nil, common.NewErrorf("invalid_threshold",
+			"thresholds should be in [0, 100] percent, not both zero, got %d, %d",
+			thresholdByCount, thresholdByStake)
+	}
+	var sorted = make([]*block.MagicBlock, len(mbs))
+	copy(sorted, mbs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartingRound < sorted[j].StartingRound
+	})
+	return &Verifier{
+		magicBlocks:      sorted,
+		signatureScheme:  signatureScheme,
+		thresholdByCount: thresholdByCount,
+		thresholdByStake: thresholdByStake,
+	}, nil
+}
+
+// magicBlock returns the magic block of given round.
+func (v *Verifier) magicBlock(round int64) (*block.MagicBlock, error) {
+	var i = sort.Search(len(v.magicBlocks), func(i int) bool {
+		return v.magicBlocks[i].StartingRound > round
+	})
+	if i == 0 {
+		return nil, common.NewErrorf("magic_block_mismatch",
+			"block round %d is before the magic block starting round %d",
+			round, v.magicBlocks[0].StartingRound)
+	}
+	return v.magicBlocks[i-1], nil
+}
+
+// ComputeHeaderHash returns hash of the block of the header.
+func ComputeHeaderHash(header *transaction.BlockHeader) string {
+	return encryption.Hash(block.HeaderHashData(header.MinerID,
+		header.PrevHash, header.CreationDate, header.Round,
+		header.RoundRandomSeed, header.MerkleTreeRoot,
+		header.ReceiptMerkleTreeRoot, header.MagicBlockHash))
+}
+
+// Verify the confirmation proves the transaction with its output is in a
+// notarized block. It checks the transaction hashes, the merkle paths to the
+// roots of the block header, the block hash and the verification tickets.
+func (v *Verifier) Verify(c *transaction.Confirmation) error {
+	if c == nil || c.Transaction == nil {
+		return common.NewError("invalid_confirmation",
+			"missing confirmation or its transaction")
+	}
+	var header = c.BlockHeader
+	if header == nil {
+		return common.NewError("invalid_confirmation",
+			"missing block header, request the confirmation with proof=true")
+	}
+	if err := verifyTransaction(c); err != nil {
+		return err
+	}
+	if err := verifyMerklePaths(c); err != nil {
+		return err
+	}
+	if err := verifyHeaderHash(c); err != nil {
+		return err
+	}
+	return v.verifyNotarization(header)
+}
+
+func verifyTransaction(c *transaction.Confirmation) error {
+	var txn = c.Transaction
+	if txn.Hash != c.Hash {
+		return common.NewErrorf("txn_mismatch",
+			"confirmation of %s has transaction %s", c.Hash, txn.Hash)
+	}
+	if txn.Hash != txn.ComputeHash() {
+		return common.NewError("txn_hash_mismatch",
+			"the transaction hash doesn't match its data")
+	}
+	if txn.OutputHash != txn.ComputeOutputHash() {
+		return common.NewError("txn_output_hash_mismatch",
+			"the transaction output hash doesn't match its output")
+	}
+	return nil
+}
+
+func verifyMerklePaths(c *transaction.Confirmation) error {
+	var header = c.BlockHeader
+	if c.MerkleTreePath == nil || c.ReceiptMerkleTreePath == nil {
+		return common.NewError("missing_merkle_path",
+			"missing merkle path of the transaction or its receipt")
+	}
+	if !util.VerifyMerklePath(c.Transaction.Hash, c.MerkleTreePath,
+		header.MerkleTreeRoot) {
+		return common.NewError("invalid_merkle_path",
+			"the transaction isn't in the merkle tree of the block")
+	}
+	if !util.VerifyMerklePath(c.Transaction.OutputHash,
+		c.ReceiptMerkleTreePath, header.ReceiptMerkleTreeRoot) {
+		return common.NewError("invalid_receipt_merkle_path",
+			"the transaction receipt isn't in the receipts merkle tree of the block")
+	}
+	return nil
+}
+
+func verifyHeaderHash(c *transaction.Confirmation) error {
+	var header = c.BlockHeader
+	if hash := ComputeHeaderHash(header); hash != header.Hash {
+		return common.NewErrorf("block_hash_mismatch",
+			"computed block hash %s, header hash %s", hash, header.Hash)
+	}
+	if c.BlockHash != header.Hash || c.Round != header.Round {
+		return common.NewError("block_mismatch",
+			"the block header isn't of the confirmation block")
+	}
+	return nil
+}
+
+// verifyNotarization checks the verification tickets of the header reach the
+// notarization thresholds of miners of the magic block of the header round.
+func (v *Verifier) verifyNotarization(header *transaction.BlockHeader) error {
+	mb, err := v.magicBlock(header.Round)
+	if err != nil {
+		return err
+	}
+
+	var verified = make(map[string]struct{}, len(header.VerificationTickets))
+	for _, vt := range header.VerificationTickets {
+		if vt == nil {
+			return common.NewError("null_ticket", "verification ticket is null")
+		}
+		if _, ok := verified[vt.VerifierID]; ok {
+			return common.NewErrorf("duplicate_ticket",
+				"duplicate verification ticket of %s", vt.VerifierID)
+		}
+		var miner = mb.Miners.GetNode(vt.VerifierID)
+		if miner == nil {
+			return common.NewErrorf("unknown_verifier",
+				"verifier %s isn't a miner of the magic block", vt.VerifierID)
+		}
+		var ss = encryption.GetSignatureScheme(v.signatureScheme)
+		if err := ss.SetPublicKey(miner.PublicKey); err != nil {
+			return common.NewErrorf("invalid_public_key",
+				"public key of miner %s: %v", vt.VerifierID, err)
+		}
+		if ok, err := ss.Verify(vt.Signature, header.Hash); err != nil || !ok {
+			return common.NewErrorf("invalid_ticket_signature",
+				"invalid signature of verifier %s", vt.VerifierID)
+		}
+		verified[vt.VerifierID] = struct{}{}
+	}
+
+	var stake, total int64
+	for _, id := range mb.Miners.Keys() {
+		total += mb.MinersStake[id]
+		if _, ok := verified[id]; ok {
+			stake += mb.MinersStake[id]
+		}
+	}
+	var byStake = v.thresholdByStake > 0 && total > 0
+	if byStake && stake*100 < total*int64(v.thresholdByStake) {
+		return common.NewErrorf("block_not_notarized",
+			"verification tickets of %d stake, %d%% of %d required", stake,
+			v.thresholdByStake, total)
+	}
+	if v.thresholdByCount == 0 && !byStake {
+		return common.NewError("block_not_notarized",
+			"missing miners stake of the magic block")
+	}
+	if v.thresholdByCount == 0 {
+		return nil
+	}
+
+	// the map, the nodes list of a decoded magic block may be not computed
+	var threshold = int(math.Ceil(float64(mb.Miners.MapSize()) *
+		float64(v.thresholdByCount) / 100))
+	if len(verified) < threshold {
+		return common.NewErrorf("block_not_notarized",
+			"%d verification tickets, %d required", len(verified), threshold)
+	}
+	return nil
+}
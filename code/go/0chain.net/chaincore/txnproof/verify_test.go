@@ -0,0 +1,216 @@
+package txnproof
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+)
+
+type testSigner struct {
+	id string
+	ss encryption.SignatureScheme
+}
+
+// makeTestMagicBlock returns magic block of miners with ed25519 keys.
+func makeTestMagicBlock(t *testing.T, miners int) (*block.MagicBlock,
+	[]*testSigner) {
+
+	var (
+		mb      = block.NewMagicBlock()
+		signers []*testSigner
+	)
+	mb.StartingRound = 1
+	mb.Miners = node.NewPool(node.NodeTypeMiner)
+	for i := 0; i < miners; i++ {
+		var ss = encryption.NewED25519Scheme()
+		require.NoError(t, ss.GenerateKeys())
+		var n = node.Provider()
+		n.Type = node.NodeTypeMiner
+		n.SetPublicKey(ss.GetPublicKey())
+		mb.Miners.AddNode(n)
+		signers = append(signers, &testSigner{id: n.GetKey(), ss: ss})
+	}
+	return mb, signers
+}
+
+// makeTestConfirmation returns confirmation of a transaction of a block
+// notarized by the given signers.
+func makeTestConfirmation(t *testing.T,
+	signers []*testSigner) *transaction.Confirmation {
+
+	var b = &block.Block{}
+	b.Round = 5
+	b.MinerID = "miner"
+	b.PrevHash = encryption.Hash("prev")
+	b.CreationDate = common.Timestamp(100)
+	b.SetRoundRandomSeed(42)
+	for i := 0; i < 5; i++ {
+		var txn = &transaction.Transaction{ClientID: "client",
+			TransactionData:   strconv.Itoa(i),
+			TransactionOutput: "output " + strconv.Itoa(i)}
+		txn.Hash = txn.ComputeHash()
+		txn.OutputHash = txn.ComputeOutputHash()
+		b.Txns = append(b.Txns, txn)
+	}
+	b.HashBlock()
+
+	var (
+		txn  = b.Txns[3]
+		mt   = b.GetMerkleTree()
+		rmt  = b.GetReceiptsMerkleTree()
+		c    = &transaction.Confirmation{Hash: txn.Hash, BlockHash: b.Hash}
+		head = &transaction.BlockHeader{
+			Hash:                  b.Hash,
+			MinerID:               b.MinerID,
+			PrevHash:              b.PrevHash,
+			CreationDate:          b.CreationDate,
+			Round:                 b.Round,
+			RoundRandomSeed:       b.GetRoundRandomSeed(),
+			MerkleTreeRoot:        mt.GetRoot(),
+			ReceiptMerkleTreeRoot: rmt.GetRoot(),
+		}
+	)
+	c.Round = b.Round
+	c.Transaction = txn
+	c.MerkleTreeRoot = mt.GetRoot()
+	c.MerkleTreePath = mt.GetPath(c)
+	c.ReceiptMerkleTreeRoot = rmt.GetRoot()
+	c.ReceiptMerkleTreePath = rmt.GetPath(transaction.NewTransactionReceipt(txn))
+	for _, s := range signers {
+		sig, err := s.ss.Sign(b.Hash)
+		require.NoError(t, err)
+		head.VerificationTickets = append(head.VerificationTickets,
+			&transaction.BlockTicket{
+				VerifierID: s.id,
+				Signature:  sig,
+			})
+	}
+	c.BlockHeader = head
+
+	// the verified confirmation is the one a client gets
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	var got transaction.Confirmation
+	require.NoError(t, json.Unmarshal(data, &got))
+	return &got
+}
+
+func TestVerifier(t *testing.T) {
+	var mb, signers = makeTestMagicBlock(t, 4)
+
+	_, err := NewVerifier([]*block.MagicBlock{mb}, "unknown", 67, 0)
+	require.Error(t, err)
+	_, err = NewVerifier([]*block.MagicBlock{mb}, "ed25519", 0, 0)
+	require.Error(t, err)
+	_, err = NewVerifier(nil, "ed25519", 67, 0)
+	require.Error(t, err)
+	v, err := NewVerifier([]*block.MagicBlock{mb}, "ed25519", 67, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Verify(makeTestConfirmation(t, signers[:3])))
+
+	// not enough tickets
+	err = v.Verify(makeTestConfirmation(t, signers[:2]))
+	require.Error(t, err)
+
+	var _, other = makeTestMagicBlock(t, 1)
+	for name, tamper := range map[string]func(c *transaction.Confirmation){
+		"no header": func(c *transaction.Confirmation) {
+			c.BlockHeader = nil
+		},
+		"txn output": func(c *transaction.Confirmation) {
+			c.Transaction.TransactionOutput = "other"
+		},
+		"txn output and its hash": func(c *transaction.Confirmation) {
+			c.Transaction.TransactionOutput = "other"
+			c.Transaction.OutputHash = c.Transaction.ComputeOutputHash()
+		},
+		"txn data": func(c *transaction.Confirmation) {
+			c.Transaction.TransactionData = "other"
+		},
+		"merkle path": func(c *transaction.Confirmation) {
+			c.MerkleTreePath.LeafIndex++
+		},
+		"merkle root": func(c *transaction.Confirmation) {
+			c.BlockHeader.MerkleTreeRoot = encryption.Hash("root")
+		},
+		"header field": func(c *transaction.Confirmation) {
+			c.BlockHeader.RoundRandomSeed++
+		},
+		"other block": func(c *transaction.Confirmation) {
+			c.BlockHash = encryption.Hash("block")
+		},
+		"duplicate ticket": func(c *transaction.Confirmation) {
+			var tickets = c.BlockHeader.VerificationTickets
+			c.BlockHeader.VerificationTickets = append(tickets[:2], tickets[0])
+		},
+		"signature": func(c *transaction.Confirmation) {
+			c.BlockHeader.VerificationTickets[1].Signature =
+				c.BlockHeader.VerificationTickets[0].Signature
+		},
+		"unknown verifier": func(c *transaction.Confirmation) {
+			c.BlockHeader.VerificationTickets[0].VerifierID = other[0].id
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var c = makeTestConfirmation(t, signers[:3])
+			tamper(c)
+			require.Error(t, v.Verify(c))
+		})
+	}
+
+	// a later magic block
+	mb.StartingRound = 10
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers)))
+}
+
+func TestVerifier_magicBlockOfRound(t *testing.T) {
+	var (
+		mb, signers = makeTestMagicBlock(t, 4)
+		next, _     = makeTestMagicBlock(t, 4)
+	)
+	next.StartingRound = 5
+	v, err := NewVerifier([]*block.MagicBlock{next, mb}, "ed25519", 67, 0)
+	require.NoError(t, err)
+	// the round 5 block is notarized by miners of the next magic block
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers)))
+
+	next.StartingRound = 6
+	require.NoError(t, v.Verify(makeTestConfirmation(t, signers)))
+}
+
+func TestVerifier_thresholdByStake(t *testing.T) {
+	var mb, signers = makeTestMagicBlock(t, 4)
+	mb.MinersStake = make(map[string]int64)
+	for i, s := range signers {
+		mb.MinersStake[s.id] = int64(10 * (i + 1)) // 10, 20, 30, 40
+	}
+
+	// by stake only
+	v, err := NewVerifier([]*block.MagicBlock{mb}, "ed25519", 0, 70)
+	require.NoError(t, err)
+	require.NoError(t, v.Verify(makeTestConfirmation(t, signers[2:])))
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers[:3])))
+
+	// both thresholds
+	v, err = NewVerifier([]*block.MagicBlock{mb}, "ed25519", 67, 70)
+	require.NoError(t, err)
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers[2:])))
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers[:3])))
+	require.NoError(t, v.Verify(makeTestConfirmation(t, signers[1:])))
+
+	// missing miners stake falls back to the threshold by count
+	mb.MinersStake = nil
+	require.NoError(t, v.Verify(makeTestConfirmation(t, signers[:3])))
+	v, err = NewVerifier([]*block.MagicBlock{mb}, "ed25519", 0, 70)
+	require.NoError(t, err)
+	require.Error(t, v.Verify(makeTestConfirmation(t, signers)))
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"0chain.net/conductor/conductrpc"
@@ -581,6 +585,90 @@ func (r *Runner) runAsyncCommand(reply chan error, name string) {
 	reply <- err // nil or error
 }
 
+//
+// assertions on chain state
+//
+
+const (
+	// assertRequestTimeout is timeout of a request of an assertion
+	assertRequestTimeout = 10 * time.Second
+	// assertGrace is extra time of the directive timeout to let an
+	// assertion report its last failure
+	assertGrace = assertRequestTimeout + time.Second
+)
+
+func (r *Runner) Assert(a *config.Assert, tm time.Duration) (err error) {
+	var n, ok = r.conf.Nodes.NodeByName(a.Node)
+	if !ok {
+		return fmt.Errorf("unknown 'assert' node: %s", a.Node)
+	}
+	if n.API == "" {
+		return fmt.Errorf("no 'api' of node %s configured", a.Node)
+	}
+
+	if r.verbose {
+		log.Printf(" [INF] assert %s %s", a.Node, a.URI)
+	}
+
+	r.setupTimeout(tm + assertGrace)
+	r.waitAssert = r.asyncAssert(n.API, a, time.Now().Add(tm))
+	return
+}
+
+func (r *Runner) asyncAssert(api string, a *config.Assert,
+	deadline time.Time) (reply chan error) {
+
+	reply = make(chan error)
+	go r.runAsyncAssert(reply, api, a, deadline)
+	return
+}
+
+// runAsyncAssert retries the assertion until it passes or the deadline.
+func (r *Runner) runAsyncAssert(reply chan error, api string,
+	a *config.Assert, deadline time.Time) {
+
+	var err error
+	for {
+		if err = queryAssert(api, a); err == nil {
+			break
+		}
+		if time.Now().Add(a.Interval).After(deadline) {
+			err = fmt.Errorf("%s %s: %v", a.Node, a.URI, err)
+			break
+		}
+		if r.verbose {
+			log.Printf(" [INF] assertion not passed yet: %v", err)
+		}
+		time.Sleep(a.Interval)
+	}
+	reply <- err // nil or error
+}
+
+func queryAssert(api string, a *config.Assert) (err error) {
+	var (
+		query  = make(url.Values)
+		uri    = strings.TrimSuffix(api, "/") + a.URI
+		client = http.Client{Timeout: assertRequestTimeout}
+	)
+	for k, v := range a.Params {
+		query.Set(k, v)
+	}
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+
+	var resp *http.Response
+	if resp, err = client.Get(uri); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	var body []byte
+	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+	return a.Verify(resp.StatusCode, body)
+}
+
 //
 // blobber related commands
 //
@@ -627,6 +715,88 @@ func (r *Runner) Challenges(cs *config.Bad) (err error) {
 	return
 }
 
+//
+// network faults
+//
+
+func (r *Runner) Partition(p *config.Partition) (err error) {
+	if r.verbose {
+		log.Printf(" [INF] partition network: %v", p.Groups)
+	}
+
+	err = r.server.UpdateAllStates(func(state *conductrpc.State) {
+		state.Partition = p
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'partition': %v", err)
+	}
+	return
+}
+
+func (r *Runner) Heal() (err error) {
+	if r.verbose {
+		log.Print(" [INF] heal network")
+	}
+
+	err = r.server.UpdateAllStates(func(state *conductrpc.State) {
+		state.Partition = nil
+		state.DropMessages = nil
+		state.DelayMessages = nil
+		state.ReorderMessages = nil
+	})
+	if err != nil {
+		return fmt.Errorf("healing network: %v", err)
+	}
+	return
+}
+
+func (r *Runner) DropMessages(dm *config.DropMessages) (err error) {
+	r.verbosePrintMessages("drop messages", &dm.Messages)
+
+	err = r.server.UpdateAllStates(func(state *conductrpc.State) {
+		// a new slice, since a state copy shares the previous one
+		state.DropMessages = append(state.DropMessages[:len(
+			state.DropMessages):len(state.DropMessages)], dm)
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'drop messages': %v", err)
+	}
+	return
+}
+
+func (r *Runner) DelayMessages(dm *config.DelayMessages) (err error) {
+	r.verbosePrintMessages("delay messages", &dm.Messages)
+
+	err = r.server.UpdateAllStates(func(state *conductrpc.State) {
+		state.DelayMessages = append(state.DelayMessages[:len(
+			state.DelayMessages):len(state.DelayMessages)], dm)
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'delay messages': %v", err)
+	}
+	return
+}
+
+func (r *Runner) ReorderMessages(rm *config.ReorderMessages) (err error) {
+	r.verbosePrintMessages("reorder messages", &rm.Messages)
+
+	err = r.server.UpdateAllStates(func(state *conductrpc.State) {
+		state.ReorderMessages = append(state.ReorderMessages[:len(
+			state.ReorderMessages):len(state.ReorderMessages)], rm)
+	})
+	if err != nil {
+		return fmt.Errorf("setting 'reorder messages': %v", err)
+	}
+	return
+}
+
+func (r *Runner) verbosePrintMessages(label string, m *config.Messages) {
+	if r.verbose {
+		log.Printf(" [INF] set '%s' from %s to %s, uri %q",
+			label, m.From, m.To, m.URI)
+	}
+}
+
 func (r *Runner) verbosePrintByGoodBad(label string, bad *config.Bad) {
 	if r.verbose {
 		log.Printf(" [INF] set '%s' of %s: good %s, bad %s",
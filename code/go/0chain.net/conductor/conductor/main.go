@@ -48,10 +48,14 @@ func main() {
 		configFile string = "conductor.yaml"
 		testsFile  string = "conductor.view-change.fault-tolerance.yaml"
 		verbose    bool   = true
+		junit      string
+		jsonReport string
 	)
 	flag.StringVar(&configFile, "config", configFile, "configurations file")
 	flag.StringVar(&testsFile, "tests", testsFile, "tests file")
 	flag.BoolVar(&verbose, "verbose", verbose, "verbose output")
+	flag.StringVar(&junit, "junit", "", "write JUnit XML report to the file")
+	flag.StringVar(&jsonReport, "json", "", "write JSON report to the file")
 	flag.Parse()
 
 	log.Print("read configurations files: ", configFile, ", ", testsFile)
@@ -68,6 +72,8 @@ func main() {
 	log.Print("create worker instance")
 	r.conf = conf
 	r.verbose = verbose
+	r.junitReport = junit
+	r.jsonReport = jsonReport
 	r.server, err = conductrpc.NewServer(conf.Bind, conf.Nodes.Names())
 	if err != nil {
 		log.Fatal("[ERR]", err)
@@ -125,14 +131,18 @@ func readConfigs(configFile, testsFile string) (conf *config.Config) {
 }
 
 type reportTestCase struct {
+	set        string // name of the set
 	name       string
 	s, e       time.Time // start at, end at
 	directives []reportFlowDirective
 }
 
 type reportFlowDirective struct {
-	success bool
-	err     error
+	index    int    // in the flow
+	name     string // of the directive
+	mustFail bool
+	success  bool
+	err      error
 }
 
 type Runner struct {
@@ -140,6 +150,10 @@ type Runner struct {
 	conf    *config.Config
 	verbose bool
 
+	// junitReport and jsonReport are paths of the reports files, if set
+	junitReport string
+	jsonReport  string
+
 	// state
 
 	lastVCRound Round // last view change round
@@ -158,6 +172,7 @@ type Runner struct {
 	waitNoPreogressCount   int                           // } got rounds
 	waitNoViewChange       config.WaitNoViewChainge      // no VC expected
 	waitCommand            chan error                    // wait a command
+	waitAssert             chan error                    // wait an assertion
 	// timeout and monitor
 	timer   *time.Timer // waiting timer
 	monitor NodeName    // monitor node
@@ -196,6 +211,8 @@ func (r *Runner) isWaiting() (tm *time.Timer, ok bool) {
 		return tm, true
 	case r.waitCommand != nil:
 		return tm, true
+	case r.waitAssert != nil:
+		return tm, true
 	}
 
 	return tm, false
@@ -690,6 +707,11 @@ func (r *Runner) proceedWaiting() (err error) {
 				err = fmt.Errorf("executing command: %v", err)
 			}
 			r.waitCommand = nil // reset
+		case err = <-r.waitAssert:
+			if err != nil {
+				err = fmt.Errorf("assertion failed: %v", err)
+			}
+			r.waitAssert = nil // reset
 		case timeout := <-tm.C:
 			if !r.waitNoProgressUntil.IsZero() {
 				if timeout.UnixNano() >= r.waitNoProgressUntil.UnixNano() {
@@ -779,6 +801,10 @@ func (r *Runner) resetWaiters() {
 		go func(wc chan error) { <-wc }(r.waitCommand)
 		r.waitCommand = nil
 	}
+	if r.waitAssert != nil {
+		go func(wa chan error) { <-wa }(r.waitAssert)
+		r.waitAssert = nil
+	}
 
 }
 
@@ -808,6 +834,7 @@ func (r *Runner) Run() (err error, success bool) {
 	cases:
 		for i, testCase := range r.conf.TestsOfSet(&set) {
 			var report reportTestCase
+			report.set = set.Name
 			report.name = testCase.Name
 			report.s = time.Now()
 
@@ -825,8 +852,11 @@ func (r *Runner) Run() (err error, success bool) {
 				if err != nil {
 					// this is a failure, but might be an expected one
 					report.directives = append(report.directives, reportFlowDirective{
-						success: mustFail,
-						err:     err,
+						index:    j,
+						name:     d.Name(),
+						mustFail: mustFail,
+						success:  mustFail,
+						err:      err,
 					})
 
 					report.e = time.Now()
@@ -844,8 +874,11 @@ func (r *Runner) Run() (err error, success bool) {
 
 				// test case succeeded
 				report.directives = append(report.directives, reportFlowDirective{
-					success: !mustFail,
-					err:     err,
+					index:    j,
+					name:     d.Name(),
+					mustFail: mustFail,
+					success:  !mustFail,
+					err:      err,
 				})
 			}
 
@@ -856,5 +889,8 @@ func (r *Runner) Run() (err error, success bool) {
 	}
 
 	success = r.processReport()
+	if err := r.writeReports(); err != nil {
+		log.Print("[ERR] writing reports: ", err)
+	}
 	return err, success
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// JUnit XML report.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      float64         `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JSON report.
+
+type jsonReport struct {
+	Success    bool             `json:"success"`
+	DurationMs int64            `json:"duration_ms"`
+	Cases      []jsonReportCase `json:"cases"`
+}
+
+type jsonReportCase struct {
+	Set        string                `json:"set"`
+	Name       string                `json:"name"`
+	Success    bool                  `json:"success"`
+	Start      time.Time             `json:"start"`
+	End        time.Time             `json:"end"`
+	DurationMs int64                 `json:"duration_ms"`
+	Failure    *jsonReportDirective  `json:"failure,omitempty"`
+	Directives []jsonReportDirective `json:"directives"`
+}
+
+type jsonReportDirective struct {
+	Index    int    `json:"index"`
+	Name     string `json:"name"`
+	MustFail bool   `json:"must_fail,omitempty"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// failure returns first failed directive of the test case, if any.
+func (tc *reportTestCase) failure() (fd *reportFlowDirective) {
+	for i := range tc.directives {
+		if !tc.directives[i].success {
+			return &tc.directives[i]
+		}
+	}
+	return nil
+}
+
+// message of the failed directive.
+func (fd *reportFlowDirective) message() string {
+	if fd.mustFail && fd.err == nil {
+		return fmt.Sprintf("directive %d '%s' succeeded, but must fail",
+			fd.index, fd.name)
+	}
+	return fmt.Sprintf("directive %d '%s': %v", fd.index, fd.name, fd.err)
+}
+
+func (r *Runner) writeReports() (err error) {
+	if r.junitReport != "" {
+		if err = writeJUnitReport(r.junitReport, r.report); err != nil {
+			return fmt.Errorf("JUnit report: %v", err)
+		}
+	}
+	if r.jsonReport != "" {
+		if err = writeJSONReport(r.jsonReport, r.report); err != nil {
+			return fmt.Errorf("JSON report: %v", err)
+		}
+	}
+	return
+}
+
+func writeJUnitReport(path string, report []reportTestCase) (err error) {
+	var (
+		suites junitTestSuites
+		index  = make(map[string]int) // set name -> suite index
+	)
+	for i := range report {
+		var (
+			tc       = &report[i]
+			dur      = tc.e.Sub(tc.s).Seconds()
+			si, ok   = index[tc.set]
+			testCase = junitTestCase{
+				Name:      tc.name,
+				ClassName: tc.set,
+				Time:      dur,
+			}
+		)
+		if !ok {
+			si = len(suites.Suites)
+			index[tc.set] = si
+			suites.Suites = append(suites.Suites, junitTestSuite{
+				Name:      tc.set,
+				Timestamp: tc.s.UTC().Format("2006-01-02T15:04:05"),
+			})
+		}
+		var suite = &suites.Suites[si]
+		if fd := tc.failure(); fd != nil {
+			testCase.Failure = &junitFailure{
+				Message: fd.message(),
+				Type:    fd.name,
+				Text:    fd.message(),
+			}
+			suite.Failures++
+			suites.Failures++
+		}
+		suite.Tests++
+		suite.Time += dur
+		suite.Cases = append(suite.Cases, testCase)
+		suites.Tests++
+		suites.Time += dur
+	}
+
+	var data []byte
+	if data, err = xml.MarshalIndent(&suites, "", "  "); err != nil {
+		return
+	}
+	data = append([]byte(xml.Header), data...)
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func writeJSONReport(path string, report []reportTestCase) (err error) {
+	var (
+		jr    = jsonReport{Success: true}
+		total time.Duration
+	)
+	jr.Cases = make([]jsonReportCase, 0, len(report))
+	for i := range report {
+		var (
+			tc  = &report[i]
+			dur = tc.e.Sub(tc.s)
+			jc  = jsonReportCase{
+				Set:        tc.set,
+				Name:       tc.name,
+				Success:    true,
+				Start:      tc.s,
+				End:        tc.e,
+				DurationMs: dur.Milliseconds(),
+				Directives: make([]jsonReportDirective, 0,
+					len(tc.directives)),
+			}
+		)
+		for _, fd := range tc.directives {
+			var jd = jsonReportDirective{
+				Index:    fd.index,
+				Name:     fd.name,
+				MustFail: fd.mustFail,
+				Success:  fd.success,
+			}
+			if fd.err != nil {
+				jd.Error = fd.err.Error()
+			}
+			jc.Directives = append(jc.Directives, jd)
+		}
+		if fd := tc.failure(); fd != nil {
+			jc.Success = false
+			jc.Failure = &jsonReportDirective{
+				Index:    fd.index,
+				Name:     fd.name,
+				MustFail: fd.mustFail,
+				Error:    fd.message(),
+			}
+			jr.Success = false
+		}
+		total += dur
+		jr.Cases = append(jr.Cases, jc)
+	}
+	jr.DurationMs = total.Milliseconds()
+
+	var data []byte
+	if data, err = json.MarshalIndent(&jr, "", "  "); err != nil {
+		return
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -120,6 +120,8 @@ type Server struct {
 
 	// node id -> node name mapping
 	names map[NodeID]NodeName
+	// updates of all nodes states, applied to added nodes
+	updateAll []UpdateStateFunc
 
 	quitOnce sync.Once
 	quit     chan struct{}
@@ -189,6 +191,9 @@ func (s *Server) AddNode(name NodeName, lock bool) {
 		poll:    make(chan *State, 10),
 		counter: 0,
 	}
+	for _, update := range s.updateAll {
+		update(ns.state)
+	}
 
 	ns.state.send(ns.poll) // initial state sending
 	s.nodes[name] = ns
@@ -235,6 +240,19 @@ func (s *Server) UpdateStates(names []NodeName, update UpdateStateFunc) (
 	return
 }
 
+// UpdateAllStates updates states of all nodes, including nodes added later.
+func (s *Server) UpdateAllStates(update UpdateStateFunc) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.updateAll = append(s.updateAll, update)
+	for _, n := range s.nodes {
+		update(n.state)
+		n.state.send(n.poll)
+	}
+	return
+}
+
 // events handling
 
 // OnViewChange events channel. The event occurs where
@@ -1,6 +1,9 @@
 package conductrpc
 
 import (
+	"math/rand"
+	"time"
+
 	"0chain.net/conductor/config"
 )
 
@@ -45,6 +48,14 @@ type State struct {
 	Signatures *config.Bad
 	Publish    *config.Bad
 
+	// Network faults, messages are dropped by both sender and receiver
+	// of a partition, other faults are applied once by the sender of a
+	// message or the receiver of a request.
+	Partition       *config.Partition
+	DropMessages    []*config.DropMessages
+	DelayMessages   []*config.DelayMessages
+	ReorderMessages []*config.ReorderMessages
+
 	// Blobbers related states
 	StorageTree    *config.Bad // blobber sends bad files/tree responses
 	ValidatorProof *config.Bad // blobber sends invalid proof to validators
@@ -56,6 +67,36 @@ func (s *State) Name(id NodeID) NodeName {
 	return s.Nodes[id] // id -> name (or empty string)
 }
 
+// IsPartitioned returns true if the nodes of given IDs can't communicate.
+func (s *State) IsPartitioned(from, to string) bool {
+	return s.Partition.IsPartitioned(s, from, to)
+}
+
+// MessageFault returns delay of the n2n message and whether to drop it.
+func (s *State) MessageFault(from, to, uri string) (delay time.Duration,
+	drop bool) {
+
+	if s.IsPartitioned(from, to) {
+		return 0, true
+	}
+	for _, dm := range s.DropMessages {
+		if dm.IsMatch(s, from, to, uri) && rand.Intn(100) < dm.Percent {
+			return 0, true
+		}
+	}
+	for _, dm := range s.DelayMessages {
+		if dm.IsMatch(s, from, to, uri) {
+			delay += dm.Delay
+		}
+	}
+	for _, rm := range s.ReorderMessages {
+		if rm.IsMatch(s, from, to, uri) {
+			delay += time.Duration(rand.Int63n(int64(rm.Window)))
+		}
+	}
+	return
+}
+
 func (s *State) copy() (cp *State) {
 	cp = new(State)
 	(*cp) = (*s)
@@ -0,0 +1,352 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Check of a value selected from a JSON response.
+type Check struct {
+	// Select is JSONPath-style selector of the value: '$' is the response,
+	// '.name' or '['name']' selects an object field, '[1]' selects an array
+	// item, negative index counts from the end, '[*]' selects all the items
+	// and '.length()' is length of an array, an object or a string.
+	Select string `json:"select" yaml:"select" mapstructure:"select"`
+	// Equals expects the value is equal to given one. Numbers are
+	// compared by value, e.g. 10 is equal to 10.0.
+	Equals interface{} `json:"equals" yaml:"equals" mapstructure:"equals"`
+	// NotEquals expects the value isn't equal to given one.
+	NotEquals interface{} `json:"not_equals" yaml:"not_equals" mapstructure:"not_equals"`
+	// Greater expects a number greater than given one.
+	Greater interface{} `json:"greater" yaml:"greater" mapstructure:"greater"`
+	// Less expects a number less than given one.
+	Less interface{} `json:"less" yaml:"less" mapstructure:"less"`
+	// Contains expects a string containing given substring or an array
+	// containing given item.
+	Contains interface{} `json:"contains" yaml:"contains" mapstructure:"contains"`
+	// Exists expects the value is or isn't present.
+	Exists *bool `json:"exists" yaml:"exists" mapstructure:"exists"`
+}
+
+// An Assert queries REST API of a node and checks the response. The
+// assertion is retried with the interval until it passes or the directive
+// timeout elapses, since a chain state changes eventually.
+type Assert struct {
+	// Node to query.
+	Node NodeName `json:"node" yaml:"node" mapstructure:"node"`
+	// URI of the endpoint, e.g. '/v1/client/get/balance'.
+	URI string `json:"uri" yaml:"uri" mapstructure:"uri"`
+	// Params of the query.
+	Params map[string]string `json:"params" yaml:"params" mapstructure:"params"`
+	// Status of the response, 200 by default.
+	Status int `json:"status" yaml:"status" mapstructure:"status"`
+	// Checks of the response.
+	Checks []Check `json:"checks" yaml:"checks" mapstructure:"checks"`
+	// Interval between attempts, 5s by default.
+	Interval time.Duration `json:"interval" yaml:"interval" mapstructure:"interval"`
+}
+
+// Unmarshal with given name and from given map[interface{}]interface{}
+// by mapstructure package.
+func (a *Assert) Unmarshal(name string, val interface{}) (err error) {
+	if err = decodeDurations(val, a); err != nil {
+		return fmt.Errorf("invalid '%s' argument type: %T, "+
+			"decoding error: %v", name, val, err)
+	}
+	if a.Node == "" || a.URI == "" {
+		return fmt.Errorf("'%s' requires 'node' and 'uri'", name)
+	}
+	if a.Status == 0 {
+		a.Status = 200
+	}
+	if a.Interval <= 0 {
+		a.Interval = 5 * time.Second
+	}
+	for i := range a.Checks {
+		if a.Checks[i].Select == "" {
+			return fmt.Errorf("empty 'select' of check %d of '%s'", i, name)
+		}
+	}
+	return
+}
+
+// Verify the response status and body.
+func (a *Assert) Verify(status int, body []byte) (err error) {
+	if status != a.Status {
+		return fmt.Errorf("unexpected status %d, want %d: %s", status,
+			a.Status, bytes.TrimSpace(body))
+	}
+	if len(a.Checks) == 0 {
+		return
+	}
+	var (
+		dec = json.NewDecoder(bytes.NewReader(body))
+		doc interface{}
+	)
+	dec.UseNumber()
+	if err = dec.Decode(&doc); err != nil {
+		return fmt.Errorf("decoding response: %v", err)
+	}
+	for i := range a.Checks {
+		if err = a.Checks[i].Verify(doc); err != nil {
+			return fmt.Errorf("check '%s': %v", a.Checks[i].Select, err)
+		}
+	}
+	return
+}
+
+// Verify the check against decoded JSON document.
+func (c *Check) Verify(doc interface{}) (err error) {
+	var val interface{}
+	val, err = Select(doc, c.Select)
+	if c.Exists != nil {
+		if *c.Exists && err != nil {
+			return
+		}
+		if !*c.Exists {
+			if err == nil {
+				return fmt.Errorf("unexpected value %s", jsonString(val))
+			}
+			return nil
+		}
+	}
+	if err != nil {
+		return
+	}
+	if c.Equals != nil && !isEqual(val, c.Equals) {
+		return fmt.Errorf("got %s, want %s", jsonString(val),
+			jsonString(c.Equals))
+	}
+	if c.NotEquals != nil && isEqual(val, c.NotEquals) {
+		return fmt.Errorf("got %s, want not equal", jsonString(val))
+	}
+	if c.Greater != nil {
+		if cmp, ok := compareNumbers(val, c.Greater); !ok || cmp <= 0 {
+			return fmt.Errorf("got %s, want greater than %s", jsonString(val),
+				jsonString(c.Greater))
+		}
+	}
+	if c.Less != nil {
+		if cmp, ok := compareNumbers(val, c.Less); !ok || cmp >= 0 {
+			return fmt.Errorf("got %s, want less than %s", jsonString(val),
+				jsonString(c.Less))
+		}
+	}
+	if c.Contains != nil && !contains(val, c.Contains) {
+		return fmt.Errorf("got %s, want containing %s", jsonString(val),
+			jsonString(c.Contains))
+	}
+	return
+}
+
+// Select value of decoded JSON document by JSONPath-style selector.
+func Select(doc interface{}, selector string) (val interface{}, err error) {
+	var path = strings.TrimSpace(selector)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("selector should start with '$'")
+	}
+	return selectPath(doc, path[1:])
+}
+
+func selectPath(val interface{}, path string) (interface{}, error) {
+	for path != "" {
+		var (
+			key   string
+			index string
+			err   error
+		)
+		switch {
+		case strings.HasPrefix(path, ".length()"):
+			if val, err = length(val); err != nil {
+				return nil, err
+			}
+			path = path[len(".length()"):]
+			continue
+		case path[0] == '.':
+			var end = strings.IndexAny(path[1:], ".[")
+			if end < 0 {
+				end = len(path) - 1
+			}
+			key, path = path[1:end+1], path[end+1:]
+		case strings.HasPrefix(path, "['"):
+			var end = strings.Index(path, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed key in %q", path)
+			}
+			key, path = path[2:end], path[end+2:]
+		case path[0] == '[':
+			var end = strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed index in %q", path)
+			}
+			index, path = path[1:end], path[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q", path)
+		}
+
+		if index == "*" {
+			var items, ok = val.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("not an array for [*]")
+			}
+			var all = make([]interface{}, 0, len(items))
+			for _, item := range items {
+				var x, err = selectPath(item, path)
+				if err != nil {
+					return nil, err
+				}
+				all = append(all, x)
+			}
+			return all, nil
+		}
+		if val, err = selectStep(val, key, index); err != nil {
+			return nil, err
+		}
+	}
+	return val, nil
+}
+
+func selectStep(val interface{}, key, index string) (interface{}, error) {
+	if index == "" {
+		var obj, ok = val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("not an object for %q", key)
+		}
+		var x, found = obj[key]
+		if !found {
+			return nil, fmt.Errorf("no %q field", key)
+		}
+		return x, nil
+	}
+	var items, ok = val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not an array for [%s]", index)
+	}
+	var i, err = strconv.Atoi(index)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index [%s]", index)
+	}
+	if i < 0 {
+		i += len(items)
+	}
+	if i < 0 || i >= len(items) {
+		return nil, fmt.Errorf("index [%s] out of %d items", index, len(items))
+	}
+	return items[i], nil
+}
+
+func length(val interface{}) (interface{}, error) {
+	switch tt := val.(type) {
+	case []interface{}:
+		return json.Number(strconv.Itoa(len(tt))), nil
+	case map[string]interface{}:
+		return json.Number(strconv.Itoa(len(tt))), nil
+	case string:
+		return json.Number(strconv.Itoa(len(tt))), nil
+	}
+	return nil, fmt.Errorf("no length of %s", jsonString(val))
+}
+
+// toNumber converts JSON or YAML number to big.Float.
+func toNumber(val interface{}) (*big.Float, bool) {
+	var s string
+	switch tt := val.(type) {
+	case json.Number:
+		s = tt.String()
+	case int, int64, uint64, float64:
+		s = fmt.Sprint(tt)
+	default:
+		return nil, false
+	}
+	var f, _, err = big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	return f, err == nil
+}
+
+func compareNumbers(val, expected interface{}) (cmp int, ok bool) {
+	var x, y *big.Float
+	if x, ok = toNumber(val); !ok {
+		return
+	}
+	if y, ok = toNumber(expected); !ok {
+		return
+	}
+	return x.Cmp(y), true
+}
+
+// normalize converts YAML maps to JSON ones.
+func normalize(val interface{}) interface{} {
+	switch tt := val.(type) {
+	case map[interface{}]interface{}:
+		var obj = make(map[string]interface{}, len(tt))
+		for k, v := range tt {
+			obj[fmt.Sprint(k)] = normalize(v)
+		}
+		return obj
+	case []interface{}:
+		var items = make([]interface{}, 0, len(tt))
+		for _, v := range tt {
+			items = append(items, normalize(v))
+		}
+		return items
+	}
+	return val
+}
+
+func isEqual(val, expected interface{}) bool {
+	if cmp, ok := compareNumbers(val, expected); ok {
+		return cmp == 0
+	}
+	switch tt := val.(type) {
+	case []interface{}:
+		var items, ok = expected.([]interface{})
+		if !ok || len(items) != len(tt) {
+			return false
+		}
+		for i := range tt {
+			if !isEqual(tt[i], items[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		var obj, ok = normalize(expected).(map[string]interface{})
+		if !ok || len(obj) != len(tt) {
+			return false
+		}
+		for k, v := range tt {
+			if x, found := obj[k]; !found || !isEqual(v, x) {
+				return false
+			}
+		}
+		return true
+	}
+	return val == expected
+}
+
+func contains(val, expected interface{}) bool {
+	switch tt := val.(type) {
+	case string:
+		var sub, ok = expected.(string)
+		return ok && strings.Contains(tt, sub)
+	case []interface{}:
+		for _, item := range tt {
+			if isEqual(item, expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonString(val interface{}) string {
+	var data, err = json.Marshal(normalize(val))
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(data)
+}
@@ -32,6 +32,8 @@ const (
 	PhaseShare             //
 	PhasePublish           //
 	PhaseWait              //
+	PhaseComplain          // appended to match the miner SC phases
+	PhaseJustify           //
 )
 
 func ParsePhase(ps string) (ph Phase, err error) {
@@ -42,6 +44,10 @@ func ParsePhase(ps string) (ph Phase, err error) {
 		return PhaseContribute, nil
 	case "share":
 		return PhaseShare, nil
+	case "complain":
+		return PhaseComplain, nil
+	case "justify":
+		return PhaseJustify, nil
 	case "publish":
 		return PhasePublish, nil
 	case "wait":
@@ -59,6 +65,10 @@ func (p Phase) String() string {
 		return "contribute"
 	case PhaseShare:
 		return "share"
+	case PhaseComplain:
+		return "complain"
+	case PhaseJustify:
+		return "justify"
 	case PhasePublish:
 		return "publish"
 	case PhaseWait:
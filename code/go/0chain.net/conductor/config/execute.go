@@ -65,6 +65,18 @@ type Executor interface {
 	Signatures(s *Bad) (err error)
 	Publish(p *Bad) (err error)
 
+	// network faults
+
+	Partition(p *Partition) (err error)
+	Heal() (err error)
+	DropMessages(dm *DropMessages) (err error)
+	DelayMessages(dm *DelayMessages) (err error)
+	ReorderMessages(rm *ReorderMessages) (err error)
+
+	// assertions on chain state
+
+	Assert(a *Assert, timeout time.Duration) (err error)
+
 	// system command (a bash script, etc)
 	Command(name string, timeout time.Duration)
 
@@ -18,6 +18,12 @@ func (d Directive) unwrap() (name string, val interface{}, ok bool) {
 	return
 }
 
+// Name of the directive.
+func (d Directive) Name() (name string) {
+	name, _, _ = d.unwrap()
+	return
+}
+
 func getNodeNames(val interface{}) (ss []NodeName, ok bool) {
 	switch tt := val.(type) {
 	case string:
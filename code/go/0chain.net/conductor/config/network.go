@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Partition splits the network into groups of nodes. Messages between nodes
+// of different groups are dropped. A node out of the groups reaches everyone.
+type Partition struct {
+	Groups [][]NodeName `json:"groups" yaml:"groups" mapstructure:"groups"`
+}
+
+// Unmarshal with given name and from given map[interface{}]interface{}
+// by mapstructure package.
+func (p *Partition) Unmarshal(name string, val interface{}) (err error) {
+	if err = mapstructure.Decode(val, p); err != nil {
+		return fmt.Errorf("invalid '%s' argument type: %T, "+
+			"decoding error: %v", name, val, err)
+	}
+	if len(p.Groups) < 2 {
+		return fmt.Errorf("'%s' requires at least two groups", name)
+	}
+	return
+}
+
+func (p *Partition) group(name NodeName) (int, bool) {
+	for i, group := range p.Groups {
+		if isInList(group, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// IsPartitioned returns true if the Partition is not nil and the nodes of
+// given IDs are in different groups.
+func (p *Partition) IsPartitioned(state Namer, from, to string) bool {
+	if p == nil {
+		return false
+	}
+	var (
+		gfrom, okFrom = p.group(state.Name(NodeID(from)))
+		gto, okTo     = p.group(state.Name(NodeID(to)))
+	)
+	return okFrom && okTo && gfrom != gto
+}
+
+// Messages selects n2n messages by sender, receiver and URI.
+type Messages struct {
+	// From these nodes, any node if empty.
+	From []NodeName `json:"from" yaml:"from" mapstructure:"from"`
+	// To these nodes, any node if empty.
+	To []NodeName `json:"to" yaml:"to" mapstructure:"to"`
+	// URI prefix of the messages, any message if empty.
+	URI string `json:"uri" yaml:"uri" mapstructure:"uri"`
+}
+
+// IsMatch returns true if the Messages is not nil and selects the message.
+func (m *Messages) IsMatch(state Namer, from, to, uri string) bool {
+	if m == nil {
+		return false
+	}
+	if len(m.From) > 0 && !isInList(m.From, state.Name(NodeID(from))) {
+		return false
+	}
+	if len(m.To) > 0 && !isInList(m.To, state.Name(NodeID(to))) {
+		return false
+	}
+	return strings.HasPrefix(uri, m.URI)
+}
+
+// DropMessages drops given percent of the selected messages.
+type DropMessages struct {
+	Messages `json:",inline" yaml:",inline" mapstructure:",squash"`
+	// Percent of the messages to drop, all messages if zero.
+	Percent int `json:"percent" yaml:"percent" mapstructure:"percent"`
+}
+
+// Unmarshal with given name and from given map[interface{}]interface{}
+// by mapstructure package.
+func (dm *DropMessages) Unmarshal(name string, val interface{}) (err error) {
+	if err = mapstructure.Decode(val, dm); err != nil {
+		return fmt.Errorf("invalid '%s' argument type: %T, "+
+			"decoding error: %v", name, val, err)
+	}
+	if dm.Percent < 0 || dm.Percent > 100 {
+		return fmt.Errorf("invalid 'percent' of '%s': %d", name, dm.Percent)
+	}
+	if dm.Percent == 0 {
+		dm.Percent = 100
+	}
+	return
+}
+
+// DelayMessages delays the selected messages.
+type DelayMessages struct {
+	Messages `json:",inline" yaml:",inline" mapstructure:",squash"`
+	// Delay of the messages.
+	Delay time.Duration `json:"delay" yaml:"delay" mapstructure:"delay"`
+}
+
+// Unmarshal with given name and from given map[interface{}]interface{}
+// by mapstructure package.
+func (dm *DelayMessages) Unmarshal(name string, val interface{}) (err error) {
+	if err = decodeDurations(val, dm); err != nil {
+		return fmt.Errorf("invalid '%s' argument type: %T, "+
+			"decoding error: %v", name, val, err)
+	}
+	if dm.Delay <= 0 {
+		return fmt.Errorf("missing 'delay' of '%s'", name)
+	}
+	return
+}
+
+// ReorderMessages delays each of the selected messages by a random duration
+// within the window, thus messages sent within the window arrive in random
+// order.
+type ReorderMessages struct {
+	Messages `json:",inline" yaml:",inline" mapstructure:",squash"`
+	// Window of the random delays.
+	Window time.Duration `json:"window" yaml:"window" mapstructure:"window"`
+}
+
+// Unmarshal with given name and from given map[interface{}]interface{}
+// by mapstructure package.
+func (rm *ReorderMessages) Unmarshal(name string, val interface{}) (
+	err error) {
+
+	if err = decodeDurations(val, rm); err != nil {
+		return fmt.Errorf("invalid '%s' argument type: %T, "+
+			"decoding error: %v", name, val, err)
+	}
+	if rm.Window <= 0 {
+		return fmt.Errorf("missing 'window' of '%s'", name)
+	}
+	return
+}
+
+// decodeDurations decodes the value by mapstructure package parsing
+// durations such as '500ms'.
+func decodeDurations(val, out interface{}) (err error) {
+	var dec *mapstructure.Decoder
+	dec, err = mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     out,
+	})
+	if err != nil {
+		return
+	}
+	return dec.Decode(val)
+}
@@ -23,6 +23,9 @@ type Node struct {
 	StartCommand string `json:"start_command" yaml:"start_command" mapstructure:"start_command"`
 	// StopCommand to start the node.
 	StopCommand string `json:"stop_command" yaml:"stop_command" mapstructure:"stop_command"`
+	// API is base URL of REST API of the node used by assertions, e.g.
+	// 'http://localhost:7171'.
+	API string `json:"api" yaml:"api" mapstructure:"api"`
 
 	// internals
 	Command *exec.Cmd `json:"-" yaml:"-" mapstructure:"-"`
@@ -302,6 +302,60 @@ func init() {
 		return ex.Publish(&publish)
 	})
 
+	// network faults
+
+	register("partition", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var p Partition
+		if err = p.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.Partition(&p)
+	})
+
+	register("heal", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		return ex.Heal()
+	})
+
+	register("drop_messages", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var dm DropMessages
+		if err = dm.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.DropMessages(&dm)
+	})
+
+	register("delay_messages", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var dm DelayMessages
+		if err = dm.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.DelayMessages(&dm)
+	})
+
+	register("reorder_messages", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var rm ReorderMessages
+		if err = rm.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.ReorderMessages(&rm)
+	})
+
+	// assertions on chain state
+
+	register("assert", func(name string,
+		ex Executor, val interface{}, tm time.Duration) (err error) {
+		var a Assert
+		if err = a.Unmarshal(name, val); err != nil {
+			return
+		}
+		return ex.Assert(&a, tm)
+	})
+
 	// a system command
 
 	register("command", func(name string,
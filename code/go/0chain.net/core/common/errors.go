@@ -18,6 +18,12 @@ var (
 	// ErrInternal represents error corresponds to http.StatusInternalServerError.
 	ErrInternal = NewError(ErrInternalCode, "internal server error")
 
+	// ErrTooManyRequests represents error corresponds to http.StatusTooManyRequests.
+	ErrTooManyRequests = NewError(ErrTooManyRequestsCode, "too many requests")
+
+	// ErrForbidden represents error corresponds to http.StatusForbidden.
+	ErrForbidden = NewError(ErrForbiddenCode, "forbidden")
+
 	ErrDecoding = errors.New("decoding error")
 )
 
@@ -25,6 +31,9 @@ const (
 	ErrNoResourceCode = "resource_not_found"
 	ErrBadRequestCode = "invalid_request"
 	ErrInternalCode   = "internal_error"
+
+	ErrTooManyRequestsCode = "too_many_requests"
+	ErrForbiddenCode       = "forbidden"
 )
 
 /*Error type for a new application error */
@@ -30,30 +30,62 @@ type JSONResponderF func(ctx context.Context, r *http.Request) (interface{}, err
  */
 type JSONReqResponderF func(ctx context.Context, json map[string]interface{}) (interface{}, error)
 
+/*ErrorResponse - the JSON body of all the error responses of the API */
+type ErrorResponse struct {
+	// Code is a stable machine readable code of the error.
+	Code string `json:"code"`
+	// Error is a human readable message of the error.
+	Error string `json:"error"`
+}
+
+/*ErrorStatus - the HTTP status code of the error */
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInternal):
+		return http.StatusInternalServerError
+	case errors.Is(err, ErrNoResource):
+		return http.StatusNotFound
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+/*NewErrorResponse - the error response of the error, the code of an error
+* that is not an *Error is derived from its HTTP status */
+func NewErrorResponse(err error) *ErrorResponse {
+	var cErr *Error
+	if errors.As(err, &cErr) && cErr.Code != "" {
+		return &ErrorResponse{Code: cErr.Code, Error: err.Error()}
+	}
+	var code string
+	switch ErrorStatus(err) {
+	case http.StatusInternalServerError:
+		code = ErrInternalCode
+	case http.StatusNotFound:
+		code = ErrNoResourceCode
+	default:
+		code = ErrBadRequestCode
+	}
+	return &ErrorResponse{Code: code, Error: err.Error()}
+}
+
+/*RespondError - respond the error with the ErrorResponse body */
+func RespondError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(NewErrorResponse(err))
+}
+
 /*Respond - respond either data or error as a response */
 func Respond(w http.ResponseWriter, r *http.Request, data interface{}, err error) {
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		data := make(map[string]interface{}, 2)
-		data["error"] = err.Error()
-		if cErr, ok := err.(*Error); ok {
-			data["code"] = cErr.Code
-		}
-
-		switch {
-		case errors.Is(err, ErrBadRequest):
-			w.WriteHeader(http.StatusBadRequest)
-		case errors.Is(err, ErrInternal):
-			w.WriteHeader(http.StatusInternalServerError)
-		case errors.Is(err, ErrNoResource):
-			w.WriteHeader(http.StatusNotFound)
-		default:
-			w.WriteHeader(http.StatusBadRequest)
-		}
-
-		if err := json.NewEncoder(w).Encode(data); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
+		RespondError(w, ErrorStatus(err), err)
 		return
 	}
 	if data != nil {
@@ -150,14 +182,14 @@ func ToJSONReqResponse(handler JSONReqResponderF) ReqRespHandlerf {
 		}
 		contentType := r.Header.Get("Content-type")
 		if !strings.HasPrefix(contentType, "application/json") {
-			http.Error(w, "Header Content-type=application/json not found", 400)
+			Respond(w, r, nil, NewErrBadRequest("Header Content-type=application/json not found"))
 			return
 		}
 		decoder := json.NewDecoder(r.Body)
 		var jsonData map[string]interface{}
 		err := decoder.Decode(&jsonData)
 		if err != nil {
-			http.Error(w, "Error decoding json", 500)
+			Respond(w, r, nil, NewErrBadRequest("Error decoding json", err.Error()))
 			return
 		}
 		ctx := r.Context()
@@ -330,13 +330,13 @@ func TestToJSONReqResponse(t *testing.T) {
 			want: func() http.ResponseWriter {
 				w := httptest.NewRecorder()
 
-				http.Error(w, "Header Content-type=application/json not found", 400)
+				Respond(w, nil, nil, NewErrBadRequest("Header Content-type=application/json not found"))
 
 				return w
 			}(),
 		},
 		{
-			name: "Test_ToJSONResponse_Unmarshalling_Err_Internal_Server_Err",
+			name: "Test_ToJSONResponse_Unmarshalling_Err_Bad_Request",
 			args: func() args {
 				buf := bytes.NewBuffer([]byte("}{"))
 				r := httptest.NewRequest(http.MethodGet, "/", buf)
@@ -346,7 +346,9 @@ func TestToJSONReqResponse(t *testing.T) {
 			want: func() http.ResponseWriter {
 				w := httptest.NewRecorder()
 
-				http.Error(w, "Error decoding json", 500)
+				var data map[string]interface{}
+				err := json.Unmarshal([]byte("}{"), &data)
+				Respond(w, nil, nil, NewErrBadRequest("Error decoding json", err.Error()))
 
 				return w
 			}(),
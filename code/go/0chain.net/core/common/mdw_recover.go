@@ -27,14 +27,13 @@ func Recover(handler ReqRespHandlerf) ReqRespHandlerf {
 					zap.String("method", r.Method),
 					zap.String("request uri", r.RequestURI),
 				)
-				w.Header().Set("Content-Type", "application/json")
-				data := make(map[string]interface{}, 2)
-				data["error"] = fmt.Sprintf("%v", err)
+				data := &ErrorResponse{Code: ErrInternalCode, Error: fmt.Sprintf("%v", err)}
 				if are, ok := err.(*Error); ok {
-					data["code"] = are.Code
+					data.Code = are.Code
 				}
 				buf := bytes.NewBuffer(nil)
 				json.NewEncoder(buf).Encode(data)
+				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusInternalServerError)
 				buf.WriteTo(w)
 			}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*ParamTag - the struct field tag of a query parameter, the tag value is
+* the name of the parameter optionally followed by ',required', for example
+*   ClientID string `param:"client_id,required"`
+ */
+const ParamTag = "param"
+
+/*ParseParamTag - parse the query parameter tag of the struct field */
+func ParseParamTag(field reflect.StructField) (name string, required, ok bool) {
+	tag, ok := field.Tag.Lookup(ParamTag)
+	if !ok || tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "required" {
+			required = true
+		}
+	}
+	return parts[0], required, true
+}
+
+/*DecodeParams - decode the query parameters to the fields of the struct
+* pointed by v by their param tags. The fields can be strings, integers,
+* floats and bools or pointers to them, a pointer is set only if the
+* parameter is given. The fields of embedded structs are decoded too. A
+* missing required parameter is a bad request error */
+func DecodeParams(params url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return NewErrInternal("decoding parameters", fmt.Sprintf("not a pointer to struct: %T", v))
+	}
+	return decodeParams(params, rv.Elem())
+}
+
+/*DecodeRequestParams - decode the query and form parameters of the request
+* to the fields of the struct pointed by v, see DecodeParams */
+func DecodeRequestParams(r *http.Request, v interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return NewErrBadRequest("invalid parameters", err.Error())
+	}
+	return DecodeParams(r.Form, v)
+}
+
+func decodeParams(params url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, required, ok := ParseParamTag(rt.Field(i))
+		if !ok {
+			if rt.Field(i).Anonymous && rt.Field(i).Type.Kind() == reflect.Struct {
+				if err := decodeParams(params, rv.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		val := params.Get(name)
+		if val == "" {
+			if required {
+				return NewErrBadRequest("missing required parameter", name)
+			}
+			continue
+		}
+		if err := setParam(rv.Field(i), val); err != nil {
+			return NewErrBadRequest("invalid parameter "+name, err.Error())
+		}
+	}
+	return nil
+}
+
+func setParam(field reflect.Value, val string) error {
+	if !field.CanSet() {
+		return fmt.Errorf("unexported field of type %v", field.Type())
+	}
+	switch field.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(field.Type().Elem())
+		if err := setParam(elem.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(elem)
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
+	}
+	return nil
+}
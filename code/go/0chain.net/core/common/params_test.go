@@ -0,0 +1,124 @@
+package common
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeParams(t *testing.T) {
+	t.Parallel()
+
+	type Page struct {
+		Limit int `param:"limit"`
+	}
+	type params struct {
+		Page
+		ClientID string  `param:"client_id,required"`
+		Offset   int64   `param:"offset"`
+		Ratio    float64 `param:"ratio"`
+		Active   bool    `param:"active"`
+		Round    *int64  `param:"round"`
+		Skipped  string
+	}
+	var round = int64(0)
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    params
+		wantErr bool
+	}{
+		{
+			name: "Test_DecodeParams_OK",
+			values: url.Values{
+				"client_id": {"id"},
+				"offset":    {"20"},
+				"ratio":     {"0.5"},
+				"active":    {"true"},
+				"limit":     {"10"},
+				"round":     {"0"},
+				"Skipped":   {"x"},
+			},
+			want: params{Page: Page{Limit: 10}, ClientID: "id", Offset: 20,
+				Ratio: 0.5, Active: true, Round: &round},
+		},
+		{
+			name:   "Test_DecodeParams_Optional_OK",
+			values: url.Values{"client_id": {"id"}},
+			want:   params{ClientID: "id"},
+		},
+		{
+			name:    "Test_DecodeParams_Missing_Required_ERR",
+			values:  url.Values{"offset": {"20"}},
+			wantErr: true,
+		},
+		{
+			name:    "Test_DecodeParams_Invalid_Int_ERR",
+			values:  url.Values{"client_id": {"id"}, "offset": {"x"}},
+			wantErr: true,
+		},
+		{
+			name:    "Test_DecodeParams_Invalid_Pointer_ERR",
+			values:  url.Values{"client_id": {"id"}, "round": {"x"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got params
+			err := DecodeParams(tt.values, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrBadRequest) {
+					t.Errorf("DecodeParams() error = %v, want bad request", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeParams() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want *ErrorResponse
+	}{
+		{
+			name: "Test_NewErrorResponse_Error",
+			err:  NewError("key_not_found", "key was not found"),
+			want: &ErrorResponse{Code: "key_not_found", Error: "key_not_found: key was not found"},
+		},
+		{
+			name: "Test_NewErrorResponse_No_Resource",
+			err:  NewErrNoResource("no block"),
+			want: &ErrorResponse{Code: ErrNoResourceCode, Error: "resource_not_found: no block"},
+		},
+		{
+			name: "Test_NewErrorResponse_Plain_Error",
+			err:  errors.New("value not present"),
+			want: &ErrorResponse{Code: ErrBadRequestCode, Error: "value not present"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := NewErrorResponse(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewErrorResponse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
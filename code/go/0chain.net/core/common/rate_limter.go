@@ -1,7 +1,11 @@
 package common
 
 import (
+	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/didip/tollbooth"
@@ -16,8 +20,11 @@ type ratelimit struct {
 	RequestsPerSecond float64
 }
 
-var userRateLimit *ratelimit
-var n2nRateLimit *ratelimit
+var (
+	rateLimitsMu  sync.RWMutex
+	userRateLimit *ratelimit
+	n2nRateLimit  *ratelimit
+)
 
 func (rl *ratelimit) init() {
 	if rl.RequestsPerSecond == 0 {
@@ -25,38 +32,104 @@ func (rl *ratelimit) init() {
 		return
 	}
 	rl.RateLimit = true
+	msg, _ := json.Marshal(NewErrorResponse(ErrTooManyRequests))
 	rl.Limiter = tollbooth.NewLimiter(rl.RequestsPerSecond, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Hour}).
 		SetIPLookups([]string{"RemoteAddr", "X-Forwarded-For", "X-Real-IP"}).
-		SetMethods([]string{"GET", "POST"})
+		SetMethods([]string{"GET", "POST"}).
+		SetMessage(string(msg)).
+		SetMessageContentType("application/json")
 }
 
-//ConfigRateLimits - configure the rate limits
+//ConfigRateLimits - configure the rate limits, the handlers use the new limits once configured again
 func ConfigRateLimits() {
 	userRl := viper.GetFloat64("network.user_handlers.rate_limit")
-	userRateLimit = &ratelimit{RequestsPerSecond: userRl}
-	userRateLimit.init()
+	userLimit := &ratelimit{RequestsPerSecond: userRl}
+	userLimit.init()
 
 	n2nRl := viper.GetFloat64("network.n2n_handlers.rate_limit")
-	n2nRateLimit = &ratelimit{RequestsPerSecond: n2nRl}
-	n2nRateLimit.init()
+	n2nLimit := &ratelimit{RequestsPerSecond: n2nRl}
+	n2nLimit.init()
+
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	userRateLimit, n2nRateLimit = userLimit, n2nLimit
+}
+
+func getRateLimits() (user, n2n *ratelimit) {
+	rateLimitsMu.RLock()
+	defer rateLimitsMu.RUnlock()
+	return userRateLimit, n2nRateLimit
+}
+
+// limit serves the request with the handler if the rate limit allows it
+func (rl *ratelimit) limit(handler ReqRespHandlerf, w http.ResponseWriter,
+	r *http.Request) {
+
+	if rl == nil || !rl.RateLimit {
+		Recover(handler)(w, r)
+		return
+	}
+	tollbooth.LimitFuncHandler(rl.Limiter, Recover(handler)).ServeHTTP(w, r)
+}
+
+// Quota limits the requests to the user handlers.
+type Quota interface {
+	// Take the request from the quota. A request over the quota is rejected
+	// and can be retried after returned duration.
+	Take(r *http.Request) (retryAfter time.Duration, ok bool)
+}
+
+var (
+	userQuotaMu sync.RWMutex
+	userQuota   Quota
+)
+
+// SetUserQuota sets the quota of the user handlers, it replaces the user
+// handlers rate limit. The rate limit is used again if the quota is nil.
+func SetUserQuota(q Quota) {
+	userQuotaMu.Lock()
+	defer userQuotaMu.Unlock()
+	userQuota = q
+}
+
+func getUserQuota() Quota {
+	userQuotaMu.RLock()
+	defer userQuotaMu.RUnlock()
+	return userQuota
 }
 
 //UserRateLimit - rate limiting for end user handlers
 func UserRateLimit(handler ReqRespHandlerf) ReqRespHandlerf {
-	if !userRateLimit.RateLimit {
-		return Recover(handler)
-	}
 	return func(writer http.ResponseWriter, request *http.Request) {
-		tollbooth.LimitFuncHandler(userRateLimit.Limiter, Recover(handler)).ServeHTTP(writer, request)
+		var q = getUserQuota()
+		if q == nil {
+			var user, _ = getRateLimits()
+			user.limit(handler, writer, request)
+			return
+		}
+		if retryAfter, ok := q.Take(request); !ok {
+			RespondTooManyRequests(writer, retryAfter)
+			return
+		}
+		Recover(handler)(writer, request)
 	}
 }
 
+/*RespondTooManyRequests - respond the request rejected by a rate limit, the
+* Retry-After is in whole seconds, at least one */
+func RespondTooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	var secs = int64(math.Ceil(retryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	RespondError(w, http.StatusTooManyRequests, ErrTooManyRequests)
+}
+
 //N2NRateLimit - rate limiting for n2n handlers
 func N2NRateLimit(handler ReqRespHandlerf) ReqRespHandlerf {
-	if !n2nRateLimit.RateLimit {
-		return Recover(handler)
-	}
 	return func(writer http.ResponseWriter, request *http.Request) {
-		tollbooth.LimitFuncHandler(n2nRateLimit.Limiter, Recover(handler)).ServeHTTP(writer, request)
+		var _, n2n = getRateLimits()
+		n2n.limit(handler, writer, request)
 	}
 }
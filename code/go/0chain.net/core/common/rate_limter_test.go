@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -109,3 +110,38 @@ func TestN2NRateLimit(t *testing.T) {
 		})
 	}
 }
+
+type testQuota struct {
+	retryAfter time.Duration
+	ok         bool
+}
+
+func (q testQuota) Take(*http.Request) (time.Duration, bool) {
+	return q.retryAfter, q.ok
+}
+
+func TestUserRateLimit_quota(t *testing.T) {
+	defer SetUserQuota(nil)
+
+	var called bool
+	handler := UserRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	SetUserQuota(testQuota{ok: true})
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Rate-Limit-Limit"), "quota replaces the rate limit")
+
+	called = false
+	SetUserQuota(testQuota{retryAfter: 1500 * time.Millisecond})
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"code":"too_many_requests","error":"too_many_requests: too many requests"}`,
+		w.Body.String())
+}
@@ -33,14 +33,14 @@ func ToJSONEntityReqResponse(handler JSONEntityReqResponderF, entityMetadata Ent
 		}
 		contentType := r.Header.Get("Content-type")
 		if !strings.HasPrefix(contentType, "application/json") {
-			http.Error(w, "Header Content-type=application/json not found", 400)
+			common.Respond(w, r, nil, common.NewErrBadRequest("Header Content-type=application/json not found"))
 			return
 		}
 		decoder := json.NewDecoder(r.Body)
 		entity := entityMetadata.Instance()
 		err := decoder.Decode(entity)
 		if err != nil {
-			http.Error(w, "Error decoding json", 500)
+			common.Respond(w, r, nil, common.NewErrBadRequest("Error decoding json", err.Error()))
 			return
 		}
 		ctx := r.Context()
@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"0chain.net/core/kvstore"
+	"0chain.net/core/logging"
+	"0chain.net/core/viper"
+)
+
+// Config of the journal.
+type Config struct {
+	// Enabled turns the journal on.
+	Enabled bool
+	// Dir of the on-disk store of the events, the events are kept in
+	// memory if empty.
+	Dir string
+	// KeepRounds is number of the latest rounds the events are kept for.
+	KeepRounds int64
+	// MaxEvents is max number of the kept events.
+	MaxEvents int
+	// QueueSize is max number of the events waiting for the writer, events
+	// are dropped when the queue is full.
+	QueueSize int
+	// Round returns current round of the node, the kept rounds are counted
+	// from it. Only the max number of the events is checked if it's nil.
+	Round func() int64
+}
+
+var (
+	mu      sync.RWMutex
+	journal *Journal
+)
+
+// ReadConfig reads the 'journal' section of the configurations and sets up
+// the journal of the node with given current round source.
+func ReadConfig(ctx context.Context, round func() int64) error {
+	return Setup(ctx, &Config{
+		Enabled:    viper.GetBool("journal.enabled"),
+		Dir:        viper.GetString("journal.dir"),
+		KeepRounds: viper.GetInt64("journal.keep_rounds"),
+		MaxEvents:  viper.GetInt("journal.max_events"),
+		QueueSize:  viper.GetInt("journal.queue_size"),
+		Round:      round,
+	})
+}
+
+// Setup the journal. The journal is closed when given context is done.
+// Previous setup, if any, is replaced.
+func Setup(ctx context.Context, c *Config) (err error) {
+	if c.KeepRounds <= 0 {
+		c.KeepRounds = 1000
+	}
+	if c.MaxEvents <= 0 {
+		c.MaxEvents = 100000
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 4096
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if journal != nil {
+		journal.Close()
+		journal = nil
+	}
+	if !c.Enabled {
+		return
+	}
+
+	var kv kvstore.KV = kvstore.NewMemoryKV()
+	if c.Dir != "" {
+		if kv, err = kvstore.OpenRocksKV(c.Dir); err != nil {
+			return
+		}
+	}
+	var j *Journal
+	if j, err = New(kv, c); err != nil {
+		kv.Close()
+		return
+	}
+	journal = j
+
+	go func() {
+		<-ctx.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		if journal == j {
+			journal.Close()
+			journal = nil
+		}
+	}()
+	return
+}
+
+// Get returns the journal of the node, or nil if it's disabled.
+func Get() *Journal {
+	mu.RLock()
+	defer mu.RUnlock()
+	return journal
+}
+
+// Record the event in the journal of the node, if it's enabled. The event
+// must not be changed after.
+func Record(e *Event) {
+	if j := Get(); j != nil {
+		j.Record(e)
+	}
+}
+
+func logError(msg string, fields ...zap.Field) {
+	if logging.Logger != nil {
+		logging.Logger.Error(msg, fields...)
+	}
+}
@@ -0,0 +1,100 @@
+package journal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"0chain.net/core/common"
+)
+
+// Limits of number of the events returned by the handler.
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 1000
+)
+
+// QueryResult is response of the Handler.
+type QueryResult struct {
+	Events []*Event `json:"events"`
+	// More is true if there are more events, they are queried from the
+	// round and the sequence number next to the ones of the last event.
+	More bool `json:"more"`
+}
+
+// ParseQuery parses the 'from_round', 'from_seq', 'to_round', 'type' and
+// 'limit' parameters of the request. The 'type' is a comma separated list.
+func ParseQuery(r *http.Request) (q *Query, err error) {
+	q = &Query{Limit: DefaultQueryLimit}
+	if q.FromRound, err = parseInt(r, "from_round"); err != nil {
+		return
+	}
+	if q.FromSeq, err = parseInt(r, "from_seq"); err != nil {
+		return
+	}
+	if q.ToRound, err = parseInt(r, "to_round"); err != nil {
+		return
+	}
+	if q.ToRound > 0 && q.ToRound < q.FromRound {
+		return nil, common.NewErrBadRequest("to_round is less than from_round")
+	}
+	if types := r.FormValue("type"); types != "" {
+		for _, name := range strings.Split(types, ",") {
+			var et, err = ParseEventType(strings.TrimSpace(name))
+			if err != nil {
+				return nil, common.NewErrBadRequest(err.Error())
+			}
+			q.Types = append(q.Types, et)
+		}
+	}
+	var limit int64
+	if limit, err = parseInt(r, "limit"); err != nil {
+		return
+	}
+	if limit > 0 {
+		q.Limit = int(limit)
+	}
+	if q.Limit > MaxQueryLimit {
+		q.Limit = MaxQueryLimit
+	}
+	return
+}
+
+func parseInt(r *http.Request, name string) (int64, error) {
+	var val = r.FormValue(name)
+	if val == "" {
+		return 0, nil
+	}
+	var n, err = strconv.ParseInt(val, 10, 64)
+	if err != nil || n < 0 {
+		return 0, common.NewErrBadRequest("invalid "+name, val)
+	}
+	return n, nil
+}
+
+// Handler - returns the journal events of the node.
+func Handler(_ context.Context, r *http.Request) (interface{}, error) {
+	var j = Get()
+	if j == nil {
+		return nil, common.NewErrNoResource("journal is disabled")
+	}
+	var q, err = ParseQuery(r)
+	if err != nil {
+		return nil, err
+	}
+	var limit = q.Limit
+	q.Limit++ // to check for more events
+	var events []*Event
+	if events, err = j.Query(q); err != nil {
+		return nil, common.NewErrInternal("querying journal", err.Error())
+	}
+	var res = &QueryResult{Events: events}
+	if len(events) > limit {
+		res.Events, res.More = events[:limit], true
+	}
+	if res.Events == nil {
+		res.Events = []*Event{}
+	}
+	return res, nil
+}
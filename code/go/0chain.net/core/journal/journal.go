@@ -0,0 +1,340 @@
+// Package journal keeps a bounded journal of consensus events of the node,
+// such as rounds started and blocks proposed, verified or finalized.
+//
+// The events are stored in an ordered key-value store keyed by round and
+// sequence number, thus they are queried by a range of rounds. Events of
+// rounds older than the configured number of rounds below the current round
+// of the node are pruned, as well as the oldest events above the configured
+// maximum. Events of rounds too far ahead of the current round are rejected,
+// thus, a peer sending blocks of arbitrary rounds can't push out the events.
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/core/kvstore"
+)
+
+// EventType is type of a journal event.
+type EventType string
+
+// Types of the events.
+const (
+	// RoundStarted by the node.
+	RoundStarted EventType = "round_started"
+	// BlockProposed is a block generated by the node.
+	BlockProposed EventType = "block_proposed"
+	// BlockReceived is a block proposal received from another miner.
+	BlockReceived EventType = "block_received"
+	// BlockVerified is a block verified by the node, the Error is set for
+	// a block failed the verification.
+	BlockVerified EventType = "block_verified"
+	// BlockNotarized is a block notarized in the view of the node.
+	BlockNotarized EventType = "block_notarized"
+	// BlockFinalized is a block finalized by the node.
+	BlockFinalized EventType = "block_finalized"
+	// PhaseChanged is a view change phase transition.
+	PhaseChanged EventType = "phase_changed"
+	// SyncFetch is a block fetched from other nodes, the Error is set for
+	// a failed fetch.
+	SyncFetch EventType = "sync_fetch"
+)
+
+// EventTypes is list of all the event types.
+var EventTypes = []EventType{
+	RoundStarted,
+	BlockProposed,
+	BlockReceived,
+	BlockVerified,
+	BlockNotarized,
+	BlockFinalized,
+	PhaseChanged,
+	SyncFetch,
+}
+
+// ParseEventType returns event type of given name.
+func ParseEventType(name string) (EventType, error) {
+	for _, et := range EventTypes {
+		if string(et) == name {
+			return et, nil
+		}
+	}
+	return "", fmt.Errorf("unknown event type %q", name)
+}
+
+// An Event of the journal.
+type Event struct {
+	// Seq is sequence number of the event set by the journal.
+	Seq int64 `json:"seq"`
+	// Time of the event, set by the journal if zero.
+	Time  time.Time `json:"time"`
+	Type  EventType `json:"type"`
+	Round int64     `json:"round"`
+	// Block hash, if the event is about a block.
+	Block string `json:"block,omitempty"`
+	// Node is ID of another node of the event, for example, the generator
+	// of a block or the node a block is fetched from.
+	Node string `json:"node,omitempty"`
+	// Phase of the view change.
+	Phase string `json:"phase,omitempty"`
+	// Error of a failed operation.
+	Error string `json:"error,omitempty"`
+	// Details specific to the event type.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Query of the journal events.
+type Query struct {
+	// FromRound and ToRound are inclusive bounds of rounds of the events,
+	// zero ToRound means no upper bound.
+	FromRound, ToRound int64
+	// FromSeq skips events of the FromRound with less sequence numbers, to
+	// continue a query from the last event returned.
+	FromSeq int64
+	// Types of the events, all the types if empty.
+	Types []EventType
+	// Limit is max number of the events returned.
+	Limit int
+}
+
+func (q *Query) match(e *Event) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, et := range q.Types {
+		if et == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// key prefix of the events
+var eventPrefix = []byte("e:")
+
+// eventKey orders the events by round and sequence number
+func eventKey(round, seq int64) []byte {
+	var key = make([]byte, 0, len(eventPrefix)+16)
+	key = append(key, eventPrefix...)
+	key = append(key, kvstore.Uint64Key(round)...)
+	return append(key, kvstore.Uint64Key(seq)...)
+}
+
+func parseEventKey(key []byte) (round, seq int64) {
+	key = key[len(eventPrefix):]
+	return int64(binary.BigEndian.Uint64(key[:8])),
+		int64(binary.BigEndian.Uint64(key[8:16]))
+}
+
+// Journal stores the events. The events are recorded asynchronously by a
+// writer goroutine and dropped when the queue of the writer is full.
+type Journal struct {
+	kv         kvstore.KV
+	keepRounds int64
+	maxEvents  int
+	round      func() int64
+
+	queue    chan *Event
+	flushq   chan chan struct{}
+	quit     chan struct{}
+	done     chan struct{}
+	dropped  int64 // atomic
+	rejected int64 // atomic
+
+	// used by the writer only
+	seq   int64
+	count int // number of the stored events
+}
+
+// New journal over given KV. Sequence numbers continue the ones of the
+// events stored already. The journal should be closed by the Close.
+func New(kv kvstore.KV, c *Config) (j *Journal, err error) {
+	j = &Journal{
+		kv:         kv,
+		keepRounds: c.KeepRounds,
+		maxEvents:  c.MaxEvents,
+		round:      c.Round,
+		queue:      make(chan *Event, c.QueueSize),
+		flushq:     make(chan chan struct{}),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	err = kv.Iterate(eventPrefix, eventPrefix, func(key, _ []byte) bool {
+		var _, seq = parseEventKey(key)
+		if seq > j.seq {
+			j.seq = seq
+		}
+		j.count++
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading journal: %v", err)
+	}
+	go j.run()
+	return
+}
+
+// Record the event. Events of rounds ahead of the current round by more
+// than the kept rounds are rejected.
+func (j *Journal) Record(e *Event) {
+	if j.round != nil && e.Round > j.round()+j.keepRounds {
+		atomic.AddInt64(&j.rejected, 1)
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	select {
+	case j.queue <- e:
+	default:
+		atomic.AddInt64(&j.dropped, 1)
+	}
+}
+
+// Flush waits for the events recorded before to be stored.
+func (j *Journal) Flush() {
+	var ack = make(chan struct{})
+	select {
+	case j.flushq <- ack:
+		<-ack
+	case <-j.done:
+	}
+}
+
+// Close stores the recorded events, stops the writer and closes the KV.
+func (j *Journal) Close() {
+	close(j.quit)
+	<-j.done
+	j.kv.Close()
+}
+
+// Query the events ordered by round and sequence number.
+func (j *Journal) Query(q *Query) (events []*Event, err error) {
+	var iterErr error
+	err = j.kv.Iterate(eventPrefix, eventKey(q.FromRound, q.FromSeq),
+		func(key, value []byte) bool {
+			if round, _ := parseEventKey(key); q.ToRound > 0 &&
+				round > q.ToRound {
+				return false
+			}
+			var e Event
+			if iterErr = json.Unmarshal(value, &e); iterErr != nil {
+				return false
+			}
+			if !q.match(&e) {
+				return true
+			}
+			events = append(events, &e)
+			return q.Limit <= 0 || len(events) < q.Limit
+		})
+	if err == nil {
+		err = iterErr
+	}
+	return
+}
+
+func (j *Journal) run() {
+	defer close(j.done)
+
+	var batch []*Event
+	for {
+		select {
+		case e := <-j.queue:
+			batch = append(batch[:0], e)
+			batch = j.drain(batch)
+			j.write(batch)
+		case ack := <-j.flushq:
+			j.write(j.drain(batch[:0]))
+			close(ack)
+		case <-j.quit:
+			j.write(j.drain(batch[:0]))
+			return
+		}
+	}
+}
+
+// drain appends queued events to the batch
+func (j *Journal) drain(batch []*Event) []*Event {
+	for {
+		select {
+		case e := <-j.queue:
+			batch = append(batch, e)
+		default:
+			return batch
+		}
+	}
+}
+
+func (j *Journal) write(events []*Event) {
+	if dropped := atomic.SwapInt64(&j.dropped, 0); dropped > 0 {
+		logError("journal - events dropped, queue is full",
+			zap.Int64("dropped", dropped))
+	}
+	if rejected := atomic.SwapInt64(&j.rejected, 0); rejected > 0 {
+		logError("journal - events of rounds far ahead rejected",
+			zap.Int64("rejected", rejected))
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	var batch kvstore.Batch
+	for _, e := range events {
+		j.seq++
+		e.Seq = j.seq
+		var value, err = json.Marshal(e)
+		if err != nil {
+			logError("journal - encoding event", zap.Any("type", e.Type),
+				zap.Error(err))
+			continue
+		}
+		batch.Put(eventKey(e.Round, e.Seq), value)
+	}
+	if err := j.kv.Write(&batch); err != nil {
+		logError("journal - writing events", zap.Error(err))
+		return
+	}
+	j.count += batch.Len()
+	j.prune()
+}
+
+// prune deletes events of rounds out of the kept rounds below the current
+// round and the oldest events above the max number of the events
+func (j *Journal) prune() {
+	var minRound int64
+	if j.round != nil {
+		minRound = j.round() - j.keepRounds
+	}
+	if j.count <= j.maxEvents && minRound <= 0 {
+		return
+	}
+
+	var batch kvstore.Batch
+	var err = j.kv.Iterate(eventPrefix, eventPrefix,
+		func(key, _ []byte) bool {
+			var round, _ = parseEventKey(key)
+			if round >= minRound && j.count-batch.Len() <= j.maxEvents {
+				return false
+			}
+			batch.Delete(key)
+			return true
+		})
+	if err != nil {
+		logError("journal - iterating events to prune", zap.Error(err))
+		return
+	}
+	if batch.Len() == 0 {
+		return
+	}
+	if err = j.kv.Write(&batch); err != nil {
+		logError("journal - pruning events", zap.Error(err))
+		return
+	}
+	j.count -= batch.Len()
+}
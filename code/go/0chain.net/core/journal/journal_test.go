@@ -0,0 +1,168 @@
+package journal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/kvstore"
+)
+
+func newTestJournal(t *testing.T, kv kvstore.KV, c *Config) *Journal {
+	t.Helper()
+	if c.QueueSize == 0 {
+		c.QueueSize = 100
+	}
+	var j, err = New(kv, c)
+	require.NoError(t, err)
+	return j
+}
+
+func rounds(events []*Event) (list []int64) {
+	for _, e := range events {
+		list = append(list, e.Round)
+	}
+	return
+}
+
+func TestJournal_Query(t *testing.T) {
+	var j = newTestJournal(t, kvstore.NewMemoryKV(),
+		&Config{KeepRounds: 100, MaxEvents: 100})
+	defer j.Close()
+
+	for r := int64(1); r <= 5; r++ {
+		j.Record(&Event{Type: RoundStarted, Round: r})
+		j.Record(&Event{Type: BlockFinalized, Round: r - 1, Block: "b"})
+	}
+	j.Flush()
+
+	var events, err = j.Query(&Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 10)
+	assert.Equal(t, []int64{0, 1, 1, 2, 2, 3, 3, 4, 4, 5}, rounds(events))
+	assert.False(t, events[0].Time.IsZero())
+
+	events, err = j.Query(&Query{FromRound: 2, ToRound: 3,
+		Types: []EventType{BlockFinalized}})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, rounds(events))
+
+	events, err = j.Query(&Query{FromRound: 2, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, []int64{2, 2, 3}, rounds(events))
+
+	// continue the query
+	var last = events[len(events)-1]
+	events, err = j.Query(&Query{FromRound: last.Round, FromSeq: last.Seq + 1,
+		Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4, 4}, rounds(events))
+}
+
+func TestJournal_prune(t *testing.T) {
+	var (
+		current int64
+		j       = newTestJournal(t, kvstore.NewMemoryKV(),
+			&Config{KeepRounds: 3, MaxEvents: 4,
+				Round: func() int64 { return atomic.LoadInt64(&current) }})
+	)
+	defer j.Close()
+
+	for r := int64(1); r <= 5; r++ {
+		atomic.StoreInt64(&current, r)
+		j.Record(&Event{Type: RoundStarted, Round: r})
+	}
+	j.Flush()
+	var events, err = j.Query(&Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 4, 5}, rounds(events), "rounds kept")
+
+	for i := 0; i < 3; i++ {
+		j.Record(&Event{Type: SyncFetch, Round: 5})
+	}
+	j.Flush()
+	events, err = j.Query(&Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 5, 5, 5}, rounds(events), "max events")
+}
+
+func TestJournal_roundsAhead(t *testing.T) {
+	var j = newTestJournal(t, kvstore.NewMemoryKV(),
+		&Config{KeepRounds: 3, MaxEvents: 100,
+			Round: func() int64 { return 10 }})
+	defer j.Close()
+
+	for r := int64(5); r <= 10; r++ {
+		j.Record(&Event{Type: RoundStarted, Round: r})
+	}
+	// an unvalidated block of a round far ahead is rejected, it doesn't
+	// prune the events of the current rounds
+	j.Record(&Event{Type: BlockReceived, Round: 1000000})
+	j.Record(&Event{Type: BlockReceived, Round: 13})
+	j.Flush()
+
+	var events, err = j.Query(&Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 8, 9, 10, 13}, rounds(events))
+}
+
+func TestJournal_reopen(t *testing.T) {
+	var (
+		kv = kvstore.NewMemoryKV()
+		j  = newTestJournal(t, kv, &Config{KeepRounds: 10, MaxEvents: 10})
+	)
+	j.Record(&Event{Type: RoundStarted, Round: 1})
+	j.Record(&Event{Type: RoundStarted, Round: 2})
+	j.Close() // the memory KV keeps the data
+
+	j = newTestJournal(t, kv, &Config{KeepRounds: 10, MaxEvents: 10})
+	defer j.Close()
+	j.Record(&Event{Type: RoundStarted, Round: 2})
+	j.Flush()
+
+	var events, err = j.Query(&Query{FromRound: 2})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, int64(2), events[0].Seq)
+	assert.Equal(t, int64(3), events[1].Seq)
+}
+
+func TestHandler(t *testing.T) {
+	var ctx = context.Background()
+	require.NoError(t, Setup(ctx, &Config{}))
+	var _, err = Handler(ctx, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Error(t, err, "disabled")
+
+	require.NoError(t, Setup(ctx, &Config{Enabled: true}))
+	defer Setup(ctx, &Config{})
+
+	for r := int64(1); r <= 3; r++ {
+		Record(&Event{Type: BlockVerified, Round: r, Block: "h"})
+	}
+	Get().Flush()
+
+	var res interface{}
+	res, err = Handler(ctx, httptest.NewRequest(http.MethodGet,
+		"/?from_round=2&type=block_verified,round_started&limit=1", nil))
+	require.NoError(t, err)
+	var qr = res.(*QueryResult)
+	assert.Equal(t, []int64{2}, rounds(qr.Events))
+	assert.True(t, qr.More)
+
+	for _, query := range []string{
+		"from_round=x",
+		"limit=-1",
+		"type=unknown",
+		"from_round=5&to_round=3",
+	} {
+		_, err = Handler(ctx, httptest.NewRequest(http.MethodGet,
+			"/?"+query, nil))
+		assert.Error(t, err, query)
+	}
+}
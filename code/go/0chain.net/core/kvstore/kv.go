@@ -0,0 +1,104 @@
+package kvstore
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+/*KV - an ordered key-value storage backing the store */
+type KV interface {
+	// Get returns nil value for a missing key.
+	Get(key []byte) ([]byte, error)
+	// Write applies all the operations of the batch atomically.
+	Write(batch *Batch) error
+	// Iterate calls the handler for the keys with given prefix in order
+	// starting from the from key until the handler returns false.
+	Iterate(prefix, from []byte, handler func(key, value []byte) bool) error
+	Close()
+}
+
+type batchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+/*Batch - a set of write operations applied atomically */
+type Batch struct {
+	ops []batchOp
+}
+
+//Put - put the value of the key
+func (b *Batch) Put(key, value []byte) {
+	b.ops = append(b.ops, batchOp{key: key, value: value})
+}
+
+//Delete - delete the key
+func (b *Batch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{key: key, delete: true})
+}
+
+//Len - number of operations of the batch
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+/*MemoryKV - an in-memory KV for tests and nodes not keeping the data */
+type MemoryKV struct {
+	mutex sync.RWMutex
+	data  map[string][]byte
+}
+
+//NewMemoryKV - create a new in-memory KV
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{data: make(map[string][]byte)}
+}
+
+//Get - implement interface
+func (m *MemoryKV) Get(key []byte) ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.data[string(key)], nil
+}
+
+//Write - implement interface
+func (m *MemoryKV) Write(batch *Batch) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, op := range batch.ops {
+		if op.delete {
+			delete(m.data, string(op.key))
+			continue
+		}
+		m.data[string(op.key)] = append([]byte{}, op.value...)
+	}
+	return nil
+}
+
+//Iterate - implement interface
+func (m *MemoryKV) Iterate(prefix, from []byte, handler func(key, value []byte) bool) error {
+	m.mutex.RLock()
+	var keys []string
+	for k := range m.data {
+		if bytes.HasPrefix([]byte(k), prefix) && bytes.Compare([]byte(k), from) >= 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		values[i] = m.data[k]
+	}
+	m.mutex.RUnlock()
+
+	for i, k := range keys {
+		if !handler([]byte(k), values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+//Close - implement interface
+func (m *MemoryKV) Close() {}
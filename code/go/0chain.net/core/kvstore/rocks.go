@@ -0,0 +1,78 @@
+package kvstore
+
+import (
+	"bytes"
+
+	"github.com/0chain/gorocksdb"
+)
+
+/*RocksKV - an on-disk KV using an embedded rocksdb database */
+type RocksKV struct {
+	db *gorocksdb.DB
+	ro *gorocksdb.ReadOptions
+	wo *gorocksdb.WriteOptions
+}
+
+//OpenRocksKV - open or create the rocksdb database in the directory
+func OpenRocksKV(dataDir string) (*RocksKV, error) {
+	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
+	bbto.SetBlockCache(gorocksdb.NewLRUCache(256 << 20))
+	opts := gorocksdb.NewDefaultOptions()
+	opts.SetKeepLogFileNum(5)
+	opts.SetBlockBasedTableFactory(bbto)
+	opts.SetCreateIfMissing(true)
+	db, err := gorocksdb.OpenDb(opts, dataDir)
+	if err != nil {
+		return nil, err
+	}
+	wo := gorocksdb.NewDefaultWriteOptions()
+	wo.SetSync(true)
+	return &RocksKV{db: db, ro: gorocksdb.NewDefaultReadOptions(), wo: wo}, nil
+}
+
+//Get - implement interface
+func (r *RocksKV) Get(key []byte) ([]byte, error) {
+	return r.db.GetBytes(r.ro, key)
+}
+
+//Write - implement interface
+func (r *RocksKV) Write(batch *Batch) error {
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+	for _, op := range batch.ops {
+		if op.delete {
+			wb.Delete(op.key)
+			continue
+		}
+		wb.Put(op.key, op.value)
+	}
+	return r.db.Write(r.wo, wb)
+}
+
+//Iterate - implement interface
+func (r *RocksKV) Iterate(prefix, from []byte, handler func(key, value []byte) bool) error {
+	iter := r.db.NewIterator(r.ro)
+	defer iter.Close()
+	if bytes.Compare(from, prefix) < 0 {
+		from = prefix
+	}
+	for iter.Seek(from); iter.ValidForPrefix(prefix); iter.Next() {
+		key, value := iter.Key(), iter.Value()
+		next := handler(copyBytes(key.Data()), copyBytes(value.Data()))
+		key.Free()
+		value.Free()
+		if !next {
+			break
+		}
+	}
+	return iter.Err()
+}
+
+//Close - implement interface
+func (r *RocksKV) Close() {
+	r.db.Close()
+}
+
+func copyBytes(data []byte) []byte {
+	return append([]byte{}, data...)
+}
@@ -0,0 +1,364 @@
+package kvstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+const (
+	entityPrefix = "e\x00"
+	indexPrefix  = "i\x00"
+	separator    = 0
+)
+
+/*ClusteringFunc - returns the clustering part of the storage key of an entity. The
+entities with the same key are stored as rows of the key partition ordered by the
+clustering part, like the clustering columns of Cassandra tables */
+type ClusteringFunc func(entity datastore.Entity) []byte
+
+/*Index - a secondary index of entities, like a Cassandra materialized view */
+type Index struct {
+	Name   string
+	Entity string
+	// Key returns fixed length index key of the entity, nil to not index it.
+	Key func(entity datastore.Entity) []byte
+}
+
+/*Store - a datastore.Store on an embedded ordered key-value storage; an alternative
+to the Cassandra persistencestore for small deployments and tests */
+type Store struct {
+	kv KV
+	// serializes writes reading the previous entity
+	mutex      sync.Mutex
+	clustering map[string]ClusteringFunc
+	indexes    map[string][]*Index
+	byName     map[string]*Index
+}
+
+var (
+	// Make sure Store implements datastore.Store.
+	_ datastore.Store = (*Store)(nil)
+)
+
+//NewStore - create a new store on the given KV
+func NewStore(kv KV) *Store {
+	return &Store{
+		kv:         kv,
+		clustering: make(map[string]ClusteringFunc),
+		indexes:    make(map[string][]*Index),
+		byName:     make(map[string]*Index),
+	}
+}
+
+//RegisterClustering - store entities of given name as rows of their key partition; should be called on setup
+func (s *Store) RegisterClustering(entityName string, fn ClusteringFunc) {
+	s.clustering[entityName] = fn
+}
+
+//RegisterIndex - maintain the secondary index; should be called on setup
+func (s *Store) RegisterIndex(index *Index) {
+	s.indexes[index.Entity] = append(s.indexes[index.Entity], index)
+	s.byName[index.Name] = index
+}
+
+//Close - close the underlying KV
+func (s *Store) Close() {
+	s.kv.Close()
+}
+
+//Uint64Key - big endian key ordered as the number
+func Uint64Key(n int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(n))
+	return key
+}
+
+func getEntityPrefix(entityName string) []byte {
+	return []byte(entityPrefix + entityName + "\x00")
+}
+
+func getPartitionPrefix(emd datastore.EntityMetadata, key datastore.Key) []byte {
+	prefix := append(getEntityPrefix(emd.GetName()), datastore.ToString(key)...)
+	return append(prefix, separator)
+}
+
+func (s *Store) getKey(emd datastore.EntityMetadata, key datastore.Key) []byte {
+	return append(getEntityPrefix(emd.GetName()), datastore.ToString(key)...)
+}
+
+func (s *Store) getStorageKey(entity datastore.Entity) []byte {
+	emd := entity.GetEntityMetadata()
+	if fn, ok := s.clustering[emd.GetName()]; ok {
+		return append(getPartitionPrefix(emd, entity.GetKey()), fn(entity)...)
+	}
+	return s.getKey(emd, entity.GetKey())
+}
+
+func getIndexPrefix(index *Index, indexKey []byte) []byte {
+	prefix := []byte(indexPrefix + index.Name + "\x00")
+	return append(prefix, indexKey...)
+}
+
+// addIndexes adds operations to update the indexes of the entity replacing
+// the previous one, either can be nil
+func (s *Store) addIndexes(batch *Batch, storageKey []byte, prev, entity datastore.Entity) {
+	var emd datastore.EntityMetadata
+	if entity != nil {
+		emd = entity.GetEntityMetadata()
+	} else {
+		emd = prev.GetEntityMetadata()
+	}
+	for _, index := range s.indexes[emd.GetName()] {
+		var pkey, nkey []byte
+		if prev != nil {
+			pkey = index.Key(prev)
+		}
+		if entity != nil {
+			nkey = index.Key(entity)
+		}
+		if pkey != nil && !bytes.Equal(pkey, nkey) {
+			batch.Delete(append(getIndexPrefix(index, pkey), storageKey...))
+		}
+		if nkey != nil {
+			batch.Put(append(getIndexPrefix(index, nkey), storageKey...), nil)
+		}
+	}
+}
+
+func (s *Store) getPrevious(emd datastore.EntityMetadata, storageKey []byte) (datastore.Entity, error) {
+	if len(s.indexes[emd.GetName()]) == 0 {
+		return nil, nil
+	}
+	data, err := s.kv.Get(storageKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	prev := emd.Instance()
+	if err = datastore.FromJSON(data, prev); err != nil {
+		return nil, err
+	}
+	return prev, nil
+}
+
+func (s *Store) addWrite(batch *Batch, entity datastore.Entity) error {
+	return s.addWriteKey(batch, s.getStorageKey(entity), entity)
+}
+
+func (s *Store) addWriteKey(batch *Batch, storageKey []byte, entity datastore.Entity) error {
+	prev, err := s.getPrevious(entity.GetEntityMetadata(), storageKey)
+	if err != nil {
+		return err
+	}
+	batch.Put(storageKey, datastore.ToJSON(entity).Bytes())
+	s.addIndexes(batch, storageKey, prev, entity)
+	return nil
+}
+
+/*Read - read an entity from the store, the first row of a partition */
+func (s *Store) Read(ctx context.Context, key datastore.Key, entity datastore.Entity) error {
+	emd := entity.GetEntityMetadata()
+	var data []byte
+	if _, ok := s.clustering[emd.GetName()]; ok {
+		err := s.kv.Iterate(getPartitionPrefix(emd, key), nil, func(_, value []byte) bool {
+			data = value
+			return false
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if data, err = s.kv.Get(s.getKey(emd, key)); err != nil {
+			return err
+		}
+	}
+	if data == nil {
+		return common.NewError(datastore.EntityNotFound, fmt.Sprintf("%v not found with id = %v", emd.GetName(), key))
+	}
+	return datastore.FromJSON(data, entity)
+}
+
+/*Write - write an entity to the store */
+func (s *Store) Write(ctx context.Context, entity datastore.Entity) error {
+	return s.MultiWrite(ctx, entity.GetEntityMetadata(), []datastore.Entity{entity})
+}
+
+/*InsertIfNE - insert an entity to the store if it doesn't exist */
+func (s *Store) InsertIfNE(ctx context.Context, entity datastore.Entity) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data, err := s.kv.Get(s.getStorageKey(entity))
+	if err != nil || data != nil {
+		return err
+	}
+	batch := &Batch{}
+	if err = s.addWrite(batch, entity); err != nil {
+		return err
+	}
+	return s.kv.Write(batch)
+}
+
+/*Delete - delete an entity from the store, all rows of a partition */
+func (s *Store) Delete(ctx context.Context, entity datastore.Entity) error {
+	return s.MultiDelete(ctx, entity.GetEntityMetadata(), []datastore.Entity{entity})
+}
+
+/*MultiRead - read multiple entities from the store, the missing are set to nil */
+func (s *Store) MultiRead(ctx context.Context, entityMetadata datastore.EntityMetadata, keys []datastore.Key, entities []datastore.Entity) error {
+	for idx, key := range keys {
+		err := s.Read(ctx, key, entities[idx])
+		if err == nil {
+			continue
+		}
+		if cerr, ok := err.(*common.Error); ok && cerr.Code == datastore.EntityNotFound {
+			entities[idx] = nil
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+/*MultiWrite - write multiple entities to the store atomically */
+func (s *Store) MultiWrite(ctx context.Context, entityMetadata datastore.EntityMetadata, entities []datastore.Entity) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// the index entries are updated from the stored entities, so only the
+	// last write of a key is applied, the earlier ones are never stored
+	var (
+		keys = make([][]byte, len(entities))
+		last = make(map[string]int, len(entities))
+	)
+	for idx, entity := range entities {
+		keys[idx] = s.getStorageKey(entity)
+		last[string(keys[idx])] = idx
+	}
+	batch := &Batch{}
+	for idx, entity := range entities {
+		if last[string(keys[idx])] != idx {
+			continue
+		}
+		if err := s.addWriteKey(batch, keys[idx], entity); err != nil {
+			return err
+		}
+	}
+	return s.kv.Write(batch)
+}
+
+/*MultiDelete - delete multiple entities from the store atomically */
+func (s *Store) MultiDelete(ctx context.Context, entityMetadata datastore.EntityMetadata, entities []datastore.Entity) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	batch := &Batch{}
+	for _, entity := range entities {
+		emd := entity.GetEntityMetadata()
+		if _, ok := s.clustering[emd.GetName()]; !ok {
+			storageKey := s.getKey(emd, entity.GetKey())
+			prev, err := s.getPrevious(emd, storageKey)
+			if err != nil {
+				return err
+			}
+			batch.Delete(storageKey)
+			if prev != nil {
+				s.addIndexes(batch, storageKey, prev, nil)
+			}
+			continue
+		}
+		var err error
+		ierr := s.kv.Iterate(getPartitionPrefix(emd, entity.GetKey()), nil, func(key, value []byte) bool {
+			batch.Delete(key)
+			if len(s.indexes[emd.GetName()]) == 0 {
+				return true
+			}
+			prev := emd.Instance()
+			if err = datastore.FromJSON(value, prev); err != nil {
+				return false
+			}
+			s.addIndexes(batch, key, prev, nil)
+			return true
+		})
+		if ierr != nil {
+			return ierr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.kv.Write(batch)
+}
+
+/*IteratePartition - iterate the rows of the key partition ordered by the clustering
+part starting from the given one until the handler returns false */
+func (s *Store) IteratePartition(ctx context.Context, emd datastore.EntityMetadata, key datastore.Key, from []byte, handler func(entity datastore.Entity) bool) error {
+	prefix := getPartitionPrefix(emd, key)
+	return s.iterate(emd, prefix, append(prefix, from...), handler)
+}
+
+/*Iterate - iterate all the entities of the given type ordered by the key until the
+handler returns false */
+func (s *Store) Iterate(ctx context.Context, emd datastore.EntityMetadata, handler func(entity datastore.Entity) bool) error {
+	return s.iterate(emd, getEntityPrefix(emd.GetName()), nil, handler)
+}
+
+func (s *Store) iterate(emd datastore.EntityMetadata, prefix, from []byte, handler func(entity datastore.Entity) bool) error {
+	var err error
+	ierr := s.kv.Iterate(prefix, from, func(_, value []byte) bool {
+		entity := emd.Instance()
+		if err = datastore.FromJSON(value, entity); err != nil {
+			return false
+		}
+		return handler(entity)
+	})
+	if ierr != nil {
+		return ierr
+	}
+	return err
+}
+
+/*CountIndex - count the entities with the given key of the secondary index */
+func (s *Store) CountIndex(ctx context.Context, indexName string, indexKey []byte) (int, error) {
+	index, ok := s.byName[indexName]
+	if !ok {
+		return 0, common.NewErrorf("unknown_index", "unknown index: %v", indexName)
+	}
+	var count int
+	err := s.kv.Iterate(getIndexPrefix(index, indexKey), nil, func(_, _ []byte) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+/*AddToCollection - Add to collection */
+func (s *Store) AddToCollection(ctx context.Context, entity datastore.CollectionEntity) error {
+	// This may be NOOP for persistence stores
+	return nil
+}
+
+/*MultiAddToCollection - Add multiple entities to collection */
+func (s *Store) MultiAddToCollection(ctx context.Context, entityMetadata datastore.EntityMetadata, entities []datastore.Entity) error {
+	return nil
+}
+
+/*IterateCollection - iterate the given collection */
+func (s *Store) IterateCollection(ctx context.Context, entityMetadata datastore.EntityMetadata, collectionName string, handler datastore.CollectionIteratorHandler) error {
+	return nil
+}
+
+func (s *Store) DeleteFromCollection(ctx context.Context, entity datastore.CollectionEntity) error {
+	return nil
+}
+
+func (s *Store) MultiDeleteFromCollection(ctx context.Context, entityMetadata datastore.EntityMetadata, entities []datastore.Entity) error {
+	return nil
+}
+
+func (s *Store) GetCollectionSize(ctx context.Context, entityMetadata datastore.EntityMetadata, collectionName string) int64 {
+	return -1
+}
@@ -0,0 +1,172 @@
+package kvstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+type testEntity struct {
+	datastore.IDField
+	Round int64  `json:"round"`
+	Hash  string `json:"hash"`
+}
+
+var testEntityMetadata = &datastore.EntityMetadataImpl{
+	Name:     "test_entity",
+	Provider: func() datastore.Entity { return &testEntity{} },
+}
+
+func (te *testEntity) GetEntityMetadata() datastore.EntityMetadata {
+	return testEntityMetadata
+}
+
+func newTestEntity(id string, round int64, hash string) *testEntity {
+	te := &testEntity{Round: round, Hash: hash}
+	te.SetKey(id)
+	return te
+}
+
+func makeTestStore(clustered bool) *Store {
+	s := NewStore(NewMemoryKV())
+	if clustered {
+		s.RegisterClustering(testEntityMetadata.Name, func(e datastore.Entity) []byte {
+			te := e.(*testEntity)
+			return append(Uint64Key(te.Round), te.Hash...)
+		})
+	}
+	s.RegisterIndex(&Index{
+		Name:   "test_round",
+		Entity: testEntityMetadata.Name,
+		Key: func(e datastore.Entity) []byte {
+			return Uint64Key(e.(*testEntity).Round)
+		},
+	})
+	return s
+}
+
+func requireNotFound(t *testing.T, err error) {
+	cerr, ok := err.(*common.Error)
+	require.True(t, ok, "unexpected error: %v", err)
+	require.Equal(t, datastore.EntityNotFound, cerr.Code)
+}
+
+func TestStore_ReadWrite(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = makeTestStore(false)
+		got = &testEntity{}
+	)
+	requireNotFound(t, s.Read(ctx, "a", got))
+
+	require.NoError(t, s.Write(ctx, newTestEntity("a", 1, "h1")))
+	require.NoError(t, s.Read(ctx, "a", got))
+	require.Equal(t, newTestEntity("a", 1, "h1"), got)
+
+	// the existing entity is kept
+	require.NoError(t, s.InsertIfNE(ctx, newTestEntity("a", 2, "h2")))
+	require.NoError(t, s.Read(ctx, "a", got))
+	require.EqualValues(t, 1, got.Round)
+
+	require.NoError(t, s.InsertIfNE(ctx, newTestEntity("b", 1, "h3")))
+	count, err := s.CountIndex(ctx, "test_round", Uint64Key(1))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	// overwriting moves the index entry
+	require.NoError(t, s.Write(ctx, newTestEntity("a", 2, "h1")))
+	count, err = s.CountIndex(ctx, "test_round", Uint64Key(1))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	entities := datastore.AllocateEntities(3, testEntityMetadata)
+	require.NoError(t, s.MultiRead(ctx, testEntityMetadata,
+		[]datastore.Key{"a", "c", "b"}, entities))
+	require.NotNil(t, entities[0])
+	require.Nil(t, entities[1])
+	require.Equal(t, "h3", entities[2].(*testEntity).Hash)
+
+	require.NoError(t, s.MultiDelete(ctx, testEntityMetadata,
+		[]datastore.Entity{newTestEntity("a", 0, ""), newTestEntity("b", 0, "")}))
+	requireNotFound(t, s.Read(ctx, "a", got))
+	for _, round := range []int64{1, 2} {
+		count, err = s.CountIndex(ctx, "test_round", Uint64Key(round))
+		require.NoError(t, err)
+		require.Zero(t, count)
+	}
+
+	_, err = s.CountIndex(ctx, "unknown", nil)
+	require.Error(t, err)
+}
+
+func TestStore_MultiWriteDuplicates(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = makeTestStore(false)
+		got = &testEntity{}
+	)
+	require.NoError(t, s.Write(ctx, newTestEntity("a", 1, "h1")))
+
+	// the last write wins and leaves no index entries of the earlier ones
+	require.NoError(t, s.MultiWrite(ctx, testEntityMetadata, []datastore.Entity{
+		newTestEntity("a", 2, "h2"),
+		newTestEntity("b", 2, "h3"),
+		newTestEntity("a", 3, "h4"),
+	}))
+	require.NoError(t, s.Read(ctx, "a", got))
+	require.Equal(t, newTestEntity("a", 3, "h4"), got)
+	for round, want := range map[int64]int{1: 0, 2: 1, 3: 1} {
+		count, err := s.CountIndex(ctx, "test_round", Uint64Key(round))
+		require.NoError(t, err)
+		require.Equal(t, want, count, "round %d", round)
+	}
+}
+
+func TestStore_Partition(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = makeTestStore(true)
+	)
+	var entities []datastore.Entity
+	for _, round := range []int64{3, 1, 2, 256} {
+		entities = append(entities, newTestEntity("client", round, "h"))
+	}
+	entities = append(entities, newTestEntity("other", 1, "h"))
+	require.NoError(t, s.MultiWrite(ctx, testEntityMetadata, entities))
+
+	var rounds []int64
+	collect := func(e datastore.Entity) bool {
+		rounds = append(rounds, e.(*testEntity).Round)
+		return len(rounds) < 3
+	}
+	require.NoError(t, s.IteratePartition(ctx, testEntityMetadata, "client",
+		nil, collect))
+	require.Equal(t, []int64{1, 2, 3}, rounds)
+
+	rounds = nil
+	require.NoError(t, s.IteratePartition(ctx, testEntityMetadata, "client",
+		Uint64Key(3), collect))
+	require.Equal(t, []int64{3, 256}, rounds)
+
+	got := &testEntity{}
+	require.NoError(t, s.Read(ctx, "client", got))
+	require.EqualValues(t, 1, got.Round)
+
+	var all int
+	require.NoError(t, s.Iterate(ctx, testEntityMetadata, func(datastore.Entity) bool {
+		all++
+		return true
+	}))
+	require.Equal(t, 5, all)
+
+	require.NoError(t, s.Delete(ctx, newTestEntity("client", 0, "")))
+	requireNotFound(t, s.Read(ctx, "client", got))
+	require.NoError(t, s.Read(ctx, "other", got))
+	count, err := s.CountIndex(ctx, "test_round", Uint64Key(1))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"errors"
 	"os"
 
 	"go.uber.org/zap"
@@ -22,6 +23,9 @@ var (
 
 	// Health-Check logger. Currently only used for sharder.
 	HCLogger *zap.Logger
+
+	// level of the file loggers, it can be changed at runtime
+	level zap.AtomicLevel
 )
 
 //InitLogging - initialize the logging submodule
@@ -103,6 +107,16 @@ func InitLogging(mode string) {
 	HCLogger = hcl
 	N2n = ls
 	MemUsage = lu
+	level = cfg.Level
+}
+
+// SetLevel changes level of the loggers at runtime, the in-memory loggers
+// keep their levels.
+func SetLevel(l string) error {
+	if level == (zap.AtomicLevel{}) {
+		return errors.New("logging is not initialized")
+	}
+	return level.UnmarshalText([]byte(l))
 }
 
 func createZapCore(ws zapcore.WriteSyncer, conf zap.Config) zapcore.Core {
@@ -0,0 +1,269 @@
+package metric
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// PrometheusContentType is content type of the Prometheus text exposition
+// format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Quantiles of go-metrics timers and histograms exported as summaries.
+var Quantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// Labels of a sample.
+type Labels map[string]string
+
+// Sample of a labeled metric.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// PrometheusWriter writes metrics in the Prometheus text exposition format.
+// The first write error is kept and returned by the Err, following writes
+// are skipped. A metric with a sample name already written, e.g. different
+// names sanitized to the same one, is dropped and reported by the Dropped.
+type PrometheusWriter struct {
+	w       io.Writer
+	err     error
+	names   map[string]bool
+	dropped []string
+}
+
+// NewPrometheusWriter creates writer to given io.Writer.
+func NewPrometheusWriter(w io.Writer) *PrometheusWriter {
+	return &PrometheusWriter{w: w, names: make(map[string]bool)}
+}
+
+// Err returns first write error, if any.
+func (pw *PrometheusWriter) Err() error {
+	return pw.err
+}
+
+// Dropped returns sanitized names of the dropped metrics.
+func (pw *PrometheusWriter) Dropped() []string {
+	return pw.dropped
+}
+
+// claim reserves the sample names of a metric, the name followed by given
+// suffixes. It returns false if any of them is already written.
+func (pw *PrometheusWriter) claim(name string, suffixes ...string) bool {
+	var names = append([]string{name}, suffixes...)
+	for i := 1; i < len(names); i++ {
+		names[i] = name + names[i]
+	}
+	for _, n := range names {
+		if pw.names[n] {
+			pw.dropped = append(pw.dropped, name)
+			return false
+		}
+	}
+	for _, n := range names {
+		pw.names[n] = true
+	}
+	return true
+}
+
+func (pw *PrometheusWriter) printf(format string, args ...interface{}) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, args...)
+}
+
+func (pw *PrometheusWriter) header(name, help, typ string) {
+	if help != "" {
+		pw.printf("# HELP %s %s\n", name, escapeHelp(help))
+	}
+	pw.printf("# TYPE %s %s\n", name, typ)
+}
+
+func (pw *PrometheusWriter) sample(name string, labels Labels, value float64) {
+	pw.printf("%s%s %s\n", name, formatLabels(labels), formatValue(value))
+}
+
+// Gauge writes a gauge.
+func (pw *PrometheusWriter) Gauge(name, help string, value float64) {
+	name = SanitizeName(name)
+	if !pw.claim(name) {
+		return
+	}
+	pw.header(name, help, "gauge")
+	pw.sample(name, nil, value)
+}
+
+// Counter writes a counter.
+func (pw *PrometheusWriter) Counter(name, help string, value float64) {
+	name = SanitizeName(name)
+	if !pw.claim(name) {
+		return
+	}
+	pw.header(name, help, "counter")
+	pw.sample(name, nil, value)
+}
+
+// GaugeVec writes a gauge of the labeled samples. Nothing is written for
+// empty samples list.
+func (pw *PrometheusWriter) GaugeVec(name, help string, samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+	name = SanitizeName(name)
+	if !pw.claim(name) {
+		return
+	}
+	pw.header(name, help, "gauge")
+	for _, s := range samples {
+		pw.sample(name, s.Labels, s.Value)
+	}
+}
+
+// CounterVec writes a counter of the labeled samples. Nothing is written for
+// empty samples list.
+func (pw *PrometheusWriter) CounterVec(name, help string, samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+	name = SanitizeName(name)
+	if !pw.claim(name) {
+		return
+	}
+	pw.header(name, help, "counter")
+	for _, s := range samples {
+		pw.sample(name, s.Labels, s.Value)
+	}
+}
+
+// Summary writes a summary of given quantiles values, sum and count.
+func (pw *PrometheusWriter) Summary(name, help string, quantiles,
+	values []float64, sum float64, count int64) {
+
+	name = SanitizeName(name)
+	if !pw.claim(name, "_sum", "_count") {
+		return
+	}
+	pw.header(name, help, "summary")
+	for i, q := range quantiles {
+		pw.sample(name, Labels{"quantile": formatValue(q)}, values[i])
+	}
+	pw.sample(name+"_sum", nil, sum)
+	pw.sample(name+"_count", nil, float64(count))
+}
+
+// Registry writes all metrics of given go-metrics registry sorted by name.
+// Timers are exported in seconds. Of the names sanitized to the same one
+// the first in the order is written.
+func (pw *PrometheusWriter) Registry(r metrics.Registry) {
+	var (
+		all   = make(map[string]interface{})
+		names []string
+	)
+	r.Each(func(name string, m interface{}) {
+		all[name] = m
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch m := all[name].(type) {
+		case metrics.Counter:
+			pw.Counter(name+"_total", "", float64(m.Count()))
+		case metrics.Gauge:
+			pw.Gauge(name, "", float64(m.Value()))
+		case metrics.GaugeFloat64:
+			pw.Gauge(name, "", m.Value())
+		case metrics.Meter:
+			pw.Counter(name+"_total", "", float64(m.Count()))
+		case metrics.Timer:
+			var (
+				s      = m.Snapshot()
+				values = s.Percentiles(Quantiles)
+			)
+			for i := range values {
+				values[i] /= 1e9
+			}
+			pw.Summary(name+"_seconds", "", Quantiles, values,
+				float64(s.Sum())/1e9, s.Count())
+		case metrics.Histogram:
+			var s = m.Snapshot()
+			pw.Summary(name, "", Quantiles, s.Percentiles(Quantiles),
+				float64(s.Sum()), s.Count())
+		}
+	}
+}
+
+// SanitizeName replaces characters not allowed in Prometheus metric names
+// with underscores, e.g. 'sc:id:func:name' becomes 'sc_id_func_name'.
+func SanitizeName(name string) string {
+	var sb strings.Builder
+	sb.Grow(len(name))
+	for i, r := range name {
+		switch {
+		case r == '_',
+			r >= 'a' && r <= 'z',
+			r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9' && i > 0:
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var keys = make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(SanitizeName(k))
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabel(labels[k]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
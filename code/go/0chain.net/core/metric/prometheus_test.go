@@ -0,0 +1,94 @@
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+func TestSanitizeName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "block_save_time", want: "block_save_time"},
+		{name: "sc:6dba:func:add_miner", want: "sc_6dba_func_add_miner"},
+		{name: "tokens Poured", want: "tokens_Poured"},
+		{name: "1st", want: "_st"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeName(tt.name); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrometheusWriter_Registry(t *testing.T) {
+	t.Parallel()
+
+	var r = metrics.NewRegistry()
+	metrics.GetOrRegisterCounter("feesPaid", r).Inc(5)
+	metrics.GetOrRegisterGauge("queue", r).Update(3)
+	metrics.GetOrRegisterTimer("sc:id:func:pour", r).Update(2 * time.Second)
+
+	var (
+		buf bytes.Buffer
+		pw  = NewPrometheusWriter(&buf)
+	)
+	pw.Gauge("current_round", "Current round.", 10)
+	pw.GaugeVec("send_failures", "", []Sample{
+		{Labels: Labels{"peer": `m"1`, "id": "x"}, Value: 2},
+	})
+	pw.Registry(r)
+	if err := pw.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out = buf.String()
+	for _, want := range []string{
+		"# HELP current_round Current round.\n" +
+			"# TYPE current_round gauge\ncurrent_round 10\n",
+		"# TYPE send_failures gauge\n" +
+			`send_failures{id="x",peer="m\"1"} 2` + "\n",
+		"# TYPE feesPaid_total counter\nfeesPaid_total 5\n",
+		"# TYPE queue gauge\nqueue 3\n",
+		"# TYPE sc_id_func_pour_seconds summary\n" +
+			`sc_id_func_pour_seconds{quantile="0.5"} 2` + "\n",
+		"sc_id_func_pour_seconds_sum 2\nsc_id_func_pour_seconds_count 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrometheusWriter_duplicates(t *testing.T) {
+	t.Parallel()
+
+	var r = metrics.NewRegistry()
+	metrics.GetOrRegisterGauge("a:b", r).Update(1)
+	metrics.GetOrRegisterGauge("a_b", r).Update(2)
+	metrics.GetOrRegisterHistogram("h", r, metrics.NewUniformSample(10)).Update(3)
+
+	var (
+		buf bytes.Buffer
+		pw  = NewPrometheusWriter(&buf)
+	)
+	pw.Gauge("h_count", "", 4)
+	pw.Registry(r)
+	pw.Counter("h_count", "", 5)
+
+	var want = "# TYPE h_count gauge\nh_count 4\n" +
+		"# TYPE a_b gauge\na_b 1\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := strings.Join(pw.Dropped(), ","); got != "a_b,h,h_count" {
+		t.Errorf("dropped: %s", got)
+	}
+}
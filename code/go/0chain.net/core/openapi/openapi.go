@@ -0,0 +1,226 @@
+// Package openapi generates OpenAPI 3 document of the REST API of the node.
+//
+// The endpoints are described by Go types of their query parameters, JSON
+// request bodies and responses. The schemas of the types are derived from
+// their JSON encoding, the query parameters from the 'param' field tags
+// decoded by the common.DecodeParams. The {name} segments of a path are
+// the path parameters.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Version of the OpenAPI specification of the document.
+const Version = "3.0.3"
+
+// An Endpoint of the API.
+type Endpoint struct {
+	// Method of the endpoint, GET if empty.
+	Method string
+	// Path of the endpoint, optionally with {name} path parameters.
+	Path string
+	// OperationID is unique name of the endpoint, derived from the path if
+	// empty.
+	OperationID string
+	Summary     string
+	Tags        []string
+	// Params is a struct of the query parameters with the 'param' field
+	// tags, nil if there are no parameters.
+	Params interface{}
+	// Body is a value of type of the JSON request body, nil if none.
+	Body interface{}
+	// Response is a value of type of the JSON response, nil for any value.
+	Response interface{}
+	// ContentType of the response, JSON if empty. The Response is ignored
+	// for other content types.
+	ContentType string
+}
+
+// Document is the OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem is the operations of a path.
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+// Operation of a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType of a request or response body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the named schemas referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// ErrorSchemaName is name of the schema of the error responses.
+const ErrorSchemaName = "ErrorResponse"
+
+// NewDocument builds document of the endpoints. The errorResponse is a
+// value of type of body of all the error responses.
+func NewDocument(info Info, errorResponse interface{},
+	endpoints []*Endpoint) *Document {
+
+	var (
+		doc = &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]*PathItem),
+		}
+		g = newGenerator()
+	)
+
+	g.schemas[ErrorSchemaName] = g.inline(typeOf(errorResponse))
+	var errResp = &Response{
+		Description: "error",
+		Content: jsonContent(&Schema{
+			Ref: "#/components/schemas/" + ErrorSchemaName,
+		}),
+	}
+
+	for _, ep := range endpoints {
+		var op = &Operation{
+			OperationID: ep.OperationID,
+			Summary:     ep.Summary,
+			Tags:        ep.Tags,
+			Parameters: append(pathParameters(ep.Path),
+				g.parameters(ep.Params)...),
+			Responses: map[string]*Response{
+				"200": {
+					Description: "success",
+					Content:     g.responseContent(ep),
+				},
+				"default": errResp,
+			},
+		}
+		if op.OperationID == "" {
+			op.OperationID = OperationID(ep.Path)
+		}
+		if ep.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(g.schema(typeOf(ep.Body))),
+			}
+		}
+
+		var item = doc.Paths[ep.Path]
+		if item == nil {
+			item = new(PathItem)
+			doc.Paths[ep.Path] = item
+		}
+		if ep.Method == http.MethodPost {
+			item.Post = op
+		} else {
+			item.Get = op
+		}
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// responseContent returns content of the success response of the endpoint.
+func (g *generator) responseContent(ep *Endpoint) map[string]MediaType {
+	if ep.ContentType == "" || ep.ContentType == "application/json" {
+		return jsonContent(g.schema(typeOf(ep.Response)))
+	}
+	return map[string]MediaType{ep.ContentType: {Schema: &Schema{Type: "string"}}}
+}
+
+var pathParamRE = regexp.MustCompile(`{([^/{}]+)}`)
+
+// pathParameters returns the parameters of the {name} segments of the path.
+func pathParameters(path string) (list []*Parameter) {
+	for _, m := range pathParamRE.FindAllStringSubmatch(path, -1) {
+		list = append(list, &Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return
+}
+
+var nonAlnumRE = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// OperationID returns snake case operation ID of the path.
+func OperationID(path string) string {
+	return strings.Trim(nonAlnumRE.ReplaceAllString(path, "_"), "_")
+}
+
+var registry struct {
+	mu        sync.Mutex
+	endpoints []*Endpoint
+}
+
+// Register the endpoints served by the node.
+func Register(endpoints ...*Endpoint) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.endpoints = append(registry.endpoints, endpoints...)
+}
+
+// Registered returns the registered endpoints sorted by path.
+func Registered() []*Endpoint {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	var list = append([]*Endpoint(nil), registry.endpoints...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
@@ -0,0 +1,148 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/common"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Created  time.Time            `json:"created"`
+	Balance  int64                `json:"balance,string"`
+	Children []*testNode          `json:"children,omitempty"`
+	Meta     map[string]int       `json:"meta"`
+	Data     []byte               `json:"data"`
+	Any      interface{}          `json:"any"`
+	Hidden   string               `json:"-"`
+	Plain    float64              //
+	private  int                  //
+	Raw      json.RawMessage      `json:"raw"`
+	Nested   struct{ Ok bool }    `json:"nested"`
+	Keys     map[string][]string  `json:"keys"`
+	Timeouts map[int]*testBase    `json:"timeouts"`
+	Values   [2]uint8             `json:"values"`
+	Pointer  *map[string]struct{} `json:"pointer"`
+}
+
+type testPage struct {
+	Limit int `param:"limit"`
+}
+
+type testParams struct {
+	ClientID string `param:"client_id,required"`
+	Offset   int64  `param:"offset"`
+	Other    string
+	testPage
+}
+
+func TestNewDocument(t *testing.T) {
+	var doc = NewDocument(Info{Title: "API", Version: "1"},
+		common.ErrorResponse{}, []*Endpoint{
+			{
+				Path:     "/v1/node/get",
+				Summary:  "node",
+				Params:   testParams{},
+				Response: &testNode{},
+			},
+			{
+				Method:      http.MethodPost,
+				Path:        "/v1/node/put",
+				OperationID: "putNode",
+				Body:        testNode{},
+			},
+			{
+				Path:        "/v1/node/{id}/stats",
+				Summary:     "node stats page",
+				Params:      testPage{},
+				ContentType: "text/html",
+			},
+		})
+
+	// is valid JSON
+	var _, err = json.Marshal(doc)
+	require.NoError(t, err)
+
+	var get = doc.Paths["/v1/node/get"].Get
+	require.NotNil(t, get)
+	assert.Equal(t, "v1_node_get", get.OperationID)
+	require.Len(t, get.Parameters, 3)
+	assert.Equal(t, &Parameter{Name: "client_id", In: "query", Required: true,
+		Schema: &Schema{Type: "string"}}, get.Parameters[0])
+	assert.Equal(t, &Parameter{Name: "offset", In: "query",
+		Schema: &Schema{Type: "integer", Format: "int64"}}, get.Parameters[1])
+	assert.Equal(t, "limit", get.Parameters[2].Name)
+	assert.Equal(t, "#/components/schemas/openapi.testNode",
+		get.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, "#/components/schemas/ErrorResponse",
+		get.Responses["default"].Content["application/json"].Schema.Ref)
+
+	var put = doc.Paths["/v1/node/put"].Post
+	require.NotNil(t, put)
+	assert.Equal(t, "putNode", put.OperationID)
+	assert.Equal(t, "#/components/schemas/openapi.testNode",
+		put.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Equal(t, &Schema{}, put.Responses["200"].Content["application/json"].Schema)
+
+	var stats = doc.Paths["/v1/node/{id}/stats"].Get
+	require.NotNil(t, stats)
+	assert.Equal(t, "v1_node_id_stats", stats.OperationID)
+	require.Len(t, stats.Parameters, 2)
+	assert.Equal(t, &Parameter{Name: "id", In: "path", Required: true,
+		Schema: &Schema{Type: "string"}}, stats.Parameters[0])
+	assert.Equal(t, "limit", stats.Parameters[1].Name)
+	assert.Equal(t, map[string]MediaType{"text/html": {
+		Schema: &Schema{Type: "string"}}}, stats.Responses["200"].Content)
+
+	var errSchema = doc.Components.Schemas[ErrorSchemaName]
+	require.NotNil(t, errSchema)
+	assert.Equal(t, &Schema{Type: "string"}, errSchema.Properties["code"])
+	assert.Equal(t, &Schema{Type: "string"}, errSchema.Properties["error"])
+
+	var node = doc.Components.Schemas["openapi.testNode"]
+	require.NotNil(t, node)
+	var ref = &Schema{Ref: "#/components/schemas/openapi.testNode"}
+	for name, want := range map[string]*Schema{
+		"id":       {Type: "string"},
+		"created":  {Type: "string", Format: "date-time"},
+		"balance":  {Type: "string"},
+		"children": {Type: "array", Items: ref},
+		"meta": {Type: "object", AdditionalProperties: &Schema{
+			Type: "integer", Format: "int64"}},
+		"data":  {Type: "string", Format: "byte"},
+		"any":   {},
+		"Plain": {Type: "number", Format: "double"},
+		"raw":   {},
+		"nested": {Type: "object", Properties: map[string]*Schema{
+			"Ok": {Type: "boolean"}}},
+		"keys": {Type: "object", AdditionalProperties: &Schema{
+			Type: "array", Items: &Schema{Type: "string"}}},
+		"timeouts": {Type: "object", AdditionalProperties: &Schema{
+			Ref: "#/components/schemas/openapi.testBase"}},
+		"values": {Type: "array", Items: &Schema{Type: "integer",
+			Format: "int32"}},
+		"pointer": {Type: "object", AdditionalProperties: &Schema{
+			Type: "object", Properties: map[string]*Schema{}}},
+	} {
+		assert.Equal(t, want, node.Properties[name], name)
+	}
+	assert.Len(t, node.Properties, 14)
+}
+
+func TestRegistered(t *testing.T) {
+	Register(&Endpoint{Path: "/v1/b"}, &Endpoint{Path: "/v1/a"})
+	var list = Registered()
+	require.Len(t, list, 2)
+	assert.Equal(t, "/v1/a", list[0].Path)
+	assert.Equal(t, "/v1/b", list[1].Path)
+}
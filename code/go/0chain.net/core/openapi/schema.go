@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"0chain.net/core/common"
+)
+
+// Schema of a JSON value, the empty Schema is any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func typeOf(v interface{}) reflect.Type {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return t
+	}
+	return reflect.TypeOf(v)
+}
+
+// generator of the schemas, named struct types are the components
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// SchemaName returns name of the component schema of the named type, for
+// example 'storagesc.StakePoolStat'.
+func SchemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// schema returns reference to the component schema of a named struct, or
+// the inline schema of other types
+func (g *generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" || t == timeType ||
+		isMarshaler(t) {
+		return g.inline(t)
+	}
+	var name, ok = g.names[t]
+	if !ok {
+		name = SchemaName(t)
+		g.names[t] = name // before the fields, for recursive types
+		g.schemas[name] = g.inline(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func isMarshaler(t reflect.Type) bool {
+	var pt = reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// inline returns schema of the type
+func (g *generator) inline(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+	if t.Implements(jsonMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return &Schema{} // custom encoding
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		var s = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		g.properties(s, t)
+		return s
+	default:
+		return &Schema{} // interfaces
+	}
+}
+
+// properties adds JSON encoded fields of the struct to the schema
+func (g *generator) properties(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			field    = t.Field(i)
+			tag      = field.Tag.Get("json")
+			name     = field.Name
+			opts     []string
+			embedded = field.Anonymous
+		)
+		if tag == "-" {
+			continue
+		}
+		if tag != "" {
+			var parts = strings.Split(tag, ",")
+			if parts[0] != "" {
+				name, embedded = parts[0], false
+			}
+			opts = parts[1:]
+		}
+
+		var ft = field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if embedded && ft.Kind() == reflect.Struct && !isMarshaler(ft) {
+			g.properties(s, ft) // promoted fields
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		var fs = g.schema(field.Type)
+		for _, opt := range opts {
+			if opt == "string" {
+				fs = &Schema{Type: "string"}
+			}
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// parameters returns query parameters of the struct by the param tags
+func (g *generator) parameters(params interface{}) (list []*Parameter) {
+	var t = typeOf(params)
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		var name, required, ok = common.ParseParamTag(t.Field(i))
+		if !ok {
+			if t.Field(i).Anonymous {
+				list = append(list, g.parameters(t.Field(i).Type)...)
+			}
+			continue
+		}
+		list = append(list, &Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   g.inline(t.Field(i).Type),
+		})
+	}
+	return
+}
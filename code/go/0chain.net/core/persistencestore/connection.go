@@ -107,16 +107,26 @@ func GetCon(ctx context.Context) SessionI {
 	return ctx.Value(CONNECTION).(SessionI)
 }
 
-/*WithEntityConnection takes a context and adds a connection value to it */
+/*WithEntityConnection takes a context and adds a connection value to it, the context is
+returned as is for the entities not stored in the cassandra store */
 func WithEntityConnection(ctx context.Context, entityMetadata datastore.EntityMetadata) context.Context {
+	if entityMetadata != nil && entityMetadata.GetStore() != storageAPI {
+		return ctx
+	}
 	return WithConnection(ctx)
 }
 
 /*Close - close all the connections in the context */
 func Close(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	con, ok := ctx.Value(CONNECTION).(SessionI)
+	if !ok {
+		return
+	}
 	mutex.Lock()
 	defer mutex.Unlock()
-	con := GetCon(ctx)
 	if con != Session {
 		con.Close()
 	}
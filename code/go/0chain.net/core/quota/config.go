@@ -0,0 +1,177 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"0chain.net/core/common"
+	"0chain.net/core/viper"
+)
+
+// Limit of a token bucket.
+type Limit struct {
+	// Rate is number of the tokens added per second, there is no limit if
+	// it's zero.
+	Rate float64 `mapstructure:"rate"`
+	// Burst is size of the bucket.
+	Burst int `mapstructure:"burst"`
+}
+
+// APIKey of a client with its own limit. The IP limit doesn't apply to the
+// requests with an API key.
+type APIKey struct {
+	Key string `mapstructure:"key"`
+	// Name of the client in the metrics.
+	Name  string `mapstructure:"name"`
+	Limit `mapstructure:",squash"`
+}
+
+// Endpoint quotas, the path is a prefix of the paths of the endpoint.
+type Endpoint struct {
+	Path string `mapstructure:"path"`
+	// Cost is number of the tokens taken by a request.
+	Cost int `mapstructure:"cost"`
+	// Limit of all the requests to the endpoint.
+	Limit `mapstructure:",squash"`
+}
+
+// Config of the quotas.
+type Config struct {
+	// Enabled turns the quotas on, they replace the user handlers rate
+	// limit.
+	Enabled bool `mapstructure:"enabled"`
+	// Client is limit of a client without an API key.
+	Client Limit `mapstructure:"client"`
+	// IP is limit of all the requests from an IP without an API key, the
+	// wallets of the IP included. Verification of a wallet signature not
+	// seen before takes a token of it.
+	IP Limit `mapstructure:"ip"`
+	// APIKeys of the clients.
+	APIKeys []APIKey `mapstructure:"api_keys"`
+	// Endpoints quotas.
+	Endpoints []Endpoint `mapstructure:"endpoints"`
+	// SignatureTTL is max difference of the signed client timestamp and
+	// the node time.
+	SignatureTTL time.Duration `mapstructure:"signature_ttl"`
+	// SignatureScheme of the wallets, the wallet signatures are ignored if
+	// it's empty.
+	SignatureScheme string `mapstructure:"-"`
+	// NodeID is ID of this node, the wallet signatures are bound to it.
+	NodeID string `mapstructure:"-"`
+}
+
+var (
+	mu     sync.RWMutex
+	quotas *Quotas
+	nodeID string
+)
+
+// SetNodeID sets ID of this node the wallets sign requests to, it must be
+// called before ReadConfig.
+func SetNodeID(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+	nodeID = id
+}
+
+// ReadConfig reads the 'network.user_handlers.quota' section of the
+// configurations and sets up the quotas of the node.
+func ReadConfig() error {
+	var c Config
+	if err := viper.UnmarshalKey("network.user_handlers.quota", &c); err != nil {
+		return err
+	}
+	c.SignatureScheme = viper.GetString("server_chain.client.signature_scheme")
+	mu.RLock()
+	c.NodeID = nodeID
+	mu.RUnlock()
+	return Setup(&c)
+}
+
+// Setup the quotas of the user handlers. Previous setup, if any, is
+// replaced, the clients start with full buckets.
+func Setup(c *Config) error {
+	if err := c.setDefaults(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !c.Enabled {
+		quotas = nil
+		common.SetUserQuota(nil)
+		return nil
+	}
+	quotas = New(c)
+	common.SetUserQuota(quotas)
+	return nil
+}
+
+// Get returns the quotas of the node, or nil if they are disabled.
+func Get() *Quotas {
+	mu.RLock()
+	defer mu.RUnlock()
+	return quotas
+}
+
+// setDefaults validates the configurations and sets the defaults, a bucket
+// fits at least one request to any endpoint
+func (c *Config) setDefaults() error {
+	if c.SignatureTTL <= 0 {
+		c.SignatureTTL = 5 * time.Minute
+	}
+
+	var maxCost = 1
+	for i := range c.Endpoints {
+		var e = &c.Endpoints[i]
+		if e.Path == "" {
+			return errors.New("quota: endpoint without path")
+		}
+		if e.Cost < 0 {
+			return fmt.Errorf("quota: negative cost of %s", e.Path)
+		}
+		if e.Cost == 0 {
+			e.Cost = 1
+		}
+		if e.Cost > maxCost {
+			maxCost = e.Cost
+		}
+		e.Limit = e.Limit.withBurst(e.Cost)
+	}
+
+	c.Client = c.Client.withBurst(maxCost)
+	c.IP = c.IP.withBurst(maxCost)
+
+	var keys = make(map[string]struct{}, len(c.APIKeys))
+	for i := range c.APIKeys {
+		var ak = &c.APIKeys[i]
+		if ak.Key == "" {
+			return errors.New("quota: empty API key")
+		}
+		if _, ok := keys[ak.Key]; ok {
+			return fmt.Errorf("quota: duplicate API key %q", ak.Name)
+		}
+		keys[ak.Key] = struct{}{}
+		if ak.Name == "" {
+			ak.Name = fmt.Sprintf("key%d", i)
+		}
+		ak.Limit = ak.Limit.withBurst(maxCost)
+	}
+	return nil
+}
+
+// withBurst returns the limit with the burst of a second of the rate, but
+// not less than given min
+func (l Limit) withBurst(min int) Limit {
+	if l.Burst <= 0 {
+		l.Burst = int(math.Ceil(l.Rate))
+	}
+	if l.Burst < min {
+		l.Burst = min
+	}
+	return l
+}
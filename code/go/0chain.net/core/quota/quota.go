@@ -0,0 +1,321 @@
+// Package quota limits the requests to the user handlers of a node per
+// endpoint and per client. A client is identified by an API key, by a
+// signature of its wallet or by its IP. The requests take tokens of the
+// token buckets of the endpoint, of the client and, for a client without an
+// API key, of the IP of the client. Thus, wallets made up by a client share
+// the limit of its IP. Expensive endpoints take more tokens per request.
+// Verification of a wallet signature takes a token of the IP bucket before
+// the signature is checked, and failed verifications are cached, thus,
+// invalid signatures can't be used to load the node. A signature is bound
+// to the node and the path of the request, it can't be replayed to other
+// nodes or endpoints.
+package quota
+
+import (
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/time/rate"
+
+	"0chain.net/core/encryption"
+	"0chain.net/core/metric"
+)
+
+// Headers identifying a client.
+const (
+	// APIKeyHeader is the API key of the client.
+	APIKeyHeader = "X-Api-Key"
+	// ClientKeyHeader is the public key of the wallet of the client.
+	ClientKeyHeader = "X-Client-Key"
+	// ClientTimestampHeader is current unix time of the client in seconds.
+	ClientTimestampHeader = "X-Client-Timestamp"
+	// ClientSignatureHeader is signature of the hash of the timestamp, ID
+	// of the node and path of the request by the wallet of the client, see
+	// SignedMessage.
+	ClientSignatureHeader = "X-Client-Signature"
+)
+
+// SignedMessage returns the message of the wallet signature of a request to
+// the node of given ID and the path at the timestamp: "ts:node_id:path".
+func SignedMessage(ts, nodeID, path string) string {
+	return ts + ":" + nodeID + ":" + path
+}
+
+// Headers is list of the headers identifying a client.
+var Headers = []string{APIKeyHeader, ClientKeyHeader, ClientTimestampHeader,
+	ClientSignatureHeader}
+
+// Kinds of the limits rejecting a request.
+const (
+	EndpointLimit = "endpoint"
+	ClientLimit   = "client"
+	IPLimit       = "ip"
+)
+
+// clientsTTL is time the token buckets of an idle client are kept for
+const clientsTTL = time.Hour
+
+// endpoint of the quotas
+type endpoint struct {
+	path    string
+	cost    int
+	limiter *rate.Limiter // nil if the endpoint is not limited
+}
+
+// client is identity of the client of a request
+type client struct {
+	key   string // key of the token bucket of the client
+	label string // label of the client in the metrics
+	limit Limit
+	ip    bool // ip limit applies
+}
+
+// rejection is a key of the counter of the rejected requests
+type rejection struct {
+	endpoint, client, limit string
+}
+
+// Quotas of a node.
+type Quotas struct {
+	conf      *Config
+	endpoints []*endpoint // sorted by path length descending
+	apiKeys   map[string]*APIKey
+	scheme    func() encryption.SignatureScheme
+	clients   *cache.Cache // client key -> *rate.Limiter
+	wallets   *cache.Cache // signature -> wallet client ID
+	failures  *cache.Cache // IP, public key and timestamp -> struct{}
+
+	mu       sync.Mutex
+	rejected map[rejection]int64
+}
+
+// New quotas of given configurations, the configuration must be set up.
+func New(c *Config) *Quotas {
+	var q = &Quotas{
+		conf:     c,
+		apiKeys:  make(map[string]*APIKey, len(c.APIKeys)),
+		clients:  cache.New(clientsTTL, clientsTTL/6),
+		wallets:  cache.New(c.SignatureTTL, c.SignatureTTL),
+		failures: cache.New(c.SignatureTTL, c.SignatureTTL),
+		rejected: make(map[rejection]int64),
+	}
+	for i := range c.APIKeys {
+		q.apiKeys[c.APIKeys[i].Key] = &c.APIKeys[i]
+	}
+	for _, e := range c.Endpoints {
+		var ep = &endpoint{path: e.Path, cost: e.Cost}
+		if e.Rate > 0 {
+			ep.limiter = rate.NewLimiter(rate.Limit(e.Rate), e.Burst)
+		}
+		q.endpoints = append(q.endpoints, ep)
+	}
+	sort.SliceStable(q.endpoints, func(i, j int) bool {
+		return len(q.endpoints[i].path) > len(q.endpoints[j].path)
+	})
+	if c.SignatureScheme != "" {
+		q.scheme = func() encryption.SignatureScheme {
+			return encryption.GetSignatureScheme(c.SignatureScheme)
+		}
+	}
+	return q
+}
+
+// Take the request from the quotas. A request over the quotas is rejected
+// and can be retried after returned duration.
+func (q *Quotas) Take(r *http.Request) (retryAfter time.Duration, ok bool) {
+	var (
+		now  = time.Now()
+		ep   = q.endpoint(r.URL.Path)
+		cl   = q.client(r, now)
+		cost = 1
+		path = "*"
+		ress []*rate.Reservation
+	)
+	if ep != nil {
+		cost, path = ep.cost, ep.path
+	}
+
+	// all the limits must allow the request, the tokens are returned to
+	// the buckets if any rejects it
+	var reserve = func(lim *rate.Limiter, limit string) bool {
+		var res = lim.ReserveN(now, cost)
+		if !res.OK() {
+			retryAfter = time.Duration(float64(time.Second) *
+				float64(cost) / float64(lim.Limit()))
+		} else if delay := res.DelayFrom(now); delay > 0 {
+			res.CancelAt(now)
+			retryAfter = delay
+		} else {
+			ress = append(ress, res)
+			return true
+		}
+		for _, res := range ress {
+			res.CancelAt(now)
+		}
+		q.reject(rejection{endpoint: path, client: cl.label, limit: limit})
+		return false
+	}
+
+	if cl.limit.Rate > 0 &&
+		!reserve(q.limiter(cl.key, cl.limit), ClientLimit) {
+		return
+	}
+	if cl.ip && q.conf.IP.Rate > 0 &&
+		!reserve(q.limiter("all:"+remoteIP(r), q.conf.IP), IPLimit) {
+		return
+	}
+	if ep != nil && ep.limiter != nil && !reserve(ep.limiter, EndpointLimit) {
+		return
+	}
+	return 0, true
+}
+
+// endpoint of the path, nil if there are no quotas of the path
+func (q *Quotas) endpoint(path string) *endpoint {
+	for _, ep := range q.endpoints {
+		if strings.HasPrefix(path, ep.path) {
+			return ep
+		}
+	}
+	return nil
+}
+
+// client of the request, a request with unknown API key or invalid
+// signature is identified by its IP
+func (q *Quotas) client(r *http.Request, now time.Time) *client {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if ak, ok := q.apiKeys[key]; ok {
+			return &client{key: "key:" + ak.Key, label: "api_key:" + ak.Name,
+				limit: ak.Limit}
+		}
+	}
+	if id, ok := q.wallet(r, now); ok {
+		return &client{key: "wallet:" + id, label: "wallet",
+			limit: q.conf.Client, ip: true}
+	}
+	return &client{key: "ip:" + remoteIP(r), label: "ip",
+		limit: q.conf.Client, ip: true}
+}
+
+// wallet returns ID of the wallet signing the request
+func (q *Quotas) wallet(r *http.Request, now time.Time) (id string, ok bool) {
+	var (
+		publicKey = r.Header.Get(ClientKeyHeader)
+		ts        = r.Header.Get(ClientTimestampHeader)
+		sign      = r.Header.Get(ClientSignatureHeader)
+	)
+	if q.scheme == nil || publicKey == "" || ts == "" || sign == "" {
+		return
+	}
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return
+	}
+	if age := now.Sub(time.Unix(secs, 0)); age > q.conf.SignatureTTL ||
+		age < -q.conf.SignatureTTL {
+		return
+	}
+	var cacheKey = publicKey + ":" + ts + ":" + sign + ":" + r.URL.Path
+	if cached, found := q.wallets.Get(cacheKey); found {
+		return cached.(string), true
+	}
+
+	// the failures are cached per IP, thus, a client can't make signatures
+	// of other IPs fail
+	var ip = remoteIP(r)
+	var failureKey = ip + ":" + publicKey + ":" + ts + ":" + r.URL.Path
+	if _, failed := q.failures.Get(failureKey); failed {
+		return
+	}
+	// the verification is expensive, it's paid by the IP bucket
+	if q.conf.IP.Rate > 0 &&
+		!q.limiter("all:"+ip, q.conf.IP).AllowN(now, 1) {
+		return
+	}
+
+	var msg = SignedMessage(ts, q.conf.NodeID, r.URL.Path)
+	if id, ok = q.verify(publicKey, msg, sign); !ok {
+		q.failures.SetDefault(failureKey, struct{}{})
+		return
+	}
+	q.wallets.SetDefault(cacheKey, id)
+	return id, true
+}
+
+// verify the signature of the message returning ID of the wallet
+func (q *Quotas) verify(publicKey, msg, sign string) (id string, ok bool) {
+	var scheme = q.scheme()
+	if err := scheme.SetPublicKey(publicKey); err != nil {
+		return
+	}
+	if ok, err := scheme.Verify(sign, encryption.Hash(msg)); err != nil || !ok {
+		return "", false
+	}
+	keyBytes, err := hex.DecodeString(publicKey)
+	if err != nil {
+		return
+	}
+	return encryption.Hash(keyBytes), true
+}
+
+// limiter returns token bucket of the client, creating it if missing
+func (q *Quotas) limiter(key string, limit Limit) *rate.Limiter {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if lim, ok := q.clients.Get(key); ok {
+		return lim.(*rate.Limiter)
+	}
+	var lim = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+	q.clients.SetDefault(key, lim)
+	return lim
+}
+
+func (q *Quotas) reject(key rejection) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rejected[key]++
+}
+
+// WriteMetrics writes the counters of the rejected requests.
+func (q *Quotas) WriteMetrics(pw *metric.PrometheusWriter) {
+	q.mu.Lock()
+	var samples = make([]metric.Sample, 0, len(q.rejected))
+	for key, count := range q.rejected {
+		samples = append(samples, metric.Sample{
+			Labels: metric.Labels{
+				"endpoint": key.endpoint,
+				"client":   key.client,
+				"limit":    key.limit,
+			},
+			Value: float64(count),
+		})
+	}
+	q.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		var a, b = samples[i].Labels, samples[j].Labels
+		if a["endpoint"] != b["endpoint"] {
+			return a["endpoint"] < b["endpoint"]
+		}
+		if a["client"] != b["client"] {
+			return a["client"] < b["client"]
+		}
+		return a["limit"] < b["limit"]
+	})
+	pw.CounterVec("quota_rejected_requests_total",
+		"Requests rejected by the quotas.", samples)
+}
+
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,271 @@
+package quota
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/core/encryption"
+	"0chain.net/core/metric"
+	"0chain.net/core/viper"
+)
+
+func newTestQuotas(t *testing.T, c *Config) *Quotas {
+	t.Helper()
+	require.NoError(t, c.setDefaults())
+	return New(c)
+}
+
+func newRequest(path, ip string, header ...string) *http.Request {
+	var r = httptest.NewRequest(http.MethodGet, path, nil)
+	r.RemoteAddr = ip + ":1234"
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	return r
+}
+
+func TestQuotas_Take_client(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client: Limit{Rate: 1, Burst: 2},
+	})
+
+	for i := 0; i < 2; i++ {
+		_, ok := q.Take(newRequest("/v1/block/get", "10.0.0.1"))
+		require.True(t, ok)
+	}
+	retryAfter, ok := q.Take(newRequest("/v1/block/get", "10.0.0.1"))
+	require.False(t, ok)
+	assert.InDelta(t, time.Second, retryAfter, float64(100*time.Millisecond))
+
+	_, ok = q.Take(newRequest("/v1/block/get", "10.0.0.2"))
+	assert.True(t, ok, "other client")
+}
+
+func TestQuotas_Take_cost(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client: Limit{Rate: 1, Burst: 1},
+		Endpoints: []Endpoint{
+			{Path: "/v1/screst/", Cost: 5},
+			{Path: "/v1/screst/cheap", Cost: 1},
+		},
+	})
+	assert.Equal(t, 5, q.conf.Client.Burst, "burst fits the max cost")
+
+	_, ok := q.Take(newRequest("/v1/screst/sc/getConfig", "10.0.0.1"))
+	require.True(t, ok)
+	retryAfter, ok := q.Take(newRequest("/v1/screst/cheap", "10.0.0.1"))
+	require.False(t, ok)
+	assert.InDelta(t, time.Second, retryAfter, float64(100*time.Millisecond))
+}
+
+func TestQuotas_Take_endpoint(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client: Limit{Rate: 10, Burst: 10},
+		Endpoints: []Endpoint{
+			{Path: "/_diagnostics/state_dump", Cost: 1,
+				Limit: Limit{Rate: 1, Burst: 1}},
+		},
+	})
+
+	_, ok := q.Take(newRequest("/_diagnostics/state_dump", "10.0.0.1"))
+	require.True(t, ok)
+	_, ok = q.Take(newRequest("/_diagnostics/state_dump", "10.0.0.2"))
+	require.False(t, ok, "endpoint limit is shared by the clients")
+
+	// the rejected request doesn't take the client tokens
+	for i := 0; i < 10; i++ {
+		_, ok = q.Take(newRequest("/v1/block/get", "10.0.0.2"))
+		require.True(t, ok)
+	}
+
+	var buf bytes.Buffer
+	q.WriteMetrics(metric.NewPrometheusWriter(&buf))
+	assert.Equal(t, "# HELP quota_rejected_requests_total Requests rejected by the quotas.\n"+
+		"# TYPE quota_rejected_requests_total counter\n"+
+		`quota_rejected_requests_total{client="ip",endpoint="/_diagnostics/state_dump",limit="endpoint"} 1`+"\n",
+		buf.String())
+}
+
+func TestQuotas_Take_apiKey(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client:  Limit{Rate: 1, Burst: 1},
+		IP:      Limit{Rate: 1, Burst: 1},
+		APIKeys: []APIKey{{Key: "secret", Limit: Limit{Rate: 1, Burst: 3}}},
+	})
+
+	for i := 0; i < 3; i++ {
+		_, ok := q.Take(newRequest("/", "10.0.0.1", APIKeyHeader, "secret"))
+		require.True(t, ok, "API key is not limited by IP")
+	}
+	_, ok := q.Take(newRequest("/", "10.0.0.1", APIKeyHeader, "secret"))
+	require.False(t, ok)
+
+	_, ok = q.Take(newRequest("/", "10.0.0.1", APIKeyHeader, "unknown"))
+	require.True(t, ok, "unknown API key is limited by IP")
+	_, ok = q.Take(newRequest("/", "10.0.0.1", APIKeyHeader, "unknown"))
+	require.False(t, ok)
+}
+
+func TestQuotas_Take_wallet(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client:          Limit{Rate: 1, Burst: 1},
+		IP:              Limit{Rate: 1, Burst: 7},
+		SignatureScheme: "ed25519",
+		NodeID:          "node",
+	})
+
+	var signed = func(t *testing.T) []string {
+		var scheme = encryption.NewED25519Scheme()
+		require.NoError(t, scheme.GenerateKeys())
+		var ts = strconv.FormatInt(time.Now().Unix(), 10)
+		sign, err := scheme.Sign(encryption.Hash(SignedMessage(ts, "node", "/")))
+		require.NoError(t, err)
+		return []string{ClientKeyHeader, scheme.GetPublicKey(),
+			ClientTimestampHeader, ts, ClientSignatureHeader, sign}
+	}
+
+	// wallets behind the same IP have their own buckets
+	var w1, w2 = signed(t), signed(t)
+	_, ok := q.Take(newRequest("/", "10.0.0.1", w1...))
+	require.True(t, ok)
+	_, ok = q.Take(newRequest("/", "10.0.0.1", w1...))
+	require.False(t, ok)
+	_, ok = q.Take(newRequest("/", "10.0.0.1", w2...))
+	require.True(t, ok)
+
+	// invalid signature is limited as the IP
+	var invalid = signed(t)
+	invalid[5] = w1[5]
+	_, ok = q.Take(newRequest("/", "10.0.0.1", invalid...))
+	require.True(t, ok)
+	_, ok = q.Take(newRequest("/", "10.0.0.1", invalid...))
+	require.False(t, ok)
+
+	// the signature is bound to the path and the node
+	var now = time.Now()
+	_, ok = q.wallet(newRequest("/", "10.0.0.1", w2...), now)
+	require.True(t, ok)
+	_, ok = q.wallet(newRequest("/v1/other", "10.0.0.1", w2...), now)
+	require.False(t, ok, "replayed to other path")
+	var other = newTestQuotas(t, &Config{SignatureScheme: "ed25519",
+		NodeID: "other"})
+	_, ok = other.wallet(newRequest("/", "10.0.0.1", w2...), now)
+	require.False(t, ok, "replayed to other node")
+
+	// wallets made up by a client share the IP bucket, the verifications
+	// and the requests above took all the 7 tokens
+	_, ok = q.Take(newRequest("/", "10.0.0.1", signed(t)...))
+	require.False(t, ok)
+	_, ok = q.Take(newRequest("/", "10.0.0.2", signed(t)...))
+	require.True(t, ok)
+}
+
+func TestQuotas_Take_walletIP(t *testing.T) {
+	var q = newTestQuotas(t, &Config{
+		Client:          Limit{Rate: 1, Burst: 2},
+		IP:              Limit{Rate: 1, Burst: 6},
+		SignatureScheme: "ed25519",
+	})
+	var verified int
+	q.scheme = func() encryption.SignatureScheme {
+		verified++
+		return encryption.NewED25519Scheme()
+	}
+
+	var ts = strconv.FormatInt(time.Now().Unix(), 10)
+	var wallets [][]string
+	for i := 0; i < 3; i++ {
+		var scheme = encryption.NewED25519Scheme()
+		require.NoError(t, scheme.GenerateKeys())
+		sign, err := scheme.Sign(encryption.Hash(SignedMessage(ts, "", "/")))
+		require.NoError(t, err)
+		wallets = append(wallets, []string{ClientKeyHeader,
+			scheme.GetPublicKey(), ClientTimestampHeader, ts,
+			ClientSignatureHeader, sign})
+	}
+
+	// the verifications and the requests take the IP tokens
+	for _, w := range wallets {
+		_, ok := q.Take(newRequest("/", "10.0.0.1", w...))
+		require.True(t, ok)
+	}
+	require.Equal(t, 3, verified)
+	_, ok := q.Take(newRequest("/", "10.0.0.1"))
+	require.False(t, ok, "the IP bucket is empty")
+
+	// verified signature is cached, but the request is limited by the IP
+	_, ok = q.Take(newRequest("/", "10.0.0.1", wallets[0]...))
+	require.False(t, ok)
+	require.Equal(t, 3, verified)
+
+	// failed verification is cached per IP
+	var invalid = append([]string{}, wallets[1]...)
+	invalid[5] = wallets[2][5]
+	_, ok = q.Take(newRequest("/", "10.0.0.2", invalid...))
+	require.True(t, ok, "limited as the IP")
+	require.Equal(t, 4, verified)
+	q.Take(newRequest("/", "10.0.0.2", invalid...))
+	require.Equal(t, 4, verified, "failure is cached")
+	invalid[1] = wallets[0][1]
+	_, ok = q.Take(newRequest("/", "10.0.0.3", invalid...))
+	require.True(t, ok)
+	require.Equal(t, 5, verified)
+}
+
+func TestReadConfig(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "0chain.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+server_chain:
+  client:
+    signature_scheme: ed25519
+network:
+  user_handlers:
+    quota:
+      enabled: true
+      client:
+        rate: 10
+      ip:
+        rate: 50
+        burst: 100
+      signature_ttl: 1m
+      api_keys:
+        - key: "Secret"
+          name: explorer
+          rate: 100
+      endpoints:
+        - path: /v1/screst/
+          cost: 5
+`), 0644))
+	require.NoError(t, viper.ReadConfigFile(path))
+	require.NoError(t, ReadConfig())
+	defer Setup(&Config{})
+
+	var q = Get()
+	require.NotNil(t, q)
+	assert.Equal(t, &Config{
+		Enabled:         true,
+		Client:          Limit{Rate: 10, Burst: 10},
+		IP:              Limit{Rate: 50, Burst: 100},
+		SignatureTTL:    time.Minute,
+		SignatureScheme: "ed25519",
+		APIKeys: []APIKey{
+			{Key: "Secret", Name: "explorer", Limit: Limit{Rate: 100, Burst: 100}},
+		},
+		Endpoints: []Endpoint{
+			{Path: "/v1/screst/", Cost: 5, Limit: Limit{Burst: 5}},
+		},
+	}, q.conf)
+
+	require.NoError(t, Setup(&Config{}))
+	assert.Nil(t, Get())
+	assert.Error(t, Setup(&Config{Endpoints: []Endpoint{{Cost: 1}}}))
+}
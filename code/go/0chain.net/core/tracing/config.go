@@ -0,0 +1,234 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/core/logging"
+	"0chain.net/core/viper"
+)
+
+// Config of the tracing.
+type Config struct {
+	// Enabled turns the tracing on.
+	Enabled bool
+	// ChainID is used to derive trace IDs of rounds.
+	ChainID string
+	// Service name, 'miner' or 'sharder'.
+	Service string
+	// Instance ID, the node ID.
+	Instance string
+	// File of the local file exporter, disabled if empty.
+	File string
+	// OTLPEndpoint of OpenTelemetry collector, for example
+	// 'http://localhost:4318/v1/traces', disabled if empty.
+	OTLPEndpoint string
+	// BatchSize is max number of spans exported at once.
+	BatchSize int
+	// QueueSize is max number of finished spans waiting for export, spans
+	// are dropped when the queue is full.
+	QueueSize int
+	// FlushInterval of not full batches.
+	FlushInterval time.Duration
+}
+
+var (
+	enabled int32 // atomic
+	conf    atomic.Value
+
+	procMutex sync.Mutex
+	proc      *processor
+)
+
+func init() {
+	conf.Store(&Config{})
+}
+
+func getConfig() *Config {
+	return conf.Load().(*Config)
+}
+
+// Enabled returns true if the tracing is turned on.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// ReadConfig reads the 'tracing' section of the configurations and sets up
+// the tracing of given service and node ID.
+func ReadConfig(ctx context.Context, service, instance string) error {
+	return Setup(ctx, &Config{
+		Enabled:       viper.GetBool("tracing.enabled"),
+		ChainID:       viper.GetString("server_chain.id"),
+		Service:       service,
+		Instance:      instance,
+		File:          viper.GetString("tracing.file"),
+		OTLPEndpoint:  viper.GetString("tracing.otlp_endpoint"),
+		BatchSize:     viper.GetInt("tracing.batch_size"),
+		QueueSize:     viper.GetInt("tracing.queue_size"),
+		FlushInterval: viper.GetDuration("tracing.flush_interval"),
+	})
+}
+
+// Setup the tracing. The exporters are flushed and shut down when given
+// context is done. Previous setup, if any, is replaced.
+func Setup(ctx context.Context, c *Config) (err error) {
+	if c.BatchSize <= 0 {
+		c.BatchSize = 512
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 4096
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 5 * time.Second
+	}
+
+	procMutex.Lock()
+	defer procMutex.Unlock()
+
+	if proc != nil {
+		proc.stop()
+		proc = nil
+	}
+	atomic.StoreInt32(&enabled, 0)
+	conf.Store(c)
+
+	if !c.Enabled {
+		return
+	}
+
+	var exporters []Exporter
+	if c.File != "" {
+		var fe *FileExporter
+		if fe, err = NewFileExporter(c.File, c); err != nil {
+			return
+		}
+		exporters = append(exporters, fe)
+	}
+	if c.OTLPEndpoint != "" {
+		exporters = append(exporters, NewOTLPExporter(c.OTLPEndpoint, c))
+	}
+
+	proc = newProcessor(c, exporters)
+	go proc.run(ctx)
+	atomic.StoreInt32(&enabled, 1)
+	return
+}
+
+// export finished span
+func export(s *Span) {
+	procMutex.Lock()
+	var p = proc
+	procMutex.Unlock()
+	if p != nil {
+		p.enqueue(s.snapshot())
+	}
+}
+
+// processor exports finished spans by batches
+type processor struct {
+	queue     chan *Span
+	quit      chan struct{}
+	done      chan struct{}
+	exporters []Exporter
+	batchSize int
+	interval  time.Duration
+	dropped   int64 // atomic
+}
+
+func newProcessor(c *Config, exporters []Exporter) *processor {
+	return &processor{
+		queue:     make(chan *Span, c.QueueSize),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+		exporters: exporters,
+		batchSize: c.BatchSize,
+		interval:  c.FlushInterval,
+	}
+}
+
+func (p *processor) enqueue(s *Span) {
+	select {
+	case p.queue <- s:
+	default:
+		atomic.AddInt64(&p.dropped, 1)
+	}
+}
+
+// stop the processor and wait for the last export
+func (p *processor) stop() {
+	close(p.quit)
+	<-p.done
+}
+
+func (p *processor) run(ctx context.Context) {
+	defer close(p.done)
+
+	var (
+		tick  = time.NewTicker(p.interval)
+		batch = make([]*Span, 0, p.batchSize)
+	)
+	defer tick.Stop()
+
+	var flush = func() {
+		if dropped := atomic.SwapInt64(&p.dropped, 0); dropped > 0 {
+			logError("tracing - spans dropped, queue is full", zap.Int64("dropped", dropped))
+		}
+		if len(batch) == 0 {
+			return
+		}
+		for _, e := range p.exporters {
+			var ectx, cancel = context.WithTimeout(context.Background(),
+				p.interval)
+			if err := e.ExportSpans(ectx, batch); err != nil {
+				logError("tracing - exporting spans", zap.Error(err))
+			}
+			cancel()
+		}
+		batch = batch[:0]
+	}
+
+	var shutdown = func() {
+		for {
+			select {
+			case s := <-p.queue:
+				batch = append(batch, s)
+				continue
+			default:
+			}
+			break
+		}
+		flush()
+		for _, e := range p.exporters {
+			if err := e.Shutdown(); err != nil {
+				logError("tracing - shutting down exporter", zap.Error(err))
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdown()
+			return
+		case <-p.quit:
+			shutdown()
+			return
+		case s := <-p.queue:
+			if batch = append(batch, s); len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-tick.C:
+			flush()
+		}
+	}
+}
+
+func logError(msg string, fields ...zap.Field) {
+	if logging.Logger != nil {
+		logging.Logger.Error(msg, fields...)
+	}
+}
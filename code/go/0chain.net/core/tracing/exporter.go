@@ -0,0 +1,278 @@
+package tracing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// An Exporter exports finished spans.
+type Exporter interface {
+	// ExportSpans exports batch of the spans.
+	ExportSpans(ctx context.Context, spans []*Span) error
+	// Shutdown releases resources of the exporter.
+	Shutdown() error
+}
+
+// FileExporter writes spans to a local file, a JSON object per line.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	conf *Config
+}
+
+// NewFileExporter opens given file to append the spans.
+func NewFileExporter(path string, conf *Config) (*FileExporter, error) {
+	var file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file, w: bufio.NewWriter(file), conf: conf}, nil
+}
+
+type fileSpan struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_span_id,omitempty"`
+	Name       string                 `json:"name"`
+	Service    string                 `json:"service"`
+	Instance   string                 `json:"instance,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	DurationMs float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// ExportSpans writes the spans to the file.
+func (fe *FileExporter) ExportSpans(_ context.Context, spans []*Span) (
+	err error) {
+
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+
+	var enc = json.NewEncoder(fe.w)
+	for _, s := range spans {
+		var fs = fileSpan{
+			TraceID:    s.Context.TraceID.String(),
+			SpanID:     s.Context.SpanID.String(),
+			Name:       s.Name,
+			Service:    fe.conf.Service,
+			Instance:   fe.conf.Instance,
+			Start:      s.Start,
+			End:        s.End,
+			DurationMs: float64(s.End.Sub(s.Start)) / float64(time.Millisecond),
+			Attributes: s.Attributes,
+			Error:      s.Error,
+		}
+		if !s.Parent.IsZero() {
+			fs.ParentID = s.Parent.String()
+		}
+		if err = enc.Encode(&fs); err != nil {
+			return
+		}
+	}
+	return fe.w.Flush()
+}
+
+// Shutdown closes the file.
+func (fe *FileExporter) Shutdown() (err error) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	if err = fe.w.Flush(); err != nil {
+		fe.file.Close()
+		return
+	}
+	return fe.file.Close()
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector by the OTLP/HTTP
+// protocol with JSON encoding.
+type OTLPExporter struct {
+	endpoint string
+	client   *http.Client
+	conf     *Config
+}
+
+// NewOTLPExporter creates exporter to given collector endpoint, for
+// example 'http://localhost:4318/v1/traces'.
+func NewOTLPExporter(endpoint string, conf *Config) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		conf:     conf,
+	}
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// OTLP span kind and status codes.
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+func otlpAttr(key string, val interface{}) (a otlpAttribute) {
+	a.Key = key
+	switch tt := val.(type) {
+	case string:
+		a.Value.StringValue = &tt
+	case int:
+		var s = strconv.Itoa(tt)
+		a.Value.IntValue = &s
+	case int64:
+		var s = strconv.FormatInt(tt, 10)
+		a.Value.IntValue = &s
+	case float64:
+		a.Value.DoubleValue = &tt
+	case bool:
+		a.Value.BoolValue = &tt
+	default:
+		var s = fmt.Sprint(tt)
+		a.Value.StringValue = &s
+	}
+	return
+}
+
+func otlpAttrs(attrs map[string]interface{}) (list []otlpAttribute) {
+	var keys = make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		list = append(list, otlpAttr(k, attrs[k]))
+	}
+	return
+}
+
+func (oe *OTLPExporter) request(spans []*Span) *otlpRequest {
+	var rs otlpResourceSpans
+	rs.Resource.Attributes = append(rs.Resource.Attributes,
+		otlpAttr("service.name", oe.conf.Service))
+	if oe.conf.Instance != "" {
+		rs.Resource.Attributes = append(rs.Resource.Attributes,
+			otlpAttr("service.instance.id", oe.conf.Instance))
+	}
+
+	var ss = otlpScopeSpans{Scope: otlpScope{Name: "0chain.net/core/tracing"}}
+	for _, s := range spans {
+		var span = otlpSpan{
+			TraceID:           s.Context.TraceID.String(),
+			SpanID:            s.Context.SpanID.String(),
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttrs(s.Attributes),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+		if !s.Parent.IsZero() {
+			span.ParentSpanID = s.Parent.String()
+		}
+		if s.Error != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.Error}
+		}
+		ss.Spans = append(ss.Spans, span)
+	}
+	rs.ScopeSpans = []otlpScopeSpans{ss}
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{rs}}
+}
+
+// ExportSpans sends the spans to the collector.
+func (oe *OTLPExporter) ExportSpans(ctx context.Context, spans []*Span) (
+	err error) {
+
+	var body []byte
+	if body, err = json.Marshal(oe.request(spans)); err != nil {
+		return
+	}
+	var req *http.Request
+	req, err = http.NewRequest(http.MethodPost, oe.endpoint,
+		bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp *http.Response
+	if resp, err = oe.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var msg, _ = ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OTLP collector: %s: %s", resp.Status,
+			bytes.TrimSpace(msg))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return
+}
+
+// Shutdown of the OTLPExporter does nothing.
+func (oe *OTLPExporter) Shutdown() error {
+	return nil
+}
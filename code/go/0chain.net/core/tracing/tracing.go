@@ -0,0 +1,335 @@
+// Package tracing traces lifecycle of blocks across miners and sharders.
+//
+// Trace ID of a span is derived from the chain ID and the round, thus spans
+// of all nodes of a round share the same trace without coordination. The
+// trace context is propagated in the n2n 'traceparent' header of the W3C
+// Trace Context format, linking a span of a receiver to the latest span of
+// the round of the sender.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderTraceParent is the W3C Trace Context header.
+const HeaderTraceParent = "traceparent"
+
+// A Rounder is an entity of a round, such as a block or a VRF share.
+type Rounder interface {
+	GetRoundNumber() int64
+}
+
+// TraceID of a trace.
+type TraceID [16]byte
+
+// String returns hex representation of the ID.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID of a span.
+type SpanID [8]byte
+
+// String returns hex representation of the ID.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsZero returns true for the zero ID.
+func (id SpanID) IsZero() bool {
+	return id == SpanID{}
+}
+
+// SpanContext identifies a span across nodes.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid returns true if the SpanContext refers to a span.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && !sc.SpanID.IsZero()
+}
+
+// TraceParent returns the 'traceparent' header value of the SpanContext.
+func (sc SpanContext) TraceParent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"
+}
+
+// ParseTraceParent parses the 'traceparent' header value.
+func ParseTraceParent(val string) (sc SpanContext, err error) {
+	var parts = strings.Split(strings.TrimSpace(val), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[3]) != 2 {
+		return sc, fmt.Errorf("invalid traceparent %q", val)
+	}
+	if parts[0] == "ff" {
+		return sc, fmt.Errorf("invalid traceparent version %q", parts[0])
+	}
+	if err = decodeID(sc.TraceID[:], parts[1]); err != nil {
+		return
+	}
+	if err = decodeID(sc.SpanID[:], parts[2]); err != nil {
+		return
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("zero IDs in traceparent %q", val)
+	}
+	return
+}
+
+func decodeID(dst []byte, s string) error {
+	if hex.DecodedLen(len(s)) != len(dst) {
+		return fmt.Errorf("invalid ID length: %q", s)
+	}
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return fmt.Errorf("invalid ID %q: %v", s, err)
+	}
+	return nil
+}
+
+// RoundTraceID returns trace ID of given round.
+func RoundTraceID(round int64) (id TraceID) {
+	var sum = sha256.Sum256([]byte(getConfig().ChainID + ":" +
+		strconv.FormatInt(round, 10)))
+	copy(id[:], sum[:])
+	return
+}
+
+func newSpanID() (id SpanID) {
+	for id.IsZero() {
+		if _, err := rand.Read(id[:]); err != nil {
+			panic("tracing: reading random span ID: " + err.Error())
+		}
+	}
+	return
+}
+
+// A Span is a timed operation of a round. All the methods are safe for
+// a nil Span, that is returned when the tracing is disabled.
+type Span struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanID
+	Round      int64
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]interface{}
+	Error      string
+
+	mu    sync.Mutex
+	ended bool
+}
+
+// SetAttribute of the span.
+func (s *Span) SetAttribute(key string, val interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = val
+}
+
+// SetError marks the span failed, a nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Error = err.Error()
+}
+
+// Finish the span and export it. Following calls are ignored.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+	export(s)
+}
+
+// snapshot returns copy of the span safe to use concurrently.
+func (s *Span) snapshot() *Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var cp = &Span{
+		Name:       s.Name,
+		Context:    s.Context,
+		Parent:     s.Parent,
+		Round:      s.Round,
+		Start:      s.Start,
+		End:        s.End,
+		Attributes: make(map[string]interface{}, len(s.Attributes)),
+		Error:      s.Error,
+	}
+	for k, v := range s.Attributes {
+		cp.Attributes[k] = v
+	}
+	return cp
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+)
+
+// FromContext returns span of the context, if any.
+func FromContext(ctx context.Context) *Span {
+	var s, _ = ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// WithRemoteParent returns context with span context received from
+// another node. The span context is also kept as the remote parent of its
+// trace for spans started without the context.
+func WithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	if !Enabled() || !sc.IsValid() {
+		return ctx
+	}
+	spans.setRemote(sc)
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// StartSpan starts span of given round and block hash, the hash can be
+// empty. The parent of the span is a span of the round of the context,
+// or the remote span of the context, or the latest span of the round
+// started by this node, or the latest span of the round received from
+// another node, in this order. The span should be finished by the Finish.
+func StartSpan(ctx context.Context, name string, round int64,
+	hash string) (context.Context, *Span) {
+
+	if !Enabled() {
+		return ctx, nil
+	}
+
+	var (
+		tid = RoundTraceID(round)
+		s   = &Span{
+			Name:       name,
+			Context:    SpanContext{TraceID: tid, SpanID: newSpanID()},
+			Round:      round,
+			Start:      time.Now(),
+			Attributes: map[string]interface{}{"round": round},
+		}
+	)
+	if hash != "" {
+		s.Attributes["block"] = hash
+	}
+
+	if p := FromContext(ctx); p != nil && p.Context.TraceID == tid {
+		s.Parent = p.Context.SpanID
+	} else if rc, ok := ctx.Value(remoteKey).(SpanContext); ok &&
+		rc.TraceID == tid {
+		s.Parent = rc.SpanID
+	} else if pc, ok := spans.parent(tid); ok {
+		s.Parent = pc.SpanID
+	}
+
+	spans.setLocal(s.Context)
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// Inject sets the 'traceparent' header of given round: the latest span of
+// the round started by this node or received from another node.
+func Inject(h http.Header, round int64) {
+	if !Enabled() {
+		return
+	}
+	if sc, ok := spans.parent(RoundTraceID(round)); ok {
+		h.Set(HeaderTraceParent, sc.TraceParent())
+	}
+}
+
+// Extract the 'traceparent' header.
+func Extract(h http.Header) (sc SpanContext, ok bool) {
+	var val = h.Get(HeaderTraceParent)
+	if val == "" {
+		return
+	}
+	var err error
+	if sc, err = ParseTraceParent(val); err != nil {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
+
+// maxTraces is number of the latest traces kept to find parent spans.
+const maxTraces = 256
+
+type traceParents struct {
+	local, remote SpanContext
+}
+
+// spanRegistry keeps the latest local and remote span contexts of the
+// latest traces.
+type spanRegistry struct {
+	mu     sync.Mutex
+	traces map[TraceID]*traceParents
+	order  []TraceID // FIFO of the traces
+}
+
+var spans = newSpanRegistry()
+
+func newSpanRegistry() *spanRegistry {
+	return &spanRegistry{traces: make(map[TraceID]*traceParents)}
+}
+
+func (sr *spanRegistry) get(tid TraceID) *traceParents {
+	if tp, ok := sr.traces[tid]; ok {
+		return tp
+	}
+	if len(sr.order) >= maxTraces {
+		delete(sr.traces, sr.order[0])
+		sr.order = sr.order[1:]
+	}
+	var tp = new(traceParents)
+	sr.traces[tid] = tp
+	sr.order = append(sr.order, tid)
+	return tp
+}
+
+func (sr *spanRegistry) setLocal(sc SpanContext) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.get(sc.TraceID).local = sc
+}
+
+func (sr *spanRegistry) setRemote(sc SpanContext) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.get(sc.TraceID).remote = sc
+}
+
+// parent returns the latest local span context of the trace, or the remote
+// one if there are no local spans.
+func (sr *spanRegistry) parent(tid TraceID) (sc SpanContext, ok bool) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	var tp, found = sr.traces[tid]
+	if !found {
+		return
+	}
+	if tp.local.IsValid() {
+		return tp.local, true
+	}
+	return tp.remote, tp.remote.IsValid()
+}
@@ -0,0 +1,185 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest(t *testing.T, c *Config) {
+	t.Helper()
+	c.Enabled = true
+	c.ChainID = "chain"
+	c.Service = "miner"
+	c.Instance = "node-1"
+	c.FlushInterval = time.Hour // flushed on shutdown
+	require.NoError(t, Setup(context.Background(), c))
+	spans = newSpanRegistry()
+	t.Cleanup(func() {
+		require.NoError(t, Setup(context.Background(), &Config{}))
+	})
+}
+
+func TestParseTraceParent(t *testing.T) {
+	var sc = SpanContext{TraceID: RoundTraceID(10), SpanID: newSpanID()}
+	var got, err = ParseTraceParent(sc.TraceParent())
+	require.NoError(t, err)
+	assert.Equal(t, sc, got)
+
+	for _, val := range []string{
+		"",
+		"00-abc-def-01",
+		"ff-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01",
+		"00-" + TraceID{}.String() + "-" + sc.SpanID.String() + "-01",
+		"00-" + sc.TraceID.String() + "-zzzzzzzzzzzzzzzz-01",
+	} {
+		_, err = ParseTraceParent(val)
+		assert.Error(t, err, val)
+	}
+}
+
+func TestStartSpan_disabled(t *testing.T) {
+	require.NoError(t, Setup(context.Background(), &Config{}))
+	var ctx, span = StartSpan(context.Background(), "block.verify", 1, "h")
+	assert.Nil(t, span)
+	assert.Nil(t, FromContext(ctx))
+	span.SetAttribute("k", "v") // nil safe
+	span.SetError(errors.New("err"))
+	span.Finish()
+
+	var h = http.Header{}
+	Inject(h, 1)
+	assert.Empty(t, h.Get(HeaderTraceParent))
+}
+
+func TestStartSpan_parents(t *testing.T) {
+	setupTest(t, &Config{})
+
+	// the first span of a round is a root
+	var ctx, root = StartSpan(context.Background(), "block.generate", 5, "")
+	assert.Equal(t, RoundTraceID(5), root.Context.TraceID)
+	assert.True(t, root.Parent.IsZero())
+
+	// child of a span of the context
+	var _, child = StartSpan(ctx, "block.verify", 5, "hash")
+	assert.Equal(t, root.Context.SpanID, child.Parent)
+	assert.Equal(t, "hash", child.Attributes["block"])
+
+	// other round of the context isn't a parent
+	var _, other = StartSpan(ctx, "block.finalize", 4, "")
+	assert.True(t, other.Parent.IsZero())
+
+	// without context the latest local span of the round is the parent
+	var _, latest = StartSpan(context.Background(), "block.notarize", 5, "")
+	assert.Equal(t, child.Context.SpanID, latest.Parent)
+
+	// remote span of the context
+	var remote = SpanContext{TraceID: RoundTraceID(6), SpanID: newSpanID()}
+	var rctx = WithRemoteParent(context.Background(), remote)
+	var _, received = StartSpan(rctx, "vrf_share.add", 6, "")
+	assert.Equal(t, remote.SpanID, received.Parent)
+}
+
+func TestInjectExtract(t *testing.T) {
+	setupTest(t, &Config{})
+
+	var h = http.Header{}
+	Inject(h, 7)
+	assert.Empty(t, h.Get(HeaderTraceParent), "no spans of the round")
+
+	// a remote span is propagated while there are no local spans
+	var remote = SpanContext{TraceID: RoundTraceID(7), SpanID: newSpanID()}
+	WithRemoteParent(context.Background(), remote)
+	Inject(h, 7)
+	var sc, ok = Extract(h)
+	require.True(t, ok)
+	assert.Equal(t, remote, sc)
+
+	var _, span = StartSpan(context.Background(), "block.generate", 7, "")
+	Inject(h, 7)
+	sc, ok = Extract(h)
+	require.True(t, ok)
+	assert.Equal(t, span.Context, sc)
+}
+
+func TestFileExporter(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "spans.jsonl")
+	setupTest(t, &Config{File: path})
+
+	var ctx, parent = StartSpan(context.Background(), "block.generate", 3, "")
+	var _, span = StartSpan(ctx, "state.compute", 3, "hash")
+	span.SetError(errors.New("missing node"))
+	span.Finish()
+	span.Finish() // exported once
+	parent.Finish()
+
+	// flush and close the file
+	require.NoError(t, Setup(context.Background(), &Config{}))
+
+	var file, err = os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var lines []fileSpan
+	for sc := bufio.NewScanner(file); sc.Scan(); {
+		var fs fileSpan
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &fs))
+		lines = append(lines, fs)
+	}
+	require.Len(t, lines, 2)
+	assert.Equal(t, "state.compute", lines[0].Name)
+	assert.Equal(t, "missing node", lines[0].Error)
+	assert.Equal(t, parent.Context.SpanID.String(), lines[0].ParentID)
+	assert.Equal(t, parent.Context.TraceID.String(), lines[0].TraceID)
+	assert.Equal(t, "miner", lines[0].Service)
+	assert.Equal(t, "block.generate", lines[1].Name)
+	assert.Empty(t, lines[1].ParentID)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var got = make(chan otlpRequest, 1)
+	var srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var req otlpRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			got <- req
+		}))
+	defer srv.Close()
+
+	setupTest(t, &Config{OTLPEndpoint: srv.URL + "/v1/traces"})
+
+	var _, span = StartSpan(context.Background(), "block.store", 9, "hash")
+	span.Finish()
+	require.NoError(t, Setup(context.Background(), &Config{}))
+
+	var req = <-got
+	require.Len(t, req.ResourceSpans, 1)
+	var rs = req.ResourceSpans[0]
+	require.Len(t, rs.Resource.Attributes, 2)
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	assert.Equal(t, "miner", *rs.Resource.Attributes[0].Value.StringValue)
+	require.Len(t, rs.ScopeSpans, 1)
+	require.Len(t, rs.ScopeSpans[0].Spans, 1)
+	var sp = rs.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "block.store", sp.Name)
+	assert.Equal(t, span.Context.TraceID.String(), sp.TraceID)
+	assert.Equal(t, span.Context.SpanID.String(), sp.SpanID)
+	assert.Equal(t, otlpStatusOK, sp.Status.Code)
+	require.Len(t, sp.Attributes, 2)
+	assert.Equal(t, "block", sp.Attributes[0].Key)
+	assert.Equal(t, "round", sp.Attributes[1].Key)
+	assert.Equal(t, "9", *sp.Attributes[1].Value.IntValue)
+}
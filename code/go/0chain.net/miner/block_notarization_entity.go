@@ -27,6 +27,11 @@ func (notarization *Notarization) GetKey() datastore.Key {
 	return datastore.ToKey(notarization.BlockID)
 }
 
+// GetRoundNumber returns round of the block.
+func (notarization *Notarization) GetRoundNumber() int64 {
+	return notarization.Round
+}
+
 /*NotarizationProvider - entity provider for block_notarization object */
 func NotarizationProvider() datastore.Entity {
 	notarization := &Notarization{}
@@ -361,7 +361,7 @@ func (mc *Chain) ChainStarted(ctx context.Context) bool {
 				return mc.isStarted()
 			}
 			timeoutCount++
-			timer = time.NewTimer(time.Millisecond * time.Duration(mc.RoundTimeoutSofttoMin))
+			timer = time.NewTimer(time.Millisecond * time.Duration(mc.GetRoundTimeoutSofttoMin()))
 		}
 	}
 	return false
@@ -12,6 +12,7 @@ import (
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/chaincore/node"
 	"0chain.net/core/common"
+	"0chain.net/core/openapi"
 
 	"0chain.net/chaincore/client"
 	"0chain.net/core/memorystore"
@@ -23,6 +24,12 @@ func SetupHandlers() {
 	http.HandleFunc("/_chain_stats", common.UserRateLimit(ChainStatsWriter))
 	http.HandleFunc("/_diagnostics/wallet_stats", common.UserRateLimit(GetWalletStats))
 	http.HandleFunc("/v1/miner/get/stats", common.UserRateLimit(common.ToJSONResponse(MinerStatsHandler)))
+
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/chain/get/stats", Summary: "chain statistics"},
+		&openapi.Endpoint{Path: "/v1/miner/get/stats", Summary: "miner statistics",
+			Response: ExplorerStats{}},
+	)
 }
 
 /*ChainStatsHandler - a handler to provide block statistics */
@@ -14,6 +14,7 @@ import (
 	"0chain.net/chaincore/state"
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
+	"0chain.net/core/journal"
 	"0chain.net/core/logging"
 	"0chain.net/core/memorystore"
 
@@ -226,8 +227,15 @@ func VerifyBlockHandler(ctx context.Context, entity datastore.Entity) (
 	if err = b.Validate(ctx); err != nil {
 		logging.Logger.Debug("verify block handler -- can't validate",
 			zap.Int64("round", b.Round), zap.Error(err))
+		// the round of an invalid block can't be trusted
+		journal.Record(&journal.Event{Type: journal.BlockReceived,
+			Round: mc.GetCurrentRound(), Block: b.Hash, Node: b.MinerID,
+			Error:   err.Error(),
+			Details: map[string]interface{}{"block_round": b.Round}})
 		return nil, err
 	}
+	journal.Record(&journal.Event{Type: journal.BlockReceived,
+		Round: b.Round, Block: b.Hash, Node: b.MinerID})
 
 	var msg = NewBlockMessage(MessageVerify, node.GetSender(ctx), nil, b)
 	mc.GetBlockMessageChannel() <- msg
@@ -23,14 +23,18 @@ import (
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/rpc"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/threshold/bls"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/build"
 	"0chain.net/core/common"
 	"0chain.net/core/ememorystore"
+	"0chain.net/core/journal"
 	"0chain.net/core/logging"
 	"0chain.net/core/memorystore"
+	"0chain.net/core/quota"
+	"0chain.net/core/tracing"
 	"0chain.net/core/viper"
 	"0chain.net/miner"
 	"0chain.net/smartcontract/setupsc"
@@ -180,6 +184,16 @@ func main() {
 	logging.Logger.Info("Chain info", zap.String("chain_id", config.GetServerChainID()), zap.String("mode", mode))
 	logging.Logger.Info("Self identity", zap.Any("set_index", node.Self.Underlying().SetIndex), zap.Any("id", node.Self.Underlying().GetKey()))
 
+	if err := tracing.ReadConfig(ctx, "miner", node.Self.Underlying().GetKey()); err != nil {
+		logging.Logger.Panic("tracing setup", zap.Error(err))
+	}
+	if err := journal.ReadConfig(ctx, mc.GetCurrentRound); err != nil {
+		logging.Logger.Panic("journal setup", zap.Error(err))
+	}
+	if err := rpc.ReadConfig(ctx, node.Self.Underlying().Port); err != nil {
+		logging.Logger.Panic("rpc gateway setup", zap.Error(err))
+	}
+
 	initIntegrationsTests(node.Self.Underlying().GetKey())
 	defer shutdownIntegrationTests()
 
@@ -202,6 +216,14 @@ func main() {
 	common.HandleShutdown(server)
 	memorystore.GetInfo()
 	common.ConfigRateLimits()
+	quota.SetNodeID(node.Self.Underlying().GetKey())
+	if err := quota.ReadConfig(); err != nil {
+		logging.Logger.Panic("quota setup", zap.Error(err))
+	}
+	config.RegisterReloadable(quota.ReadConfig, "network.user_handlers.quota")
+	if err := config.SetupReload(ctx); err != nil {
+		logging.Logger.Panic("config reload setup", zap.Error(err))
+	}
 	initN2NHandlers()
 
 	initWorkers(ctx)
@@ -15,6 +15,7 @@ import (
 
 	"0chain.net/core/common"
 	"0chain.net/core/encryption"
+	"0chain.net/core/tracing"
 
 	. "0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -224,6 +225,11 @@ func (mc *Chain) GetBlsShare(ctx context.Context, r *round.Round) (string, error
 // AddVRFShare - implement the interface for the RoundRandomBeacon protocol.
 func (mc *Chain) AddVRFShare(ctx context.Context, mr *Round, vrfs *round.VRFShare) bool {
 	var rn = mr.GetRoundNumber()
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "vrf_share.add", rn, "")
+	span.SetAttribute("sender", vrfs.GetParty().GetKey())
+	defer span.Finish()
+
 	Logger.Info("DKG AddVRFShare", zap.Int64("Round", rn), zap.Int("RoundTimeoutCount", mr.GetTimeoutCount()),
 		zap.Int("Sender", vrfs.GetParty().SetIndex), zap.Int("vrf_timeoutcount", vrfs.GetRoundTimeoutCount()),
 		zap.String("vrf_share", vrfs.Share))
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"0chain.net/core/logging"
+	"0chain.net/core/journal"
+	"0chain.net/core/tracing"
 	"0chain.net/core/memorystore"
 	metrics "github.com/rcrowley/go-metrics"
 
@@ -50,6 +52,10 @@ func (mc *Chain) addMyVRFShare(ctx context.Context, pr *Round, r *Round) {
 		return
 	}
 
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "vrf_share.create", rn, "")
+	defer span.Finish()
+
 	var dkg = mc.GetDKG(rn)
 	if dkg == nil {
 		logging.Logger.Error("add_my_vrf_share -- DKG is nil, my VRF share is not added",
@@ -306,6 +312,9 @@ func (mc *Chain) startRound(ctx context.Context, r *Round, seed int64) {
 	logging.Logger.Info("Starting a new round",
 		zap.Int64("round", r.GetRoundNumber()),
 		zap.Int64("random seed", r.GetRandomSeed()))
+	journal.Record(&journal.Event{Type: journal.RoundStarted,
+		Round: r.GetRoundNumber(),
+		Details: map[string]interface{}{"random_seed": seed}})
 	mc.startNewRound(ctx, r)
 }
 
@@ -411,6 +420,10 @@ func (mc *Chain) GenerateRoundBlock(ctx context.Context, r *Round) (*block.Block
 	defer func() { rbgTimer.UpdateSince(ts) }()
 
 	roundNumber := r.GetRoundNumber()
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.generate", roundNumber, "")
+	defer span.Finish()
+
 	pround := mc.GetRound(roundNumber - 1)
 	if pround == nil {
 		logging.Logger.Error("generate round block - no prior round", zap.Any("round", roundNumber-1))
@@ -452,6 +465,15 @@ func (mc *Chain) GenerateRoundBlock(ctx context.Context, r *Round) (*block.Block
 			"required MB missing or still not finalized")
 	}
 
+	if unknown := mc.UnknownActiveFeatures(rn); len(unknown) > 0 {
+		logging.Logger.Error("gen_block",
+			zap.String("err", "unknown protocol features are active"),
+			zap.Strings("features", unknown),
+			zap.Int64("round", rn))
+		return nil, common.NewErrorf("gen_block",
+			"unknown protocol features are active: %v", unknown)
+	}
+
 	b.LatestFinalizedMagicBlockHash = lfmbr.Hash
 	b.LatestFinalizedMagicBlockRound = lfmbr.Round
 
@@ -561,6 +583,8 @@ func (mc *Chain) GenerateRoundBlock(ctx context.Context, r *Round) (*block.Block
 		}
 
 		mc.AddRoundBlock(r, b)
+		span.SetAttribute("block", b.Hash)
+		span.SetAttribute("txns", len(b.Txns))
 		if generationTries > 1 {
 			logging.Logger.Info("generate block - multiple tries",
 				zap.Int64("round", b.Round), zap.Int("tries", generationTries))
@@ -577,6 +601,13 @@ func (mc *Chain) GenerateRoundBlock(ctx context.Context, r *Round) (*block.Block
 
 	mc.addToRoundVerification(ctx, r, b)
 	r.AddProposedBlock(b)
+	journal.Record(&journal.Event{Type: journal.BlockProposed,
+		Round: b.Round, Block: b.Hash,
+		Details: map[string]interface{}{
+			"txns":  len(b.Txns),
+			"tries": generationTries,
+			"rank":  b.RoundRank,
+		}})
 	go mc.SendBlock(ctx, b)
 	return b, nil
 }
@@ -703,10 +734,10 @@ func (mc *Chain) addToRoundVerification(ctx context.Context, mr *Round, b *block
 
 /*GetBlockProposalWaitTime - get the time to wait for the block proposals of the given round */
 func (mc *Chain) GetBlockProposalWaitTime(r round.RoundI) time.Duration {
-	if mc.BlockProposalWaitMode == chain.BlockProposalWaitDynamic {
+	if mc.GetBlockProposalWaitMode() == chain.BlockProposalWaitDynamic {
 		return mc.computeBlockProposalDynamicWaitTime(r)
 	}
-	return mc.BlockProposalMaxWaitTime
+	return mc.GetBlockProposalMaxWaitTime()
 }
 
 func (mc *Chain) computeBlockProposalDynamicWaitTime(r round.RoundI) time.Duration {
@@ -724,7 +755,7 @@ func (mc *Chain) computeBlockProposalDynamicWaitTime(r round.RoundI) time.Durati
 		if medianTimeMS > mc.BlockProposalMaxWaitTime {
 			return medianTimeMS
 		}*/
-	return mc.BlockProposalMaxWaitTime
+	return mc.GetBlockProposalMaxWaitTime()
 }
 
 /*CollectBlocksForVerification - keep collecting the blocks till timeout and then start verifying */
@@ -849,7 +880,22 @@ func (mc *Chain) CollectBlocksForVerification(ctx context.Context, r *Round) {
 }
 
 /*VerifyRoundBlock - given a block is verified for a round*/
-func (mc *Chain) VerifyRoundBlock(ctx context.Context, r round.RoundI, b *block.Block) (*block.BlockVerificationTicket, error) {
+func (mc *Chain) VerifyRoundBlock(ctx context.Context, r round.RoundI, b *block.Block) (
+	bvt *block.BlockVerificationTicket, err error) {
+
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.verify", b.Round, b.Hash)
+	defer func() {
+		span.SetError(err)
+		span.Finish()
+		var e = &journal.Event{Type: journal.BlockVerified, Round: b.Round,
+			Block: b.Hash, Node: b.MinerID}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		journal.Record(e)
+	}()
+
 	if !mc.CanShardBlocks(r.GetRoundNumber()) {
 		return nil, common.NewError("fewer_active_sharders", "Number of active sharders not sufficient")
 	}
@@ -863,7 +909,7 @@ func (mc *Chain) VerifyRoundBlock(ctx context.Context, r round.RoundI, b *block.
 		return mc.SignBlock(ctx, b)
 	}
 	var hasPriorBlock = b.PrevBlock != nil
-	bvt, err := mc.VerifyBlock(ctx, b)
+	bvt, err = mc.VerifyBlock(ctx, b)
 	if err != nil {
 		b.SetVerificationStatus(block.VerificationFailed)
 		return nil, err
@@ -919,6 +965,10 @@ func (mc *Chain) checkBlockNotarization(ctx context.Context, r *Round, b *block.
 			zap.Any("block hash", b.Hash))
 		return false
 	}
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.notarize", b.Round, b.Hash)
+	defer span.Finish()
+
 	if !mc.AddNotarizedBlock(ctx, r, b) {
 		return true
 	}
@@ -980,6 +1030,8 @@ func (mc *Chain) AddNotarizedBlock(ctx context.Context, r *Round, b *block.Block
 	if !ok {
 		return false
 	}
+	journal.Record(&journal.Event{Type: journal.BlockNotarized,
+		Round: b.Round, Block: b.Hash, Node: b.MinerID})
 
 	mc.UpdateNodeState(b)
 
@@ -1166,9 +1218,9 @@ func (mc *Chain) SyncFetchFinalizedBlockFromSharders(ctx context.Context,
 func (mc *Chain) GetNextRoundTimeoutTime(ctx context.Context) int {
 
 	ssft := int(math.Ceil(chain.SteadyStateFinalizationTimer.Mean() / 1000000))
-	tick := mc.RoundTimeoutSofttoMin
-	if tick < mc.RoundTimeoutSofttoMult*ssft {
-		tick = mc.RoundTimeoutSofttoMult * ssft
+	tick := mc.GetRoundTimeoutSofttoMin()
+	if mult := mc.GetRoundTimeoutSofttoMult(); tick < mult*ssft {
+		tick = mult * ssft
 	}
 	logging.Logger.Info("nextTimeout", zap.Int("tick", tick))
 	return tick
@@ -1192,7 +1244,7 @@ func (mc *Chain) HandleRoundTimeout(ctx context.Context, round int64) {
 
 	var r = mc.GetMinerRound(round)
 
-	if r.GetSoftTimeoutCount() == mc.RoundRestartMult {
+	if r.GetSoftTimeoutCount() == mc.GetRoundRestartMult() {
 		logging.Logger.Info("triggering restartRound",
 			zap.Int64("round", r.GetRoundNumber()))
 		mc.restartRound(ctx, round)
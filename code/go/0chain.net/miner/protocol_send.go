@@ -5,6 +5,7 @@ import (
 
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/node"
 	"0chain.net/core/datastore"
 	. "0chain.net/core/logging"
 	"go.uber.org/zap"
@@ -63,11 +64,12 @@ func (mc *Chain) ForcePushNotarizedBlock(ctx context.Context, b *block.Block) {
 	}
 }
 
-/*SendFinalizedBlock - send the finalized block to the sharders */
+/*SendFinalizedBlock - send the finalized block to the sharders and observers */
 func (mc *Chain) SendFinalizedBlock(ctx context.Context, b *block.Block) {
 	if mc.BlocksToSharder == chain.FINALIZED {
 		mb := mc.GetMagicBlock(b.Round)
 		m2s := mb.Sharders
 		m2s.SendAll(FinalizedBlockSender(b))
 	}
+	node.SendToObservers(FinalizedBlockSender(b))
 }
@@ -19,6 +19,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/ememorystore"
+	"0chain.net/core/journal"
 	"0chain.net/core/logging"
 
 	"0chain.net/core/util"
@@ -31,14 +32,17 @@ import (
 
 const (
 	// transactions
-	scNameContributeMpk = "contributeMpk"
-	scNamePublishShares = "shareSignsOrShares"
-	scNameWait          = "wait"
+	scNameContributeMpk   = "contributeMpk"
+	scNameComplainDealers = "complainDealers"
+	scNameJustifyShares   = "justifyShares"
+	scNamePublishShares   = "shareSignsOrShares"
+	scNameWait            = "wait"
 	// REST API requests
-	scRestAPIGetDKGMiners  = "/getDkgList"
-	scRestAPIGetMinersMPKS = "/getMpksList"
-	scRestAPIGetMagicBlock = "/getMagicBlock"
-	scRestAPIGetMinerList  = "/getMinerList"
+	scRestAPIGetDKGMiners     = "/getDkgList"
+	scRestAPIGetDKGComplaints = "/getDkgComplaints"
+	scRestAPIGetMinersMPKS    = "/getMpksList"
+	scRestAPIGetMagicBlock    = "/getMagicBlock"
+	scRestAPIGetMinerList     = "/getMinerList"
 )
 
 // PhaseFunc represents local VC function returns optional
@@ -75,6 +79,8 @@ func (vcp *viewChangeProcess) init(mc *Chain) {
 		minersc.Start:      mc.DKGProcessStart,
 		minersc.Contribute: mc.ContributeMpk,
 		minersc.Share:      mc.SendSijs,
+		minersc.Complain:   mc.ComplainDealers,
+		minersc.Justify:    mc.JustifyShares,
 		minersc.Publish:    mc.PublishShareOrSigns,
 		minersc.Wait:       mc.Wait,
 	}
@@ -162,13 +168,22 @@ func (mc *Chain) DKGProcess(ctx context.Context) {
 			zap.Bool("active", active),
 			zap.Any("phase funcs", getFunctionName(mc.viewChangeProcess.phaseFuncs[pn.Phase])))
 
-		// only go through if pn.Phase is expected
+		// only go through if pn.Phase is expected, the SC decides whether
+		// the complaint phases follow the share phase
+		var cp = mc.CurrentPhase()
 		if !(pn.Phase == minersc.Start ||
-			pn.Phase == mc.CurrentPhase()+1 || retrySharePhase) {
+			pn.Phase == minersc.NextPhase(cp, false) ||
+			pn.Phase == minersc.NextPhase(cp, true) || retrySharePhase) {
 			logging.Logger.Debug(
 				"dkg process: jumping over a phase; skip and wait for restart",
 				zap.Any("current_phase", mc.CurrentPhase()),
 				zap.Any("next_phase", pn.Phase))
+			journal.Record(&journal.Event{Type: journal.PhaseChanged,
+				Round: pn.StartRound, Phase: minersc.Unknown.String(),
+				Error: "jumping over phase " + pn.Phase.String(),
+				Details: map[string]interface{}{
+					"prev_phase": mc.CurrentPhase().String(),
+				}})
 			mc.SetCurrentPhase(minersc.Unknown)
 			continue
 		}
@@ -211,6 +226,11 @@ func (mc *Chain) DKGProcess(ctx context.Context) {
 			prevPhase := mc.CurrentPhase()
 			mc.SetCurrentPhase(pn.Phase)
 			phaseStartRound = pn.StartRound
+			journal.Record(&journal.Event{Type: journal.PhaseChanged,
+				Round: pn.StartRound, Phase: pn.Phase.String(),
+				Details: map[string]interface{}{
+					"prev_phase": prevPhase.String(),
+				}})
 			logging.Logger.Debug("dkg process: moved phase",
 				zap.Any("prev_phase", prevPhase),
 				zap.Any("current_phase", mc.CurrentPhase()),
@@ -521,6 +541,164 @@ func (mc *Chain) SendSijs(ctx context.Context, lfb *block.Block,
 	return // (nil, nil)
 }
 
+//
+//                       C O M P L A I N   /   J U S T I F Y
+//
+
+func (mc *Chain) getDKGComplaints(ctx context.Context, lfb *block.Block,
+	mb *block.MagicBlock, active bool) (dc *minersc.DKGComplaints, err error) {
+
+	if active {
+		var n util.Serializable
+		n, err = mc.GetBlockStateNode(lfb, minersc.DKGComplaintsKey)
+		if err != nil {
+			if err == util.ErrValueNotPresent {
+				return minersc.NewDKGComplaints(), nil // no complaints
+			}
+			return
+		}
+		if n == nil {
+			return minersc.NewDKGComplaints(), nil
+		}
+
+		dc = minersc.NewDKGComplaints()
+		if err = dc.Decode(n.Encode()); err != nil {
+			return nil, err
+		}
+
+		return
+	}
+
+	var (
+		got util.Serializable
+		ok  bool
+	)
+
+	got = chain.GetFromSharders(ctx, minersc.ADDRESS, scRestAPIGetDKGComplaints,
+		mb.Sharders.N2NURLs(), func() util.Serializable {
+			return minersc.NewDKGComplaints()
+		}, func(val util.Serializable) bool {
+			return false // keep all
+		}, func(val util.Serializable) int64 {
+			return 0 // no highness for complaints
+		})
+
+	if dc, ok = got.(*minersc.DKGComplaints); !ok {
+		return nil, common.NewError("get_dkg_complaints_from_sharders",
+			"no DKG complaints given")
+	}
+
+	return
+}
+
+// ComplainDealers accuses dealers this miner hasn't received a valid secret
+// share from during the share phase.
+func (mc *Chain) ComplainDealers(ctx context.Context, lfb *block.Block,
+	mb *block.MagicBlock, active bool) (tx *httpclientutil.Transaction,
+	err error) {
+
+	mc.viewChangeProcess.Lock()
+	defer mc.viewChangeProcess.Unlock()
+
+	if !mc.viewChangeProcess.isDKGSet() {
+		return nil, common.NewError("complain_dealers", "DKG is not set")
+	}
+
+	var (
+		selfNode    = node.Self.Underlying()
+		selfNodeKey = selfNode.GetKey()
+		mpks        = mc.viewChangeProcess.mpks.GetMpks()
+		vcdkg       = mc.viewChangeProcess.viewChangeDKG
+	)
+
+	if _, ok := mpks[selfNodeKey]; !ok {
+		return // (nil, nil)
+	}
+
+	var dc = new(minersc.DealersComplaint)
+	for key := range mpks {
+		if key != selfNodeKey && !vcdkg.HasSecretShare(key) {
+			dc.Dealers = append(dc.Dealers, key)
+		}
+	}
+
+	if len(dc.Dealers) == 0 {
+		return // nothing to complain about
+	}
+
+	logging.Logger.Info("[vc] complain dealers",
+		zap.Strings("dealers", dc.Dealers))
+
+	var data = new(httpclientutil.SmartContractTxnData)
+	data.Name = scNameComplainDealers
+	data.InputArgs = dc
+
+	tx = httpclientutil.NewTransactionEntity(selfNodeKey, mc.ID,
+		selfNode.PublicKey)
+	tx.ToClientID = minersc.ADDRESS
+
+	err = httpclientutil.SendSmartContractTxn(tx, minersc.ADDRESS, 0, 0, data,
+		mb.Miners.N2NURLs())
+	return
+}
+
+// JustifyShares reveals secret shares of this miner for all miners
+// complained about it.
+func (mc *Chain) JustifyShares(ctx context.Context, lfb *block.Block,
+	mb *block.MagicBlock, active bool) (tx *httpclientutil.Transaction,
+	err error) {
+
+	var dc *minersc.DKGComplaints
+	if dc, err = mc.getDKGComplaints(ctx, lfb, mb, active); err != nil {
+		return
+	}
+
+	var (
+		selfNode    = node.Self.Underlying()
+		selfNodeKey = selfNode.GetKey()
+		complainers = dc.Complainers(selfNodeKey)
+	)
+
+	if len(complainers) == 0 {
+		return // no complaints
+	}
+
+	mc.viewChangeProcess.Lock()
+	defer mc.viewChangeProcess.Unlock()
+
+	if !mc.viewChangeProcess.isDKGSet() {
+		return nil, common.NewError("justify_shares", "DKG is not set")
+	}
+
+	var sj = &minersc.SharesJustification{
+		Shares: make(map[string]string, len(complainers)),
+	}
+	for _, key := range complainers {
+		share, ok := mc.viewChangeProcess.viewChangeDKG.GetKeyShare(
+			bls.ComputeIDdkg(key))
+		if !ok {
+			return nil, common.NewErrorf("justify_shares",
+				"missing secret share for %s", key)
+		}
+		sj.Shares[key] = share.GetHexString()
+	}
+
+	logging.Logger.Info("[vc] justify shares",
+		zap.Strings("complainers", complainers))
+
+	var data = new(httpclientutil.SmartContractTxnData)
+	data.Name = scNameJustifyShares
+	data.InputArgs = sj
+
+	tx = httpclientutil.NewTransactionEntity(selfNodeKey, mc.ID,
+		selfNode.PublicKey)
+	tx.ToClientID = minersc.ADDRESS
+
+	err = httpclientutil.SendSmartContractTxn(tx, minersc.ADDRESS, 0, 0, data,
+		mb.Miners.N2NURLs())
+	return
+}
+
 func (mc *Chain) GetMagicBlockFromSC(ctx context.Context, lfb *block.Block, mb *block.MagicBlock,
 	active bool) (magicBlock *block.MagicBlock, err error) {
 
@@ -674,12 +852,40 @@ func (mc *Chain) Wait(ctx context.Context, lfb *block.Block,
 		return // node leaves BC, don't do anything here
 	}
 
+	var dc *minersc.DKGComplaints
+	if dc, err = mc.getDKGComplaints(ctx, lfb, mb, active); err != nil {
+		return nil, common.NewErrorf("vc_wait",
+			"getting DKG complaints: %v", err)
+	}
+
 	var (
 		mpks        = mc.viewChangeProcess.mpks.GetMpks()
 		vcdkg       = mc.viewChangeProcess.viewChangeDKG
 		selfNodeKey = node.Self.Underlying().GetKey()
 	)
 
+	// shares revealed on chain by dealers this miner complained about
+	for dealer := range magicBlock.Mpks.Mpks {
+		var justified, ok = dc.JustifiedShare(dealer, selfNodeKey)
+		if !ok {
+			continue
+		}
+		var mpk *block.MPK
+		if mpk, ok = mpks[dealer]; !ok {
+			continue
+		}
+		var share bls.Key
+		if share.SetHexString(justified) != nil ||
+			!vcdkg.ValidateShare(bls.ConvertStringToMpk(mpk.Mpk), share) {
+			continue
+		}
+		err = vcdkg.AddSecretShare(bls.ComputeIDdkg(dealer), justified, true)
+		if err != nil {
+			return nil, common.NewErrorf("vc_wait",
+				"adding justified secret share: %v", err)
+		}
+	}
+
 	for key, share := range magicBlock.GetShareOrSigns().GetShares() {
 		if key == selfNodeKey {
 			continue // skip self
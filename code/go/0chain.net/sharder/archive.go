@@ -0,0 +1,463 @@
+package sharder
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/ememorystore"
+	"0chain.net/core/persistencestore"
+	"0chain.net/sharder/blockstore"
+
+	. "0chain.net/core/logging"
+)
+
+// ArchiveVersion is the version of the chain archive format.
+const ArchiveVersion = 1
+
+const (
+	archiveManifestName = "manifest.json"
+	archiveRoundPrefix  = "rounds/"
+)
+
+// ArchiveRound is everything a sharder stores for a finalized round.
+type ArchiveRound struct {
+	Round         *round.Round                      `json:"round"`
+	BlockSummary  *block.BlockSummary               `json:"block_summary"`
+	Block         *block.Block                      `json:"block"`
+	MagicBlockMap *block.MagicBlockMap              `json:"magic_block_map,omitempty"`
+	TxnSummaries  []*transaction.TransactionSummary `json:"txn_summaries"`
+}
+
+// ArchiveManifest is the last entry of a chain archive. It lists the sha256
+// of every round entry so an archive can be verified before importing it.
+type ArchiveManifest struct {
+	Version      int               `json:"version"`
+	ChainID      string            `json:"chain_id"`
+	From         int64             `json:"from"`
+	To           int64             `json:"to"`
+	CreationDate common.Timestamp  `json:"creation_date"`
+	Blocks       int               `json:"blocks"`
+	MagicBlocks  int               `json:"magic_blocks"`
+	Transactions int               `json:"transactions"`
+	Entries      map[string]string `json:"entries"`
+}
+
+// archiveStore reads and writes the stored data of rounds.
+type archiveStore interface {
+	readArchiveRound(ctx context.Context, rNum int64) (*ArchiveRound, error)
+	writeArchiveRound(ctx context.Context, ar *ArchiveRound) error
+	GetBlockHash(ctx context.Context, roundNumber int64) (string, error)
+}
+
+// ChainArchive exports a round range of the stored chain data to a portable
+// archive and imports verified archives back.
+type ChainArchive struct {
+	chainID string
+	store   archiveStore
+}
+
+// NewChainArchive creates a chain archive of given chain over given store.
+func NewChainArchive(chainID string, store archiveStore) *ChainArchive {
+	return &ChainArchive{chainID: chainID, store: store}
+}
+
+// NewChainArchive returns chain archive of the sharder stored data.
+func (sc *Chain) NewChainArchive() *ChainArchive {
+	return NewChainArchive(sc.ID, sc)
+}
+
+func archiveRoundName(rNum int64) string {
+	return archiveRoundPrefix + strconv.FormatInt(rNum, 10) + ".json"
+}
+
+func writeArchiveEntry(tw *tar.Writer, name string, data []byte) error {
+	var header = &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Export writes the stored data of the from-to rounds to the given writer as
+// a gzipped tar archive of per round JSON entries followed by the manifest.
+func (ca *ChainArchive) Export(ctx context.Context, w io.Writer, from,
+	to int64) (*ArchiveManifest, error) {
+
+	if from <= 0 || to < from {
+		return nil, common.NewErrorf("export_archive",
+			"invalid rounds range %d-%d", from, to)
+	}
+
+	var (
+		zw = gzip.NewWriter(w)
+		tw = tar.NewWriter(zw)
+		mf = &ArchiveManifest{
+			Version:      ArchiveVersion,
+			ChainID:      ca.chainID,
+			From:         from,
+			To:           to,
+			CreationDate: common.Now(),
+			Entries:      make(map[string]string, to-from+1),
+		}
+	)
+
+	for rNum := from; rNum <= to; rNum++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		ar, err := ca.store.readArchiveRound(ctx, rNum)
+		if err != nil {
+			return nil, common.NewErrorf("export_archive",
+				"round %d: %v", rNum, err)
+		}
+		data, err := json.Marshal(ar)
+		if err != nil {
+			return nil, err
+		}
+		var name = archiveRoundName(rNum)
+		if err = writeArchiveEntry(tw, name, data); err != nil {
+			return nil, err
+		}
+		var sum = sha256.Sum256(data)
+		mf.Entries[name] = hex.EncodeToString(sum[:])
+		mf.Blocks++
+		mf.Transactions += len(ar.TxnSummaries)
+		if ar.MagicBlockMap != nil {
+			mf.MagicBlocks++
+		}
+	}
+
+	data, err := json.Marshal(mf)
+	if err != nil {
+		return nil, err
+	}
+	if err = writeArchiveEntry(tw, archiveManifestName, data); err != nil {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
+// readArchive calls the handler for every round entry of the archive in
+// order and returns the manifest. The handler is called before the entry is
+// checked against the manifest, so an entry is trusted only when
+// readArchive returns no error.
+func readArchive(r io.Reader,
+	handler func(ar *ArchiveRound) error) (*ArchiveManifest, error) {
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, common.NewErrorf("invalid_archive", "gzip: %v", err)
+	}
+	defer zr.Close()
+
+	var (
+		tr     = tar.NewReader(zr)
+		sums   = make(map[string]string)
+		rounds []int64
+		mf     *ArchiveManifest
+	)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, common.NewErrorf("invalid_archive", "tar: %v", err)
+		}
+		if mf != nil {
+			return nil, common.NewErrorf("invalid_archive",
+				"unexpected entry %s after the manifest", header.Name)
+		}
+		if header.Name == archiveManifestName {
+			mf = new(ArchiveManifest)
+			if err = json.NewDecoder(tr).Decode(mf); err != nil {
+				return nil, common.NewErrorf("invalid_archive",
+					"decoding manifest: %v", err)
+			}
+			continue
+		}
+
+		var (
+			hasher = sha256.New()
+			ar     = new(ArchiveRound)
+		)
+		err = json.NewDecoder(io.TeeReader(tr, hasher)).Decode(ar)
+		if err != nil {
+			return nil, common.NewErrorf("invalid_archive",
+				"decoding %s: %v", header.Name, err)
+		}
+		// hash the rest of the entry, if any
+		if _, err = io.Copy(hasher, tr); err != nil {
+			return nil, err
+		}
+		if ar.Round == nil || header.Name != archiveRoundName(ar.Round.Number) {
+			return nil, common.NewErrorf("invalid_archive",
+				"unexpected entry %s", header.Name)
+		}
+		sums[header.Name] = hex.EncodeToString(hasher.Sum(nil))
+		rounds = append(rounds, ar.Round.Number)
+		if err = handler(ar); err != nil {
+			return nil, err
+		}
+	}
+
+	if mf == nil {
+		return nil, common.NewError("invalid_archive", "missing manifest")
+	}
+	if mf.Version != ArchiveVersion {
+		return nil, common.NewErrorf("invalid_archive",
+			"unsupported version %d", mf.Version)
+	}
+	if int64(len(rounds)) != mf.To-mf.From+1 || len(mf.Entries) != len(rounds) {
+		return nil, common.NewErrorf("invalid_archive",
+			"expected %d rounds, got %d", mf.To-mf.From+1, len(rounds))
+	}
+	for i, rNum := range rounds {
+		if rNum != mf.From+int64(i) {
+			return nil, common.NewErrorf("invalid_archive",
+				"expected round %d, got %d", mf.From+int64(i), rNum)
+		}
+	}
+	for name, sum := range sums {
+		if mf.Entries[name] != sum {
+			return nil, common.NewErrorf("invalid_archive",
+				"checksum mismatch of %s", name)
+		}
+	}
+	return mf, nil
+}
+
+// verifyArchiveRound checks the round entry is consistent and its block
+// hash is the computed one.
+func verifyArchiveRound(ar *ArchiveRound) error {
+	var (
+		r  = ar.Round
+		b  = ar.Block
+		bs = ar.BlockSummary
+	)
+	if b == nil || bs == nil {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: missing block or block summary", r.Number)
+	}
+	if r.BlockHash != b.Hash || r.Number != b.Round {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: round summary doesn't match the block", r.Number)
+	}
+	if mb := b.MagicBlock; mb != nil && (mb.Miners == nil || mb.Sharders == nil ||
+		mb.GetHash() != mb.Hash) {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: magic block hash mismatch", r.Number)
+	}
+	if hash := b.ComputeHash(); hash != b.Hash {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: block hash %s, computed %s", r.Number, b.Hash, hash)
+	}
+	if bs.Hash != b.Hash || bs.Round != b.Round || bs.MinerID != b.MinerID ||
+		bs.NumTxns != len(b.Txns) ||
+		bs.MerkleTreeRoot != b.GetMerkleTree().GetRoot() ||
+		bs.ReceiptMerkleTreeRoot != b.GetReceiptsMerkleTree().GetRoot() {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: block summary doesn't match the block", r.Number)
+	}
+
+	var mbm = ar.MagicBlockMap
+	switch {
+	case b.MagicBlock == nil && mbm != nil:
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: magic block map of a block without magic block", r.Number)
+	case b.MagicBlock != nil && (mbm == nil || mbm.Hash != b.Hash ||
+		mbm.BlockRound != b.Round ||
+		mbm.ID != strconv.FormatInt(b.MagicBlock.MagicBlockNumber, 10)):
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: magic block map doesn't match the block", r.Number)
+	}
+
+	if len(ar.TxnSummaries) != len(b.Txns) {
+		return common.NewErrorf("invalid_archive_round",
+			"round %d: expected %d txn summaries, got %d", r.Number,
+			len(b.Txns), len(ar.TxnSummaries))
+	}
+	b.ComputeTxnMap()
+	for _, ts := range ar.TxnSummaries {
+		if ts.Round != b.Round || !b.HasTransaction(ts.Hash) {
+			return common.NewErrorf("invalid_archive_round",
+				"round %d: txn summary %s doesn't match the block", r.Number,
+				ts.Hash)
+		}
+	}
+	return nil
+}
+
+// Verify checks the archive entries against the manifest, the hashes of
+// the archived blocks and the chain linkage of the blocks to each other and
+// to the locally stored block preceding the archived range.
+func (ca *ChainArchive) Verify(ctx context.Context,
+	r io.Reader) (*ArchiveManifest, error) {
+
+	var prevHash string
+	mf, err := readArchive(r, func(ar *ArchiveRound) error {
+		if err := verifyArchiveRound(ar); err != nil {
+			return err
+		}
+		var b = ar.Block
+		if prevHash == "" {
+			hash, err := ca.store.GetBlockHash(ctx, b.Round-1)
+			if err == nil && hash != b.PrevHash {
+				return common.NewErrorf("invalid_archive_round",
+					"round %d: previous block %s, locally stored %s",
+					b.Round, b.PrevHash, hash)
+			}
+		} else if b.PrevHash != prevHash {
+			return common.NewErrorf("invalid_archive_round",
+				"round %d: previous block %s, archived %s", b.Round,
+				b.PrevHash, prevHash)
+		}
+		prevHash = b.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mf.ChainID != ca.chainID {
+		return nil, common.NewErrorf("invalid_archive",
+			"chain %s, expected %s", mf.ChainID, ca.chainID)
+	}
+	return mf, nil
+}
+
+// Import verifies the archive file and, only if the whole archive is valid,
+// writes the archived rounds to the store.
+func (ca *ChainArchive) Import(ctx context.Context,
+	path string) (*ArchiveManifest, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mf, err := ca.Verify(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	_, err = readArchive(f, func(ar *ArchiveRound) error {
+		if err := ca.store.writeArchiveRound(ctx, ar); err != nil {
+			return common.NewErrorf("import_archive", "round %d: %v",
+				ar.Round.Number, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
+func (sc *Chain) readArchiveRound(ctx context.Context, rNum int64) (
+	*ArchiveRound, error) {
+
+	r, err := sc.GetRoundFromStore(ctx, rNum)
+	if err != nil {
+		return nil, err
+	}
+	if r.BlockHash == "" {
+		return nil, common.NewErrorf("read_archive_round",
+			"round %d has empty block hash", rNum)
+	}
+
+	var ar = &ArchiveRound{Round: r}
+	bsmd := datastore.GetEntityMetadata("block_summary")
+	bctx := ememorystore.WithEntityConnection(ctx, bsmd)
+	ar.BlockSummary, err = sc.GetBlockSummary(bctx, r.BlockHash)
+	ememorystore.Close(bctx)
+	if err != nil {
+		return nil, err
+	}
+	if ar.Block, err = sc.GetBlockFromStore(r.BlockHash, rNum); err != nil {
+		return nil, err
+	}
+	if ar.Block.MagicBlock != nil {
+		var number = strconv.FormatInt(ar.Block.MagicBlock.MagicBlockNumber, 10)
+		if ar.MagicBlockMap, err = sc.GetMagicBlockMap(ctx, number); err != nil {
+			return nil, err
+		}
+	}
+
+	tsmd := datastore.GetEntityMetadata("txn_summary")
+	tctx := persistencestore.WithEntityConnection(ctx, tsmd)
+	defer persistencestore.Close(tctx)
+	ar.TxnSummaries = make([]*transaction.TransactionSummary, 0,
+		len(ar.Block.Txns))
+	for _, txn := range ar.Block.Txns {
+		ts, err := sc.GetTransactionSummary(tctx, txn.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("txn summary %s: %v", txn.Hash, err)
+		}
+		ar.TxnSummaries = append(ar.TxnSummaries, ts)
+	}
+	return ar, nil
+}
+
+func (sc *Chain) writeArchiveRound(ctx context.Context,
+	ar *ArchiveRound) error {
+
+	if err := blockstore.GetStore().Write(ar.Block); err != nil {
+		return err
+	}
+	if ar.MagicBlockMap != nil {
+		if err := sc.StoreMagicBlockMapFromBlock(ctx, ar.MagicBlockMap); err != nil {
+			return err
+		}
+	}
+	if err := sc.StoreBlockSummary(ctx, ar.BlockSummary); err != nil {
+		return err
+	}
+
+	var sTxns = make([]datastore.Entity, 0, len(ar.TxnSummaries))
+	for _, ts := range ar.TxnSummaries {
+		sTxns = append(sTxns, ts)
+	}
+	if err := sc.storeTransactions(ctx, sTxns); err != nil {
+		return err
+	}
+	if err := sc.StoreTxnClientHistory(ctx, ar.Block); err != nil {
+		Logger.Error("import archive - store txn client history",
+			zap.Int64("round", ar.Block.Round), zap.Error(err))
+	}
+
+	// the round is stored last, it's what marks the round as stored
+	return sc.StoreRound(ctx, ar.Round)
+}
@@ -0,0 +1,290 @@
+package sharder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+)
+
+type testArchiveStore struct {
+	rounds map[int64]*ArchiveRound
+}
+
+func newTestArchiveStore() *testArchiveStore {
+	return &testArchiveStore{rounds: make(map[int64]*ArchiveRound)}
+}
+
+func (s *testArchiveStore) readArchiveRound(ctx context.Context, rNum int64) (
+	*ArchiveRound, error) {
+
+	if ar, ok := s.rounds[rNum]; ok {
+		return ar, nil
+	}
+	return nil, common.NewError("not_found", "round not found")
+}
+
+func (s *testArchiveStore) writeArchiveRound(ctx context.Context,
+	ar *ArchiveRound) error {
+
+	s.rounds[ar.Round.Number] = ar
+	return nil
+}
+
+func (s *testArchiveStore) GetBlockHash(ctx context.Context,
+	roundNumber int64) (string, error) {
+
+	if ar, ok := s.rounds[roundNumber]; ok {
+		return ar.Round.BlockHash, nil
+	}
+	return "", common.NewError("not_found", "round not found")
+}
+
+// addRound stores a round with a block following the one of previous round.
+func (s *testArchiveStore) addRound(rNum int64, txns int, mb bool) *ArchiveRound {
+	var b = block.NewBlock("", rNum)
+	b.MinerID = "miner"
+	b.CreationDate = common.Timestamp(rNum)
+	if prev, ok := s.rounds[rNum-1]; ok {
+		b.PrevHash = prev.Block.Hash
+	}
+	for i := 0; i < txns; i++ {
+		var txn = &transaction.Transaction{ClientID: "client",
+			TransactionData: strconv.FormatInt(rNum, 10) + ":" + strconv.Itoa(i)}
+		txn.Hash = txn.ComputeHash()
+		b.Txns = append(b.Txns, txn)
+	}
+	if mb {
+		b.MagicBlock = block.NewMagicBlock()
+		b.MagicBlock.MagicBlockNumber = rNum
+		b.MagicBlock.StartingRound = rNum
+		b.MagicBlock.Miners = node.NewPool(node.NodeTypeMiner)
+		b.MagicBlock.Sharders = node.NewPool(node.NodeTypeSharder)
+	}
+	b.HashBlock()
+
+	var ar = &ArchiveRound{
+		Round: &round.Round{Number: rNum, BlockHash: b.Hash},
+		BlockSummary: &block.BlockSummary{
+			Hash:                  b.Hash,
+			Round:                 rNum,
+			MinerID:               b.MinerID,
+			NumTxns:               len(b.Txns),
+			MerkleTreeRoot:        b.GetMerkleTree().GetRoot(),
+			ReceiptMerkleTreeRoot: b.GetReceiptsMerkleTree().GetRoot(),
+		},
+		Block:        b,
+		TxnSummaries: []*transaction.TransactionSummary{},
+	}
+	if mb {
+		ar.MagicBlockMap = &block.MagicBlockMap{Hash: b.Hash, BlockRound: rNum}
+		ar.MagicBlockMap.ID = strconv.FormatInt(rNum, 10)
+	}
+	for _, txn := range b.Txns {
+		var ts = &transaction.TransactionSummary{Round: rNum}
+		ts.Hash = txn.Hash
+		ar.TxnSummaries = append(ar.TxnSummaries, ts)
+	}
+	s.rounds[rNum] = ar
+	return ar
+}
+
+func exportTestArchive(t *testing.T, src *testArchiveStore, from,
+	to int64) string {
+
+	var (
+		path = filepath.Join(t.TempDir(), "archive.tar.gz")
+		buf  bytes.Buffer
+	)
+	mf, err := NewChainArchive("chain", src).Export(context.Background(),
+		&buf, from, to)
+	require.NoError(t, err)
+	require.Equal(t, int(to-from+1), mf.Blocks)
+	require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+// rewriteTestArchive rewrites the archive entries with the given function.
+func rewriteTestArchive(t *testing.T, path string,
+	rewrite func(name string, data []byte) []byte) {
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	var (
+		tr  = tar.NewReader(zr)
+		buf bytes.Buffer
+		zw  = gzip.NewWriter(&buf)
+		tw  = tar.NewWriter(zw)
+	)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		require.NoError(t, writeArchiveEntry(tw, header.Name,
+			rewrite(header.Name, data)))
+	}
+	require.NoError(t, f.Close())
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, ioutil.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestChainArchive(t *testing.T) {
+	var (
+		ctx = context.Background()
+		src = newTestArchiveStore()
+	)
+	src.addRound(1, 0, false)
+	src.addRound(2, 3, true)
+	src.addRound(3, 1, false)
+	src.addRound(4, 2, false)
+
+	var path = exportTestArchive(t, src, 2, 4)
+
+	// the destination has the round preceding the archived range
+	var dst = newTestArchiveStore()
+	dst.rounds[1] = src.rounds[1]
+	mf, err := NewChainArchive("chain", dst).Import(ctx, path)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, mf.From)
+	require.EqualValues(t, 4, mf.To)
+	require.Equal(t, 1, mf.MagicBlocks)
+	require.Equal(t, 6, mf.Transactions)
+	require.Len(t, dst.rounds, 4)
+	for rNum := int64(2); rNum <= 4; rNum++ {
+		var ar = dst.rounds[rNum]
+		require.Equal(t, src.rounds[rNum].Block.Hash, ar.Block.Hash)
+		require.Equal(t, src.rounds[rNum].Round.BlockHash, ar.Round.BlockHash)
+		require.Len(t, ar.TxnSummaries, len(ar.Block.Txns))
+	}
+	require.Equal(t, src.rounds[2].MagicBlockMap, dst.rounds[2].MagicBlockMap)
+
+	// a missing round fails the export
+	_, err = NewChainArchive("chain", src).Export(ctx, &bytes.Buffer{}, 3, 5)
+	require.Error(t, err)
+}
+
+func TestChainArchive_invalid(t *testing.T) {
+	var (
+		ctx = context.Background()
+		src = newTestArchiveStore()
+	)
+	src.addRound(1, 1, false)
+	src.addRound(2, 1, false)
+	src.addRound(3, 1, false)
+	var path = exportTestArchive(t, src, 1, 3)
+
+	// different chain
+	var dst = newTestArchiveStore()
+	_, err := NewChainArchive("other", dst).Import(ctx, path)
+	require.Error(t, err)
+	require.Empty(t, dst.rounds)
+
+	// the archive doesn't follow the locally stored chain
+	dst = newTestArchiveStore()
+	dst.addRound(0, 0, false)
+	_, err = NewChainArchive("chain", dst).Import(ctx, path)
+	require.Error(t, err)
+	require.Len(t, dst.rounds, 1)
+
+	for name, rewrite := range map[string]func(ar *ArchiveRound){
+		"block hash": func(ar *ArchiveRound) {
+			ar.Block.MinerID = "other"
+		},
+		"round summary": func(ar *ArchiveRound) {
+			ar.Round.BlockHash = src.rounds[1].Block.Hash
+		},
+		"block summary": func(ar *ArchiveRound) {
+			ar.BlockSummary.NumTxns = 2
+		},
+		"txn summary": func(ar *ArchiveRound) {
+			ar.TxnSummaries[0].Round = 1
+		},
+		"magic block map": func(ar *ArchiveRound) {
+			ar.MagicBlockMap = &block.MagicBlockMap{}
+		},
+		"magic block": func(ar *ArchiveRound) {
+			ar.Block.MagicBlock = block.NewMagicBlock()
+			ar.Block.MagicBlock.Hash = "hash"
+			ar.Block.HashBlock()
+			ar.Round.BlockHash = ar.Block.Hash
+			ar.BlockSummary.Hash = ar.Block.Hash
+		},
+		"linkage": func(ar *ArchiveRound) {
+			ar.Block.PrevHash = ar.Block.Hash
+			ar.Block.HashBlock()
+			ar.Round.BlockHash = ar.Block.Hash
+			ar.BlockSummary.Hash = ar.Block.Hash
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var path = exportTestArchive(t, src, 1, 3)
+			// the checksum of the rewritten entry is updated too
+			var sum string
+			rewriteTestArchive(t, path, func(entry string, data []byte) []byte {
+				switch entry {
+				case archiveRoundName(2):
+					var ar ArchiveRound
+					require.NoError(t, json.Unmarshal(data, &ar))
+					rewrite(&ar)
+					data, err := json.Marshal(&ar)
+					require.NoError(t, err)
+					sum = sha256Hex(data)
+					return data
+				case archiveManifestName:
+					var mf ArchiveManifest
+					require.NoError(t, json.Unmarshal(data, &mf))
+					mf.Entries[archiveRoundName(2)] = sum
+					data, err := json.Marshal(&mf)
+					require.NoError(t, err)
+					return data
+				}
+				return data
+			})
+			var dst = newTestArchiveStore()
+			_, err := NewChainArchive("chain", dst).Import(ctx, path)
+			require.Error(t, err)
+			require.Empty(t, dst.rounds)
+		})
+	}
+
+	t.Run("checksum", func(t *testing.T) {
+		var path = exportTestArchive(t, src, 1, 3)
+		rewriteTestArchive(t, path, func(entry string, data []byte) []byte {
+			if entry == archiveRoundName(3) {
+				data = append(data, ' ')
+			}
+			return data
+		})
+		var dst = newTestArchiveStore()
+		_, err := NewChainArchive("chain", dst).Import(ctx, path)
+		require.Error(t, err)
+		require.Empty(t, dst.rounds)
+	})
+}
+
+func sha256Hex(data []byte) string {
+	var sum = sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -169,11 +169,14 @@ func (sc *Chain) GetMagicBlockMap(ctx context.Context, magicBlockNumber string)
 }
 
 // GetHighestMagicBlockMap returns highest stored MB map. The highest means with
-// greatest MB number. It works with Cassandra only.
+// greatest MB number. It works with Cassandra and the kvstore.
 func (sc *Chain) GetHighestMagicBlockMap(ctx context.Context) (
 	mbm *block.MagicBlockMap, err error) {
 
 	var mbmemd = datastore.GetEntityMetadata("magic_block_map")
+	if kvs, ok := getKVStore(mbmemd); ok {
+		return getHighestKVMagicBlockMap(ctx, kvs, mbmemd)
+	}
 	mbm = mbmemd.Instance().(*block.MagicBlockMap)
 
 	var mctx = persistencestore.WithEntityConnection(ctx, mbmemd)
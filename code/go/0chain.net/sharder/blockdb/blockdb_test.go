@@ -2,6 +2,8 @@ package blockdb
 
 import (
 	"io"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
@@ -105,3 +107,47 @@ func TestDBWrite(t *testing.T) {
 	err = db.Close()
 	require.NoError(t, err)
 }
+
+func TestDBAppend(t *testing.T) {
+	var (
+		file = filepath.Join(t.TempDir(), "append")
+		sp   StudentProvider
+	)
+	var open = func() *BlockDB {
+		db, err := NewBlockDB(file, 4, true)
+		require.NoError(t, err)
+		require.NoError(t, db.Append(&sp))
+		return db
+	}
+
+	db := open()
+	require.NoError(t, db.WriteData(&Student{Name: "Bitcoin", ID: "2009"}))
+	require.NoError(t, db.WriteData(&Student{Name: "Linux", ID: "1991"}))
+	require.NoError(t, db.Sync())
+	// not indexed by the saved header
+	require.NoError(t, db.WriteData(&Student{Name: "Apache", ID: "1995"}))
+	require.NoError(t, db.Close())
+
+	// partially written record
+	f, err := os.OpenFile(file+"."+FileExtData, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0, 0, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	db = open()
+	require.Equal(t, []Key{"2009", "1991", "1995"}, db.index.GetKeys())
+	require.NoError(t, db.WriteData(&Student{Name: "Go", ID: "2012"}))
+	for _, id := range []Key{"2009", "1991", "1995", "2012"} {
+		var s Student
+		require.NoError(t, db.Read(id, &s))
+		require.Equal(t, id, s.GetKey())
+	}
+	require.NoError(t, db.Save())
+
+	db = open()
+	defer db.Close()
+	records, err := db.ReadAll(&sp)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+}
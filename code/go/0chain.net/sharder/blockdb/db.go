@@ -34,6 +34,11 @@ type BlockDB struct {
 	dataFile  *os.File
 }
 
+var (
+	// Make sure BlockDB implements AppendDatabase.
+	_ AppendDatabase = (*BlockDB)(nil)
+)
+
 /*NewBlockDB - create a new block db
 -- file name is of the form directory/where/to/store/dbfile. The actual files will be dbfile.idx and dbfile.dat
 -- create - create a new one or only try to open an existing one
@@ -98,19 +103,34 @@ func (bdb *BlockDB) Read(key Key, record Record) error {
 }
 
 func (bdb *BlockDB) read(dataFile io.Reader, record Record) error {
+	data, err := bdb.readData(dataFile)
+	if err != nil {
+		return err
+	}
+	return bdb.decode(data, record)
+}
+
+func (bdb *BlockDB) readData(dataFile io.Reader) ([]byte, error) {
 	var dlen int32
 	err := binary.Read(dataFile, binary.LittleEndian, &dlen)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if dlen < 0 {
+		return nil, fmt.Errorf("invalid data length: %v", dlen)
 	}
 	data := make([]byte, dlen)
 	n, err := io.ReadFull(dataFile, data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if int32(n) != dlen {
-		return fmt.Errorf("read data length doesnot match expected data length dlen=%v n=%v", dlen, n)
+		return nil, fmt.Errorf("read data length doesnot match expected data length dlen=%v n=%v", dlen, n)
 	}
+	return data, nil
+}
+
+func (bdb *BlockDB) decode(data []byte, record Record) (err error) {
 	if bdb.compress {
 		data, err = compDe.Decompress(data)
 		if err != nil {
@@ -118,14 +138,16 @@ func (bdb *BlockDB) read(dataFile io.Reader, record Record) error {
 		}
 	}
 	buffer := bytes.NewBuffer(data)
-	err = record.Decode(buffer)
-	return err
+	return record.Decode(buffer)
 }
 
 //ReadAll - read all the records
 func (bdb *BlockDB) ReadAll(rp RecordProvider) ([]Record, error) {
 	keys := bdb.index.GetKeys()
 	records := make([]Record, 0, len(keys))
+	if _, err := bdb.dataFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
 	dataFile := bufio.NewReader(bdb.dataFile)
 	for range keys {
 		record := rp.NewRecord()
@@ -143,7 +165,7 @@ func (bdb *BlockDB) ReadAll(rp RecordProvider) ([]Record, error) {
 
 //WriteData - write the data
 func (bdb *BlockDB) WriteData(record Record) error {
-	offset, err := bdb.dataFile.Seek(0, 1)
+	offset, err := bdb.dataFile.Seek(0, io.SeekEnd)
 	if err != nil {
 		return err
 	}
@@ -190,10 +212,101 @@ func (bdb *BlockDB) Iterate(ctx context.Context, handler DBIteratorHandler, rp R
 
 //Save - implement interface
 func (bdb *BlockDB) Save() error {
-	bdb.saveHeader()
+	if err := bdb.saveHeader(); err != nil {
+		bdb.Close()
+		return err
+	}
 	return bdb.Close()
 }
 
+/*Append - open an existing database or create a new one to append records to it.
+The records written after the last save or sync are indexed again reading the data
+file and a partially written last record is truncated */
+func (bdb *BlockDB) Append(rp RecordProvider) error {
+	err := os.MkdirAll(filepath.Dir(bdb.file), 0755)
+	if err != nil {
+		return err
+	}
+	if bdb.index == nil {
+		bdb.SetIndex(newMapIndex())
+	}
+	f, err := os.OpenFile(bdb.getHeaderFileName(), os.O_RDONLY, 0644)
+	if err == nil {
+		err = bdb.readHeader(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	bdb.dataFile, err = os.OpenFile(bdb.getDataFileName(), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if err = bdb.recover(rp); err != nil {
+		bdb.dataFile.Close()
+		return err
+	}
+	return nil
+}
+
+//recover - index the records following the last indexed one
+func (bdb *BlockDB) recover(rp RecordProvider) error {
+	var offset int64
+	if keys := bdb.index.GetKeys(); len(keys) > 0 {
+		last, err := bdb.index.GetOffset(keys[len(keys)-1])
+		if err != nil {
+			return err
+		}
+		var dlen int32
+		err = binary.Read(io.NewSectionReader(bdb.dataFile, last, 4), binary.LittleEndian, &dlen)
+		if err != nil {
+			return fmt.Errorf("reading indexed record at %v: %v", last, err)
+		}
+		offset = last + 4 + int64(dlen)
+	}
+	fi, err := bdb.dataFile.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if offset > size {
+		return fmt.Errorf("data file is shorter than its index: %v < %v", size, offset)
+	}
+	dataFile := bufio.NewReader(io.NewSectionReader(bdb.dataFile, offset, size-offset))
+	for offset < size {
+		data, err := bdb.readData(dataFile)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break // partially written record
+		}
+		if err != nil {
+			return err
+		}
+		record := rp.NewRecord()
+		if err = bdb.decode(data, record); err != nil {
+			return fmt.Errorf("decoding record at %v: %v", offset, err)
+		}
+		bdb.index.SetOffset(record.GetKey(), offset)
+		offset += 4 + int64(len(data))
+	}
+	if offset < size {
+		if err = bdb.dataFile.Truncate(offset); err != nil {
+			return err
+		}
+	}
+	_, err = bdb.dataFile.Seek(offset, io.SeekStart)
+	return err
+}
+
+//Sync - flush the data and save the header keeping the database open
+func (bdb *BlockDB) Sync() error {
+	if err := bdb.dataFile.Sync(); err != nil {
+		return err
+	}
+	return bdb.saveHeader()
+}
+
 //Close - implement interface
 func (bdb *BlockDB) Close() error {
 	if bdb.dataFile != nil {
@@ -212,7 +325,7 @@ func (bdb *BlockDB) Delete() error {
 }
 
 func (bdb *BlockDB) saveHeader() error {
-	headerFile, err := os.OpenFile(bdb.getHeaderFileName(), os.O_RDWR|os.O_CREATE, 0644)
+	headerFile, err := os.OpenFile(bdb.getHeaderFileName(), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
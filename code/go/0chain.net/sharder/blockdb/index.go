@@ -20,6 +20,11 @@ type mapIndex struct {
 	mutex sync.RWMutex
 }
 
+//NewMapIndex - create a new in memory index that supports setting offsets
+func NewMapIndex() Index {
+	return newMapIndex()
+}
+
 func newMapIndex() *mapIndex {
 	idx := &mapIndex{}
 	idx.index = make(map[Key]int64)
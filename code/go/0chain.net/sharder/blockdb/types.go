@@ -59,3 +59,10 @@ type Database interface {
 	WriteData(record Record) error
 	Iterate(ctx context.Context, handler DBIteratorHandler, rp RecordProvider) error
 }
+
+//AppendDatabase - a database that can be reopened to append records to it
+type AppendDatabase interface {
+	Database
+	Append(rp RecordProvider) error
+	Sync() error
+}
@@ -209,6 +209,21 @@ func (fbs *FSBlockStore) read(hash string, round int64) (*block.Block, error) {
 	return b, nil
 }
 
+// readBlockFile reads the block from the zlib compressed file of the block
+func readBlockFile(fileName string, b *block.Block) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := zlib.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return datastore.ReadJSON(r, b)
+}
+
 // Delete - delete from the hash of the block
 func (fbs *FSBlockStore) Delete(hash string) error {
 	return common.NewError("interface_not_implemented", "FSBlockStore cannote provide this interface")
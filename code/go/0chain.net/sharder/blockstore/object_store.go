@@ -0,0 +1,115 @@
+package blockstore
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio-go"
+)
+
+type (
+	// ObjectStore - a cold storage of block files by object names.
+	ObjectStore interface {
+		// Put uploads the file as the object with given name.
+		Put(name string, filePath string) error
+
+		// Get downloads the object with given name to the file.
+		Get(name string, filePath string) error
+
+		// Exists returns true if the object with given name is stored.
+		Exists(name string) bool
+	}
+
+	// MinioObjectStore - an object store backed by a Minio bucket.
+	MinioObjectStore struct {
+		Minio MinioClient
+	}
+
+	// DirObjectStore - an object store backed by a local directory, for
+	// example a mounted network file system.
+	DirObjectStore struct {
+		Directory string
+	}
+)
+
+var (
+	// Make sure MinioObjectStore implements ObjectStore.
+	_ ObjectStore = (*MinioObjectStore)(nil)
+	// Make sure DirObjectStore implements ObjectStore.
+	_ ObjectStore = (*DirObjectStore)(nil)
+)
+
+// NewMinioObjectStore - create a new object store of the minio client bucket.
+func NewMinioObjectStore(mc MinioClient) *MinioObjectStore {
+	return &MinioObjectStore{Minio: mc}
+}
+
+// Put is a part of ObjectStore interface implementation.
+func (mos *MinioObjectStore) Put(name string, filePath string) error {
+	_, err := mos.Minio.FPutObject(mos.Minio.BucketName(), name, filePath, minio.PutObjectOptions{})
+	return err
+}
+
+// Get is a part of ObjectStore interface implementation.
+func (mos *MinioObjectStore) Get(name string, filePath string) error {
+	return mos.Minio.FGetObject(mos.Minio.BucketName(), name, filePath, minio.GetObjectOptions{})
+}
+
+// Exists is a part of ObjectStore interface implementation.
+func (mos *MinioObjectStore) Exists(name string) bool {
+	_, err := mos.Minio.StatObject(mos.Minio.BucketName(), name, minio.StatObjectOptions{})
+	return err == nil
+}
+
+// NewDirObjectStore - create a new object store of the directory.
+func NewDirObjectStore(dir string) *DirObjectStore {
+	return &DirObjectStore{Directory: dir}
+}
+
+// Put is a part of ObjectStore interface implementation.
+func (dos *DirObjectStore) Put(name string, filePath string) error {
+	return copyFile(filePath, filepath.Join(dos.Directory, name))
+}
+
+// Get is a part of ObjectStore interface implementation.
+func (dos *DirObjectStore) Get(name string, filePath string) error {
+	return copyFile(filepath.Join(dos.Directory, name), filePath)
+}
+
+// Exists is a part of ObjectStore interface implementation.
+func (dos *DirObjectStore) Exists(name string) bool {
+	_, err := os.Stat(filepath.Join(dos.Directory, name))
+	return err == nil
+}
+
+// copyFile copies the file replacing the destination file only when the
+// copy is complete
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err = os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	dst, err := ioutil.TempFile(filepath.Dir(to), filepath.Base(to)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dst.Name())
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Chmod(0644); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(dst.Name(), to)
+}
@@ -0,0 +1,573 @@
+package blockstore
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+	"0chain.net/sharder/blockdb"
+)
+
+const (
+	// DefaultSegmentRoundRange is default number of rounds of a segment.
+	DefaultSegmentRoundRange = 10000
+
+	// number of segments kept open, the least recently used are closed
+	maxOpenSegments = 16
+	// number of blocks written to a segment between saving its index, the
+	// blocks written after the last save are indexed again on open
+	segmentSyncInterval = 100
+
+	compactedFileName = "compacted"
+	compactFileSuffix = ".compact"
+	commitFileSuffix  = ".commit"
+)
+
+type (
+	// SegmentBlockStore - a block store appending blocks to a segment file
+	// per range of rounds with an offset index instead of a file per block.
+	SegmentBlockStore struct {
+		RootDirectory         string
+		RoundRange            int64
+		blockMetadataProvider datastore.EntityMetadata
+		compress              bool
+
+		mutex    sync.Mutex
+		segments map[int64]*segment
+		tick     int64
+	}
+
+	// segment - an open segment file of the store
+	segment struct {
+		mutex  sync.Mutex
+		number int64
+		db     blockdb.AppendDatabase
+		index  blockdb.Index
+		dirty  int // blocks written since the last sync
+		// set if the segment failed to reopen after compaction, the
+		// segment is removed from the store and opened again by next access
+		err error
+
+		refs int   // protected by the store mutex
+		used int64 // protected by the store mutex
+	}
+)
+
+var (
+	// Make sure SegmentBlockStore implements BlockStore.
+	_ BlockStore = (*SegmentBlockStore)(nil)
+)
+
+// NewSegmentBlockStore - create a new segment block store.
+func NewSegmentBlockStore(rootDir string, roundRange int64) *SegmentBlockStore {
+	if roundRange <= 0 {
+		roundRange = DefaultSegmentRoundRange
+	}
+	return &SegmentBlockStore{
+		RootDirectory:         rootDir,
+		RoundRange:            roundRange,
+		blockMetadataProvider: datastore.GetEntityMetadata("block"),
+		compress:              true,
+		segments:              make(map[int64]*segment),
+	}
+}
+
+type blockRecord struct {
+	*block.Block
+}
+
+var (
+	// MakeSure blockRecord implements blockdb.Record interface.
+	_ blockdb.Record = (*blockRecord)(nil)
+)
+
+// GetKey is a part of blockdb.Record interface implementation.
+func (br *blockRecord) GetKey() blockdb.Key {
+	return blockdb.Key(br.Block.Hash)
+}
+
+// Encode is a part of blockdb.Record interface implementation.
+func (br *blockRecord) Encode(writer io.Writer) error {
+	return datastore.WriteJSON(writer, br.Block)
+}
+
+// Decode is a part of blockdb.Record interface implementation.
+func (br *blockRecord) Decode(reader io.Reader) error {
+	return datastore.ReadJSON(reader, br.Block)
+}
+
+type blockRecordProvider struct {
+	blockMetadataProvider datastore.EntityMetadata
+}
+
+func (brp *blockRecordProvider) NewRecord() blockdb.Record {
+	return &blockRecord{
+		Block: brp.blockMetadataProvider.Instance().(*block.Block),
+	}
+}
+
+func (sbs *SegmentBlockStore) segmentNumber(round int64) int64 {
+	return round / sbs.RoundRange
+}
+
+func (sbs *SegmentBlockStore) getSegmentFile(number int64) string {
+	return filepath.Join(sbs.RootDirectory, strconv.FormatInt(number, 10))
+}
+
+func (sbs *SegmentBlockStore) openSegment(file string) (blockdb.AppendDatabase, blockdb.Index, error) {
+	db, err := blockdb.NewBlockDB(file, 64, sbs.compress)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := blockdb.NewMapIndex()
+	db.SetIndex(index)
+	err = db.Append(&blockRecordProvider{blockMetadataProvider: sbs.blockMetadataProvider})
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, index, nil
+}
+
+// acquire returns the open segment of given number, the segment is released
+// by the release; a missing segment is created only if create is true
+func (sbs *SegmentBlockStore) acquire(number int64, create bool) (*segment, error) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+
+	s, ok := sbs.segments[number]
+	if !ok {
+		file := sbs.getSegmentFile(number)
+		if err := recoverSegment(file); err != nil {
+			return nil, err
+		}
+		if !create {
+			_, err := os.Stat(file + "." + blockdb.FileExtData)
+			if err != nil {
+				return nil, err
+			}
+		}
+		db, index, err := sbs.openSegment(file)
+		if err != nil {
+			return nil, err
+		}
+		s = &segment{number: number, db: db, index: index}
+		sbs.segments[number] = s
+		sbs.evict()
+	}
+	sbs.tick++
+	s.refs++
+	s.used = sbs.tick
+	return s, nil
+}
+
+func (sbs *SegmentBlockStore) release(s *segment) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	s.refs--
+}
+
+// evict closes the least recently used segments not in use
+func (sbs *SegmentBlockStore) evict() {
+	for len(sbs.segments) > maxOpenSegments {
+		var lru *segment
+		for _, s := range sbs.segments {
+			if s.refs == 0 && (lru == nil || s.used < lru.used) {
+				lru = s
+			}
+		}
+		if lru == nil {
+			return
+		}
+		if err := lru.close(); err != nil {
+			Logger.Error("segment block store - close segment",
+				zap.Int64("segment", lru.number), zap.Error(err))
+		}
+		delete(sbs.segments, lru.number)
+	}
+}
+
+func (s *segment) close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.dirty > 0 {
+		if err := s.db.Sync(); err != nil {
+			s.db.Close()
+			return err
+		}
+	}
+	return s.db.Close()
+}
+
+// Close - save the indexes and close all open segments.
+func (sbs *SegmentBlockStore) Close() (err error) {
+	sbs.mutex.Lock()
+	defer sbs.mutex.Unlock()
+	for number, s := range sbs.segments {
+		if cerr := s.close(); cerr != nil {
+			err = cerr
+		}
+		delete(sbs.segments, number)
+	}
+	return
+}
+
+// Write - append the block to the segment of its round
+func (sbs *SegmentBlockStore) Write(b *block.Block) error {
+	if len(b.Hash) != 64 {
+		return encryption.ErrInvalidHash
+	}
+	s, err := sbs.acquire(sbs.segmentNumber(b.Round), true)
+	if err != nil {
+		return err
+	}
+	defer sbs.release(s)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+
+	offset, err := s.index.GetOffset(blockdb.Key(b.Hash))
+	if err == blockdb.ErrKeyNotFound {
+		if err = s.db.WriteData(&blockRecord{Block: b}); err != nil {
+			return err
+		}
+		s.dirty++
+		offset, err = s.index.GetOffset(blockdb.Key(b.Hash))
+	}
+	if err != nil {
+		return err
+	}
+
+	// the magic block is also stored by its hash, the offset of the magic
+	// block hash can't be restored reading the data so the index is saved
+	if b.MagicBlock != nil && b.Round == b.MagicBlock.StartingRound &&
+		len(b.MagicBlock.Hash) == 64 {
+
+		mbKey := blockdb.Key(b.MagicBlock.Hash)
+		if _, err = s.index.GetOffset(mbKey); err == blockdb.ErrKeyNotFound {
+			s.index.SetOffset(mbKey, offset)
+			s.dirty = segmentSyncInterval
+		}
+	}
+	if s.dirty >= segmentSyncInterval {
+		if err = s.db.Sync(); err != nil {
+			return err
+		}
+		s.dirty = 0
+	}
+	return nil
+}
+
+// ReadWithBlockSummary - read the block given the block summary
+func (sbs *SegmentBlockStore) ReadWithBlockSummary(bs *block.BlockSummary) (*block.Block, error) {
+	return sbs.Read(bs.Hash, bs.Round)
+}
+
+// Read - read the block from the segment of given round
+func (sbs *SegmentBlockStore) Read(hash string, round int64) (*block.Block, error) {
+	if len(hash) != 64 {
+		return nil, encryption.ErrInvalidHash
+	}
+	s, err := sbs.acquire(sbs.segmentNumber(round), false)
+	if err != nil {
+		return nil, err
+	}
+	defer sbs.release(s)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	b := sbs.blockMetadataProvider.Instance().(*block.Block)
+	if err = s.db.Read(blockdb.Key(hash), &blockRecord{Block: b}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Delete - delete from the hash of the block
+func (sbs *SegmentBlockStore) Delete(hash string) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannote provide this interface")
+}
+
+// DeleteBlock - delete the given block rewriting its segment
+func (sbs *SegmentBlockStore) DeleteBlock(b *block.Block) error {
+	_, err := sbs.Compact(b.Round, func(sb *block.Block) bool {
+		return sb.Hash != b.Hash
+	})
+	return err
+}
+
+// Compact - rewrite the segment of given round keeping only the blocks accepted
+// by the keep function, usually the finalized ones. It returns number of removed
+// blocks, the segment isn't rewritten if all blocks are kept
+func (sbs *SegmentBlockStore) Compact(round int64, keep func(b *block.Block) bool) (removed int, err error) {
+	s, err := sbs.acquire(sbs.segmentNumber(round), false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		sbs.mutex.Lock()
+		defer sbs.mutex.Unlock()
+		s.refs--
+		if s.err != nil && sbs.segments[s.number] == s {
+			// will be opened again by next access
+			delete(sbs.segments, s.number)
+		}
+	}()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	// the keys sharing an offset are block and magic block hashes
+	var (
+		keys    = s.index.GetKeys()
+		offsets = make([]int64, 0, len(keys))
+		aliases = make(map[int64][]blockdb.Key, len(keys))
+	)
+	for _, key := range keys {
+		offset, err := s.index.GetOffset(key)
+		if err != nil {
+			return 0, err
+		}
+		if _, ok := aliases[offset]; !ok {
+			offsets = append(offsets, offset)
+		}
+		aliases[offset] = append(aliases[offset], key)
+	}
+
+	var (
+		file      = sbs.getSegmentFile(s.number)
+		cfile     = file + compactFileSuffix
+		cdb, cerr = blockdb.NewBlockDB(cfile, 64, sbs.compress)
+		cindex    = blockdb.NewMapIndex()
+	)
+	if cerr != nil {
+		return 0, cerr
+	}
+	cdb.SetIndex(cindex)
+	removeFiles(cfile)
+	if err = cdb.Create(); err != nil {
+		return 0, err
+	}
+	// the compacted files of a started replacement are moved on next open
+	var replaced bool
+	defer func() {
+		if (err != nil || removed == 0) && !replaced {
+			cdb.Close()
+			removeFiles(cfile)
+		}
+	}()
+
+	for _, offset := range offsets {
+		b := sbs.blockMetadataProvider.Instance().(*block.Block)
+		if err = s.db.Read(aliases[offset][0], &blockRecord{Block: b}); err != nil {
+			return 0, err
+		}
+		if !keep(b) {
+			removed++
+			continue
+		}
+		if err = cdb.WriteData(&blockRecord{Block: b}); err != nil {
+			return 0, err
+		}
+		coffset, err := cindex.GetOffset(blockdb.Key(b.Hash))
+		if err != nil {
+			return 0, err
+		}
+		for _, key := range aliases[offset] {
+			cindex.SetOffset(key, coffset)
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err = cdb.Sync(); err != nil {
+		return 0, err
+	}
+	if err = cdb.Close(); err != nil {
+		return 0, err
+	}
+
+	// the old segment is kept open until the compacted one is opened and
+	// is still used if its data file isn't replaced
+	replaced, err = replaceSegment(cfile, file)
+	if err == nil {
+		var (
+			db    blockdb.AppendDatabase
+			index blockdb.Index
+		)
+		if db, index, err = sbs.openSegment(file); err == nil {
+			s.db.Close()
+			s.db, s.index, s.dirty = db, index, 0
+			return removed, nil
+		}
+	}
+	if replaced {
+		s.db.Close()
+		s.err = err
+	}
+	return 0, err
+}
+
+// replaceSegment moves the compacted segment files over the segment ones,
+// replaced is true if the data file is moved. The commit marker is written
+// first, an interrupted replacement is completed by the recoverSegment, so
+// the data and the index files, keeping the magic block hashes, always match.
+func replaceSegment(from, to string) (replaced bool, err error) {
+	marker, err := os.Create(to + commitFileSuffix)
+	if err != nil {
+		return false, err
+	}
+	if err = marker.Close(); err != nil {
+		os.Remove(to + commitFileSuffix)
+		return false, err
+	}
+	err = os.Rename(from+"."+blockdb.FileExtData, to+"."+blockdb.FileExtData)
+	if err != nil {
+		os.Remove(to + commitFileSuffix)
+		return false, err
+	}
+	err = os.Rename(from+"."+blockdb.FileExtHeader, to+"."+blockdb.FileExtHeader)
+	if err != nil {
+		return true, err
+	}
+	return true, os.Remove(to + commitFileSuffix)
+}
+
+// recoverSegment completes replacement of the segment files interrupted
+// after its commit marker is written, otherwise it removes the compacted
+// files left by an interrupted compaction.
+func recoverSegment(file string) error {
+	var cfile = file + compactFileSuffix
+	if _, err := os.Stat(file + commitFileSuffix); os.IsNotExist(err) {
+		removeFiles(cfile)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, ext := range []string{blockdb.FileExtData, blockdb.FileExtHeader} {
+		err := os.Rename(cfile+"."+ext, file+"."+ext)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	Logger.Info("segment block store - compaction completed",
+		zap.String("segment", file))
+	return os.Remove(file + commitFileSuffix)
+}
+
+func removeFiles(file string) {
+	os.Remove(file + "." + blockdb.FileExtHeader)
+	os.Remove(file + "." + blockdb.FileExtData)
+}
+
+// CompactFinalized - compact the segments with all rounds finalized up to the
+// given round that are not compacted yet. The last compacted segment is saved
+// so every segment is compacted once. It returns number of removed blocks
+func (sbs *SegmentBlockStore) CompactFinalized(ctx context.Context, round int64, keep func(b *block.Block) bool) (removed int, err error) {
+	compactedFile := filepath.Join(sbs.RootDirectory, compactedFileName)
+	number := int64(-1)
+	if data, err := ioutil.ReadFile(compactedFile); err == nil {
+		number, err = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	} else if !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	for number++; (number+1)*sbs.RoundRange <= round; number++ {
+		select {
+		case <-ctx.Done():
+			return removed, ctx.Err()
+		default:
+		}
+		n, err := sbs.Compact(number*sbs.RoundRange, keep)
+		if err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed += n
+		err = ioutil.WriteFile(compactedFile, []byte(strconv.FormatInt(number, 10)), 0644)
+		if err != nil {
+			return removed, err
+		}
+		Logger.Info("segment block store - compacted", zap.Int64("segment", number),
+			zap.Int("removed", n))
+	}
+	return removed, nil
+}
+
+// ConvertFSBlockStore - append the blocks stored by the FSBlockStore under the
+// given root directory. The converted files are left as is to be removed once the
+// store is checked. It returns number of converted files
+func (sbs *SegmentBlockStore) ConvertFSBlockStore(ctx context.Context, rootDir string) (converted int, err error) {
+	segmentsDir, err := filepath.Abs(sbs.RootDirectory)
+	if err != nil {
+		return 0, err
+	}
+	err = filepath.Walk(rootDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if fi.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && abs == segmentsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, fileExt) {
+			return nil
+		}
+		b := sbs.blockMetadataProvider.Instance().(*block.Block)
+		if err := readBlockFile(path, b); err != nil {
+			return common.NewErrorf("convert_fs_block_store", "%v: %v", path, err)
+		}
+		if err = sbs.Write(b); err != nil {
+			return err
+		}
+		converted++
+		if converted%10000 == 0 {
+			Logger.Info("segment block store - converting",
+				zap.Int("converted", converted), zap.String("path", path))
+		}
+		return nil
+	})
+	if cerr := sbs.Close(); err == nil {
+		err = cerr
+	}
+	return converted, err
+}
+
+func (sbs *SegmentBlockStore) UploadToCloud(hash string, round int64) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannote provide this interface")
+}
+
+func (sbs *SegmentBlockStore) DownloadFromCloud(hash string, round int64) error {
+	return common.NewError("interface_not_implemented", "SegmentBlockStore cannote provide this interface")
+}
+
+func (sbs *SegmentBlockStore) CloudObjectExists(hash string) bool {
+	return false
+}
@@ -0,0 +1,238 @@
+package blockstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/encryption"
+	"0chain.net/sharder/blockdb"
+)
+
+func makeTestSegmentBlock(round int64, name string) *block.Block {
+	b := block.NewBlock("", round)
+	b.Hash = encryption.Hash(fmt.Sprintf("%s:%d", name, round))
+	return b
+}
+
+func TestSegmentBlockStore_WriteRead(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		sbs = NewSegmentBlockStore(dir, 10)
+		bs  []*block.Block
+	)
+	for r := int64(0); r < 25; r++ {
+		b := makeTestSegmentBlock(r, "block")
+		require.NoError(t, sbs.Write(b))
+		require.NoError(t, sbs.Write(b), "duplicate is ignored")
+		bs = append(bs, b)
+	}
+	mb := makeTestSegmentBlock(25, "block")
+	mb.MagicBlock = block.NewMagicBlock()
+	mb.MagicBlock.Hash = encryption.Hash("magic block")
+	mb.MagicBlock.StartingRound = 25
+	require.NoError(t, sbs.Write(mb))
+
+	var check = func(sbs *SegmentBlockStore) {
+		for _, b := range bs {
+			got, err := sbs.Read(b.Hash, b.Round)
+			require.NoError(t, err)
+			require.Equal(t, b.Hash, got.Hash)
+			require.Equal(t, b.Round, got.Round)
+		}
+		got, err := sbs.Read(mb.MagicBlock.Hash, 25)
+		require.NoError(t, err)
+		require.Equal(t, mb.Hash, got.Hash)
+
+		_, err = sbs.Read(bs[0].Hash, 11)
+		require.Equal(t, blockdb.ErrKeyNotFound, err)
+		_, err = sbs.Read(bs[0].Hash, 100)
+		require.True(t, os.IsNotExist(err))
+	}
+	check(sbs)
+
+	// indexes of not closed store are restored reading the segments
+	check(NewSegmentBlockStore(dir, 10))
+
+	require.NoError(t, sbs.Close())
+	check(NewSegmentBlockStore(dir, 10))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	require.Len(t, files, 6, "a data and an index file per segment")
+}
+
+func TestSegmentBlockStore_Compact(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		sbs       = NewSegmentBlockStore(dir, 10)
+		finalized = make(map[int64]string)
+		orphans   []*block.Block
+	)
+	for r := int64(0); r < 30; r++ {
+		b := makeTestSegmentBlock(r, "finalized")
+		require.NoError(t, sbs.Write(b))
+		finalized[r] = b.Hash
+		if r%3 == 0 {
+			o := makeTestSegmentBlock(r, "orphan")
+			require.NoError(t, sbs.Write(o))
+			orphans = append(orphans, o)
+		}
+	}
+	var keep = func(b *block.Block) bool {
+		return finalized[b.Round] == b.Hash
+	}
+
+	removed, err := sbs.CompactFinalized(context.Background(), 25, keep)
+	require.NoError(t, err)
+	require.Equal(t, 7, removed, "orphans of the first two segments")
+	for _, o := range orphans {
+		_, err := sbs.Read(o.Hash, o.Round)
+		if o.Round < 20 {
+			require.Equal(t, blockdb.ErrKeyNotFound, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+	for r, hash := range finalized {
+		_, err := sbs.Read(hash, r)
+		require.NoError(t, err)
+	}
+
+	// compacted segments are skipped
+	removed, err = sbs.CompactFinalized(context.Background(), 30, keep)
+	require.NoError(t, err)
+	require.Equal(t, 3, removed)
+
+	// appended after compaction
+	b := makeTestSegmentBlock(5, "late")
+	require.NoError(t, sbs.Write(b))
+	require.NoError(t, sbs.DeleteBlock(b))
+	_, err = sbs.Read(b.Hash, b.Round)
+	require.Equal(t, blockdb.ErrKeyNotFound, err)
+
+	require.NoError(t, sbs.Close())
+	sbs = NewSegmentBlockStore(dir, 10)
+	for r, hash := range finalized {
+		_, err := sbs.Read(hash, r)
+		require.NoError(t, err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+compactFileSuffix+"*"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func TestSegmentBlockStore_CompactReplaceFails(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		sbs = NewSegmentBlockStore(dir, 10)
+		b   = makeTestSegmentBlock(1, "kept")
+		o   = makeTestSegmentBlock(2, "orphan")
+	)
+	require.NoError(t, sbs.Write(b))
+	require.NoError(t, sbs.Write(o))
+
+	// the commit marker can't be written
+	marker := sbs.getSegmentFile(0) + commitFileSuffix
+	require.NoError(t, os.MkdirAll(filepath.Join(marker, "busy"), 0755))
+	require.Error(t, sbs.DeleteBlock(o))
+
+	// the old segment is still open
+	_, err := sbs.Read(b.Hash, b.Round)
+	require.NoError(t, err)
+	_, err = sbs.Read(o.Hash, o.Round)
+	require.NoError(t, err)
+	require.NoError(t, sbs.Write(makeTestSegmentBlock(3, "next")))
+
+	require.NoError(t, os.RemoveAll(marker))
+	require.NoError(t, sbs.DeleteBlock(o))
+	_, err = sbs.Read(o.Hash, o.Round)
+	require.Equal(t, blockdb.ErrKeyNotFound, err)
+	_, err = sbs.Read(b.Hash, b.Round)
+	require.NoError(t, err)
+}
+
+func TestSegmentBlockStore_CompactInterrupted(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		sbs = NewSegmentBlockStore(dir, 10)
+		b   = makeTestSegmentBlock(1, "kept")
+		o   = makeTestSegmentBlock(2, "orphan")
+	)
+	b.MagicBlock = block.NewMagicBlock()
+	b.MagicBlock.Hash = encryption.Hash("magic block")
+	b.MagicBlock.StartingRound = 1
+	require.NoError(t, sbs.Write(b))
+	require.NoError(t, sbs.Write(o))
+
+	// the index file can't be replaced after the data file is
+	header := sbs.getSegmentFile(0) + "." + blockdb.FileExtHeader
+	require.NoError(t, os.Remove(header))
+	require.NoError(t, os.MkdirAll(filepath.Join(header, "busy"), 0755))
+	removed, err := sbs.Compact(1, func(sb *block.Block) bool {
+		return sb.Hash != o.Hash
+	})
+	require.Error(t, err)
+	require.Zero(t, removed)
+	_, err = sbs.Read(b.Hash, b.Round)
+	require.Error(t, err, "the segment failed")
+
+	// the replacement is completed on next open
+	require.NoError(t, os.RemoveAll(header))
+	for _, sbs := range []*SegmentBlockStore{sbs, NewSegmentBlockStore(dir, 10)} {
+		got, err := sbs.Read(b.MagicBlock.Hash, b.Round)
+		require.NoError(t, err, "magic block hash is kept")
+		require.Equal(t, b.Hash, got.Hash)
+		_, err = sbs.Read(o.Hash, o.Round)
+		require.Equal(t, blockdb.ErrKeyNotFound, err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.com*"))
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// a block stored by its magic block hash too is one removed block
+	removed, err = sbs.Compact(1, func(*block.Block) bool { return false })
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+}
+
+func TestSegmentBlockStore_ConvertFSBlockStore(t *testing.T) {
+	var (
+		fsDir = t.TempDir()
+		fbs   = NewFSBlockStore(fsDir, &minioClientMock{})
+		sbs   = NewSegmentBlockStore(filepath.Join(fsDir, "segments"), 10)
+		bs    []*block.Block
+	)
+	for r := int64(1); r <= 15; r++ {
+		b := makeTestSegmentBlock(r, "block")
+		require.NoError(t, fbs.Write(b))
+		bs = append(bs, b)
+	}
+
+	converted, err := sbs.ConvertFSBlockStore(context.Background(), fsDir)
+	require.NoError(t, err)
+	require.Equal(t, len(bs), converted)
+	for _, b := range bs {
+		got, err := sbs.Read(b.Hash, b.Round)
+		require.NoError(t, err)
+		require.Equal(t, b.Hash, got.Hash)
+	}
+
+	// converted again skipping the stored blocks and the segments directory
+	converted, err = sbs.ConvertFSBlockStore(context.Background(), fsDir)
+	require.NoError(t, err)
+	require.Equal(t, len(bs), converted)
+	fi, err := os.Stat(sbs.getSegmentFile(0) + "." + blockdb.FileExtData)
+	require.NoError(t, err)
+	size := fi.Size()
+	_, err = sbs.ConvertFSBlockStore(context.Background(), fsDir)
+	require.NoError(t, err)
+	fi, err = os.Stat(sbs.getSegmentFile(0) + "." + blockdb.FileExtData)
+	require.NoError(t, err)
+	require.Equal(t, size, fi.Size())
+}
@@ -0,0 +1,452 @@
+package blockstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/encryption"
+	. "0chain.net/core/logging"
+)
+
+const (
+	warmFileExt = ".dat.zst"
+	// warm blocks are written once and rarely read
+	warmCompressionLevel = 19
+
+	// DefaultRestoredCacheSize is default number of restored cold blocks.
+	DefaultRestoredCacheSize = 100
+	// DefaultMaxKept is default number of blocks kept hotter than their age.
+	DefaultMaxKept = 10000
+)
+
+// Tier - a storage tier of a block.
+type Tier int
+
+// The storage tiers from the fastest to the cheapest.
+const (
+	TierHot  Tier = iota // the FSBlockStore file
+	TierWarm             // the local file compressed better
+	TierCold             // the object store object
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierHot:
+		return "hot"
+	case TierWarm:
+		return "warm"
+	case TierCold:
+		return "cold"
+	}
+	return "unknown"
+}
+
+// TieringPolicy - the rules to move blocks to the cheaper tiers.
+type TieringPolicy struct {
+	// WarmRounds is age in rounds to move a block to the warm tier, 0 disables.
+	WarmRounds int64
+	// ColdRounds is age in rounds to move a block to the cold tier, 0 disables.
+	ColdRounds int64
+	// HotAccesses is number of reads in the access window keeping a block one
+	// tier hotter than its age, 0 disables.
+	HotAccesses int64
+	// AccessWindow is the period the reads are counted for.
+	AccessWindow time.Duration
+	// MaxKept is max number of blocks kept hotter than their age, blocks
+	// above it are moved by their age regardless of reads.
+	MaxKept int
+}
+
+// Tier returns the tier of a block of given age in rounds and number of reads
+// in the current access window.
+func (tp *TieringPolicy) Tier(age int64, accesses int64) Tier {
+	var tier = TierHot
+	switch {
+	case tp.ColdRounds > 0 && age >= tp.ColdRounds:
+		tier = TierCold
+	case tp.WarmRounds > 0 && age >= tp.WarmRounds:
+		tier = TierWarm
+	}
+	if tier > TierHot && tp.HotAccesses > 0 && accesses >= tp.HotAccesses {
+		tier--
+	}
+	return tier
+}
+
+// Coldest returns the coldest tier blocks are moved to.
+func (tp *TieringPolicy) Coldest() Tier {
+	switch {
+	case tp.ColdRounds > 0:
+		return TierCold
+	case tp.WarmRounds > 0:
+		return TierWarm
+	}
+	return TierHot
+}
+
+// MinRounds returns the age in rounds of the blocks to be moved.
+func (tp *TieringPolicy) MinRounds() int64 {
+	if tp.WarmRounds > 0 && (tp.ColdRounds == 0 || tp.WarmRounds < tp.ColdRounds) {
+		return tp.WarmRounds
+	}
+	return tp.ColdRounds
+}
+
+// TieredBlockStore - a block store moving blocks from the FSBlockStore files
+// to the warm local files and the cold object store by the tiering policy.
+// The cold blocks are restored on read and the recently restored are kept.
+type TieredBlockStore struct {
+	*FSBlockStore
+	WarmDirectory    string
+	RestoreDirectory string
+	Cold             ObjectStore
+	Policy           TieringPolicy
+
+	compDe   common.CompDe
+	restored *lru.Cache // block hash -> restored file
+
+	accessMutex sync.Mutex
+	accesses    map[string]int64
+	windowStart time.Time
+	kept        map[string]int64 // blocks kept hotter than their age, hash -> round
+
+	restoreMutex sync.Mutex
+	restoring    map[string]*restoreCall // cold blocks being restored by hash
+}
+
+// restoreCall is a restore of a cold block shared by concurrent reads.
+type restoreCall struct {
+	done chan struct{}
+	err  error
+}
+
+var (
+	// Make sure TieredBlockStore implements BlockStore.
+	_ BlockStore = (*TieredBlockStore)(nil)
+)
+
+// NewTieredBlockStore - create a new tiered block store of the hot FSBlockStore.
+// The previously restored cold blocks are removed.
+func NewTieredBlockStore(fsbs *FSBlockStore, warmDir, restoreDir string, cold ObjectStore,
+	policy TieringPolicy, restoredCacheSize int) (*TieredBlockStore, error) {
+
+	if restoredCacheSize <= 0 {
+		restoredCacheSize = DefaultRestoredCacheSize
+	}
+	if policy.MaxKept <= 0 {
+		policy.MaxKept = DefaultMaxKept
+	}
+	if err := os.RemoveAll(restoreDir); err != nil {
+		return nil, err
+	}
+	restored, err := lru.NewWithEvict(restoredCacheSize, func(_, file interface{}) {
+		os.Remove(file.(string))
+	})
+	if err != nil {
+		return nil, err
+	}
+	zcompde := common.NewZStdCompDe()
+	zcompde.SetLevel(warmCompressionLevel)
+	return &TieredBlockStore{
+		FSBlockStore:     fsbs,
+		WarmDirectory:    warmDir,
+		RestoreDirectory: restoreDir,
+		Cold:             cold,
+		Policy:           policy,
+		compDe:           zcompde,
+		restored:         restored,
+		accesses:         make(map[string]int64),
+		windowStart:      time.Now(),
+		kept:             make(map[string]int64),
+		restoring:        make(map[string]*restoreCall),
+	}, nil
+}
+
+func (tbs *TieredBlockStore) getWarmFileName(hash string, round int64) string {
+	return filepath.Join(tbs.WarmDirectory,
+		strconv.FormatInt(round/chain.GetServerChain().RoundRange, 10),
+		hash+warmFileExt)
+}
+
+func (tbs *TieredBlockStore) getRestoredFileName(hash string) string {
+	return filepath.Join(tbs.RestoreDirectory, hash+fileExt)
+}
+
+// access counts a read of the block returning number of reads in the window
+func (tbs *TieredBlockStore) access(hash string) int64 {
+	tbs.accessMutex.Lock()
+	defer tbs.accessMutex.Unlock()
+	if tbs.Policy.AccessWindow > 0 && time.Since(tbs.windowStart) >= tbs.Policy.AccessWindow {
+		tbs.accesses = make(map[string]int64)
+		tbs.windowStart = time.Now()
+	}
+	tbs.accesses[hash]++
+	return tbs.accesses[hash]
+}
+
+func (tbs *TieredBlockStore) getAccesses(hash string) int64 {
+	tbs.accessMutex.Lock()
+	defer tbs.accessMutex.Unlock()
+	return tbs.accesses[hash]
+}
+
+// GetTier returns the local tier of the block, blocks missing locally are
+// considered cold.
+func (tbs *TieredBlockStore) GetTier(hash string, round int64) Tier {
+	if _, err := os.Stat(tbs.getFileName(hash, round)); err == nil {
+		return TierHot
+	}
+	if _, err := os.Stat(tbs.getWarmFileName(hash, round)); err == nil {
+		return TierWarm
+	}
+	return TierCold
+}
+
+// ReadWithBlockSummary - read the block given the block summary
+func (tbs *TieredBlockStore) ReadWithBlockSummary(bs *block.BlockSummary) (*block.Block, error) {
+	return tbs.Read(bs.Hash, bs.Round)
+}
+
+// Read - read the block from its tier, a cold block is restored
+func (tbs *TieredBlockStore) Read(hash string, round int64) (*block.Block, error) {
+	if len(hash) != 64 {
+		return nil, encryption.ErrInvalidHash
+	}
+	tbs.access(hash)
+
+	// the tiers are checked from the hot one as blocks are moved only to
+	// the colder tiers and removed from the previous tier after that
+	b := tbs.blockMetadataProvider.Instance().(*block.Block)
+	err := readBlockFile(tbs.getFileName(hash, round), b)
+	if !os.IsNotExist(err) {
+		return b, err
+	}
+	err = tbs.readWarm(hash, round, b)
+	if !os.IsNotExist(err) {
+		return b, err
+	}
+	if file, ok := tbs.restored.Get(hash); ok {
+		err = readBlockFile(file.(string), b)
+		if !os.IsNotExist(err) {
+			return b, err
+		}
+	}
+	return tbs.restore(hash)
+}
+
+func (tbs *TieredBlockStore) readWarm(hash string, round int64, b *block.Block) error {
+	data, err := ioutil.ReadFile(tbs.getWarmFileName(hash, round))
+	if err != nil {
+		return err
+	}
+	if data, err = tbs.compDe.Decompress(data); err != nil {
+		return err
+	}
+	return datastore.ReadJSON(bytes.NewReader(data), b)
+}
+
+// restore downloads the cold block keeping it in the restored blocks.
+// Concurrent reads of the same cold block share single download.
+func (tbs *TieredBlockStore) restore(hash string) (*block.Block, error) {
+	file := tbs.getRestoredFileName(hash)
+
+	tbs.restoreMutex.Lock()
+	call, ok := tbs.restoring[hash]
+	if ok || tbs.restored.Contains(hash) {
+		tbs.restoreMutex.Unlock()
+		if ok {
+			<-call.done
+			if call.err != nil {
+				return nil, call.err
+			}
+		}
+		b := tbs.blockMetadataProvider.Instance().(*block.Block)
+		if err := readBlockFile(file, b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	call = &restoreCall{done: make(chan struct{})}
+	tbs.restoring[hash] = call
+	tbs.restoreMutex.Unlock()
+
+	b, err := tbs.download(hash, file)
+
+	tbs.restoreMutex.Lock()
+	delete(tbs.restoring, hash)
+	tbs.restoreMutex.Unlock()
+	call.err = err
+	close(call.done)
+	return b, err
+}
+
+func (tbs *TieredBlockStore) download(hash, file string) (*block.Block, error) {
+	if err := tbs.Cold.Get(hash, file); err != nil {
+		return nil, err
+	}
+	b := tbs.blockMetadataProvider.Instance().(*block.Block)
+	if err := readBlockFile(file, b); err != nil {
+		os.Remove(file)
+		return nil, err
+	}
+	tbs.restored.Add(hash, file)
+	Logger.Info("tiered block store - restored cold block",
+		zap.String("block", hash), zap.Int64("round", b.Round))
+	return b, nil
+}
+
+// DeleteBlock - delete the local copies of the given block
+func (tbs *TieredBlockStore) DeleteBlock(b *block.Block) error {
+	tbs.restored.Remove(b.Hash)
+	tbs.accessMutex.Lock()
+	delete(tbs.kept, b.Hash)
+	delete(tbs.accesses, b.Hash)
+	tbs.accessMutex.Unlock()
+	for _, file := range []string{
+		tbs.getFileName(b.Hash, b.Round),
+		tbs.getWarmFileName(b.Hash, b.Round),
+	} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply moves the block to the tier of the policy for its age and reads,
+// blocks are never moved to a hotter tier. It returns the tiers of the block
+// before and after. At most Policy.MaxKept blocks are kept hotter than their
+// age.
+func (tbs *TieredBlockStore) Apply(hash string, round, currentRound int64) (from, to Tier, err error) {
+	var (
+		age    = currentRound - round
+		acc    = tbs.getAccesses(hash)
+		forAge = tbs.Policy.Tier(age, 0)
+	)
+	from, to = tbs.GetTier(hash, round), tbs.Policy.Tier(age, acc)
+	tbs.accessMutex.Lock()
+	_, isKept := tbs.kept[hash]
+	switch {
+	case to < forAge && (isKept || len(tbs.kept) < tbs.Policy.MaxKept):
+		tbs.kept[hash] = round
+	case to < forAge:
+		to = forAge // no room to keep it hotter
+	default:
+		delete(tbs.kept, hash)
+	}
+	tbs.accessMutex.Unlock()
+	if to <= from {
+		return from, from, nil
+	}
+
+	switch to {
+	case TierWarm:
+		err = tbs.moveToWarm(hash, round)
+	case TierCold:
+		err = tbs.UploadToCloud(hash, round)
+	}
+	if err != nil {
+		return from, from, err
+	}
+	return from, to, nil
+}
+
+// ApplyKept applies the policy to the blocks previously kept hotter than
+// their age because of reads.
+func (tbs *TieredBlockStore) ApplyKept(currentRound int64) (moved int, err error) {
+	tbs.accessMutex.Lock()
+	kept := make(map[string]int64, len(tbs.kept))
+	for hash, round := range tbs.kept {
+		kept[hash] = round
+	}
+	tbs.accessMutex.Unlock()
+
+	for hash, round := range kept {
+		from, to, err := tbs.Apply(hash, round, currentRound)
+		if err != nil {
+			return moved, err
+		}
+		if to != from {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+func (tbs *TieredBlockStore) moveToWarm(hash string, round int64) error {
+	var (
+		hot = tbs.getFileName(hash, round)
+		b   = tbs.blockMetadataProvider.Instance().(*block.Block)
+	)
+	if err := readBlockFile(hot, b); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := datastore.WriteJSON(&buf, b); err != nil {
+		return err
+	}
+
+	warm := tbs.getWarmFileName(hash, round)
+	if err := os.MkdirAll(filepath.Dir(warm), 0755); err != nil {
+		return err
+	}
+	tmp := warm + ".tmp"
+	if err := ioutil.WriteFile(tmp, tbs.compDe.Compress(buf.Bytes()), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, warm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(hot)
+}
+
+// UploadToCloud - move the block to the cold object store
+func (tbs *TieredBlockStore) UploadToCloud(hash string, round int64) error {
+	var (
+		hot  = tbs.getFileName(hash, round)
+		warm = tbs.getWarmFileName(hash, round)
+	)
+	// the cold objects are the FSBlockStore files
+	if _, err := os.Stat(hot); os.IsNotExist(err) {
+		b := tbs.blockMetadataProvider.Instance().(*block.Block)
+		if err = tbs.readWarm(hash, round, b); err != nil {
+			return err
+		}
+		if err = tbs.write(hash, round, b); err != nil {
+			return err
+		}
+	}
+	if err := tbs.Cold.Put(hash, hot); err != nil {
+		return err
+	}
+	if err := os.Remove(hot); err != nil {
+		return err
+	}
+	if err := os.Remove(warm); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DownloadFromCloud - move the block from the cold object store to the hot tier
+func (tbs *TieredBlockStore) DownloadFromCloud(hash string, round int64) error {
+	return tbs.Cold.Get(hash, tbs.getFileName(hash, round))
+}
+
+// CloudObjectExists - check the block is in the cold object store
+func (tbs *TieredBlockStore) CloudObjectExists(hash string) bool {
+	return tbs.Cold.Exists(hash)
+}
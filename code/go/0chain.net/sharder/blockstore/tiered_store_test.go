@@ -0,0 +1,209 @@
+package blockstore
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+)
+
+func makeTestTieredBlockStore(t *testing.T, policy TieringPolicy,
+	restored int) *TieredBlockStore {
+
+	dir := t.TempDir()
+	tbs, err := NewTieredBlockStore(
+		NewFSBlockStore(filepath.Join(dir, "hot"), &minioClientMock{}),
+		filepath.Join(dir, "warm"), filepath.Join(dir, "restored"),
+		NewDirObjectStore(filepath.Join(dir, "cold")), policy, restored)
+	require.NoError(t, err)
+	return tbs
+}
+
+func TestTieringPolicy_Tier(t *testing.T) {
+	var policy = TieringPolicy{WarmRounds: 10, ColdRounds: 100, HotAccesses: 5}
+	for _, tt := range []struct {
+		age, accesses int64
+		want          Tier
+	}{
+		{age: 0, want: TierHot},
+		{age: 10, want: TierWarm},
+		{age: 99, accesses: 4, want: TierWarm},
+		{age: 99, accesses: 5, want: TierHot},
+		{age: 100, want: TierCold},
+		{age: 1000, accesses: 5, want: TierWarm},
+	} {
+		require.Equal(t, tt.want, policy.Tier(tt.age, tt.accesses), "%+v", tt)
+	}
+	require.Equal(t, TierCold, policy.Coldest())
+	require.EqualValues(t, 10, policy.MinRounds())
+
+	policy = TieringPolicy{ColdRounds: 100}
+	require.Equal(t, TierHot, policy.Tier(99, 0))
+	require.Equal(t, TierCold, policy.Tier(100, 0))
+	require.EqualValues(t, 100, policy.MinRounds())
+	require.Equal(t, TierHot, (&TieringPolicy{}).Coldest())
+}
+
+func TestTieredBlockStore_Apply(t *testing.T) {
+	var (
+		tbs = makeTestTieredBlockStore(t, TieringPolicy{WarmRounds: 10,
+			ColdRounds: 100}, 0)
+		b = makeTestSegmentBlock(5, "block")
+	)
+	require.NoError(t, tbs.Write(b))
+
+	var check = func(want Tier) {
+		require.Equal(t, want, tbs.GetTier(b.Hash, b.Round))
+		got, err := tbs.Read(b.Hash, b.Round)
+		require.NoError(t, err)
+		require.Equal(t, b.Hash, got.Hash)
+	}
+
+	from, to, err := tbs.Apply(b.Hash, b.Round, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Tier{TierHot, TierHot}, []Tier{from, to})
+	check(TierHot)
+
+	from, to, err = tbs.Apply(b.Hash, b.Round, 15)
+	require.NoError(t, err)
+	require.Equal(t, []Tier{TierHot, TierWarm}, []Tier{from, to})
+	check(TierWarm)
+
+	from, to, err = tbs.Apply(b.Hash, b.Round, 105)
+	require.NoError(t, err)
+	require.Equal(t, []Tier{TierWarm, TierCold}, []Tier{from, to})
+	require.True(t, tbs.CloudObjectExists(b.Hash))
+	check(TierCold)
+	require.True(t, tbs.restored.Contains(b.Hash))
+
+	// blocks are never moved to the hotter tier by the policy
+	from, to, err = tbs.Apply(b.Hash, b.Round, 15)
+	require.NoError(t, err)
+	require.Equal(t, []Tier{TierCold, TierCold}, []Tier{from, to})
+
+	require.NoError(t, tbs.DownloadFromCloud(b.Hash, b.Round))
+	require.Equal(t, TierHot, tbs.GetTier(b.Hash, b.Round))
+}
+
+func TestTieredBlockStore_KeptByAccesses(t *testing.T) {
+	var (
+		tbs = makeTestTieredBlockStore(t, TieringPolicy{WarmRounds: 10,
+			HotAccesses: 2, AccessWindow: time.Hour}, 0)
+		b = makeTestSegmentBlock(5, "block")
+	)
+	require.NoError(t, tbs.Write(b))
+	for i := 0; i < 2; i++ {
+		_, err := tbs.Read(b.Hash, b.Round)
+		require.NoError(t, err)
+	}
+
+	_, to, err := tbs.Apply(b.Hash, b.Round, 20)
+	require.NoError(t, err)
+	require.Equal(t, TierHot, to)
+	require.Contains(t, tbs.kept, b.Hash)
+
+	// the new access window
+	tbs.windowStart = time.Now().Add(-time.Hour)
+	tbs.access("other")
+	moved, err := tbs.ApplyKept(20)
+	require.NoError(t, err)
+	require.Equal(t, 1, moved)
+	require.Equal(t, TierWarm, tbs.GetTier(b.Hash, b.Round))
+	require.Empty(t, tbs.kept)
+}
+
+func TestTieredBlockStore_RestoredLRU(t *testing.T) {
+	var (
+		tbs = makeTestTieredBlockStore(t, TieringPolicy{ColdRounds: 10}, 2)
+		bs  []*block.Block
+	)
+	for r := int64(1); r <= 3; r++ {
+		b := makeTestSegmentBlock(r, "block")
+		require.NoError(t, tbs.Write(b))
+		require.NoError(t, tbs.UploadToCloud(b.Hash, b.Round))
+		bs = append(bs, b)
+	}
+	for _, b := range bs {
+		got, err := tbs.Read(b.Hash, b.Round)
+		require.NoError(t, err)
+		require.Equal(t, b.Hash, got.Hash)
+	}
+	require.Equal(t, 2, tbs.restored.Len())
+	_, err := os.Stat(tbs.getRestoredFileName(bs[0].Hash))
+	require.True(t, os.IsNotExist(err), "evicted restored block is removed")
+	_, err = os.Stat(tbs.getRestoredFileName(bs[2].Hash))
+	require.NoError(t, err)
+
+	require.NoError(t, tbs.DeleteBlock(bs[2]))
+	_, err = os.Stat(tbs.getRestoredFileName(bs[2].Hash))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestTieredBlockStore_MaxKept(t *testing.T) {
+	var (
+		tbs = makeTestTieredBlockStore(t, TieringPolicy{WarmRounds: 10,
+			HotAccesses: 1, AccessWindow: time.Hour, MaxKept: 1}, 0)
+		b1 = makeTestSegmentBlock(5, "block")
+		b2 = makeTestSegmentBlock(6, "block")
+	)
+	for _, b := range []*block.Block{b1, b2} {
+		require.NoError(t, tbs.Write(b))
+		_, err := tbs.Read(b.Hash, b.Round)
+		require.NoError(t, err)
+	}
+
+	_, to, err := tbs.Apply(b1.Hash, b1.Round, 20)
+	require.NoError(t, err)
+	require.Equal(t, TierHot, to)
+	_, to, err = tbs.Apply(b2.Hash, b2.Round, 20)
+	require.NoError(t, err)
+	require.Equal(t, TierWarm, to, "no room to keep the block hotter")
+	require.Len(t, tbs.kept, 1)
+
+	require.NoError(t, tbs.DeleteBlock(b1))
+	require.Empty(t, tbs.kept)
+}
+
+func TestTieredBlockStore_ConcurrentRestore(t *testing.T) {
+	var (
+		tbs = makeTestTieredBlockStore(t, TieringPolicy{ColdRounds: 10}, 0)
+		b   = makeTestSegmentBlock(1, "block")
+	)
+	require.NoError(t, tbs.Write(b))
+	require.NoError(t, tbs.UploadToCloud(b.Hash, b.Round))
+
+	var (
+		cold = &countingObjectStore{ObjectStore: tbs.Cold}
+		wg   sync.WaitGroup
+	)
+	tbs.Cold = cold
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := tbs.Read(b.Hash, b.Round)
+			require.NoError(t, err)
+			require.Equal(t, b.Hash, got.Hash)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, atomic.LoadInt32(&cold.gets), "shared download")
+}
+
+// countingObjectStore counts downloads of the objects.
+type countingObjectStore struct {
+	ObjectStore
+	gets int32
+}
+
+func (cos *countingObjectStore) Get(key, file string) error {
+	atomic.AddInt32(&cos.gets, 1)
+	time.Sleep(10 * time.Millisecond)
+	return cos.ObjectStore.Get(key, file)
+}
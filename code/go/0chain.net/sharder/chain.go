@@ -63,6 +63,10 @@ type Chain struct {
 	SharderStats   Stats
 	BlockSyncStats *SyncStats
 	TieringStats   *MinioStats
+	// ReplicationAudit is nil if the audit is disabled.
+	ReplicationAudit *ReplicationAudit
+	// finality wakes up transaction confirmation waiters
+	finality finalityNotifier
 }
 
 /*GetBlockChannel - get the block channel where the incoming blocks from the network are put into for further processing */
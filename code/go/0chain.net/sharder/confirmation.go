@@ -0,0 +1,377 @@
+package sharder
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+const (
+	// DefaultConfirmationWait is the default long-poll timeout of a
+	// transaction confirmation request waiting for a finality level.
+	DefaultConfirmationWait = 10 * time.Second
+	// MaxConfirmationWait is the max long-poll timeout, it's below the
+	// write timeout of the sharder HTTP server.
+	MaxConfirmationWait = 25 * time.Second
+	// notarizedTxnSearchRounds is number of rounds below the latest
+	// finalized one notarized blocks are searched for a transaction.
+	notarizedTxnSearchRounds = 10
+)
+
+// finalityNotifier wakes up goroutines waiting for a transaction to be
+// notarized or finalized. Waiters are keyed by hash of the transaction until
+// it's seen in a block, and by round of the block then. Thus, a block wakes
+// up only waiters of its transactions and of its or previous rounds. A key
+// is removed once its block is notified or its last waiter gives up, hashes
+// of transactions never put in a block aren't kept. The zero value is ready
+// to use.
+type finalityNotifier struct {
+	mutex  sync.Mutex
+	txns   map[string]*finalityWaiters
+	rounds map[int64]*finalityWaiters
+}
+
+// finalityWaiters is the channel of a key and number of its waiters.
+type finalityWaiters struct {
+	ch      chan struct{}
+	waiters int
+}
+
+// wait returns channel closed once a block of given round or above is
+// notified, or, for not positive round, a block with given transaction. The
+// release function must be called when the waiter stops waiting.
+func (fn *finalityNotifier) wait(hash string, round int64) (
+	wake <-chan struct{}, release func()) {
+
+	fn.mutex.Lock()
+	defer fn.mutex.Unlock()
+
+	var fw *finalityWaiters
+	if round > 0 {
+		if fn.rounds == nil {
+			fn.rounds = make(map[int64]*finalityWaiters)
+		}
+		if fw = fn.rounds[round]; fw == nil {
+			fw = &finalityWaiters{ch: make(chan struct{})}
+			fn.rounds[round] = fw
+		}
+	} else {
+		if fn.txns == nil {
+			fn.txns = make(map[string]*finalityWaiters)
+		}
+		if fw = fn.txns[hash]; fw == nil {
+			fw = &finalityWaiters{ch: make(chan struct{})}
+			fn.txns[hash] = fw
+		}
+	}
+	fw.waiters++
+
+	var released bool
+	return fw.ch, func() {
+		fn.mutex.Lock()
+		defer fn.mutex.Unlock()
+		if released {
+			return
+		}
+		released = true
+		if fw.waiters--; fw.waiters > 0 {
+			return
+		}
+		// the key may be notified and waited again already
+		if round > 0 {
+			if fn.rounds[round] == fw {
+				delete(fn.rounds, round)
+			}
+		} else if fn.txns[hash] == fw {
+			delete(fn.txns, hash)
+		}
+	}
+}
+
+// notify wakes up waiters related to given notarized or finalized block.
+func (fn *finalityNotifier) notify(b *block.Block) {
+	fn.mutex.Lock()
+	defer fn.mutex.Unlock()
+	for round, fw := range fn.rounds {
+		if round <= b.Round {
+			close(fw.ch)
+			delete(fn.rounds, round)
+		}
+	}
+	if len(fn.txns) == 0 {
+		return
+	}
+	for _, txn := range b.Txns {
+		if fw, ok := fn.txns[txn.Hash]; ok {
+			close(fw.ch)
+			delete(fn.txns, txn.Hash)
+		}
+	}
+}
+
+// finalityRank of the finality level, zero for unknown one.
+func finalityRank(finality string) int {
+	switch finality {
+	case transaction.FinalityNotarized:
+		return 1
+	case transaction.FinalityFinalized:
+		return 2
+	case transaction.FinalityDeterministic:
+		return 3
+	}
+	return 0
+}
+
+// setConfirmationFinality sets finality and depth of the confirmation of a
+// transaction of a block on the finalized chain.
+func (sc *Chain) setConfirmationFinality(ctx context.Context,
+	confirmation *transaction.Confirmation) {
+
+	var lfb = sc.GetLatestFinalizedBlock()
+	if lfb == nil || confirmation.Round > lfb.Round {
+		confirmation.Finality = transaction.FinalityNotarized
+		confirmation.Depth = 0
+		return
+	}
+	confirmation.Finality = transaction.FinalityFinalized
+	confirmation.Depth = lfb.Round - confirmation.Round
+
+	if ldb := sc.LatestDeterministicBlock; ldb != nil &&
+		confirmation.Round <= ldb.Round {
+		confirmation.Finality = transaction.FinalityDeterministic
+		return
+	}
+	if b, err := sc.GetBlock(ctx, confirmation.BlockHash); err == nil &&
+		sc.IsFinalizedDeterministically(b) {
+		confirmation.Finality = transaction.FinalityDeterministic
+	}
+}
+
+// newConfirmationBlockHeader returns header of the block with its
+// verification tickets, the merkle roots are the ones of the block.
+func newConfirmationBlockHeader(b *block.Block, merkleTreeRoot,
+	receiptMerkleTreeRoot string) *transaction.BlockHeader {
+
+	var header = &transaction.BlockHeader{
+		Hash:                  b.Hash,
+		MinerID:               b.MinerID,
+		PrevHash:              b.PrevHash,
+		CreationDate:          b.CreationDate,
+		Round:                 b.Round,
+		RoundRandomSeed:       b.GetRoundRandomSeed(),
+		MerkleTreeRoot:        merkleTreeRoot,
+		ReceiptMerkleTreeRoot: receiptMerkleTreeRoot,
+	}
+	if b.MagicBlock != nil {
+		header.MagicBlockHash = b.MagicBlock.Hash
+		if header.MagicBlockHash == "" {
+			header.MagicBlockHash = b.MagicBlock.GetHash()
+		}
+	}
+	var tickets = b.GetVerificationTickets()
+	header.VerificationTickets = make([]*transaction.BlockTicket, 0,
+		len(tickets))
+	for _, vt := range tickets {
+		header.VerificationTickets = append(header.VerificationTickets,
+			&transaction.BlockTicket{
+				VerifierID: vt.VerifierID,
+				Signature:  vt.Signature,
+			})
+	}
+	return header
+}
+
+// getNotarizedTxnConfirmation returns confirmation of a transaction of a
+// notarized block kept in memory, nil if there is no such block. For a
+// finalized round only the finalized block is considered, the confirmation
+// is dropped if the finalized block doesn't have the transaction.
+func (sc *Chain) getNotarizedTxnConfirmation(ctx context.Context,
+	hash string, proof bool) *transaction.Confirmation {
+
+	var (
+		lfb  = sc.GetLatestFinalizedBlock()
+		from = int64(1)
+		to   = sc.GetCurrentRound()
+	)
+	if lfb != nil && lfb.Round-notarizedTxnSearchRounds > from {
+		from = lfb.Round - notarizedTxnSearchRounds
+	}
+
+	for rNum := to; rNum >= from; rNum-- {
+		var r = sc.GetRound(rNum)
+		if r == nil {
+			continue
+		}
+		var blocks []*block.Block
+		for _, b := range r.GetNotarizedBlocks() {
+			if b.GetTransaction(hash) != nil {
+				blocks = append(blocks, b)
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if lfb == nil || rNum > lfb.Round {
+			var confirmation = newNotarizedTxnConfirmation(hash, blocks[0], proof)
+			confirmation.Finality = transaction.FinalityNotarized
+			return confirmation
+		}
+
+		fhash, err := sc.GetBlockHash(ctx, rNum)
+		if err != nil {
+			return nil // unknown finalized block of the round
+		}
+		var fb *block.Block
+		for _, b := range blocks {
+			if b.Hash == fhash {
+				fb = b
+				break
+			}
+		}
+		if fb == nil {
+			if b, err := sc.GetBlock(ctx, fhash); err == nil &&
+				b.GetTransaction(hash) != nil {
+				fb = b
+			}
+		}
+		if fb == nil {
+			var confirmation = newNotarizedTxnConfirmation(hash, blocks[0], proof)
+			confirmation.Finality = transaction.FinalityDropped
+			return confirmation
+		}
+		// finalized, but not stored yet
+		var confirmation = newNotarizedTxnConfirmation(hash, fb, proof)
+		sc.setConfirmationFinality(ctx, confirmation)
+		return confirmation
+	}
+	return nil
+}
+
+// newNotarizedTxnConfirmation returns confirmation of a transaction of
+// given block.
+func newNotarizedTxnConfirmation(hash string, b *block.Block,
+	proof bool) *transaction.Confirmation {
+
+	var confirmation = datastore.GetEntityMetadata("txn_confirmation").
+		Instance().(*transaction.Confirmation)
+	confirmation.Hash = hash
+	confirmation.BlockHash = b.Hash
+	confirmation.Round = b.Round
+	confirmation.MinerID = b.MinerID
+	confirmation.RoundRandomSeed = b.GetRoundRandomSeed()
+	confirmation.CreationDate = b.CreationDate
+	setConfirmationBlock(confirmation, b, proof)
+	return confirmation
+}
+
+// waitConfirmation gets the confirmation of given transaction until it
+// reaches the given finality level, it's dropped or the timeout expires. It
+// returns the last confirmation seen on timeout. A transaction seen
+// notarized and then missing after its round is finalized is reported
+// dropped.
+func waitConfirmation(ctx context.Context, hash string,
+	get func(context.Context) (*transaction.Confirmation, error),
+	finalizedRound func() int64, notifier *finalityNotifier, level string,
+	timeout time.Duration) (*transaction.Confirmation, error) {
+
+	var (
+		tm      = time.NewTimer(timeout)
+		seen    *transaction.Confirmation
+		wake    <-chan struct{}
+		release = func() {}
+	)
+	defer tm.Stop()
+	defer func() { release() }()
+
+	for {
+		// subscribe before the check to don't miss a notification
+		var round int64
+		if seen != nil {
+			round = seen.Round
+		}
+		release()
+		wake, release = notifier.wait(hash, round)
+		confirmation, err := get(ctx)
+		switch {
+		case err == nil && confirmation.Finality == transaction.FinalityDropped:
+			return confirmation, nil
+		case err == nil && finalityRank(confirmation.Finality) >= finalityRank(level):
+			return confirmation, nil
+		case err == nil:
+			seen = confirmation
+		case seen != nil && finalizedRound() >= seen.Round:
+			seen.Finality = transaction.FinalityDropped
+			seen.Depth = 0
+			return seen, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tm.C:
+			if seen != nil {
+				return seen, nil
+			}
+			return confirmation, err
+		case <-wake:
+		}
+	}
+}
+
+// WaitTransactionConfirmation returns the transaction confirmation once its
+// finality reaches the given level or the transaction is dropped. On the
+// timeout it returns the current confirmation. An empty level returns the
+// current confirmation immediately. The block header proving the
+// confirmation is set if the proof is requested.
+func (sc *Chain) WaitTransactionConfirmation(ctx context.Context, hash,
+	level string, timeout time.Duration, proof bool) (
+	*transaction.Confirmation, error) {
+
+	if level == "" {
+		return sc.GetTransactionConfirmation(ctx, hash, proof)
+	}
+	return waitConfirmation(ctx, hash, func(ctx context.Context) (
+		*transaction.Confirmation, error) {
+
+		return sc.GetTransactionConfirmation(ctx, hash, proof)
+	}, func() int64 {
+		if lfb := sc.GetLatestFinalizedBlock(); lfb != nil {
+			return lfb.Round
+		}
+		return 0
+	}, &sc.finality, level, timeout)
+}
+
+// getConfirmationParams returns parameters of a transaction confirmation
+// request with the timeout of waiting for the wait_for finality level.
+func getConfirmationParams(r *http.Request) (p *confirmationParams,
+	timeout time.Duration, err error) {
+
+	p = new(confirmationParams)
+	if err = common.DecodeRequestParams(r, p); err != nil {
+		return nil, 0, err
+	}
+	if p.WaitFor != "" && finalityRank(p.WaitFor) == 0 {
+		return nil, 0, common.InvalidRequest("wait_for should be one of " +
+			"notarized, finalized or deterministic")
+	}
+	timeout = DefaultConfirmationWait
+	if p.Timeout != nil {
+		if *p.Timeout <= 0 {
+			return nil, 0, common.InvalidRequest("timeout should be a positive" +
+				" number of seconds")
+		}
+		timeout = time.Duration(*p.Timeout) * time.Second
+	}
+	if timeout > MaxConfirmationWait {
+		timeout = MaxConfirmationWait
+	}
+	return p, timeout, nil
+}
@@ -0,0 +1,281 @@
+package sharder
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/chaincore/txnproof"
+	"0chain.net/core/common"
+)
+
+// testConfirmations returns the confirmations in order, repeating the last.
+type testConfirmations struct {
+	mutex         sync.Mutex
+	confirmations []*transaction.Confirmation
+	finalized     int64
+}
+
+func (tc *testConfirmations) get(ctx context.Context) (
+	*transaction.Confirmation, error) {
+
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	var c = tc.confirmations[0]
+	if len(tc.confirmations) > 1 {
+		tc.confirmations = tc.confirmations[1:]
+	}
+	if c == nil {
+		return nil, common.NewError("not_found", "txn not found")
+	}
+	var cc = *c
+	return &cc, nil
+}
+
+func (tc *testConfirmations) finalizedRound() int64 {
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	return tc.finalized
+}
+
+func testConfirmation(round int64, finality string) *transaction.Confirmation {
+	return &transaction.Confirmation{Hash: "txn", Round: round,
+		Finality: finality}
+}
+
+// notifyTestWaiter notifies blocks with the test transaction until the
+// wait returns.
+func notifyTestWaiter(notifier *finalityNotifier) (stop func()) {
+	var (
+		done = make(chan struct{})
+		tk   = time.NewTicker(time.Millisecond)
+		b    = block.NewBlock("", 10)
+	)
+	b.Txns = []*transaction.Transaction{{}}
+	b.Txns[0].Hash = "txn"
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-tk.C:
+				notifier.notify(b)
+			}
+		}
+	}()
+	return func() { tk.Stop(); close(done) }
+}
+
+func TestWaitConfirmation(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		notifier = new(finalityNotifier)
+		stop     = notifyTestWaiter(notifier)
+	)
+	defer stop()
+
+	var tc = &testConfirmations{confirmations: []*transaction.Confirmation{
+		nil,
+		testConfirmation(5, transaction.FinalityNotarized),
+		testConfirmation(5, transaction.FinalityFinalized),
+		testConfirmation(5, transaction.FinalityDeterministic),
+	}}
+	c, err := waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityFinalized, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityFinalized, c.Finality)
+
+	// timeout returns the last state
+	tc = &testConfirmations{confirmations: []*transaction.Confirmation{
+		testConfirmation(5, transaction.FinalityNotarized),
+	}}
+	c, err = waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityDeterministic, 20*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityNotarized, c.Finality)
+
+	// timeout returns the last state seen, not the last error
+	tc = &testConfirmations{confirmations: []*transaction.Confirmation{
+		testConfirmation(5, transaction.FinalityNotarized),
+		nil,
+	}, finalized: 4}
+	c, err = waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityFinalized, 20*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityNotarized, c.Finality)
+
+	tc = &testConfirmations{confirmations: []*transaction.Confirmation{nil}}
+	_, err = waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityNotarized, 20*time.Millisecond)
+	require.Error(t, err)
+
+	// dropped
+	tc = &testConfirmations{confirmations: []*transaction.Confirmation{
+		testConfirmation(5, transaction.FinalityDropped),
+	}}
+	c, err = waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityFinalized, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityDropped, c.Finality)
+
+	// seen notarized, missing after the round is finalized
+	tc = &testConfirmations{confirmations: []*transaction.Confirmation{
+		testConfirmation(5, transaction.FinalityNotarized),
+		nil,
+	}, finalized: 4}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tc.mutex.Lock()
+		tc.finalized = 5
+		tc.mutex.Unlock()
+	}()
+	c, err = waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityFinalized, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityDropped, c.Finality)
+	require.EqualValues(t, 5, c.Round)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = waitConfirmation(cctx, "txn", tc.get, tc.finalizedRound, notifier,
+		transaction.FinalityFinalized, time.Second)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestFinalityNotifier(t *testing.T) {
+	var (
+		fn     finalityNotifier
+		closed = func(ch <-chan struct{}) bool {
+			select {
+			case <-ch:
+				return true
+			default:
+				return false
+			}
+		}
+		txn, _     = fn.wait("txn", 0)
+		other, _   = fn.wait("other", 0)
+		round5, _  = fn.wait("", 5)
+		round10, _ = fn.wait("", 10)
+		b          = block.NewBlock("", 7)
+	)
+	b.Txns = []*transaction.Transaction{{}}
+	b.Txns[0].Hash = "txn"
+	fn.notify(b)
+
+	require.True(t, closed(txn))
+	require.False(t, closed(other), "not related transaction")
+	require.True(t, closed(round5))
+	require.False(t, closed(round10), "not related round")
+	require.Len(t, fn.txns, 1)
+	require.Len(t, fn.rounds, 1)
+}
+
+func TestFinalityNotifier_release(t *testing.T) {
+	var (
+		fn            finalityNotifier
+		first, done1  = fn.wait("txn", 0)
+		second, done2 = fn.wait("txn", 0)
+		_, done3      = fn.wait("", 5)
+	)
+	require.Equal(t, first, second, "waiters of a key share the channel")
+
+	done1()
+	done1()
+	require.Len(t, fn.txns, 1, "the key is kept while it's waited")
+	done2()
+	require.Empty(t, fn.txns, "the last waiter removes the key")
+	done3()
+	require.Empty(t, fn.rounds)
+
+	// a waiter released after the notification keeps a new waiter of the key
+	_, done1 = fn.wait("txn", 0)
+	var b = block.NewBlock("", 7)
+	b.Txns = []*transaction.Transaction{{}}
+	b.Txns[0].Hash = "txn"
+	fn.notify(b)
+	_, done2 = fn.wait("txn", 0)
+	done1()
+	require.Len(t, fn.txns, 1)
+	done2()
+	require.Empty(t, fn.txns)
+
+	// timed out and canceled long polls of not seen transactions
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		tc          = &testConfirmations{
+			confirmations: []*transaction.Confirmation{nil},
+		}
+	)
+	for i := 0; i < 3; i++ {
+		_, err := waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, &fn,
+			transaction.FinalityFinalized, time.Millisecond)
+		require.Error(t, err)
+	}
+	cancel()
+	_, err := waitConfirmation(ctx, "txn", tc.get, tc.finalizedRound, &fn,
+		transaction.FinalityFinalized, time.Second)
+	require.Equal(t, context.Canceled, err)
+	require.Empty(t, fn.txns)
+	require.Empty(t, fn.rounds)
+}
+
+func TestGetConfirmationParams(t *testing.T) {
+	p, timeout, err := getConfirmationParams(httptest.NewRequest("GET",
+		"/v1/transaction/get/confirmation?hash=x", nil))
+	require.NoError(t, err)
+	require.Equal(t, "x", p.Hash)
+	require.Empty(t, p.WaitFor)
+	require.False(t, p.Proof)
+	require.Equal(t, DefaultConfirmationWait, timeout)
+
+	p, timeout, err = getConfirmationParams(httptest.NewRequest("GET",
+		"/v1/transaction/get/confirmation?hash=x&wait_for=deterministic"+
+			"&timeout=100&proof=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, transaction.FinalityDeterministic, p.WaitFor)
+	require.True(t, p.Proof)
+	require.Equal(t, MaxConfirmationWait, timeout)
+
+	for _, query := range []string{"wait_for=notarized", "hash=x&wait_for=dropped",
+		"hash=x&timeout=0", "hash=x&timeout=x", "hash=x&proof=x"} {
+		_, _, err = getConfirmationParams(httptest.NewRequest("GET",
+			"/v1/transaction/get/confirmation?"+query, nil))
+		require.Error(t, err, query)
+	}
+}
+
+func TestSetConfirmationBlock_header(t *testing.T) {
+	var b = block.NewBlock("", 7)
+	b.MinerID = "miner"
+	b.PrevHash = "prev"
+	b.SetRoundRandomSeed(11)
+	var txn = &transaction.Transaction{ClientID: "client"}
+	txn.Hash = txn.ComputeHash()
+	b.Txns = append(b.Txns, txn)
+	b.MagicBlock = block.NewMagicBlock()
+	b.MagicBlock.Miners = node.NewPool(node.NodeTypeMiner)
+	b.MagicBlock.Sharders = node.NewPool(node.NodeTypeSharder)
+	b.HashBlock()
+	b.AddVerificationTicket(&block.VerificationTicket{VerifierID: "v",
+		Signature: "sig"})
+
+	var c = &transaction.Confirmation{Hash: txn.Hash}
+	setConfirmationBlock(c, b, false)
+	require.Nil(t, c.BlockHeader)
+
+	setConfirmationBlock(c, b, true)
+	var header = c.BlockHeader
+	require.NotNil(t, header)
+	require.Equal(t, b.Hash, header.Hash)
+	require.Equal(t, b.Hash, txnproof.ComputeHeaderHash(header))
+	require.Equal(t, []*transaction.BlockTicket{{VerifierID: "v",
+		Signature: "sig"}}, header.VerificationTickets)
+}
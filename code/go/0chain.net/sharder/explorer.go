@@ -0,0 +1,504 @@
+package sharder
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/kvstore"
+)
+
+const (
+	// ExplorerDefaultLimit is default page size of the explorer lists.
+	ExplorerDefaultLimit = 20
+	// ExplorerMaxLimit is max page size of the explorer lists.
+	ExplorerMaxLimit = 100
+)
+
+// key prefixes of the explorer index
+const (
+	explorerBlockPrefix    = "b/" // round, hash -> block
+	explorerMinerPrefix    = "m/" // miner, round, hash -> block
+	explorerMinerCount     = "n/" // miner -> number of blocks
+	explorerTxnPrefix      = "t/" // block hash, index -> transaction
+	explorerFunctionPrefix = "f/" // SC address, function -> number of calls
+)
+
+// ExplorerBlock is a finalized block entry of the explorer index.
+type ExplorerBlock struct {
+	Hash         string           `json:"hash"`
+	Round        int64            `json:"round"`
+	MinerID      string           `json:"miner_id"`
+	PrevHash     string           `json:"prev_hash"`
+	CreationDate common.Timestamp `json:"creation_date"`
+	NumTxns      int              `json:"num_txns"`
+}
+
+// ExplorerTxn is a transaction entry of the explorer index.
+type ExplorerTxn struct {
+	Hash       string `json:"hash"`
+	BlockHash  string `json:"block_hash"`
+	Round      int64  `json:"round"`
+	Index      int    `json:"index"`
+	ClientID   string `json:"client_id"`
+	ToClientID string `json:"to_client_id,omitempty"`
+	Type       int    `json:"transaction_type"`
+	Function   string `json:"function,omitempty"`
+	Value      int64  `json:"transaction_value"`
+	Fee        int64  `json:"transaction_fee"`
+	Status     int    `json:"transaction_status"`
+}
+
+// ExplorerFunction is call count of a SC function.
+type ExplorerFunction struct {
+	Address  string `json:"sc_address"`
+	Function string `json:"function"`
+	Calls    int64  `json:"calls"`
+}
+
+// ExplorerBlocks is a page of the explorer blocks.
+type ExplorerBlocks struct {
+	Blocks []*ExplorerBlock `json:"blocks"`
+	// NextCursor is cursor of next page, empty for last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ExplorerMinerBlocks is a page of blocks produced by a miner.
+type ExplorerMinerBlocks struct {
+	MinerID string `json:"miner_id"`
+	// Total is number of finalized blocks of the miner.
+	Total int64 `json:"total"`
+	ExplorerBlocks
+}
+
+// ExplorerTxns is a page of the explorer transactions of a block.
+type ExplorerTxns struct {
+	Transactions []*ExplorerTxn `json:"transactions"`
+	// NextCursor is cursor of next page, empty for last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ExplorerTxnFilter filters transactions of a block, negative type and
+// empty function match all.
+type ExplorerTxnFilter struct {
+	Type     int
+	Function string
+}
+
+func (f *ExplorerTxnFilter) match(et *ExplorerTxn) bool {
+	return (f.Type < 0 || et.Type == f.Type) &&
+		(f.Function == "" || et.Function == f.Function)
+}
+
+// ExplorerIndex is a local index of finalized blocks and their transactions
+// serving the explorer list queries. The keys are ordered so a list is a
+// range of a key prefix and the cursor is the key of the last entry.
+type ExplorerIndex struct {
+	kv kvstore.KV
+	// serializes the counters updates
+	mutex sync.Mutex
+}
+
+var explorerIndex *ExplorerIndex
+
+// NewExplorerIndex returns explorer index on given KV.
+func NewExplorerIndex(kv kvstore.KV) *ExplorerIndex {
+	return &ExplorerIndex{kv: kv}
+}
+
+// SetupExplorerIndex sets the explorer index maintained by the sharder.
+func SetupExplorerIndex(ei *ExplorerIndex) {
+	explorerIndex = ei
+}
+
+// GetExplorerIndex returns the explorer index, nil if it's disabled.
+func GetExplorerIndex() *ExplorerIndex {
+	return explorerIndex
+}
+
+func explorerKey(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// getTxnFunction returns called SC function of a SC transaction.
+func getTxnFunction(txn *transaction.Transaction) string {
+	if txn.TransactionType != transaction.TxnTypeSmartContract {
+		return ""
+	}
+	var data smartcontractinterface.SmartContractTransactionData
+	if err := json.Unmarshal([]byte(txn.TransactionData), &data); err != nil {
+		return ""
+	}
+	return data.FunctionName
+}
+
+func (ei *ExplorerIndex) addCounter(batch *kvstore.Batch, key []byte,
+	delta int64) error {
+
+	value, err := ei.kv.Get(key)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if len(value) == 8 {
+		count = int64(binary.BigEndian.Uint64(value))
+	}
+	batch.Put(key, kvstore.Uint64Key(count+delta))
+	return nil
+}
+
+// IndexBlock adds the finalized block and its transactions to the index.
+// Indexing a block twice is no-op.
+func (ei *ExplorerIndex) IndexBlock(b *block.Block) error {
+	ei.mutex.Lock()
+	defer ei.mutex.Unlock()
+
+	var (
+		hash     = []byte(b.Hash)
+		blockKey = explorerKey([]byte(explorerBlockPrefix),
+			kvstore.Uint64Key(b.Round), hash)
+	)
+	if value, err := ei.kv.Get(blockKey); err != nil || value != nil {
+		return err
+	}
+
+	var (
+		batch = &kvstore.Batch{}
+		eb    = &ExplorerBlock{
+			Hash:         b.Hash,
+			Round:        b.Round,
+			MinerID:      b.MinerID,
+			PrevHash:     b.PrevHash,
+			CreationDate: b.CreationDate,
+			NumTxns:      len(b.Txns),
+		}
+		functions = make(map[string]int64)
+	)
+	blockValue, err := json.Marshal(eb)
+	if err != nil {
+		return err
+	}
+	batch.Put(blockKey, blockValue)
+	batch.Put(explorerKey([]byte(explorerMinerPrefix+b.MinerID+"/"),
+		kvstore.Uint64Key(b.Round), hash), blockValue)
+	if err = ei.addCounter(batch, []byte(explorerMinerCount+b.MinerID), 1); err != nil {
+		return err
+	}
+
+	for i, txn := range b.Txns {
+		var et = &ExplorerTxn{
+			Hash:       txn.Hash,
+			BlockHash:  b.Hash,
+			Round:      b.Round,
+			Index:      i,
+			ClientID:   txn.ClientID,
+			ToClientID: txn.ToClientID,
+			Type:       txn.TransactionType,
+			Function:   getTxnFunction(txn),
+			Value:      txn.Value,
+			Fee:        txn.Fee,
+			Status:     txn.Status,
+		}
+		var value []byte
+		if value, err = json.Marshal(et); err != nil {
+			return err
+		}
+		var index = make([]byte, 4)
+		binary.BigEndian.PutUint32(index, uint32(i))
+		batch.Put(explorerKey([]byte(explorerTxnPrefix), hash, index), value)
+		if et.Function != "" {
+			functions[et.ToClientID+"/"+et.Function]++
+		}
+	}
+	for fn, calls := range functions {
+		err = ei.addCounter(batch, []byte(explorerFunctionPrefix+fn), calls)
+		if err != nil {
+			return err
+		}
+	}
+	return ei.kv.Write(batch)
+}
+
+// decodeCursor returns the iteration start of given prefix and cursor, the
+// cursor is exclusive.
+func decodeCursor(prefix []byte, cursor string) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	last, err := hex.DecodeString(cursor)
+	if err != nil {
+		return nil, common.InvalidRequest("invalid cursor")
+	}
+	return append(explorerKey(prefix, last), 0), nil
+}
+
+// iterate returns up to limit JSON decoded entries of given prefix matching
+// the filter starting from the cursor or given start key until the filter
+// stops the iteration. It returns cursor of the next page as well.
+func (ei *ExplorerIndex) iterate(prefix []byte, cursor string, start []byte,
+	limit int, decode func(value []byte) (interface{}, error),
+	filter func(entry interface{}) (match, next bool)) (
+	entries []interface{}, next string, err error) {
+
+	var from []byte
+	if from, err = decodeCursor(prefix, cursor); err != nil {
+		return nil, "", err
+	}
+	if from == nil {
+		from = explorerKey(prefix, start)
+	}
+	var (
+		last []byte
+		more bool
+		derr error
+	)
+	err = ei.kv.Iterate(prefix, from, func(key, value []byte) bool {
+		var entry interface{}
+		if entry, derr = decode(value); derr != nil {
+			return false
+		}
+		match, next := filter(entry)
+		if match && len(entries) == limit {
+			more = true
+			return false
+		}
+		if match {
+			entries, last = append(entries, entry), key[len(prefix):]
+		}
+		return next
+	})
+	if err == nil {
+		err = derr
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if more {
+		next = hex.EncodeToString(last)
+	}
+	return entries, next, nil
+}
+
+func decodeExplorerBlock(value []byte) (interface{}, error) {
+	var eb ExplorerBlock
+	err := json.Unmarshal(value, &eb)
+	return &eb, err
+}
+
+func decodeExplorerTxn(value []byte) (interface{}, error) {
+	var et ExplorerTxn
+	err := json.Unmarshal(value, &et)
+	return &et, err
+}
+
+// GetBlocks returns blocks of given rounds range, the toRound is inclusive,
+// optionally produced by given miner.
+func (ei *ExplorerIndex) GetBlocks(fromRound, toRound int64, minerID,
+	cursor string, limit int) (*ExplorerBlocks, error) {
+
+	var prefix = []byte(explorerBlockPrefix)
+	if minerID != "" {
+		prefix = []byte(explorerMinerPrefix + minerID + "/")
+	}
+	entries, next, err := ei.iterate(prefix, cursor,
+		kvstore.Uint64Key(fromRound), limit, decodeExplorerBlock,
+		func(entry interface{}) (bool, bool) {
+			var inRange = toRound <= 0 || entry.(*ExplorerBlock).Round <= toRound
+			return inRange, inRange
+		})
+	if err != nil {
+		return nil, err
+	}
+	var ebs = &ExplorerBlocks{
+		Blocks:     make([]*ExplorerBlock, 0, len(entries)),
+		NextCursor: next,
+	}
+	for _, entry := range entries {
+		ebs.Blocks = append(ebs.Blocks, entry.(*ExplorerBlock))
+	}
+	return ebs, nil
+}
+
+// GetMinerBlocks returns the block production history of given miner.
+func (ei *ExplorerIndex) GetMinerBlocks(minerID string, fromRound int64,
+	cursor string, limit int) (*ExplorerMinerBlocks, error) {
+
+	ebs, err := ei.GetBlocks(fromRound, 0, minerID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ei.kv.Get([]byte(explorerMinerCount + minerID))
+	if err != nil {
+		return nil, err
+	}
+	var emb = &ExplorerMinerBlocks{MinerID: minerID, ExplorerBlocks: *ebs}
+	if len(value) == 8 {
+		emb.Total = int64(binary.BigEndian.Uint64(value))
+	}
+	return emb, nil
+}
+
+// GetBlockTxns returns filtered transactions of given block.
+func (ei *ExplorerIndex) GetBlockTxns(blockHash string,
+	filter *ExplorerTxnFilter, cursor string, limit int) (*ExplorerTxns, error) {
+
+	var prefix = explorerKey([]byte(explorerTxnPrefix), []byte(blockHash))
+	entries, next, err := ei.iterate(prefix, cursor, nil, limit,
+		decodeExplorerTxn, func(entry interface{}) (bool, bool) {
+			return filter.match(entry.(*ExplorerTxn)), true
+		})
+	if err != nil {
+		return nil, err
+	}
+	var ets = &ExplorerTxns{
+		Transactions: make([]*ExplorerTxn, 0, len(entries)),
+		NextCursor:   next,
+	}
+	for _, entry := range entries {
+		ets.Transactions = append(ets.Transactions, entry.(*ExplorerTxn))
+	}
+	return ets, nil
+}
+
+// GetTopFunctions returns up to limit SC functions with most calls.
+func (ei *ExplorerIndex) GetTopFunctions(limit int) ([]*ExplorerFunction, error) {
+	var (
+		prefix    = []byte(explorerFunctionPrefix)
+		functions = make([]*ExplorerFunction, 0)
+	)
+	err := ei.kv.Iterate(prefix, nil, func(key, value []byte) bool {
+		var (
+			name = string(key[len(prefix):])
+			i    = strings.LastIndexByte(name, '/')
+		)
+		if i < 0 || len(value) != 8 {
+			return true
+		}
+		functions = append(functions, &ExplorerFunction{
+			Address:  name[:i],
+			Function: name[i+1:],
+			Calls:    int64(binary.BigEndian.Uint64(value)),
+		})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(functions, func(i, j int) bool {
+		return functions[i].Calls > functions[j].Calls
+	})
+	if len(functions) > limit {
+		functions = functions[:limit]
+	}
+	return functions, nil
+}
+
+// explorerParams are parameters of an explorer request with a page
+type explorerParams interface {
+	page() *explorerPageParams
+}
+
+func (p *explorerPageParams) page() *explorerPageParams {
+	return p
+}
+
+// decodeExplorerParams decodes parameters of an explorer request, the page
+// size is the default one if not given and it's limited.
+func decodeExplorerParams(r *http.Request, p explorerParams) error {
+	var page = p.page()
+	page.Limit = ExplorerDefaultLimit
+	if err := common.DecodeRequestParams(r, p); err != nil {
+		return err
+	}
+	if page.Limit <= 0 {
+		return common.InvalidRequest("invalid limit")
+	}
+	if page.Limit > ExplorerMaxLimit {
+		page.Limit = ExplorerMaxLimit
+	}
+	return nil
+}
+
+func getExplorerIndex() (*ExplorerIndex, error) {
+	var ei = GetExplorerIndex()
+	if ei == nil {
+		return nil, common.NewError("explorer_disabled",
+			"explorer index is disabled on this sharder")
+	}
+	return ei, nil
+}
+
+/*ExplorerBlocksHandler - a handler to list blocks of a rounds range, optionally of a miner */
+func ExplorerBlocksHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	ei, err := getExplorerIndex()
+	if err != nil {
+		return nil, err
+	}
+	var p explorerBlocksParams
+	if err = decodeExplorerParams(r, &p); err != nil {
+		return nil, err
+	}
+	if p.FromRound < 0 || p.ToRound < 0 {
+		return nil, common.InvalidRequest("invalid rounds range")
+	}
+	return ei.GetBlocks(p.FromRound, p.ToRound, p.MinerID, p.Cursor, p.Limit)
+}
+
+/*ExplorerBlockTxnsHandler - a handler to list transactions of a block filtered by type and SC function */
+func ExplorerBlockTxnsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	ei, err := getExplorerIndex()
+	if err != nil {
+		return nil, err
+	}
+	var p explorerBlockTxnsParams
+	if err = decodeExplorerParams(r, &p); err != nil {
+		return nil, err
+	}
+	var filter = &ExplorerTxnFilter{Type: -1, Function: p.Function}
+	if p.Type != nil {
+		if *p.Type < 0 {
+			return nil, common.InvalidRequest("invalid type")
+		}
+		filter.Type = *p.Type
+	}
+	return ei.GetBlockTxns(p.BlockHash, filter, p.Cursor, p.Limit)
+}
+
+/*ExplorerTopFunctionsHandler - a handler to list SC functions with most calls */
+func ExplorerTopFunctionsHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	ei, err := getExplorerIndex()
+	if err != nil {
+		return nil, err
+	}
+	var p explorerPageParams
+	if err = decodeExplorerParams(r, &p); err != nil {
+		return nil, err
+	}
+	return ei.GetTopFunctions(p.Limit)
+}
+
+/*ExplorerMinerBlocksHandler - a handler to list the block production history of a miner */
+func ExplorerMinerBlocksHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	ei, err := getExplorerIndex()
+	if err != nil {
+		return nil, err
+	}
+	var p explorerMinerBlocksParams
+	if err = decodeExplorerParams(r, &p); err != nil {
+		return nil, err
+	}
+	if p.FromRound < 0 {
+		return nil, common.InvalidRequest("invalid from_round")
+	}
+	return ei.GetMinerBlocks(p.MinerID, p.FromRound, p.Cursor, p.Limit)
+}
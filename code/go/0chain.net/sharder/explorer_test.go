@@ -0,0 +1,131 @@
+package sharder
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+	"0chain.net/core/kvstore"
+)
+
+func makeTestExplorerBlock(round int64, miner string, txns ...*transaction.Transaction) *block.Block {
+	var b = block.NewBlock("", round)
+	b.MinerID = miner
+	b.Txns = txns
+	b.Hash = encryption.Hash(miner + string(kvstore.Uint64Key(round)))
+	return b
+}
+
+func makeTestExplorerTxn(name string, typ int, data string) *transaction.Transaction {
+	var txn = &transaction.Transaction{
+		ClientID:        "client",
+		ToClientID:      "sc",
+		TransactionType: typ,
+		TransactionData: data,
+	}
+	txn.Hash = encryption.Hash(name)
+	return txn
+}
+
+func TestExplorerIndex(t *testing.T) {
+	var ei = NewExplorerIndex(kvstore.NewMemoryKV())
+	for r := int64(1); r <= 5; r++ {
+		var miner = "m1"
+		if r%2 == 0 {
+			miner = "m2"
+		}
+		require.NoError(t, ei.IndexBlock(makeTestExplorerBlock(r, miner)))
+	}
+	var b = makeTestExplorerBlock(6, "m1",
+		makeTestExplorerTxn("send", transaction.TxnTypeSend, ""),
+		makeTestExplorerTxn("lock", transaction.TxnTypeSmartContract,
+			`{"name":"lock","input":{}}`),
+		makeTestExplorerTxn("lock2", transaction.TxnTypeSmartContract,
+			`{"name":"lock","input":{}}`),
+		makeTestExplorerTxn("pay", transaction.TxnTypeSmartContract,
+			`{"name":"pay","input":{}}`))
+	require.NoError(t, ei.IndexBlock(b))
+	// indexing twice doesn't count twice
+	require.NoError(t, ei.IndexBlock(b))
+
+	ebs, err := ei.GetBlocks(2, 4, "", "", 2)
+	require.NoError(t, err)
+	require.Len(t, ebs.Blocks, 2)
+	require.EqualValues(t, 2, ebs.Blocks[0].Round)
+	require.NotEmpty(t, ebs.NextCursor)
+	ebs, err = ei.GetBlocks(2, 4, "", ebs.NextCursor, 2)
+	require.NoError(t, err)
+	require.Len(t, ebs.Blocks, 1)
+	require.EqualValues(t, 4, ebs.Blocks[0].Round)
+	require.Empty(t, ebs.NextCursor, "no more blocks in the range")
+
+	ebs, err = ei.GetBlocks(0, 0, "m2", "", 10)
+	require.NoError(t, err)
+	require.Len(t, ebs.Blocks, 2)
+	for _, eb := range ebs.Blocks {
+		require.Equal(t, "m2", eb.MinerID)
+	}
+
+	emb, err := ei.GetMinerBlocks("m1", 2, "", 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, emb.Total)
+	require.EqualValues(t, 3, emb.Blocks[0].Round)
+	require.NotEmpty(t, emb.NextCursor)
+
+	ets, err := ei.GetBlockTxns(b.Hash, &ExplorerTxnFilter{
+		Type: transaction.TxnTypeSmartContract}, "", 1)
+	require.NoError(t, err)
+	require.Len(t, ets.Transactions, 1)
+	require.Equal(t, "lock", ets.Transactions[0].Function)
+	ets, err = ei.GetBlockTxns(b.Hash, &ExplorerTxnFilter{
+		Type: transaction.TxnTypeSmartContract}, ets.NextCursor, 5)
+	require.NoError(t, err)
+	require.Len(t, ets.Transactions, 2)
+	require.Empty(t, ets.NextCursor)
+
+	ets, err = ei.GetBlockTxns(b.Hash, &ExplorerTxnFilter{Type: -1,
+		Function: "pay"}, "", 5)
+	require.NoError(t, err)
+	require.Len(t, ets.Transactions, 1)
+	require.Equal(t, 3, ets.Transactions[0].Index)
+
+	fns, err := ei.GetTopFunctions(1)
+	require.NoError(t, err)
+	require.Equal(t, []*ExplorerFunction{
+		{Address: "sc", Function: "lock", Calls: 2}}, fns)
+}
+
+func TestExplorerHandlers(t *testing.T) {
+	SetupExplorerIndex(nil)
+	_, err := ExplorerBlocksHandler(context.Background(),
+		httptest.NewRequest("GET", "/v1/explorer/blocks", nil))
+	require.Error(t, err)
+
+	var ei = NewExplorerIndex(kvstore.NewMemoryKV())
+	SetupExplorerIndex(ei)
+	defer SetupExplorerIndex(nil)
+	require.NoError(t, ei.IndexBlock(makeTestExplorerBlock(1, "m1")))
+
+	resp, err := ExplorerMinerBlocksHandler(context.Background(),
+		httptest.NewRequest("GET", "/v1/explorer/miner/blocks?miner_id=m1", nil))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resp.(*ExplorerMinerBlocks).Total)
+
+	for _, url := range []string{
+		"/v1/explorer/blocks?limit=0",
+		"/v1/explorer/blocks?from_round=x",
+		"/v1/explorer/blocks?cursor=zz",
+	} {
+		_, err = ExplorerBlocksHandler(context.Background(),
+			httptest.NewRequest("GET", url, nil))
+		require.Error(t, err, url)
+	}
+	_, err = ExplorerBlockTxnsHandler(context.Background(),
+		httptest.NewRequest("GET", "/v1/explorer/block/transactions", nil))
+	require.Error(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -14,7 +13,9 @@ import (
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/chain"
 	"0chain.net/chaincore/diagnostics"
+	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
+	"0chain.net/core/openapi"
 )
 
 /* SetupHandlers sets up the necessary API end points */
@@ -22,28 +23,125 @@ func SetupHandlers() {
 	http.HandleFunc("/v1/block/get", common.UserRateLimit(common.ToJSONResponse(BlockHandler)))
 	http.HandleFunc("/v1/block/magic/get", common.UserRateLimit(common.ToJSONResponse(MagicBlockHandler)))
 	http.HandleFunc("/v1/transaction/get/confirmation", common.UserRateLimit(common.ToJSONResponse(TransactionConfirmationHandler)))
+	http.HandleFunc("/v1/transaction/get/history", common.UserRateLimit(common.ToJSONResponse(TxnClientHistoryHandler)))
+	http.HandleFunc("/v1/explorer/blocks", common.UserRateLimit(common.ToJSONResponse(ExplorerBlocksHandler)))
+	http.HandleFunc("/v1/explorer/block/transactions", common.UserRateLimit(common.ToJSONResponse(ExplorerBlockTxnsHandler)))
+	http.HandleFunc("/v1/explorer/sc/functions/top", common.UserRateLimit(common.ToJSONResponse(ExplorerTopFunctionsHandler)))
+	http.HandleFunc("/v1/explorer/miner/blocks", common.UserRateLimit(common.ToJSONResponse(ExplorerMinerBlocksHandler)))
 	http.HandleFunc("/v1/chain/get/stats", common.UserRateLimit(common.ToJSONResponse(ChainStatsHandler)))
 	http.HandleFunc("/_chain_stats", common.UserRateLimit(ChainStatsWriter))
 	http.HandleFunc("/_health_check", common.UserRateLimit(HealthCheckWriter))
+	http.HandleFunc("/_replication_audit", common.UserRateLimit(ReplicationAuditWriter))
 	http.HandleFunc("/v1/sharder/get/stats", common.UserRateLimit(common.ToJSONResponse(SharderStatsHandler)))
+
+	openapi.Register(
+		&openapi.Endpoint{Path: "/v1/block/get", Summary: "block by hash or round",
+			Params: blockParams{}, Response: chain.BlockResponse{}},
+		&openapi.Endpoint{Path: "/v1/block/magic/get", Summary: "magic block by number",
+			Params: magicBlockParams{}, Response: block.Block{}},
+		&openapi.Endpoint{Path: "/v1/transaction/get/confirmation",
+			Summary: "transaction confirmation", Params: confirmationParams{},
+			Response: transaction.Confirmation{}},
+		&openapi.Endpoint{Path: "/v1/transaction/get/history",
+			Summary: "transactions history of the client", Params: txnHistoryParams{},
+			Response: TxnHistory{}},
+		&openapi.Endpoint{Path: "/v1/explorer/blocks",
+			Summary: "blocks of a rounds range", Params: explorerBlocksParams{},
+			Response: ExplorerBlocks{}},
+		&openapi.Endpoint{Path: "/v1/explorer/block/transactions",
+			Summary: "transactions of the block", Params: explorerBlockTxnsParams{},
+			Response: ExplorerTxns{}},
+		&openapi.Endpoint{Path: "/v1/explorer/sc/functions/top",
+			Summary: "SC functions with most calls", Params: explorerPageParams{},
+			Response: []*ExplorerFunction{}},
+		&openapi.Endpoint{Path: "/v1/explorer/miner/blocks",
+			Summary: "blocks produced by the miner", Params: explorerMinerBlocksParams{},
+			Response: ExplorerMinerBlocks{}},
+		&openapi.Endpoint{Path: "/v1/chain/get/stats", Summary: "chain statistics"},
+		&openapi.Endpoint{Path: "/v1/sharder/get/stats", Summary: "sharder statistics",
+			Response: ExplorerStats{}},
+	)
+}
+
+// blockParams are query parameters of the block handler, the block is
+// hash of the block, required if the round is not given
+type blockParams struct {
+	Round   *int64 `param:"round"`
+	Block   string `param:"block"`
+	Content string `param:"content"`
+}
+
+// magicBlockParams are query parameters of the magic block handler
+type magicBlockParams struct {
+	MagicBlockNumber string `param:"magic_block_number"`
+}
+
+// confirmationParams are query parameters of the transaction confirmation
+// handler, the timeout is in seconds
+type confirmationParams struct {
+	Hash    string `param:"hash,required"`
+	Content string `param:"content"`
+	WaitFor string `param:"wait_for"`
+	Timeout *int64 `param:"timeout"`
+	Proof   bool   `param:"proof"`
+}
+
+// txnHistoryParams are query parameters of the transactions history handler
+type txnHistoryParams struct {
+	ClientID  string `param:"client_id,required"`
+	FromRound int64  `param:"from_round"`
+	FromHash  string `param:"from_hash"`
+	Limit     int    `param:"limit"`
+}
+
+// explorerPageParams are query parameters of a page of the explorer
+type explorerPageParams struct {
+	Cursor string `param:"cursor"`
+	Limit  int    `param:"limit"`
+}
+
+// explorerBlocksParams are query parameters of the explorer blocks handler
+type explorerBlocksParams struct {
+	explorerPageParams
+	FromRound int64  `param:"from_round"`
+	ToRound   int64  `param:"to_round"`
+	MinerID   string `param:"miner_id"`
+}
+
+// explorerBlockTxnsParams are query parameters of the explorer block
+// transactions handler
+type explorerBlockTxnsParams struct {
+	explorerPageParams
+	BlockHash string `param:"block_hash,required"`
+	Type      *int   `param:"type"`
+	Function  string `param:"function"`
+}
+
+// explorerMinerBlocksParams are query parameters of the explorer miner
+// blocks handler
+type explorerMinerBlocksParams struct {
+	explorerPageParams
+	MinerID   string `param:"miner_id,required"`
+	FromRound int64  `param:"from_round"`
 }
 
 /*BlockHandler - a handler to respond to block queries */
 func BlockHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	roundData := r.FormValue("round")
-	hash := r.FormValue("block")
-	content := r.FormValue("content")
+	var p blockParams
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
+	hash := p.Block
+	content := p.Content
 	if content == "" {
 		content = "header"
 	}
 	parts := strings.Split(content, ",")
 	sc := GetSharderChain()
 	lfb := sc.GetLatestFinalizedBlock()
-	if roundData != "" {
-		roundNumber, err := strconv.ParseInt(roundData, 10, 64)
-		if err != nil {
-			return nil, err
-		}
+	if p.Round != nil {
+		var err error
+		roundNumber := *p.Round
 		if roundNumber > lfb.Round {
 			return nil, common.InvalidRequest("Block not available")
 		}
@@ -85,9 +183,12 @@ func BlockHandler(ctx context.Context, r *http.Request) (interface{}, error) {
 
 /*MagicBlockHandler - a handler to respond to magic block queries */
 func MagicBlockHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	magicBlockNumber := r.FormValue("magic_block_number")
+	var p magicBlockParams
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
 	sc := GetSharderChain()
-	mbm, err := sc.GetMagicBlockMap(ctx, magicBlockNumber)
+	mbm, err := sc.GetMagicBlockMap(ctx, p.MagicBlockNumber)
 	if err != nil {
 		return nil, err
 	}
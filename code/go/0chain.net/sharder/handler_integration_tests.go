@@ -9,8 +9,6 @@ import (
 	"0chain.net/core/datastore"
 	"0chain.net/core/persistencestore"
 
-	"0chain.net/core/common"
-
 	crpc "0chain.net/conductor/conductrpc"
 )
 
@@ -26,13 +24,12 @@ func revertString(s string) string {
 func TransactionConfirmationHandler(ctx context.Context, r *http.Request) (
 	interface{}, error) {
 
-	var hash = r.FormValue("hash")
-	if hash == "" {
-		return nil, common.InvalidRequest("transaction hash (parameter hash)" +
-			" is required")
+	p, timeout, err := getConfirmationParams(r)
+	if err != nil {
+		return nil, err
 	}
 
-	var content = r.FormValue("content")
+	var content = p.Content
 	if content == "" {
 		content = "confirmation"
 	}
@@ -44,10 +41,11 @@ func TransactionConfirmationHandler(ctx context.Context, r *http.Request) (
 	defer persistencestore.Close(ctx)
 
 	var (
-		state             = crpc.Client().State()
-		sc                = GetSharderChain()
-		confirmation, err = sc.GetTransactionConfirmation(ctx, hash)
+		state = crpc.Client().State()
+		sc    = GetSharderChain()
 	)
+	confirmation, err := sc.WaitTransactionConfirmation(ctx, p.Hash,
+		p.WaitFor, timeout, p.Proof)
 
 	if confirmation != nil && state.VerifyTransaction != nil {
 		confirmation.Hash = revertString(confirmation.Hash)
@@ -8,17 +8,15 @@ import (
 
 	"0chain.net/core/datastore"
 	"0chain.net/core/persistencestore"
-
-	"0chain.net/core/common"
 )
 
 /*TransactionConfirmationHandler - given a transaction hash, confirm it's presence in a block */
 func TransactionConfirmationHandler(ctx context.Context, r *http.Request) (interface{}, error) {
-	hash := r.FormValue("hash")
-	if hash == "" {
-		return nil, common.InvalidRequest("transaction hash (parameter hash) is required")
+	p, timeout, err := getConfirmationParams(r)
+	if err != nil {
+		return nil, err
 	}
-	content := r.FormValue("content")
+	content := p.Content
 	if content == "" {
 		content = "confirmation"
 	}
@@ -26,7 +24,8 @@ func TransactionConfirmationHandler(ctx context.Context, r *http.Request) (inter
 	ctx = persistencestore.WithEntityConnection(ctx, transactionConfirmationEntityMetadata)
 	defer persistencestore.Close(ctx)
 	sc := GetSharderChain()
-	confirmation, err := sc.GetTransactionConfirmation(ctx, hash)
+	confirmation, err := sc.WaitTransactionConfirmation(ctx, p.Hash, p.WaitFor, timeout,
+		p.Proof)
 	if content == "confirmation" {
 		return confirmation, err
 	}
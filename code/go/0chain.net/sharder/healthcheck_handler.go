@@ -50,3 +50,67 @@ func HealthCheckWriter(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "</table>")
 
 }
+
+// ReplicationAuditWriter - a handler to provide the replication audit reports
+func ReplicationAuditWriter(w http.ResponseWriter, r *http.Request) {
+	sc := GetSharderChain()
+	w.Header().Set("Content-Type", "text/html")
+	chain.PrintCSS(w)
+	diagnostics.WriteStatisticsCSS(w)
+
+	self := node.Self.Underlying()
+	fmt.Fprintf(w, "<div>%v - %v</div>", self.GetPseudoName(), self.Description)
+	ra := sc.ReplicationAudit
+	if ra == nil {
+		fmt.Fprintf(w, "<div>Replication audit is disabled</div>")
+		return
+	}
+	last, totals := ra.GetReports()
+	fmt.Fprintf(w, "<table>")
+	fmt.Fprintf(w, "<tr><td valign='top'><h2>Replication Audit Configuration</h2>")
+	fmt.Fprintf(w, "<table width='100%%'>")
+	fmt.Fprintf(w, "<tr><td>Audit Enabled</td><td class='string'>%v</td></tr>", ra.Config.Enabled)
+	fmt.Fprintf(w, "<tr><td>Replicators</td><td class='string'>%v</td></tr>", sc.NumReplicators)
+	fmt.Fprintf(w, "<tr><td>Window</td><td class='string'>%v</td></tr>", ra.Config.Window)
+	fmt.Fprintf(w, "<tr><td>Settle Rounds</td><td class='string'>%v</td></tr>", ra.Config.Settle)
+	fmt.Fprintf(w, "<tr><td>Sample Size</td><td class='string'>%v</td></tr>", ra.Config.SampleSize)
+	fmt.Fprintf(w, "<tr><td>Repeat Interval</td><td class='string'>%v</td></tr>", ra.Config.RepeatInterval)
+	fmt.Fprintf(w, "<tr><td>Repair</td><td class='string'>%v</td></tr>", ra.Config.Repair)
+	fmt.Fprintf(w, "</table>")
+	fmt.Fprintf(w, "</td><td valign='top'><h2>Totals</h2>")
+	writeReplicationAuditCounters(w, &totals)
+	fmt.Fprintf(w, "</td></tr>")
+
+	fmt.Fprintf(w, "<tr><td valign='top' colspan='2'><h2>Last Cycle</h2>")
+	if last == nil {
+		fmt.Fprintf(w, "<div>No cycle completed yet</div>")
+	} else {
+		fmt.Fprintf(w, "<div>Cycle %v: rounds %v - %v, %v - %v</div>", last.Cycle,
+			last.Low, last.High, last.Start.Format(HealthCheckDateTimeFormat),
+			last.End.Format(HealthCheckDateTimeFormat))
+		writeReplicationAuditCounters(w, last)
+		fmt.Fprintf(w, "<h3>Under-replicated Ranges</h3>")
+		fmt.Fprintf(w, "<table width='100%%'>")
+		fmt.Fprintf(w, "<tr><td class='sheader'>Low</td><td class='sheader'>High</td>"+
+			"<td class='sheader'>Rounds</td><td class='sheader'>Min Holders</td>"+
+			"<td class='sheader'>Expected</td><td class='sheader'>Repaired</td></tr>")
+		for _, ur := range last.Ranges {
+			fmt.Fprintf(w, "<tr><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td></tr>",
+				ur.Low, ur.High, ur.Rounds, ur.MinHolders, ur.Expected, ur.Repaired)
+		}
+		fmt.Fprintf(w, "</table>")
+	}
+	fmt.Fprintf(w, "</td></tr>")
+	fmt.Fprintf(w, "</table>")
+}
+
+func writeReplicationAuditCounters(w http.ResponseWriter, report *ReplicationAuditReport) {
+	fmt.Fprintf(w, "<table width='100%%'>")
+	fmt.Fprintf(w, "<tr><td>Cycles</td><td class='string'>%v</td></tr>", report.Cycle)
+	fmt.Fprintf(w, "<tr><td>Sampled Rounds</td><td class='string'>%v</td></tr>", report.Sampled)
+	fmt.Fprintf(w, "<tr><td>Unknown Rounds</td><td class='string'>%v</td></tr>", report.Unknown)
+	fmt.Fprintf(w, "<tr><td>Under-replicated</td><td class='string'>%v</td></tr>", report.UnderReplicated)
+	fmt.Fprintf(w, "<tr><td>Repaired</td><td class='string'>%v</td></tr>", report.Repaired)
+	fmt.Fprintf(w, "<tr><td>Repair Failures</td><td class='string'>%v</td></tr>", report.RepairFailures)
+	fmt.Fprintf(w, "</table>")
+}
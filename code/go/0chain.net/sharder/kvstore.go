@@ -0,0 +1,200 @@
+package sharder
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/kvstore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/persistencestore"
+)
+
+// kvRoundToHashIndex is the kvstore replacement of the round_to_hash
+// materialized view of the txn_summary table.
+const kvRoundToHashIndex = "round_to_hash"
+
+// migrateBatchSize is number of rows written to the kvstore at once.
+const migrateBatchSize = 1000
+
+// migratedTables are the Cassandra tables copied to the kvstore, the
+// entities are named as the tables. The txn_confirmation entity has no table.
+var migratedTables = []string{
+	"txn_summary",
+	"txn_client_history",
+	"magic_block_map",
+}
+
+// SetupKVStore registers the clustering and indexes of the sharder entities
+// kept in the kvstore, replacing the Cassandra table schemas.
+func SetupKVStore(kvs *kvstore.Store) {
+	kvs.RegisterIndex(&kvstore.Index{
+		Name:   kvRoundToHashIndex,
+		Entity: "txn_summary",
+		Key: func(entity datastore.Entity) []byte {
+			return kvstore.Uint64Key(entity.(*transaction.TransactionSummary).Round)
+		},
+	})
+	// primary key (client_id, round, hash)
+	kvs.RegisterClustering("txn_client_history", func(entity datastore.Entity) []byte {
+		var tch = entity.(*transaction.TxnClientHistory)
+		return append(kvstore.Uint64Key(tch.Round), tch.Hash...)
+	})
+}
+
+// getKVStore returns the kvstore of the entity, if any.
+func getKVStore(emd datastore.EntityMetadata) (*kvstore.Store, bool) {
+	kvs, ok := emd.GetStore().(*kvstore.Store)
+	return kvs, ok
+}
+
+// getHighestKVMagicBlockMap finds the MB map with greatest MB number, the
+// kvstore keeps the MB maps ordered by the number as a string.
+func getHighestKVMagicBlockMap(ctx context.Context, kvs *kvstore.Store,
+	mbmemd datastore.EntityMetadata) (*block.MagicBlockMap, error) {
+
+	var (
+		highest *block.MagicBlockMap
+		number  int64 = -1
+		perr    error
+	)
+	err := kvs.Iterate(ctx, mbmemd, func(entity datastore.Entity) bool {
+		var (
+			mbm   = entity.(*block.MagicBlockMap)
+			n, ie = strconv.ParseInt(mbm.ID, 10, 64)
+		)
+		if ie != nil {
+			perr = ie
+			return false
+		}
+		if n > number {
+			highest, number = mbm, n
+		}
+		return true
+	})
+	if err == nil {
+		err = perr
+	}
+	if err != nil {
+		return nil, common.NewErrorf("get_highest_mbm",
+			"iterating MB maps: %v", err)
+	}
+	if highest == nil {
+		return nil, common.NewError(datastore.EntityNotFound,
+			"get_highest_mbm: no MB maps stored")
+	}
+	return highest, nil
+}
+
+// MigrateFromCassandra copies all the persistence store entities from the
+// Cassandra tables to the kvstore. The tables missing in the keyspace, e.g.
+// magic_block_map not created by the scylla schema, are skipped.
+func MigrateFromCassandra(ctx context.Context, kvs *kvstore.Store) (
+	migrated map[string]int, err error) {
+
+	return migrateTables(ctx, kvs, persistencestore.GetConnection())
+}
+
+// cassandraTables returns the tables of the keyspace.
+func cassandraTables(c persistencestore.SessionI) (map[string]bool, error) {
+	var (
+		tables = make(map[string]bool)
+		iter   = c.Query("SELECT table_name FROM system_schema.tables"+
+			" WHERE keyspace_name = ?", persistencestore.KeySpace).Iter()
+		name string
+	)
+	for iter.Scan(&name) {
+		tables[name] = true
+	}
+	if err := iter.Close(); err != nil {
+		return nil, common.NewErrorf("migrate_cassandra",
+			"reading tables: %v", err)
+	}
+	return tables, nil
+}
+
+// stringID converts numeric id of a JSON row to a string, the id column of
+// magic_block_map is a bigint, but the entity keys are strings.
+func stringID(row string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(row), &fields); err != nil {
+		return "", err
+	}
+	if id, ok := fields["id"]; ok && len(id) > 0 && id[0] != '"' &&
+		string(id) != "null" {
+
+		fields["id"] = json.RawMessage(strconv.Quote(string(id)))
+	}
+	var data, err = json.Marshal(fields)
+	return string(data), err
+}
+
+func migrateTables(ctx context.Context, kvs *kvstore.Store,
+	c persistencestore.SessionI) (migrated map[string]int, err error) {
+
+	migrated = make(map[string]int)
+	tables, err := cassandraTables(c)
+	if err != nil {
+		return migrated, err
+	}
+	for _, name := range migratedTables {
+		if !tables[name] {
+			Logger.Info("migrate cassandra - no table, skipped",
+				zap.String("table", name))
+			continue
+		}
+		var (
+			emd      = datastore.GetEntityMetadata(name)
+			iter     = c.Query("SELECT JSON * FROM " + name).Iter()
+			entities = make([]datastore.Entity, 0, migrateBatchSize)
+			row      string
+		)
+		var flush = func() error {
+			if len(entities) == 0 {
+				return nil
+			}
+			if err := kvs.MultiWrite(ctx, emd, entities); err != nil {
+				return err
+			}
+			migrated[name] += len(entities)
+			entities = entities[:0]
+			return nil
+		}
+		for iter.Scan(&row) {
+			if name == "magic_block_map" {
+				row, err = stringID(row)
+			}
+			var entity = emd.Instance()
+			if err == nil {
+				err = datastore.FromJSON(row, entity)
+			}
+			if err != nil {
+				iter.Close()
+				return migrated, common.NewErrorf("migrate_cassandra",
+					"decoding %s row: %v", name, err)
+			}
+			if entities = append(entities, entity); len(entities) == migrateBatchSize {
+				if err = flush(); err != nil {
+					iter.Close()
+					return migrated, err
+				}
+			}
+		}
+		if err = iter.Close(); err != nil {
+			return migrated, common.NewErrorf("migrate_cassandra",
+				"reading %s: %v", name, err)
+		}
+		if err = flush(); err != nil {
+			return migrated, err
+		}
+		Logger.Info("migrate cassandra - table migrated",
+			zap.String("table", name), zap.Int("rows", migrated[name]))
+	}
+	return migrated, nil
+}
@@ -0,0 +1,162 @@
+package sharder
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/datastore"
+	"0chain.net/core/kvstore"
+	"0chain.net/core/persistencestore"
+)
+
+func TestKVStoreTxnClientHistory(t *testing.T) {
+	var (
+		ctx = context.Background()
+		kvs = kvstore.NewStore(kvstore.NewMemoryKV())
+		sc  = &Chain{}
+	)
+	SetupKVStore(kvs)
+	transaction.SetupTxnSummaryEntity(kvs)
+	transaction.SetupTxnClientHistoryEntity(kvs)
+
+	var (
+		emd      = datastore.GetEntityMetadata("txn_client_history")
+		entities []datastore.Entity
+	)
+	for _, e := range []struct {
+		round int64
+		hash  string
+	}{{2, "b"}, {1, "b"}, {2, "a"}, {3, "a"}} {
+		entities = append(entities, &transaction.TxnClientHistory{
+			ClientID: "alice", Round: e.round, Hash: e.hash,
+			Roles: []string{transaction.TxnRoleSender},
+		})
+	}
+	require.NoError(t, emd.GetStore().MultiWrite(ctx, emd, entities))
+
+	th, err := sc.GetTxnClientHistory(ctx, "alice", 0, "", 2)
+	require.NoError(t, err)
+	require.Len(t, th.Transactions, 2)
+	require.Equal(t, "b", th.Transactions[0].Hash)
+	require.EqualValues(t, 2, th.NextRound)
+	require.Equal(t, "a", th.NextHash)
+
+	th, err = sc.GetTxnClientHistory(ctx, "alice", th.NextRound, th.NextHash, 2)
+	require.NoError(t, err)
+	require.Len(t, th.Transactions, 2)
+	require.Equal(t, "b", th.Transactions[0].Hash)
+	require.EqualValues(t, 3, th.Transactions[1].Round)
+	require.Zero(t, th.NextRound)
+
+	var summaries []datastore.Entity
+	for _, hash := range []string{"a", "b", "c"} {
+		var ts = &transaction.TransactionSummary{Round: 7}
+		ts.Hash = hash
+		summaries = append(summaries, ts)
+	}
+	var tsmd = datastore.GetEntityMetadata("txn_summary")
+	require.NoError(t, tsmd.GetStore().MultiWrite(ctx, tsmd, summaries))
+	count, err := sc.getTxnCountForRound(ctx, 7)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	count, err = sc.getTxnAndCountForRound(ctx, 8)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+// cassandraMock is a Cassandra session with tables of JSON rows, the
+// SELECT JSON queries of missing tables fail.
+type cassandraMock struct {
+	tables  map[string][]string
+	queried []string
+}
+
+func (cm *cassandraMock) Query(stmt string, values ...interface{}) persistencestore.QueryI {
+	if strings.Contains(stmt, "system_schema.tables") {
+		var names []string
+		for name := range cm.tables {
+			names = append(names, name)
+		}
+		return &cassandraQueryMock{rows: names}
+	}
+	var name = stmt[strings.LastIndex(stmt, " ")+1:]
+	cm.queried = append(cm.queried, name)
+	rows, ok := cm.tables[name]
+	if !ok {
+		return &cassandraQueryMock{err: gocql.ErrNotFound}
+	}
+	return &cassandraQueryMock{rows: rows}
+}
+
+func (cm *cassandraMock) NewBatch(gocql.BatchType) persistencestore.BatchI { return nil }
+func (cm *cassandraMock) ExecuteBatch(persistencestore.BatchI) error       { return nil }
+func (cm *cassandraMock) Close()                                           {}
+
+type cassandraQueryMock struct {
+	rows []string
+	err  error
+}
+
+func (qm *cassandraQueryMock) Bind(...interface{}) persistencestore.QueryI { return qm }
+func (qm *cassandraQueryMock) Exec() error                                 { return qm.err }
+func (qm *cassandraQueryMock) Scan(...interface{}) error                   { return qm.err }
+
+func (qm *cassandraQueryMock) Iter() persistencestore.IteratorI {
+	return &cassandraIterMock{rows: qm.rows, err: qm.err}
+}
+
+type cassandraIterMock struct {
+	rows []string
+	err  error
+}
+
+func (im *cassandraIterMock) Scan(dest ...interface{}) bool {
+	if len(im.rows) == 0 {
+		return false
+	}
+	*dest[0].(*string), im.rows = im.rows[0], im.rows[1:]
+	return true
+}
+
+func (im *cassandraIterMock) Close() error { return im.err }
+
+func TestMigrateFromCassandra(t *testing.T) {
+	var (
+		ctx = context.Background()
+		kvs = kvstore.NewStore(kvstore.NewMemoryKV())
+		cm  = &cassandraMock{tables: map[string][]string{
+			"txn_summary": {`{"hash": "a", "round": 7}`},
+			"magic_block_map": {
+				`{"id": 5, "hash": "mb5", "block_round": 50}`,
+				`{"id": 12, "hash": "mb12", "block_round": 120}`,
+			},
+		}}
+	)
+	SetupKVStore(kvs)
+	transaction.SetupTxnSummaryEntity(kvs)
+	transaction.SetupTxnClientHistoryEntity(kvs)
+	block.SetupMagicBlockMapEntity(kvs)
+
+	migrated, err := migrateTables(ctx, kvs, cm)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"txn_summary": 1, "magic_block_map": 2},
+		migrated)
+	require.Equal(t, []string{"txn_summary", "magic_block_map"}, cm.queried,
+		"missing tables and txn_confirmation are not queried")
+
+	var mbm = block.MagicBlockMapProvider().(*block.MagicBlockMap)
+	require.NoError(t, kvs.Read(ctx, "5", mbm))
+	require.Equal(t, "mb5", mbm.Hash)
+	require.EqualValues(t, 50, mbm.BlockRound)
+
+	highest, err := getHighestKVMagicBlockMap(ctx, kvs,
+		datastore.GetEntityMetadata("magic_block_map"))
+	require.NoError(t, err)
+	require.Equal(t, "12", highest.ID)
+}
@@ -19,6 +19,7 @@ import (
 	"0chain.net/chaincore/block"
 	"0chain.net/core/datastore"
 	. "0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	"go.uber.org/zap"
 )
 
@@ -37,6 +38,10 @@ func (sc *Chain) UpdatePendingBlock(ctx context.Context, b *block.Block, txns []
 
 /*UpdateFinalizedBlock - updates the finalized block */
 func (sc *Chain) UpdateFinalizedBlock(ctx context.Context, b *block.Block) {
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.store", b.Round, b.Hash)
+	defer span.Finish()
+
 	fr := sc.GetRound(b.Round)
 	Logger.Info("update finalized block", zap.Int64("round", b.Round), zap.String("block", b.Hash), zap.Any("lf_round", sc.GetLatestFinalizedBlock().Round), zap.Any("current_round", sc.GetCurrentRound()))
 	if config.Development() {
@@ -54,7 +59,12 @@ func (sc *Chain) UpdateFinalizedBlock(ctx context.Context, b *block.Block) {
 	fr.Finalize(b)
 	bsHistogram.Update(int64(len(b.Txns)))
 	node.Self.Underlying().Info.AvgBlockTxns = int(math.Round(bsHistogram.Mean()))
-	sc.StoreTransactions(ctx, b)
+	sc.storeBlockTransactions(ctx, b)
+	if ei := GetExplorerIndex(); ei != nil {
+		if err := ei.IndexBlock(b); err != nil {
+			Logger.Error("db error (index explorer block)", zap.Int64("round", b.Round), zap.String("block", b.Hash), zap.Error(err))
+		}
+	}
 	err := sc.StoreBlockSummaryFromBlock(ctx, b)
 	if err != nil {
 		Logger.Error("db error (store block summary)", zap.Any("round", b.Round), zap.String("block", b.Hash), zap.Error(err))
@@ -85,6 +95,7 @@ func (sc *Chain) UpdateFinalizedBlock(ctx context.Context, b *block.Block) {
 		}
 	}
 	sc.DeleteRoundsBelow(ctx, b.Round)
+	sc.finality.notify(b)
 }
 
 func (sc *Chain) ViewChange(ctx context.Context, b *block.Block) (err error) {
@@ -625,6 +636,9 @@ func (sc *Chain) storeBlockTransactions(ctx context.Context, b *block.Block) err
 	//		zap.String("block", b.Hash),
 	//		zap.Error(err))
 	//}
+	if herr := sc.StoreTxnClientHistory(ctx, b); err == nil {
+		err = herr
+	}
 	return err
 }
 
@@ -9,7 +9,9 @@ import (
 	"0chain.net/chaincore/block"
 	"0chain.net/chaincore/chain"
 	"0chain.net/chaincore/round"
+	"0chain.net/core/journal"
 	. "0chain.net/core/logging"
+	"0chain.net/core/tracing"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +23,10 @@ func shouldNotFinalize(r round.RoundI) bool {
 func (sc *Chain) AddNotarizedBlock(ctx context.Context, r round.RoundI,
 	b *block.Block) bool {
 
+	var span *tracing.Span
+	ctx, span = tracing.StartSpan(ctx, "block.notarize", b.Round, b.Hash)
+	defer span.Finish()
+
 	_, ok, err := r.AddNotarizedBlock(b)
 	if err != nil {
 		Logger.Error("Add notarized block failed",
@@ -33,6 +39,10 @@ func (sc *Chain) AddNotarizedBlock(ctx context.Context, r round.RoundI,
 	if !ok && shouldNotFinalize(r) {
 		return false
 	}
+	if ok {
+		journal.Record(&journal.Event{Type: journal.BlockNotarized,
+			Round: b.Round, Block: b.Hash, Node: b.MinerID})
+	}
 	if sc.BlocksToSharder == chain.FINALIZED {
 		nb := r.GetNotarizedBlocks()
 		if len(nb) > 0 {
@@ -42,6 +52,7 @@ func (sc *Chain) AddNotarizedBlock(ctx context.Context, r round.RoundI,
 		}
 	}
 	sc.UpdateNodeState(b)
+	sc.finality.notify(b)
 
 	errC := make(chan error)
 	doneC := make(chan struct{})
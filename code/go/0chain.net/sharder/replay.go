@@ -0,0 +1,494 @@
+package sharder
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+
+	. "0chain.net/core/logging"
+)
+
+// ReplayMaxPaths is default limit of differing state paths reported.
+const ReplayMaxPaths = 100
+
+// StatePathDiff is a state MPT path with different values in the stored and
+// the computed states. A value is the hash of the value node, empty if the
+// path has no value in the state.
+type StatePathDiff struct {
+	Path     string `json:"path"`
+	Stored   string `json:"stored,omitempty"`
+	Computed string `json:"computed,omitempty"`
+}
+
+// ReplayDivergence describes the first replayed block the computed state of
+// which differs from the stored one.
+type ReplayDivergence struct {
+	Round         int64  `json:"round"`
+	Block         string `json:"block"`
+	StoredState   string `json:"stored_state"`
+	ComputedState string `json:"computed_state,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// Transaction is the first divergent transaction of the block, if found.
+	Transaction      string `json:"transaction,omitempty"`
+	TransactionIndex int    `json:"transaction_index"`
+	// Reason is why the transaction is considered divergent.
+	Reason string           `json:"reason,omitempty"`
+	Paths  []*StatePathDiff `json:"paths,omitempty"`
+	// PathsError is set when the differing paths can't be found, for
+	// example when the stored state of the block has been pruned.
+	PathsError string `json:"paths_error,omitempty"`
+}
+
+// ReplayReport is result of a blocks replay.
+type ReplayReport struct {
+	From         int64             `json:"from"`
+	To           int64             `json:"to"`
+	Blocks       int               `json:"blocks"`
+	Transactions int               `json:"transactions"`
+	Divergence   *ReplayDivergence `json:"divergence,omitempty"`
+}
+
+// replayExecutor executes a transaction updating the block client state.
+type replayExecutor interface {
+	UpdateState(ctx context.Context, b *block.Block,
+		txn *transaction.Transaction) error
+}
+
+// replaySource provides stored blocks to replay.
+type replaySource interface {
+	getReplayBlock(ctx context.Context, rNum int64) (*block.Block, error)
+}
+
+// Replayer re-computes the state of stored blocks starting from an initial
+// state and compares it with the stored client state hash of the blocks.
+// The computed state is kept in memory on top of the node db of the
+// initial state, nothing is written to the node db. Only the nodes of the
+// latest verified state missing in the node db are kept in memory.
+type Replayer struct {
+	executor replayExecutor
+	source   replaySource
+	// stateDB is the node db stored states of the blocks are read from
+	stateDB util.NodeDB
+	// base keeps the computed states on top of the state db
+	base *util.LevelNodeDB
+	// MaxPaths limits the differing state paths reported.
+	MaxPaths int
+}
+
+// NewReplayer creates a blocks replayer.
+func NewReplayer(executor replayExecutor, source replaySource,
+	stateDB util.NodeDB) *Replayer {
+
+	return &Replayer{
+		executor: executor,
+		source:   source,
+		stateDB:  stateDB,
+		base:     util.NewLevelNodeDB(util.NewMemoryNodeDB(), stateDB, false),
+		MaxPaths: ReplayMaxPaths,
+	}
+}
+
+// NewReplayer returns replayer of the sharder stored blocks.
+func (sc *Chain) NewReplayer() *Replayer {
+	return NewReplayer(sc, sc, sc.GetStateDB())
+}
+
+// GenesisReplayState returns the root of the state of given init states
+// created in memory of the replayer.
+func (sc *Chain) GenesisReplayState(rp *Replayer,
+	initStates *state.InitStates) util.Key {
+
+	return sc.NewInitialState(initStates, rp.base).GetRoot()
+}
+
+func (sc *Chain) getReplayBlock(ctx context.Context, rNum int64) (
+	*block.Block, error) {
+
+	hash, err := sc.getRoundBlockHash(ctx, rNum)
+	if err != nil {
+		return nil, err
+	}
+	return sc.GetBlockFromStore(hash, rNum)
+}
+
+// replayChainer implements block.Chainer for a single block replay.
+type replayChainer struct {
+	rp *Replayer
+	pb *block.Block
+	// watch is called after every executed transaction
+	watch func(i int, b *block.Block, txn *transaction.Transaction)
+	txns  int
+}
+
+func (rc *replayChainer) GetPreviousBlock(ctx context.Context,
+	b *block.Block) *block.Block {
+
+	return rc.pb
+}
+
+func (rc *replayChainer) GetBlockStateChange(b *block.Block) error {
+	return block.ErrPreviousStateUnavailable
+}
+
+func (rc *replayChainer) ComputeState(ctx context.Context,
+	pb *block.Block) error {
+
+	return block.ErrPreviousStateUnavailable
+}
+
+func (rc *replayChainer) GetStateDB() util.NodeDB {
+	return rc.rp.base
+}
+
+func (rc *replayChainer) UpdateState(ctx context.Context, b *block.Block,
+	txn *transaction.Transaction) error {
+
+	if err := rc.rp.executor.UpdateState(ctx, b, txn); err != nil {
+		return common.NewErrorf("replay_update_state", "txn %s (%d): %v",
+			txn.Hash, rc.txns, err)
+	}
+	if rc.watch != nil {
+		rc.watch(rc.txns, b, txn)
+	}
+	rc.txns++
+	return nil
+}
+
+// computeState computes state of the block on top of the previous one.
+func (rp *Replayer) computeState(ctx context.Context, b, pb *block.Block,
+	watch func(int, *block.Block, *transaction.Transaction)) (
+	*replayChainer, error) {
+
+	var rc = &replayChainer{rp: rp, pb: pb, watch: watch}
+	b.PrevBlock = pb
+	b.SetStateStatus(block.StatePending)
+	return rc, b.ComputeState(ctx, rc)
+}
+
+// Replay computes state of the from-to stored blocks in order starting
+// with the given state root of the block preceding the from round. It
+// stops on the first block the computed state of which differs.
+func (rp *Replayer) Replay(ctx context.Context, from, to int64,
+	root util.Key) (*ReplayReport, error) {
+
+	if from <= 0 || to < from {
+		return nil, common.NewErrorf("replay", "invalid rounds range %d-%d",
+			from, to)
+	}
+	if _, err := rp.base.GetNode(root); err != nil {
+		return nil, common.NewErrorf("replay",
+			"initial state %s not available: %v", util.ToHex(root), err)
+	}
+
+	var (
+		report = &ReplayReport{From: from, To: to}
+		pb     = block.NewBlock("", from-1)
+	)
+	pb.ClientStateHash = root
+	pb.CreateState(rp.base, root)
+	pb.SetStateStatus(block.StateSuccessful)
+
+	for rNum := from; rNum <= to; rNum++ {
+		b, err := rp.source.getReplayBlock(ctx, rNum)
+		if err != nil {
+			return report, common.NewErrorf("replay", "round %d: %v", rNum, err)
+		}
+		if pb.Hash != "" && b.PrevHash != pb.Hash {
+			return report, common.NewErrorf("replay",
+				"round %d: previous block %s, replayed %s", rNum, b.PrevHash,
+				pb.Hash)
+		}
+
+		var outputs = make([]string, len(b.Txns))
+		for i, txn := range b.Txns {
+			outputs[i] = txn.OutputHash
+		}
+		rc, err := rp.computeState(ctx, b, pb, nil)
+		report.Transactions += rc.txns
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			report.Divergence = rp.divergence(ctx, b, pb, outputs, rc, err)
+			Logger.Info("replay - divergent block", zap.Int64("round", rNum),
+				zap.String("block", b.Hash), zap.Error(err))
+			return report, nil
+		}
+		report.Blocks++
+
+		// keep the computed state in the replayer base node db, that's
+		// only one level on top of the state db, and drop the previous one
+		if err = b.ClientState.SaveChanges(ctx, rp.base, false); err != nil {
+			return report, err
+		}
+		if err = rp.rebase(b.ClientStateHash); err != nil {
+			return report, err
+		}
+		b.CreateState(rp.base, b.ClientStateHash)
+		b.PrevBlock = nil
+		pb = b
+	}
+	return report, nil
+}
+
+// rebase replaces the memory level of the base node db with one keeping
+// only nodes of the state root missing in the state db. A node found in the
+// state db is expected to be there with all its sub-trie.
+func (rp *Replayer) rebase(root util.Key) error {
+	var (
+		prev = rp.base.GetCurrent()
+		mem  = util.NewMemoryNodeDB()
+	)
+	var keep func(key util.Key) error
+	keep = func(key util.Key) error {
+		if _, err := rp.stateDB.GetNode(key); err == nil {
+			return nil
+		}
+		node, err := prev.GetNode(key)
+		if err != nil {
+			return common.NewErrorf("replay", "missing state node %s: %v",
+				util.ToHex(key), err)
+		}
+		if err = mem.PutNode(key, node); err != nil {
+			return err
+		}
+		switch n := node.(type) {
+		case *util.FullNode:
+			for _, ck := range n.Children {
+				if len(ck) == 0 {
+					continue
+				}
+				if err = keep(ck); err != nil {
+					return err
+				}
+			}
+		case *util.ExtensionNode:
+			return keep(n.NodeKey)
+		}
+		return nil
+	}
+	if err := keep(root); err != nil {
+		return err
+	}
+	rp.base = util.NewLevelNodeDB(mem, rp.stateDB, false)
+	return nil
+}
+
+// divergence finds the first divergent transaction of the block and the
+// state paths differing in the stored and the computed state.
+func (rp *Replayer) divergence(ctx context.Context, b, pb *block.Block,
+	outputs []string, rc *replayChainer, err error) *ReplayDivergence {
+
+	var d = &ReplayDivergence{
+		Round:            b.Round,
+		Block:            b.Hash,
+		StoredState:      util.ToHex(b.ClientStateHash),
+		TransactionIndex: -1,
+	}
+	if err != block.ErrStateMismatch {
+		// a transaction failed, it's the divergent one
+		d.Error = err.Error()
+		if rc.txns < len(b.Txns) {
+			d.Transaction = b.Txns[rc.txns].Hash
+			d.TransactionIndex = rc.txns
+			d.Reason = "execution_failed"
+		}
+		return d
+	}
+	d.ComputedState = util.ToHex(b.ClientState.GetRoot())
+
+	for i, txn := range b.Txns {
+		if txn.ComputeOutputHash() != outputs[i] {
+			d.Transaction = txn.Hash
+			d.TransactionIndex = i
+			d.Reason = "output_mismatch"
+			break
+		}
+	}
+
+	var stored = util.NewMerklePatriciaTrie(rp.stateDB, util.Sequence(b.Round))
+	stored.SetRoot(b.ClientStateHash)
+	d.Paths, err = diffStatePaths(ctx, stored, b.ClientState, rp.MaxPaths)
+	if err != nil {
+		d.PathsError = err.Error()
+		return d
+	}
+	if d.Transaction != "" || len(d.Paths) == 0 {
+		return d
+	}
+
+	// replay the block again to find the first transaction changing any of
+	// the differing paths
+	rb, err := rp.source.getReplayBlock(ctx, b.Round)
+	if err != nil {
+		return d
+	}
+	var before = make([][]byte, len(d.Paths))
+	for i, p := range d.Paths {
+		before[i] = stateValueHash(pb.ClientState, util.Path(p.Path))
+	}
+	rp.computeState(ctx, rb, pb, func(i int, b *block.Block,
+		txn *transaction.Transaction) {
+
+		if d.Transaction != "" {
+			return
+		}
+		for j, p := range d.Paths {
+			var after = stateValueHash(b.ClientState, util.Path(p.Path))
+			if !bytes.Equal(before[j], after) {
+				d.Transaction = txn.Hash
+				d.TransactionIndex = i
+				d.Reason = "changes_differing_path"
+				return
+			}
+		}
+	})
+	return d
+}
+
+// stateValueHash returns hash of the value of the path, nil if none.
+func stateValueHash(mpt util.MerklePatriciaTrieI, path util.Path) []byte {
+	value, err := mpt.GetNodeValue(path)
+	if err != nil || value == nil {
+		return nil
+	}
+	return encryption.RawHash(value.Encode())
+}
+
+// diffStatePaths returns up to limit value paths that differ in the given
+// tries skipping the equal sub-tries.
+func diffStatePaths(ctx context.Context, stored,
+	computed util.MerklePatriciaTrieI, limit int) ([]*StatePathDiff, error) {
+
+	var diffs []*StatePathDiff
+	err := diffStateNodes(ctx, stored, computed, util.Path{}, stored.GetRoot(),
+		computed.GetRoot(), func(d *StatePathDiff) bool {
+			diffs = append(diffs, d)
+			return limit <= 0 || len(diffs) < limit
+		})
+	if err != nil && err != errStopDiff {
+		return diffs, err
+	}
+	return diffs, nil
+}
+
+var errStopDiff = common.NewError("stop_diff", "stop the state diff")
+
+func diffStateNodes(ctx context.Context, stored,
+	computed util.MerklePatriciaTrieI, path util.Path, sk, ck util.Key,
+	add func(*StatePathDiff) bool) error {
+
+	if bytes.Equal(sk, ck) {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var sn, cn util.Node
+	if len(sk) > 0 {
+		var err error
+		if sn, err = stored.GetNodeDB().GetNode(sk); err != nil {
+			return common.NewErrorf("diff_state", "stored node %s: %v",
+				util.ToHex(sk), err)
+		}
+	}
+	if len(ck) > 0 {
+		var err error
+		if cn, err = computed.GetNodeDB().GetNode(ck); err != nil {
+			return common.NewErrorf("diff_state", "computed node %s: %v",
+				util.ToHex(ck), err)
+		}
+	}
+
+	sfn, sok := sn.(*util.FullNode)
+	cfn, cok := cn.(*util.FullNode)
+	if sok && cok {
+		var sv, cv = valueNodeHash(sfn.Value), valueNodeHash(cfn.Value)
+		if sv != cv && !add(&StatePathDiff{Path: string(path), Stored: sv,
+			Computed: cv}) {
+			return errStopDiff
+		}
+		for i := range sfn.Children {
+			var cpath = append(append(util.Path{}, path...), hexIndex(i))
+			err := diffStateNodes(ctx, stored, computed, cpath,
+				sfn.Children[i], cfn.Children[i], add)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// different kinds of nodes, compare all the values of the sub-tries
+	svs, err := subTrieValues(ctx, stored, path, sk)
+	if err != nil {
+		return err
+	}
+	cvs, err := subTrieValues(ctx, computed, path, ck)
+	if err != nil {
+		return err
+	}
+	var paths = make([]string, 0, len(svs)+len(cvs))
+	for p := range svs {
+		paths = append(paths, p)
+	}
+	for p := range cvs {
+		if _, ok := svs[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if svs[p] != cvs[p] && !add(&StatePathDiff{Path: p, Stored: svs[p],
+			Computed: cvs[p]}) {
+			return errStopDiff
+		}
+	}
+	return nil
+}
+
+func hexIndex(i int) byte {
+	if i < 10 {
+		return byte('0' + i)
+	}
+	return byte('a' + i - 10)
+}
+
+func valueNodeHash(vn *util.ValueNode) string {
+	if vn == nil || !vn.HasValue() {
+		return ""
+	}
+	return vn.GetHash()
+}
+
+// subTrieValues returns hashes of values of the sub-trie by full paths.
+func subTrieValues(ctx context.Context, mpt util.MerklePatriciaTrieI,
+	path util.Path, key util.Key) (map[string]string, error) {
+
+	var values = make(map[string]string)
+	if len(key) == 0 {
+		return values, nil
+	}
+	err := mpt.IterateFrom(ctx, key, func(ctx context.Context, p util.Path,
+		_ util.Key, node util.Node) error {
+
+		if vn, ok := node.(*util.ValueNode); ok && vn.HasValue() {
+			values[string(path)+string(p)] = vn.GetHash()
+		}
+		return nil
+	}, util.NodeTypeValueNode)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,261 @@
+package sharder
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// testReplayExecutor sets value of the txn data to the path of the txn
+// receiver.
+type testReplayExecutor struct {
+	// txn hash -> divergent value written to the state
+	state map[string]string
+	// txn hash -> divergent output
+	output map[string]string
+}
+
+func (e *testReplayExecutor) UpdateState(ctx context.Context, b *block.Block,
+	txn *transaction.Transaction) error {
+
+	if txn.TransactionData == "fail" {
+		return common.NewError("fail", "txn failed")
+	}
+	var value = txn.TransactionData
+	if v, ok := e.state[txn.Hash]; ok {
+		value = v
+	}
+	_, err := b.ClientState.Insert(util.Path(encryption.Hash(txn.ToClientID)),
+		&util.SecureSerializableValue{Buffer: []byte(value)})
+	txn.TransactionOutput = txn.TransactionData
+	if v, ok := e.output[txn.Hash]; ok {
+		txn.TransactionOutput = v
+	}
+	return err
+}
+
+// testReplaySource keeps JSON of blocks to return fresh stored blocks.
+type testReplaySource struct {
+	blocks map[int64][]byte
+}
+
+func (s *testReplaySource) getReplayBlock(ctx context.Context, rNum int64) (
+	*block.Block, error) {
+
+	data, ok := s.blocks[rNum]
+	if !ok {
+		return nil, common.NewError("not_found", "block not found")
+	}
+	var b = new(block.Block)
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// makeTestReplayChain computes and stores state of blocks of given txns
+// data, returns the genesis state root.
+func makeTestReplayChain(t *testing.T, stateDB util.NodeDB,
+	source *testReplaySource, txns [][]string) util.Key {
+
+	var (
+		ctx     = context.Background()
+		genesis = util.NewMerklePatriciaTrie(stateDB, 0)
+		exec    = &testReplayExecutor{}
+	)
+	_, err := genesis.Insert(util.Path(encryption.Hash("genesis")),
+		&util.SecureSerializableValue{Buffer: []byte("genesis")})
+	require.NoError(t, err)
+	require.NoError(t, genesis.SaveChanges(ctx, stateDB, false))
+
+	var (
+		prevHash string
+		prevRoot = genesis.GetRoot()
+	)
+	for i, data := range txns {
+		var b = block.NewBlock("", int64(i+1))
+		b.PrevHash = prevHash
+		b.CreateState(stateDB, prevRoot)
+		for j, d := range data {
+			var txn = &transaction.Transaction{
+				ToClientID:      "to" + strconv.Itoa(j),
+				TransactionData: d,
+			}
+			txn.Hash = encryption.Hash(strconv.Itoa(i) + ":" + strconv.Itoa(j))
+			require.NoError(t, exec.UpdateState(ctx, b, txn))
+			txn.OutputHash = txn.ComputeOutputHash()
+			b.Txns = append(b.Txns, txn)
+		}
+		b.ClientStateHash = b.ClientState.GetRoot()
+		require.NoError(t, b.ClientState.SaveChanges(ctx, stateDB, false))
+		b.HashBlock()
+		data, err := json.Marshal(b)
+		require.NoError(t, err)
+		source.blocks[b.Round] = data
+		prevHash, prevRoot = b.Hash, b.ClientStateHash
+	}
+	return genesis.GetRoot()
+}
+
+func TestReplayer(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		stateDB = util.NewMemoryNodeDB()
+		source  = &testReplaySource{blocks: make(map[int64][]byte)}
+		root    = makeTestReplayChain(t, stateDB, source, [][]string{
+			{"a", "b"}, {"c", "d", "e"}, {}, {"f"},
+		})
+		txn = func(i, j int) string {
+			return encryption.Hash(strconv.Itoa(i) + ":" + strconv.Itoa(j))
+		}
+	)
+
+	var rp = NewReplayer(&testReplayExecutor{}, source, stateDB)
+	report, err := rp.Replay(ctx, 1, 4, root)
+	require.NoError(t, err)
+	require.Nil(t, report.Divergence)
+	require.Equal(t, 4, report.Blocks)
+	require.Equal(t, 6, report.Transactions)
+	// all the computed state is found in the state db
+	require.Zero(t, rp.base.GetCurrent().Size(ctx))
+
+	// the state diverges, but not the output
+	rp = NewReplayer(&testReplayExecutor{
+		state: map[string]string{txn(1, 1): "x"},
+	}, source, stateDB)
+	report, err = rp.Replay(ctx, 1, 4, root)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Blocks)
+	var d = report.Divergence
+	require.NotNil(t, d)
+	require.EqualValues(t, 2, d.Round)
+	require.NotEqual(t, d.StoredState, d.ComputedState)
+	require.Equal(t, txn(1, 1), d.Transaction)
+	require.Equal(t, 1, d.TransactionIndex)
+	require.Equal(t, "changes_differing_path", d.Reason)
+	require.Len(t, d.Paths, 1)
+	require.Equal(t, encryption.Hash("to1"), d.Paths[0].Path)
+	require.NotEqual(t, d.Paths[0].Stored, d.Paths[0].Computed)
+	require.Empty(t, d.PathsError)
+
+	// the output diverges first
+	rp = NewReplayer(&testReplayExecutor{
+		state:  map[string]string{txn(1, 2): "x"},
+		output: map[string]string{txn(1, 0): "y"},
+	}, source, stateDB)
+	report, err = rp.Replay(ctx, 2, 4, testReplayStateRoot(t, source, 1))
+	require.NoError(t, err)
+	d = report.Divergence
+	require.Equal(t, txn(1, 0), d.Transaction)
+	require.Equal(t, "output_mismatch", d.Reason)
+	require.Len(t, d.Paths, 1)
+	require.Equal(t, encryption.Hash("to2"), d.Paths[0].Path)
+
+	// a transaction fails
+	var fails = &testReplaySource{blocks: make(map[int64][]byte)}
+	root = makeTestReplayChain(t, stateDB, fails, [][]string{{"a", "b"}, {"c"}})
+	b, err := fails.getReplayBlock(ctx, 1)
+	require.NoError(t, err)
+	b.Txns[1].TransactionData = "fail"
+	fails.blocks[1], err = json.Marshal(b)
+	require.NoError(t, err)
+	report, err = NewReplayer(&testReplayExecutor{}, fails, stateDB).
+		Replay(ctx, 1, 2, root)
+	require.NoError(t, err)
+	require.Zero(t, report.Blocks)
+	require.Equal(t, "execution_failed", report.Divergence.Reason)
+	require.Equal(t, 1, report.Divergence.TransactionIndex)
+
+	// the initial state isn't available
+	_, err = NewReplayer(&testReplayExecutor{}, source,
+		util.NewMemoryNodeDB()).Replay(ctx, 1, 4, root)
+	require.Error(t, err)
+}
+
+func TestReplayer_keepsLatestState(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		chainDB = util.NewMemoryNodeDB()
+		source  = &testReplaySource{blocks: make(map[int64][]byte)}
+		root    = makeTestReplayChain(t, chainDB, source, [][]string{
+			{"a", "b"}, {"c", "d", "e"}, {}, {"f"},
+		})
+	)
+
+	// the state db has the genesis state only
+	var stateDB = util.NewMemoryNodeDB()
+	node, err := chainDB.GetNode(root)
+	require.NoError(t, err)
+	require.NoError(t, stateDB.PutNode(root, node))
+
+	var rp = NewReplayer(&testReplayExecutor{}, source, stateDB)
+	report, err := rp.Replay(ctx, 1, 4, root)
+	require.NoError(t, err)
+	require.Nil(t, report.Divergence)
+	require.Equal(t, 4, report.Blocks)
+
+	// only nodes of the latest state are kept in memory
+	var mem = rp.base.GetCurrent().(*util.MemoryNodeDB)
+	require.NotZero(t, mem.Size(ctx))
+	latest, err := mem.GetNode(testReplayStateRoot(t, source, 4))
+	require.NoError(t, err)
+	require.NoError(t, mem.Validate(latest))
+}
+
+func testReplayStateRoot(t *testing.T, source *testReplaySource,
+	rNum int64) util.Key {
+
+	b, err := source.getReplayBlock(context.Background(), rNum)
+	require.NoError(t, err)
+	return b.ClientStateHash
+}
+
+func TestDiffStatePaths(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		stored   = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0)
+		computed = util.NewMerklePatriciaTrie(util.NewMemoryNodeDB(), 0)
+		value    = func(v string) util.Serializable {
+			return &util.SecureSerializableValue{Buffer: []byte(v)}
+		}
+	)
+	for i := 0; i < 50; i++ {
+		var path = util.Path(encryption.Hash(strconv.Itoa(i)))
+		_, err := stored.Insert(path, value("v"))
+		require.NoError(t, err)
+		switch {
+		case i == 7:
+			_, err = computed.Insert(path, value("x"))
+		case i != 9:
+			_, err = computed.Insert(path, value("v"))
+		}
+		require.NoError(t, err)
+	}
+	_, err := computed.Insert(util.Path(encryption.Hash("new")), value("v"))
+	require.NoError(t, err)
+
+	diffs, err := diffStatePaths(ctx, stored, computed, 0)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	var paths = make(map[string]*StatePathDiff)
+	for _, d := range diffs {
+		paths[d.Path] = d
+	}
+	require.NotEmpty(t, paths[encryption.Hash("7")].Computed)
+	require.NotEmpty(t, paths[encryption.Hash("9")].Stored)
+	require.Empty(t, paths[encryption.Hash("9")].Computed)
+	require.Empty(t, paths[encryption.Hash("new")].Stored)
+
+	diffs, err = diffStatePaths(ctx, stored, computed, 2)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+}
@@ -0,0 +1,394 @@
+package sharder
+
+import (
+	"context"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/round"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	. "0chain.net/core/logging"
+)
+
+// maxUnderReplicatedRanges is max number of the under-replicated ranges kept
+// in a report.
+const maxUnderReplicatedRanges = 100
+
+// ReplicationAuditConfig is configuration of the replication audit.
+type ReplicationAuditConfig struct {
+	Enabled bool
+	// Window is number of rounds below the LFB the rounds are sampled from,
+	// 0 samples the entire blockchain.
+	Window int64
+	// Settle is number of latest finalized rounds skipped to give the
+	// replicators time to store the blocks.
+	Settle int64
+	// SampleSize is number of rounds checked per cycle.
+	SampleSize     int
+	RepeatInterval time.Duration
+	// Repair re-pushes under-replicated blocks to the replicators missing them.
+	Repair bool
+}
+
+// UnderReplicatedRange is a range of sampled rounds with blocks stored by
+// fewer sharders than expected. Adjacent under-replicated samples are
+// merged into one range.
+type UnderReplicatedRange struct {
+	Low        int64 `json:"low"`
+	High       int64 `json:"high"`
+	Rounds     int   `json:"rounds"`      // under-replicated sampled rounds
+	MinHolders int   `json:"min_holders"` // the least replicated block holders
+	Expected   int   `json:"expected"`
+	Repaired   int   `json:"repaired"` // blocks pushed to replicators
+}
+
+// ReplicationAuditReport is the result of a replication audit cycle.
+type ReplicationAuditReport struct {
+	Cycle           int64                   `json:"cycle"`
+	Start           time.Time               `json:"start"`
+	End             time.Time               `json:"end"`
+	Low             int64                   `json:"low"`
+	High            int64                   `json:"high"`
+	Sampled         int                     `json:"sampled"`
+	Unknown         int                     `json:"unknown"` // rounds without local round summary
+	UnderReplicated int                     `json:"under_replicated"`
+	Repaired        int                     `json:"repaired"`
+	RepairFailures  int                     `json:"repair_failures"`
+	Ranges          []*UnderReplicatedRange `json:"ranges"`
+}
+
+// replicationProbe checks and repairs presence of blocks on the sharders.
+type replicationProbe interface {
+	// getReplicators returns hash of the block finalized in the round and
+	// the sharders expected to store it.
+	getReplicators(ctx context.Context, round int64) (hash string,
+		replicators []*node.Node, err error)
+	holdsBlock(ctx context.Context, n *node.Node, round int64, hash string) bool
+	fetchBlock(ctx context.Context, round int64, hash string) (*block.Block, error)
+	pushBlock(ctx context.Context, n *node.Node, b *block.Block) bool
+}
+
+// ReplicationAudit samples finalized rounds and checks that the blocks are
+// stored by all the sharders expected by the replicators assignment.
+type ReplicationAudit struct {
+	Config ReplicationAuditConfig
+	probe  replicationProbe
+	rand   *rand.Rand
+
+	mutex  sync.RWMutex
+	cycle  int64
+	last   *ReplicationAuditReport
+	totals ReplicationAuditReport
+}
+
+// NewReplicationAudit returns replication audit using given probe.
+func NewReplicationAudit(config ReplicationAuditConfig,
+	probe replicationProbe) *ReplicationAudit {
+
+	return &ReplicationAudit{
+		Config: config,
+		probe:  probe,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// sampleRounds returns up to n distinct ordered rounds of [low, high].
+func (ra *ReplicationAudit) sampleRounds(low, high int64, n int) []int64 {
+	if high < low || n <= 0 {
+		return nil
+	}
+	var count = high - low + 1
+	if int64(n) >= count {
+		var rounds = make([]int64, 0, count)
+		for r := low; r <= high; r++ {
+			rounds = append(rounds, r)
+		}
+		return rounds
+	}
+	var (
+		picked = make(map[int64]struct{}, n)
+		rounds = make([]int64, 0, n)
+	)
+	for len(rounds) < n {
+		var r = low + ra.rand.Int63n(count)
+		if _, ok := picked[r]; ok {
+			continue
+		}
+		picked[r] = struct{}{}
+		rounds = append(rounds, r)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	return rounds
+}
+
+// bounds returns rounds range of an audit cycle for given LFB round.
+func (ra *ReplicationAudit) bounds(lfbRound int64) (low, high int64) {
+	high = lfbRound - ra.Config.Settle
+	low = 1
+	if ra.Config.Window > 0 && high-ra.Config.Window+1 > low {
+		low = high - ra.Config.Window + 1
+	}
+	return
+}
+
+// auditRound checks the replicators of the round block and pushes the block
+// to the ones missing it if the repair is enabled.
+func (ra *ReplicationAudit) auditRound(ctx context.Context, r int64,
+	report *ReplicationAuditReport,
+	last *UnderReplicatedRange) *UnderReplicatedRange {
+
+	hash, replicators, err := ra.probe.getReplicators(ctx, r)
+	if err != nil {
+		report.Unknown++
+		return last // unknown round doesn't split a range
+	}
+	var missing []*node.Node
+	for _, n := range replicators {
+		if !ra.probe.holdsBlock(ctx, n, r, hash) {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	report.UnderReplicated++
+	var holders = len(replicators) - len(missing)
+	if last == nil {
+		if len(report.Ranges) == maxUnderReplicatedRanges {
+			last = report.Ranges[len(report.Ranges)-1]
+		} else {
+			last = &UnderReplicatedRange{Low: r, MinHolders: holders}
+			report.Ranges = append(report.Ranges, last)
+		}
+	}
+	last.High = r
+	last.Rounds++
+	if holders < last.MinHolders {
+		last.MinHolders = holders
+	}
+	if len(replicators) > last.Expected {
+		last.Expected = len(replicators)
+	}
+	Logger.Info("replication audit - under-replicated block",
+		zap.Int64("round", r), zap.String("block", hash),
+		zap.Int("holders", holders), zap.Int("expected", len(replicators)))
+
+	if !ra.Config.Repair {
+		return last
+	}
+	b, err := ra.probe.fetchBlock(ctx, r, hash)
+	if err != nil {
+		Logger.Error("replication audit - can't get block to repair",
+			zap.Int64("round", r), zap.String("block", hash), zap.Error(err))
+		report.RepairFailures += len(missing)
+		return last
+	}
+	for _, n := range missing {
+		if ra.probe.pushBlock(ctx, n, b) {
+			report.Repaired++
+			last.Repaired++
+			continue
+		}
+		report.RepairFailures++
+	}
+	return last
+}
+
+// RunCycle audits sampled rounds below the given LFB round.
+func (ra *ReplicationAudit) RunCycle(ctx context.Context,
+	lfbRound int64) *ReplicationAuditReport {
+
+	ra.mutex.Lock()
+	ra.cycle++
+	var report = &ReplicationAuditReport{
+		Cycle:  ra.cycle,
+		Start:  time.Now(),
+		Ranges: make([]*UnderReplicatedRange, 0),
+	}
+	ra.mutex.Unlock()
+
+	report.Low, report.High = ra.bounds(lfbRound)
+	var last *UnderReplicatedRange
+	for _, r := range ra.sampleRounds(report.Low, report.High,
+		ra.Config.SampleSize) {
+
+		select {
+		case <-ctx.Done():
+			return report
+		default:
+		}
+		report.Sampled++
+		last = ra.auditRound(ctx, r, report, last)
+	}
+	report.End = time.Now()
+
+	ra.mutex.Lock()
+	defer ra.mutex.Unlock()
+	ra.last = report
+	ra.totals.Cycle = ra.cycle
+	ra.totals.Sampled += report.Sampled
+	ra.totals.Unknown += report.Unknown
+	ra.totals.UnderReplicated += report.UnderReplicated
+	ra.totals.Repaired += report.Repaired
+	ra.totals.RepairFailures += report.RepairFailures
+	return report
+}
+
+// GetReports returns the last cycle report, nil before first cycle ends, and
+// the totals of all the cycles.
+func (ra *ReplicationAudit) GetReports() (last *ReplicationAuditReport,
+	totals ReplicationAuditReport) {
+
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+	return ra.last, ra.totals
+}
+
+// SetupReplicationAudit sets up the replication audit of the sharder.
+func (sc *Chain) SetupReplicationAudit(config ReplicationAuditConfig) {
+	sc.ReplicationAudit = NewReplicationAudit(config, sc)
+}
+
+// ReplicationAuditWorker runs the replication audit cycles.
+func (sc *Chain) ReplicationAuditWorker(ctx context.Context) {
+	var ra = sc.ReplicationAudit
+	if ra == nil || !ra.Config.Enabled || ra.Config.RepeatInterval <= 0 {
+		return
+	}
+	var ticker = time.NewTicker(ra.Config.RepeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var report = ra.RunCycle(ctx, sc.GetLatestFinalizedBlock().Round)
+			Logger.Info("replication audit - cycle done",
+				zap.Int64("cycle", report.Cycle),
+				zap.Int("sampled", report.Sampled),
+				zap.Int("under_replicated", report.UnderReplicated),
+				zap.Int("repaired", report.Repaired),
+				zap.Int("repair_failures", report.RepairFailures))
+		}
+	}
+}
+
+func (sc *Chain) getRoundBlockHash(ctx context.Context, rNum int64) (
+	string, error) {
+
+	var r = sc.GetSharderRound(rNum)
+	if r == nil {
+		var err error
+		if r, err = sc.GetRoundFromStore(ctx, rNum); err != nil {
+			return "", err
+		}
+	}
+	if r.BlockHash == "" {
+		return "", common.NewErrorf("get_round_block_hash",
+			"round %d has no block hash", rNum)
+	}
+	return r.BlockHash, nil
+}
+
+// getReplicators implements replicationProbe.
+func (sc *Chain) getReplicators(ctx context.Context, rNum int64) (
+	hash string, replicators []*node.Node, err error) {
+
+	if hash, err = sc.getRoundBlockHash(ctx, rNum); err != nil {
+		return "", nil, err
+	}
+	var sharders = sc.GetMagicBlock(rNum).Sharders
+	if sc.NumReplicators <= 0 || sc.NumReplicators >= sharders.Size() {
+		return hash, sharders.CopyNodes(), nil
+	}
+	_, replicators = sc.CanShardBlockWithReplicators(rNum, hash,
+		node.Self.Underlying())
+	return hash, replicators, nil
+}
+
+// holdsBlock implements replicationProbe.
+func (sc *Chain) holdsBlock(ctx context.Context, n *node.Node, rNum int64,
+	hash string) bool {
+
+	if n == node.Self.Underlying() {
+		_, ok := sc.hasBlock(hash, rNum)
+		return ok
+	}
+	var params = &url.Values{}
+	params.Add("hash", hash)
+	params.Add("round", strconv.FormatInt(rNum, 10))
+	params.Add("check_block", "true")
+	return n.RequestEntityFromNode(ctx, BlockSummaryRequestor, params,
+		func(ctx context.Context, entity datastore.Entity) (interface{}, error) {
+			bs, ok := entity.(*block.BlockSummary)
+			if !ok || bs.Hash != hash {
+				return nil, common.NewError("replication_audit",
+					"invalid block summary")
+			}
+			return bs, nil
+		})
+}
+
+// fetchBlock implements replicationProbe.
+func (sc *Chain) fetchBlock(ctx context.Context, rNum int64, hash string) (
+	*block.Block, error) {
+
+	if b, err := sc.GetBlockFromStore(hash, rNum); err == nil {
+		return b, nil
+	}
+	var r = round.NewRound(rNum)
+	r.BlockHash = hash
+	if b := sc.requestBlock(ctx, r); b != nil {
+		return b, nil
+	}
+	return nil, common.NewErrorf("replication_audit",
+		"block %s of round %d not found", hash, rNum)
+}
+
+// pushBlock implements replicationProbe.
+func (sc *Chain) pushBlock(ctx context.Context, n *node.Node,
+	b *block.Block) bool {
+
+	return BlockReplicateSender(b)(n)
+}
+
+/*ReplicateBlockHandler - handle a finalized block pushed by the replication audit of another sharder */
+func ReplicateBlockHandler(ctx context.Context, entity datastore.Entity) (interface{}, error) {
+	sc := GetSharderChain()
+	b, ok := entity.(*block.Block)
+	if !ok {
+		return nil, common.InvalidRequest("Invalid Entity")
+	}
+	if b.Round > sc.GetLatestFinalizedBlock().Round {
+		return nil, common.NewError("replicate_block", "block is not finalized yet")
+	}
+	hash, err := sc.getRoundBlockHash(ctx, b.Round)
+	if err != nil {
+		return nil, common.NewErrorf("replicate_block", "unknown round: %v", err)
+	}
+	if hash != b.Hash || b.ComputeHash() != b.Hash {
+		return nil, common.NewError("replicate_block", "block is not the finalized one")
+	}
+	if !sc.IsBlockSharderFromHash(b.Round, b.Hash, node.Self.Underlying()) {
+		return nil, common.NewError("replicate_block", "not a replicator of the block")
+	}
+	if _, ok := sc.hasBlock(b.Hash, b.Round); ok {
+		return true, nil
+	}
+	if err = sc.storeBlock(ctx, b); err != nil {
+		return nil, err
+	}
+	Logger.Info("replicate block - stored", zap.Int64("round", b.Round),
+		zap.String("block", b.Hash))
+	return true, nil
+}
@@ -0,0 +1,115 @@
+package sharder
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/node"
+	"0chain.net/core/common"
+)
+
+type testReplicationProbe struct {
+	replicators []*node.Node
+	// stored blocks by round by node
+	stored  map[int64]map[*node.Node]bool
+	unknown map[int64]bool
+	pushed  int
+}
+
+func (p *testReplicationProbe) getReplicators(ctx context.Context,
+	round int64) (string, []*node.Node, error) {
+
+	if p.unknown[round] {
+		return "", nil, common.NewError("unknown", "unknown round")
+	}
+	return strconv.FormatInt(round, 10), p.replicators, nil
+}
+
+func (p *testReplicationProbe) holdsBlock(ctx context.Context, n *node.Node,
+	round int64, hash string) bool {
+
+	return p.stored[round] == nil || p.stored[round][n]
+}
+
+func (p *testReplicationProbe) fetchBlock(ctx context.Context, round int64,
+	hash string) (*block.Block, error) {
+
+	var b = block.NewBlock("", round)
+	b.Hash = hash
+	return b, nil
+}
+
+func (p *testReplicationProbe) pushBlock(ctx context.Context, n *node.Node,
+	b *block.Block) bool {
+
+	p.stored[b.Round][n] = true
+	p.pushed++
+	return true
+}
+
+func TestReplicationAudit(t *testing.T) {
+	var (
+		n1, n2 = &node.Node{}, &node.Node{}
+		probe  = &testReplicationProbe{
+			replicators: []*node.Node{n1, n2},
+			stored: map[int64]map[*node.Node]bool{
+				3: {n1: true},
+				4: {},
+				6: {n2: true},
+			},
+			unknown: map[int64]bool{5: true},
+		}
+		ra = NewReplicationAudit(ReplicationAuditConfig{
+			Window: 8, Settle: 2, SampleSize: 100}, probe)
+	)
+
+	var report = ra.RunCycle(context.Background(), 10)
+	require.EqualValues(t, 1, report.Low)
+	require.EqualValues(t, 8, report.High)
+	require.Equal(t, 8, report.Sampled)
+	require.Equal(t, 1, report.Unknown)
+	require.Equal(t, 3, report.UnderReplicated)
+	require.Zero(t, report.Repaired)
+	// the unknown round 5 doesn't split the range
+	require.Equal(t, []*UnderReplicatedRange{
+		{Low: 3, High: 6, Rounds: 3, MinHolders: 0, Expected: 2},
+	}, report.Ranges)
+
+	ra.Config.Repair = true
+	report = ra.RunCycle(context.Background(), 10)
+	require.Equal(t, 3, report.UnderReplicated)
+	require.Equal(t, 4, report.Repaired)
+	require.Equal(t, 4, probe.pushed)
+
+	report = ra.RunCycle(context.Background(), 10)
+	require.Zero(t, report.UnderReplicated)
+	require.Empty(t, report.Ranges)
+
+	last, totals := ra.GetReports()
+	require.Equal(t, report, last)
+	require.EqualValues(t, 3, totals.Cycle)
+	require.Equal(t, 24, totals.Sampled)
+	require.Equal(t, 6, totals.UnderReplicated)
+	require.Equal(t, 4, totals.Repaired)
+}
+
+func TestReplicationAudit_sampleRounds(t *testing.T) {
+	var ra = NewReplicationAudit(ReplicationAuditConfig{}, nil)
+	require.Empty(t, ra.sampleRounds(10, 5, 3))
+	require.Equal(t, []int64{1, 2, 3}, ra.sampleRounds(1, 3, 5))
+
+	var rounds = ra.sampleRounds(1, 1000, 50)
+	require.Len(t, rounds, 50)
+	for i := 1; i < len(rounds); i++ {
+		require.Less(t, rounds[i-1], rounds[i])
+	}
+	require.GreaterOrEqual(t, rounds[0], int64(1))
+	require.LessOrEqual(t, rounds[len(rounds)-1], int64(1000))
+
+	low, high := (&ReplicationAudit{}).bounds(10)
+	require.Equal(t, []int64{1, 10}, []int64{low, high})
+}
@@ -29,6 +29,8 @@ var (
 	BlockSummaryRequestor node.EntityRequestor
 	// BlockSummariesRequestor -
 	BlockSummariesRequestor node.EntityRequestor
+	// BlockReplicateSender - pushes a block to a replicator missing it
+	BlockReplicateSender node.EntitySendHandler
 )
 
 // SetupS2SRequestors -
@@ -51,6 +53,9 @@ func SetupS2SRequestors() {
 
 	blockSummariesEntityMetadata := datastore.GetEntityMetadata("block_summaries")
 	BlockSummariesRequestor = node.RequestEntityHandler("/v1/_s2s/blocksummaries/get", options, blockSummariesEntityMetadata)
+
+	options = &node.SendOptions{Timeout: node.TimeoutLargeMessage, CODEC: node.CODEC_MSGPACK, Compress: true}
+	BlockReplicateSender = node.SendEntityHandler("/v1/_s2s/block/replicate", options)
 }
 
 // SetupS2SResponders -
@@ -61,6 +66,7 @@ func SetupS2SResponders() {
 	http.HandleFunc("/v1/_s2s/block/get", node.ToN2NSendEntityHandler(RoundBlockRequestHandler))
 	http.HandleFunc("/v1/_s2s/blocksummary/get", node.ToN2NSendEntityHandler(BlockSummaryRequestHandler))
 	http.HandleFunc("/v1/_s2s/blocksummaries/get", node.ToN2NSendEntityHandler(BlockSummariesHandler))
+	http.HandleFunc("/v1/_s2s/block/replicate", common.N2NRateLimit(node.ToN2NReceiveEntityHandler(ReplicateBlockHandler, nil)))
 }
 
 // SetupX2SRespondes setups sharders responders for miner and sharders.
@@ -199,10 +205,16 @@ func BlockSummaryRequestHandler(ctx context.Context, r *http.Request) (interface
 		bctx := ememorystore.WithEntityConnection(ctx, bSummaryEntityMetadata)
 		defer ememorystore.Close(bctx)
 		blockS, err := sc.GetBlockSummary(bctx, bHash)
-		if err == nil {
-			return blockS, nil
+		if err != nil {
+			return nil, err
+		}
+		// the replication audit checks the block is stored as well
+		if r.FormValue("check_block") == "true" {
+			if _, ok := sc.hasBlock(bHash, blockS.Round); !ok {
+				return nil, common.NewError(datastore.EntityNotFound, "block is not stored")
+			}
 		}
-		return nil, err
+		return blockS, nil
 	}
 	return nil, common.InvalidRequest("block hash is required")
 }
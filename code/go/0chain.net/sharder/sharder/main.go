@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -24,16 +26,23 @@ import (
 	"0chain.net/chaincore/diagnostics"
 	"0chain.net/chaincore/node"
 	"0chain.net/chaincore/round"
+	"0chain.net/chaincore/rpc"
 	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/build"
 	"0chain.net/core/common"
+	"0chain.net/core/datastore"
 	"0chain.net/core/ememorystore"
 	"0chain.net/core/encryption"
+	"0chain.net/core/journal"
+	"0chain.net/core/kvstore"
 	"0chain.net/core/logging"
 	. "0chain.net/core/logging"
 	"0chain.net/core/memorystore"
 	"0chain.net/core/persistencestore"
+	"0chain.net/core/quota"
+	"0chain.net/core/tracing"
+	"0chain.net/core/util"
 	"0chain.net/core/viper"
 	"0chain.net/sharder"
 	"0chain.net/sharder/blockstore"
@@ -89,6 +98,22 @@ func main() {
 	minioFile := flag.String("minio_file", "", "minio_file")
 	initialStatesFile := flag.String("initial_states", "", "initial_states")
 	flag.String("nodes_file", "", "nodes_file (deprecated)")
+	txnHistoryBackfill := flag.String("txn_history_backfill", "",
+		"index stored transactions by clients for from:to rounds and exit")
+	convertBlockStore := flag.String("convert_block_store", "",
+		"append blocks of the file system block store directory to the segment block store and exit")
+	migrateCassandra := flag.Bool("migrate_cassandra", false,
+		"copy the persistence store entities from cassandra to the configured kvstore and exit")
+	exportArchive := flag.String("export_archive", "",
+		"export stored data of from:to rounds to the -archive file and exit")
+	importArchive := flag.String("import_archive", "",
+		"verify and import the given chain archive file and exit")
+	archiveFile := flag.String("archive", "archive.tar.gz",
+		"chain archive file of the -export_archive")
+	replayBlocks := flag.String("replay", "",
+		"re-compute state of the stored blocks of from:to rounds, report the first divergence and exit")
+	replayGenesis := flag.Bool("replay_genesis", false,
+		"replay from the initial states instead of the stored state of the from-1 round block")
 	flag.Parse()
 	config.Configuration.DeploymentMode = byte(*deploymentMode)
 	config.SetupDefaultConfig()
@@ -133,6 +158,11 @@ func main() {
 	node.Self.SetSignatureScheme(signatureScheme)
 	reader.Close()
 
+	if *migrateCassandra {
+		migrateFromCassandra(ctx)
+		return
+	}
+
 	sharder.SetupSharderChain(serverChain)
 	sc := sharder.GetSharderChain()
 	sc.SetupConfigInfoDB()
@@ -176,6 +206,11 @@ func main() {
 	}
 
 	setupBlockStorageProvider(mConf)
+	setupExplorerIndex()
+	if *convertBlockStore != "" {
+		convertFSBlockStore(ctx, *convertBlockStore)
+		return
+	}
 	sc.SetupGenesisBlock(viper.GetString("server_chain.genesis_block.id"),
 		magicBlock, initStates)
 	Logger.Info("sharder node", zap.Any("node", node.Self))
@@ -225,6 +260,16 @@ func main() {
 	Logger.Info("Self identity", zap.Any("set_index", selfNode.SetIndex),
 		zap.Any("id", selfNode.GetKey()))
 
+	if err := tracing.ReadConfig(ctx, "sharder", selfNode.GetKey()); err != nil {
+		Logger.Panic("tracing setup", zap.Error(err))
+	}
+	if err := journal.ReadConfig(ctx, sc.GetCurrentRound); err != nil {
+		Logger.Panic("journal setup", zap.Error(err))
+	}
+	if err := rpc.ReadConfig(ctx, node.Self.Underlying().Port); err != nil {
+		Logger.Panic("rpc gateway setup", zap.Error(err))
+	}
+
 	initIntegrationsTests(node.Self.Underlying().GetKey())
 	defer shutdownIntegrationTests()
 
@@ -249,6 +294,14 @@ func main() {
 	sc.SetupHealthyRound()
 
 	common.ConfigRateLimits()
+	quota.SetNodeID(node.Self.Underlying().GetKey())
+	if err := quota.ReadConfig(); err != nil {
+		Logger.Panic("quota setup", zap.Error(err))
+	}
+	config.RegisterReloadable(quota.ReadConfig, "network.user_handlers.quota")
+	if err := config.SetupReload(ctx); err != nil {
+		Logger.Panic("config reload setup", zap.Error(err))
+	}
 	initN2NHandlers()
 	initWorkers(ctx)
 
@@ -258,6 +311,33 @@ func main() {
 		return
 	}
 
+	if *txnHistoryBackfill != "" {
+		backfillTxnClientHistory(ctx, sc, *txnHistoryBackfill)
+		return
+	}
+
+	if *exportArchive != "" {
+		exportChainArchive(ctx, sc, *exportArchive, *archiveFile)
+		return
+	}
+
+	if *importArchive != "" {
+		importChainArchive(ctx, sc, *importArchive)
+		return
+	}
+
+	if *replayBlocks != "" {
+		var genesis *state.InitStates
+		if *replayGenesis {
+			if initStateErr != nil {
+				Logger.Fatal("replay: initial states", zap.Error(initStateErr))
+			}
+			genesis = initStates
+		}
+		replayStoredBlocks(ctx, sc, *replayBlocks, genesis)
+		return
+	}
+
 	startBlocksInfoLogs(sc)
 
 	if err := sc.UpdateLatesMagicBlockFromSharders(ctx); err != nil {
@@ -284,6 +364,17 @@ func main() {
 	// Do a proximity scan from finalized block till ProximityWindow
 	go sc.HealthCheckWorker(ctx, sharder.ProximityScan) // 4) progressively checks the health for each round
 
+	// Sample finalized rounds and check the blocks are stored by replicators
+	sc.SetupReplicationAudit(sharder.ReplicationAuditConfig{
+		Enabled:        viper.GetBool("server_chain.health_check.replication_audit.enabled"),
+		Window:         viper.GetInt64("server_chain.health_check.replication_audit.window"),
+		Settle:         viper.GetInt64("server_chain.health_check.replication_audit.settle_rounds"),
+		SampleSize:     viper.GetInt("server_chain.health_check.replication_audit.sample_size"),
+		RepeatInterval: viper.GetDuration("server_chain.health_check.replication_audit.repeat_interval_mins") * time.Minute,
+		Repair:         viper.GetBool("server_chain.health_check.replication_audit.repair"),
+	})
+	go sc.ReplicationAuditWorker(ctx)
+
 	defer done(ctx)
 
 	Logger.Info("Ready to listen to the requests")
@@ -392,6 +483,210 @@ func initHandlers() {
 	serverChain.SetupNodeHandlers()
 }
 
+// backfillTxnClientHistory indexes transactions of stored blocks by
+// clients for given "from:to" rounds range; the to defaults to the latest
+// finalized round.
+func backfillTxnClientHistory(ctx context.Context, sc *sharder.Chain,
+	rounds string) {
+
+	var (
+		parts    = strings.SplitN(rounds, ":", 2)
+		from, to int64
+		err      error
+	)
+	if from, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		Logger.Fatal("txn history backfill: invalid from round", zap.Error(err))
+	}
+	to = sc.GetLatestFinalizedBlock().Round
+	if len(parts) == 2 && parts[1] != "" {
+		if to, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			Logger.Fatal("txn history backfill: invalid to round", zap.Error(err))
+		}
+	}
+
+	Logger.Info("txn history backfill started", zap.Int64("from", from),
+		zap.Int64("to", to))
+	blocks, missing, partial, err := sc.BackfillTxnClientHistory(ctx, from, to)
+	if err != nil {
+		Logger.Fatal("txn history backfill", zap.Int("blocks", blocks),
+			zap.Int("missing", missing), zap.Int("partial", partial),
+			zap.Error(err))
+	}
+	Logger.Info("txn history backfill finished", zap.Int64("from", from),
+		zap.Int64("to", to), zap.Int("blocks", blocks),
+		zap.Int("missing", missing), zap.Int("partial", partial))
+}
+
+// exportChainArchive writes the stored data of the from:to rounds to the
+// archive file.
+func exportChainArchive(ctx context.Context, sc *sharder.Chain,
+	rounds, file string) {
+
+	var (
+		parts    = strings.SplitN(rounds, ":", 2)
+		from, to int64
+		err      error
+	)
+	if from, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		Logger.Fatal("export archive: invalid from round", zap.Error(err))
+	}
+	to = sc.GetLatestFinalizedBlock().Round
+	if len(parts) == 2 && parts[1] != "" {
+		if to, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			Logger.Fatal("export archive: invalid to round", zap.Error(err))
+		}
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		Logger.Fatal("export archive", zap.Error(err))
+	}
+	Logger.Info("export archive started", zap.Int64("from", from),
+		zap.Int64("to", to), zap.String("file", file))
+	mf, err := sc.NewChainArchive().Export(ctx, f, from, to)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(file)
+		Logger.Fatal("export archive", zap.Error(err))
+	}
+	Logger.Info("export archive finished", zap.String("file", file),
+		zap.Int("blocks", mf.Blocks), zap.Int("magic_blocks", mf.MagicBlocks),
+		zap.Int("transactions", mf.Transactions))
+}
+
+// importChainArchive verifies the archive file and stores its rounds.
+func importChainArchive(ctx context.Context, sc *sharder.Chain, file string) {
+	Logger.Info("import archive started", zap.String("file", file))
+	mf, err := sc.NewChainArchive().Import(ctx, file)
+	if err != nil {
+		Logger.Fatal("import archive", zap.Error(err))
+	}
+	Logger.Info("import archive finished", zap.Int64("from", mf.From),
+		zap.Int64("to", mf.To), zap.Int("blocks", mf.Blocks),
+		zap.Int("magic_blocks", mf.MagicBlocks),
+		zap.Int("transactions", mf.Transactions))
+}
+
+// replayStoredBlocks re-computes state of the stored blocks of the from:to
+// rounds and prints the report. The replay starts from the initial states,
+// if given, or from the stored state of the from-1 round block.
+func replayStoredBlocks(ctx context.Context, sc *sharder.Chain,
+	rounds string, initStates *state.InitStates) {
+
+	var (
+		parts    = strings.SplitN(rounds, ":", 2)
+		from, to int64
+		err      error
+	)
+	if from, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		Logger.Fatal("replay: invalid from round", zap.Error(err))
+	}
+	to = sc.GetLatestFinalizedBlock().Round
+	if len(parts) == 2 && parts[1] != "" {
+		if to, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			Logger.Fatal("replay: invalid to round", zap.Error(err))
+		}
+	}
+
+	var (
+		rp   = sc.NewReplayer()
+		root util.Key
+	)
+	if initStates != nil {
+		if from != 1 {
+			Logger.Fatal("replay: initial states replay starts from round 1")
+		}
+		root = sc.GenesisReplayState(rp, initStates)
+	} else {
+		hash, err := sc.GetBlockHash(ctx, from-1)
+		if err != nil {
+			Logger.Fatal("replay: initial block", zap.Error(err))
+		}
+		pb, err := sc.GetBlockFromStore(hash, from-1)
+		if err != nil {
+			Logger.Fatal("replay: initial block", zap.Error(err))
+		}
+		root = pb.ClientStateHash
+	}
+
+	Logger.Info("replay started", zap.Int64("from", from), zap.Int64("to", to))
+	report, err := rp.Replay(ctx, from, to, root)
+	if err != nil {
+		Logger.Fatal("replay", zap.Any("report", report), zap.Error(err))
+	}
+	Logger.Info("replay finished", zap.Any("report", report))
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(report); err != nil {
+		Logger.Error("replay: printing report", zap.Error(err))
+	}
+}
+
+// convertFSBlockStore appends blocks stored by the file system block store
+// in given directory to the configured segment block store.
+func convertFSBlockStore(ctx context.Context, dir string) {
+	sbs, ok := blockstore.GetStore().(*blockstore.SegmentBlockStore)
+	if !ok {
+		Logger.Fatal("convert block store: the block storage provider is not " +
+			"blockstore.SegmentBlockStore")
+	}
+	Logger.Info("convert block store started", zap.String("dir", dir))
+	converted, err := sbs.ConvertFSBlockStore(ctx, dir)
+	if err != nil {
+		Logger.Fatal("convert block store", zap.Int("converted", converted),
+			zap.Error(err))
+	}
+	Logger.Info("convert block store finished", zap.String("dir", dir),
+		zap.Int("converted", converted))
+}
+
+// migrateFromCassandra copies the persistence store entities from cassandra
+// to the configured kvstore.
+func migrateFromCassandra(ctx context.Context) {
+	emd := datastore.GetEntityMetadata("txn_summary")
+	kvs, ok := emd.GetStore().(*kvstore.Store)
+	if !ok {
+		Logger.Fatal("migrate cassandra: the persistence store is not kvstore")
+	}
+	persistencestore.InitSession()
+	Logger.Info("migrate cassandra started")
+	migrated, err := sharder.MigrateFromCassandra(ctx, kvs)
+	if err != nil {
+		Logger.Fatal("migrate cassandra", zap.Any("migrated", migrated),
+			zap.Error(err))
+	}
+	kvs.Close()
+	Logger.Info("migrate cassandra finished", zap.Any("migrated", migrated))
+}
+
+// setupPersistenceStorage returns the configured store of the sharder
+// persistence entities.
+func setupPersistenceStorage() datastore.Store {
+	switch store := viper.GetString("persistence.store"); store {
+	case "", "cassandra":
+		persistencestore.InitSession()
+		return persistencestore.GetStorageProvider()
+	case "kvstore":
+		dir := viper.GetString("persistence.kvstore_dir")
+		if dir == "" {
+			dir = "data/rocksdb/kvstore"
+		}
+		kv, err := kvstore.OpenRocksKV(dir)
+		if err != nil {
+			panic(err)
+		}
+		kvs := kvstore.NewStore(kv)
+		sharder.SetupKVStore(kvs)
+		return kvs
+	default:
+		panic(fmt.Sprintf("unknown persistence store - %v", store))
+	}
+}
+
 func initEntities() {
 	memoryStorage := memorystore.GetStorageProvider()
 
@@ -409,9 +704,9 @@ func initEntities() {
 	client.SetupEntity(memoryStorage)
 	transaction.SetupEntity(memoryStorage)
 
-	persistencestore.InitSession()
-	persistenceStorage := persistencestore.GetStorageProvider()
+	persistenceStorage := setupPersistenceStorage()
 	transaction.SetupTxnSummaryEntity(persistenceStorage)
+	transaction.SetupTxnClientHistoryEntity(persistenceStorage)
 	transaction.SetupTxnConfirmationEntity(persistenceStorage)
 	block.SetupMagicBlockMapEntity(persistenceStorage)
 
@@ -472,6 +767,11 @@ func setupBlockStorageProvider(mConf blockstore.MinioConfiguration) {
 		blockstore.SetupStore(fsbs)
 	case "blockstore.BlockDBStore":
 		blockstore.SetupStore(blockstore.NewBlockDBStore(fsbs))
+	case "blockstore.TieredBlockStore":
+		blockstore.SetupStore(newTieredBlockStore(fsbs, mClient))
+	case "blockstore.SegmentBlockStore":
+		blockstore.SetupStore(blockstore.NewSegmentBlockStore("data/blocks/segments",
+			viper.GetInt64("server_chain.block.storage.segment_round_range")))
 	case "blockstore.MultiBlockstore":
 		var bs = []blockstore.BlockStore{
 			fsbs,
@@ -484,3 +784,47 @@ func setupBlockStorageProvider(mConf blockstore.MinioConfiguration) {
 		panic(fmt.Sprintf("uknown block store provider - %v", blockStorageProvider))
 	}
 }
+
+// setupExplorerIndex opens the local explorer index, if enabled.
+func setupExplorerIndex() {
+	if !viper.GetBool("explorer.enabled") {
+		return
+	}
+	kv, err := kvstore.OpenRocksKV("data/rocksdb/explorer")
+	if err != nil {
+		panic(err)
+	}
+	sharder.SetupExplorerIndex(sharder.NewExplorerIndex(kv))
+}
+
+// newTieredBlockStore creates the tiered block store of the hot file system
+// block store by the tiering configurations.
+func newTieredBlockStore(fsbs *blockstore.FSBlockStore,
+	mClient blockstore.MinioClient) *blockstore.TieredBlockStore {
+
+	var cold blockstore.ObjectStore
+	switch coldStorage := viper.GetString("tiering.cold_storage"); coldStorage {
+	case "minio":
+		if mClient == nil {
+			panic("tiering: minio cold storage requires minio enabled")
+		}
+		cold = blockstore.NewMinioObjectStore(mClient)
+	case "directory":
+		cold = blockstore.NewDirObjectStore(viper.GetString("tiering.cold_directory"))
+	default:
+		panic(fmt.Sprintf("unknown tiering cold storage - %v", coldStorage))
+	}
+
+	tbs, err := blockstore.NewTieredBlockStore(fsbs, "data/blocks/warm",
+		"data/blocks/restored", cold, blockstore.TieringPolicy{
+			WarmRounds:   viper.GetInt64("tiering.warm_rounds"),
+			ColdRounds:   viper.GetInt64("tiering.cold_rounds"),
+			HotAccesses:  viper.GetInt64("tiering.hot_accesses"),
+			AccessWindow: viper.GetDuration("tiering.access_window"),
+			MaxKept:      viper.GetInt("tiering.max_kept"),
+		}, viper.GetInt("tiering.restored_cache_size"))
+	if err != nil {
+		panic(err)
+	}
+	return tbs
+}
@@ -14,6 +14,7 @@ import (
 	"0chain.net/core/common"
 	"0chain.net/core/datastore"
 	"0chain.net/core/ememorystore"
+	"0chain.net/core/kvstore"
 	. "0chain.net/core/logging"
 	"0chain.net/core/persistencestore"
 )
@@ -35,13 +36,17 @@ func (sc *Chain) GetTransactionSummary(ctx context.Context, hash string) (*trans
 	return txnSummary, nil
 }
 
-/*GetTransactionConfirmation - given a transaction return the confirmation of it's presence in the block chain */
-func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*transaction.Confirmation, error) {
+/*GetTransactionConfirmation - given a transaction return the confirmation of it's presence in the block chain, with the block header if the proof is requested */
+func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string, proof bool) (*transaction.Confirmation, error) {
 	var ts *transaction.TransactionSummary
 	t, err := sc.BlockTxnCache.Get(hash)
 	if err != nil {
 		ts, err = sc.GetTransactionSummary(ctx, hash)
 		if err != nil {
+			// not finalized (or not stored yet), may be notarized
+			if confirmation := sc.getNotarizedTxnConfirmation(ctx, hash, proof); confirmation != nil {
+				return confirmation, nil
+			}
 			return nil, err
 		}
 	} else {
@@ -73,6 +78,7 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 		confirmation.ReceiptMerkleTreeRoot = bs.ReceiptMerkleTreeRoot
 		b, err = sc.GetBlockBySummary(ctx, bs)
 		if err != nil {
+			sc.setConfirmationFinality(ctx, confirmation)
 			return confirmation, nil
 		}
 	} else {
@@ -82,7 +88,15 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 		confirmation.RoundRandomSeed = b.GetRoundRandomSeed()
 		confirmation.CreationDate = b.CreationDate
 	}
-	txn := b.GetTransaction(hash)
+	setConfirmationBlock(confirmation, b, proof)
+	sc.setConfirmationFinality(ctx, confirmation)
+	return confirmation, nil
+}
+
+// setConfirmationBlock sets the transaction, its merkle paths in the block
+// and, if the proof is requested, the block header to the confirmation.
+func setConfirmationBlock(confirmation *transaction.Confirmation, b *block.Block, proof bool) {
+	txn := b.GetTransaction(confirmation.Hash)
 	confirmation.Status = txn.Status
 	confirmation.Transaction = txn
 	mt := b.GetMerkleTree()
@@ -92,7 +106,10 @@ func (sc *Chain) GetTransactionConfirmation(ctx context.Context, hash string) (*
 	confirmation.ReceiptMerkleTreeRoot = rmt.GetRoot()
 	confirmation.ReceiptMerkleTreePath = rmt.GetPath(transaction.NewTransactionReceipt(txn))
 	confirmation.PreviousBlockHash = b.PrevHash
-	return confirmation, nil
+	if proof {
+		confirmation.BlockHeader = newConfirmationBlockHeader(b,
+			confirmation.MerkleTreeRoot, confirmation.ReceiptMerkleTreeRoot)
+	}
 }
 
 /*StoreTransactions - persists given list of transactions*/
@@ -157,6 +174,9 @@ func getSelectTxn(table string, column string) string {
 }
 func (sc *Chain) getTxnCountForRound(ctx context.Context, r int64) (int, error) {
 	txnSummaryEntityMetadata := datastore.GetEntityMetadata("txn_summary")
+	if kvs, ok := getKVStore(txnSummaryEntityMetadata); ok {
+		return kvs.CountIndex(ctx, kvRoundToHashIndex, kvstore.Uint64Key(r))
+	}
 	tctx := persistencestore.WithEntityConnection(ctx, txnSummaryEntityMetadata)
 	defer persistencestore.Close(tctx)
 	c := persistencestore.GetCon(tctx)
@@ -186,6 +206,9 @@ func (sc *Chain) getTxnCountForRound(ctx context.Context, r int64) (int, error)
 }
 func (sc *Chain) getTxnAndCountForRound(ctx context.Context, r int64) (int, error) {
 	txnSummaryEntityMetadata := datastore.GetEntityMetadata("txn_summary")
+	if kvs, ok := getKVStore(txnSummaryEntityMetadata); ok {
+		return kvs.CountIndex(ctx, kvRoundToHashIndex, kvstore.Uint64Key(r))
+	}
 	tctx := persistencestore.WithEntityConnection(ctx, txnSummaryEntityMetadata)
 	defer persistencestore.Close(tctx)
 	c := persistencestore.GetCon(tctx)
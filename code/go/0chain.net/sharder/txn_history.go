@@ -0,0 +1,290 @@
+package sharder
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+	"0chain.net/core/kvstore"
+	. "0chain.net/core/logging"
+	"0chain.net/core/persistencestore"
+)
+
+const (
+	// TxnHistoryDefaultLimit is default page size of the client history.
+	TxnHistoryDefaultLimit = 20
+	// TxnHistoryMaxLimit is max page size of the client history.
+	TxnHistoryMaxLimit = 100
+)
+
+// order of roles of a client in a transaction
+var txnRolesOrder = map[string]int{
+	transaction.TxnRoleSender:    0,
+	transaction.TxnRoleRecipient: 1,
+	transaction.TxnRoleTransfer:  2,
+}
+
+// getTxnClientHistory returns client history entries of transactions of
+// given block: senders, recipients and recipients of SC transfers. The
+// transfers are known only if the block state was computed by this sharder,
+// otherwise the entries are marked partial.
+func getTxnClientHistory(b *block.Block) []datastore.Entity {
+	var (
+		entities []datastore.Entity
+		partial  = b.GetStateStatus() != block.StateSuccessful
+	)
+	for _, txn := range b.Txns {
+		var (
+			roles   = make(map[string][]string)
+			clients []string
+		)
+		var add = func(clientID, role string) {
+			if clientID == "" {
+				return
+			}
+			if _, ok := roles[clientID]; !ok {
+				clients = append(clients, clientID)
+			}
+			for _, r := range roles[clientID] {
+				if r == role {
+					return
+				}
+			}
+			roles[clientID] = append(roles[clientID], role)
+		}
+		add(txn.ClientID, transaction.TxnRoleSender)
+		add(txn.ToClientID, transaction.TxnRoleRecipient)
+		for _, id := range b.GetTxnTransfers(txn.Hash) {
+			if len(roles[id]) == 0 {
+				add(id, transaction.TxnRoleTransfer)
+			}
+		}
+
+		for _, clientID := range clients {
+			var rs = roles[clientID]
+			sort.Slice(rs, func(i, j int) bool {
+				return txnRolesOrder[rs[i]] < txnRolesOrder[rs[j]]
+			})
+			entities = append(entities, &transaction.TxnClientHistory{
+				ClientID: clientID,
+				Round:    b.Round,
+				Hash:     txn.Hash,
+				Roles:    rs,
+				Partial:  partial,
+			})
+		}
+	}
+	return entities
+}
+
+// StoreTxnClientHistory indexes transactions of given block by clients.
+func (sc *Chain) StoreTxnClientHistory(ctx context.Context,
+	b *block.Block) error {
+
+	var entities = getTxnClientHistory(b)
+	if len(entities) == 0 {
+		return nil
+	}
+	var (
+		emd  = datastore.GetEntityMetadata("txn_client_history")
+		tctx = persistencestore.WithEntityConnection(ctx, emd)
+	)
+	defer persistencestore.Close(tctx)
+	if err := emd.GetStore().MultiWrite(tctx, emd, entities); err != nil {
+		Logger.Error("save txn client history error", zap.Int64("round", b.Round),
+			zap.String("block", b.Hash), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// TxnHistory is a page of transactions sent or received by a client.
+type TxnHistory struct {
+	ClientID     string                          `json:"client_id"`
+	Transactions []*transaction.TxnClientHistory `json:"transactions"`
+	// NextRound and NextHash are cursor of next page, empty for last page
+	NextRound int64  `json:"next_round,omitempty"`
+	NextHash  string `json:"next_hash,omitempty"`
+}
+
+// GetTxnClientHistory returns up to limit transactions of given client
+// starting from given round. The fromHash is exclusive cursor of previous
+// page within the fromRound.
+func (sc *Chain) GetTxnClientHistory(ctx context.Context, clientID string,
+	fromRound int64, fromHash string, limit int) (*TxnHistory, error) {
+
+	var emd = datastore.GetEntityMetadata("txn_client_history")
+	if kvs, ok := getKVStore(emd); ok {
+		return getKVTxnClientHistory(ctx, kvs, emd, clientID, fromRound,
+			fromHash, limit)
+	}
+
+	var (
+		tctx = persistencestore.WithEntityConnection(ctx, emd)
+		c    = persistencestore.GetCon(tctx)
+		q    persistencestore.QueryI
+	)
+	defer persistencestore.Close(tctx)
+
+	// one more to know whether there's next page
+	if fromHash == "" {
+		q = c.Query("SELECT JSON * FROM "+emd.GetName()+
+			" WHERE client_id = ? AND round >= ? LIMIT ?",
+			clientID, fromRound, limit+1)
+	} else {
+		q = c.Query("SELECT JSON * FROM "+emd.GetName()+
+			" WHERE client_id = ? AND (round, hash) > (?, ?) LIMIT ?",
+			clientID, fromRound, fromHash, limit+1)
+	}
+
+	var (
+		iter = q.Iter()
+		th   = &TxnHistory{ClientID: clientID}
+		json string
+	)
+	for iter.Scan(&json) {
+		var entry = emd.Instance().(*transaction.TxnClientHistory)
+		if err := datastore.FromJSON(json, entry); err != nil {
+			iter.Close()
+			return nil, err
+		}
+		th.Transactions = append(th.Transactions, entry)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	th.setNextPage(limit)
+	return th, nil
+}
+
+// setNextPage cuts the one more than limit transactions requested and sets
+// the cursor of the next page.
+func (th *TxnHistory) setNextPage(limit int) {
+	if len(th.Transactions) > limit {
+		th.Transactions = th.Transactions[:limit]
+		var last = th.Transactions[limit-1]
+		th.NextRound, th.NextHash = last.Round, last.Hash
+	}
+}
+
+// getKVTxnClientHistory is GetTxnClientHistory for the kvstore, the history
+// rows of a client are ordered by (round, hash).
+func getKVTxnClientHistory(ctx context.Context, kvs *kvstore.Store,
+	emd datastore.EntityMetadata, clientID string, fromRound int64,
+	fromHash string, limit int) (*TxnHistory, error) {
+
+	var from = kvstore.Uint64Key(fromRound)
+	if fromHash != "" {
+		// exclusive, right after the hash
+		from = append(append(from, fromHash...), 0)
+	}
+	var th = &TxnHistory{ClientID: clientID}
+	err := kvs.IteratePartition(ctx, emd, clientID, from,
+		func(entity datastore.Entity) bool {
+			th.Transactions = append(th.Transactions,
+				entity.(*transaction.TxnClientHistory))
+			return len(th.Transactions) <= limit
+		})
+	if err != nil {
+		return nil, err
+	}
+	th.setNextPage(limit)
+	return th, nil
+}
+
+// computeTxnTransfers computes state of given stored block on top of the
+// stored state of its previous block to find recipients of SC transfers of
+// its transactions. It fails if the previous state has been pruned.
+func (sc *Chain) computeTxnTransfers(ctx context.Context,
+	b *block.Block) error {
+
+	pb, err := sc.getReplayBlock(ctx, b.Round-1)
+	if err != nil {
+		return err
+	}
+	var rp = sc.NewReplayer()
+	if _, err = rp.base.GetNode(pb.ClientStateHash); err != nil {
+		return err
+	}
+	pb.CreateState(rp.base, pb.ClientStateHash)
+	pb.SetStateStatus(block.StateSuccessful)
+	_, err = rp.computeState(ctx, b, pb, nil)
+	b.PrevBlock = nil
+	return err
+}
+
+// BackfillTxnClientHistory indexes transactions of stored blocks of given
+// rounds range. Recipients of SC transfers are found computing state of the
+// blocks, entries of blocks the previous state of which is not available
+// anymore are indexed without them and marked partial.
+func (sc *Chain) BackfillTxnClientHistory(ctx context.Context, from,
+	to int64) (blocks, missing, partial int, err error) {
+
+	for r := from; r <= to; r++ {
+		select {
+		case <-ctx.Done():
+			return blocks, missing, partial, ctx.Err()
+		default:
+		}
+		var hash string
+		if hash, err = sc.GetBlockHash(ctx, r); err != nil {
+			missing++
+			continue
+		}
+		var b *block.Block
+		if b, err = sc.GetBlockFromHash(ctx, hash, r); err != nil {
+			missing++
+			continue
+		}
+		if b.GetStateStatus() != block.StateSuccessful {
+			// compute a stored copy, not a block of the chain
+			var sb *block.Block
+			sb, err = sc.GetBlockFromStore(hash, r)
+			if err == nil {
+				if err = sc.computeTxnTransfers(ctx, sb); err == nil {
+					b = sb
+				}
+			}
+			if err != nil {
+				Logger.Debug("txn client history backfill - no SC transfers",
+					zap.Int64("round", r), zap.Error(err))
+				partial++
+			}
+		}
+		if err = sc.StoreTxnClientHistory(ctx, b); err != nil {
+			return blocks, missing, partial, err
+		}
+		blocks++
+		if blocks%1000 == 0 {
+			Logger.Info("txn client history backfill", zap.Int64("round", r),
+				zap.Int("blocks", blocks), zap.Int("missing", missing),
+				zap.Int("partial", partial))
+		}
+	}
+	return blocks, missing, partial, nil
+}
+
+/*TxnClientHistoryHandler - a handler to respond to the client transactions history queries */
+func TxnClientHistoryHandler(ctx context.Context, r *http.Request) (interface{}, error) {
+	var p = txnHistoryParams{Limit: TxnHistoryDefaultLimit}
+	if err := common.DecodeRequestParams(r, &p); err != nil {
+		return nil, err
+	}
+	if p.FromRound < 0 {
+		return nil, common.InvalidRequest("invalid from_round")
+	}
+	if p.Limit <= 0 {
+		return nil, common.InvalidRequest("invalid limit")
+	}
+	if p.Limit > TxnHistoryMaxLimit {
+		p.Limit = TxnHistoryMaxLimit
+	}
+	return GetSharderChain().GetTxnClientHistory(ctx, p.ClientID, p.FromRound,
+		p.FromHash, p.Limit)
+}
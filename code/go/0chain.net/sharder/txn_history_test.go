@@ -0,0 +1,72 @@
+package sharder
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+)
+
+func TestGetTxnClientHistory(t *testing.T) {
+	var (
+		b     = block.NewBlock("", 10)
+		send  = &transaction.Transaction{ClientID: "alice", ToClientID: "bob"}
+		sc    = &transaction.Transaction{ClientID: "bob", ToClientID: "sc"}
+		self  = &transaction.Transaction{ClientID: "carol", ToClientID: "carol"}
+		hashO = encryption.Hash("send")
+	)
+	send.Hash = hashO
+	sc.Hash = encryption.Hash("sc")
+	self.Hash = encryption.Hash("self")
+	b.Txns = []*transaction.Transaction{send, sc, self}
+	// SC transfers back to the sender and to a third party
+	b.AddTxnTransfers(sc.Hash, []string{"sc", "bob", "dave"})
+	b.SetStateStatus(block.StateSuccessful)
+
+	var entries = getTxnClientHistory(b)
+	var got = make(map[string][]string)
+	for _, e := range entries {
+		var h = e.(*transaction.TxnClientHistory)
+		assert.EqualValues(t, 10, h.Round)
+		assert.False(t, h.Partial)
+		got[h.Hash+":"+h.ClientID] = h.Roles
+	}
+
+	assert.Equal(t, map[string][]string{
+		hashO + ":alice":  {transaction.TxnRoleSender},
+		hashO + ":bob":    {transaction.TxnRoleRecipient},
+		sc.Hash + ":bob":  {transaction.TxnRoleSender},
+		sc.Hash + ":sc":   {transaction.TxnRoleRecipient},
+		sc.Hash + ":dave": {transaction.TxnRoleTransfer},
+		self.Hash + ":carol": {transaction.TxnRoleSender,
+			transaction.TxnRoleRecipient},
+	}, got)
+
+	// the transfers are unknown without the block state computed
+	b.SetStateStatus(block.StateSynched)
+	for _, e := range getTxnClientHistory(b) {
+		assert.True(t, e.(*transaction.TxnClientHistory).Partial)
+	}
+}
+
+func TestTxnClientHistoryHandler_InvalidRequest(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"client_id=&limit=10",
+		"client_id=alice&from_round=x",
+		"client_id=alice&from_round=-1",
+		"client_id=alice&limit=0",
+		"client_id=alice&limit=x",
+	} {
+		var r = httptest.NewRequest("GET", "/v1/transaction/get/history?"+query,
+			nil)
+		_, err := TxnClientHistoryHandler(context.Background(), r)
+		require.Error(t, err, query)
+	}
+}
@@ -25,6 +25,12 @@ import (
 
 const minerScSharderHealthCheck = "sharder_health_check"
 
+const (
+	compactBlockStoreInterval = 10 * time.Minute
+	// rounds below the LFB to let the finalized blocks to be stored
+	compactBlockStoreLag = 1000
+)
+
 /*SetupWorkers - setup the background workers */
 func SetupWorkers(ctx context.Context) {
 	sc := GetSharderChain()
@@ -41,9 +47,15 @@ func SetupWorkers(ctx context.Context) {
 	go sc.UpdateMagicBlockWorker(ctx)
 	go sc.RegisterSharderKeepWorker(ctx)
 	// Move old blocks to cloud
-	if viper.GetBool("minio.enabled") {
+	if tbs, ok := blockstore.GetStore().(*blockstore.TieredBlockStore); ok {
+		go sc.TieringWorker(ctx, tbs)
+	} else if viper.GetBool("minio.enabled") {
 		go sc.MinioWorker(ctx)
 	}
+	// Remove orphaned blocks from the finalized segments
+	if sbs, ok := blockstore.GetStore().(*blockstore.SegmentBlockStore); ok {
+		go sc.CompactBlockStoreWorker(ctx, sbs)
+	}
 
 	go sc.SharderHealthCheck(ctx)
 }
@@ -231,6 +243,112 @@ func (sc *Chain) MinioWorker(ctx context.Context) {
 	}
 }
 
+// CompactBlockStoreWorker periodically removes not finalized blocks from
+// the segments of the block store with all rounds finalized.
+func (sc *Chain) CompactBlockStoreWorker(ctx context.Context,
+	sbs *blockstore.SegmentBlockStore) {
+
+	var keep = func(b *block.Block) bool {
+		hash, err := sc.GetBlockHash(ctx, b.Round)
+		// keep blocks of rounds not stored by the sharder
+		return err != nil || hash == b.Hash
+	}
+	ticker := time.NewTicker(compactBlockStoreInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lfb := sc.GetLatestFinalizedBlock()
+			if lfb == nil {
+				continue
+			}
+			removed, err := sbs.CompactFinalized(ctx,
+				lfb.Round-compactBlockStoreLag, keep)
+			if err != nil {
+				logging.Logger.Error("compact block store", zap.Error(err),
+					zap.Int("removed", removed))
+			}
+		}
+	}
+}
+
+// TieringWorker periodically moves old blocks to the colder tiers of the
+// tiered block store by its policy.
+func (sc *Chain) TieringWorker(ctx context.Context,
+	tbs *blockstore.TieredBlockStore) {
+
+	var minRounds = tbs.Policy.MinRounds()
+	if minRounds <= 0 {
+		logging.Logger.Info("tiering is disabled by the policy")
+		return
+	}
+	ticker := time.NewTicker(time.Duration(viper.GetInt64("tiering.worker_frequency")) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sc.applyTiering(ctx, tbs, minRounds)
+		}
+	}
+}
+
+// applyTiering walks from the newest block old enough to be moved to the
+// older ones until a block already in the coldest tier. Blocks not stored
+// by this sharder are skipped, they aren't local, thus, look cold.
+func (sc *Chain) applyTiering(ctx context.Context,
+	tbs *blockstore.TieredBlockStore, minRounds int64) {
+
+	var (
+		currentRound = sc.GetCurrentRound()
+		coldest      = tbs.Policy.Coldest()
+		moved        = make(map[blockstore.Tier]int)
+		self         = node.Self.Underlying()
+	)
+	for r := currentRound - minRounds; r > 0; r-- {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		hash, err := sc.GetBlockHash(ctx, r)
+		if err != nil {
+			logging.Logger.Error("tiering - unable to get block hash from round number",
+				zap.Int64("round", r))
+			continue
+		}
+		if !sc.IsBlockSharderFromHash(r, hash, self) {
+			continue
+		}
+		from, to, err := tbs.Apply(hash, r, currentRound)
+		if err != nil {
+			logging.Logger.Error("tiering - move block", zap.Int64("round", r),
+				zap.Stringer("from", from), zap.Error(err))
+			continue
+		}
+		if to != from {
+			moved[to]++
+			if to == blockstore.TierCold {
+				sc.TieringStats.TotalBlocksUploaded++
+				sc.TieringStats.LastRoundUploaded = r
+				sc.TieringStats.LastUploadTime = time.Now()
+			}
+		}
+		if from == coldest {
+			break
+		}
+	}
+	kept, err := tbs.ApplyKept(currentRound)
+	if err != nil {
+		logging.Logger.Error("tiering - move kept blocks", zap.Error(err))
+	}
+	logging.Logger.Info("tiering - moved old blocks", zap.Int("warm", moved[blockstore.TierWarm]),
+		zap.Int("cold", moved[blockstore.TierCold]), zap.Int("kept", kept))
+}
+
 func (sc *Chain) moveBlockToCloud(ctx context.Context, round int64, hash string, fs blockstore.BlockStore, swg *sizedwaitgroup.SizedWaitGroup) {
 	err := fs.UploadToCloud(hash, round)
 	if err != nil {
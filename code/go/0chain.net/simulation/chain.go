@@ -0,0 +1,266 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// n2n URIs of the simulated protocol, the same as of the real one where
+// there is such.
+const (
+	verifyBlockURI        = "/v1/_m2m/block/verify"
+	verificationTicketURI = "/v1/_m2m/block/verification_ticket"
+	notarizationURI       = "/v1/_m2m/block/notarization"
+	finalizedBlockURI     = "/v1/_m2s/block/finalized"
+	getNotarizedBlockURI  = "/v1/_x2m/block/notarized_block/get"
+	getFinalizedBlockURI  = "/v1/_x2m/block/finalized_block/get"
+)
+
+// protocol is the protocol rules shared by all simulated nodes. The
+// consensus rules, that is magic block of a round, verification tickets,
+// notarization threshold and finality, are the rules of chain.Chain.
+type protocol struct {
+	id               string
+	thresholdByCount int
+	// magic blocks ordered by starting round
+	magicBlocks []*block.MagicBlock
+	// chain knowing all the magic blocks
+	chain *chain.Chain
+}
+
+func newProtocol(id string, thresholdByCount int,
+	magicBlocks []*block.MagicBlock, genesis *block.Block) *protocol {
+
+	var p = &protocol{
+		id:               id,
+		thresholdByCount: thresholdByCount,
+		magicBlocks:      magicBlocks,
+	}
+	p.chain = p.newChain(magicBlocks, genesis)
+	return p
+}
+
+// newChain returns chain of a simulated node knowing given magic blocks,
+// with the genesis block as the latest finalized one.
+func (p *protocol) newChain(magicBlocks []*block.MagicBlock,
+	genesis *block.Block) *chain.Chain {
+
+	var c = chain.Provider().(*chain.Chain)
+	c.ID = p.id
+	c.ThresholdByCount = p.thresholdByCount
+	c.ThresholdMode = chain.ThresholdModeCount
+	for _, mb := range magicBlocks {
+		setMagicBlock(c, mb)
+	}
+	genesis = copyBlock(genesis)
+	c.AddBlock(genesis)
+	setLatestFinalizedBlock(c, genesis)
+	return c
+}
+
+// setLatestFinalizedBlock of the chain. Unlike Chain.SetLatestFinalizedBlock
+// it doesn't notify the LFB ticket and state sync workers, they don't run in
+// simulation.
+func setLatestFinalizedBlock(c *chain.Chain, b *block.Block) {
+	c.LatestFinalizedBlock = b
+}
+
+// setMagicBlock initializes node pools of the magic block, like
+// Chain.UpdateMagicBlock does, and adds it to the chain.
+func setMagicBlock(c *chain.Chain, mb *block.MagicBlock) {
+	mb.Miners.ComputeProperties()
+	mb.Sharders.ComputeProperties()
+	c.SetMagicBlock(mb)
+}
+
+// magicBlock returns the magic block of the round.
+func (p *protocol) magicBlock(round int64) *block.MagicBlock {
+	return p.chain.GetMagicBlock(round)
+}
+
+// magicBlockStarting returns magic block starting at the round, nil if
+// there is no such one. It's carried by the block of the round and used
+// chain.ViewChangeOffset rounds later.
+func (p *protocol) magicBlockStarting(round int64) *block.MagicBlock {
+	for _, mb := range p.magicBlocks[1:] {
+		if mb.StartingRound == round {
+			return mb
+		}
+	}
+	return nil
+}
+
+// roundSeed of the round derived from the previous block.
+func roundSeed(prevHash string, round int64, timeouts int) int64 {
+	var hash = encryption.Hash(prevHash + ":" + strconv.FormatInt(round, 10) +
+		":" + strconv.Itoa(timeouts))
+	seed, _ := strconv.ParseUint(hash[:15], 16, 64)
+	return int64(seed)
+}
+
+// generator returns ID of the miner generating block of the round after
+// given number of the round timeouts.
+func (p *protocol) generator(prev *block.Block, round int64, timeouts int) string {
+	var ids = p.magicBlock(round).Miners.Keys()
+	sort.Strings(ids)
+	return ids[roundSeed(prev.Hash, round, timeouts)%int64(len(ids))]
+}
+
+func parseRound(r *http.Request) (int64, error) {
+	round, err := strconv.ParseInt(r.FormValue("round"), 10, 64)
+	if err != nil {
+		return 0, common.InvalidRequest("invalid round")
+	}
+	return round, nil
+}
+
+// verifyBlock checks the block follows the previous one by the protocol
+// rules. It returns state of the block computed on top of the previous
+// block state.
+func (p *protocol) verifyBlock(prevState util.NodeDB, prev, b *block.Block) (
+	util.NodeDB, error) {
+
+	if b.Round != prev.Round+1 || b.PrevHash != prev.Hash {
+		return nil, common.NewErrorf("invalid_block",
+			"block %s doesn't follow %s", b.Hash, prev.Hash)
+	}
+	if b.Hash != b.ComputeHash() {
+		return nil, common.NewErrorf("invalid_block",
+			"invalid hash of block %s", b.Hash)
+	}
+	if b.MinerID != p.generator(prev, b.Round, b.RoundTimeoutCount) {
+		return nil, common.NewErrorf("invalid_block", "block %s of round %d "+
+			"isn't from the generator", b.Hash, b.Round)
+	}
+	if b.GetRoundRandomSeed() != roundSeed(prev.Hash, b.Round,
+		b.RoundTimeoutCount) {
+
+		return nil, common.NewErrorf("invalid_block",
+			"invalid seed of block %s", b.Hash)
+	}
+	var mb = p.magicBlockStarting(b.Round)
+	if (mb == nil) != (b.MagicBlock == nil) ||
+		(mb != nil && mb.Hash != b.MagicBlock.Hash) {
+
+		return nil, common.NewErrorf("invalid_block", "unexpected magic "+
+			"block of block %s", b.Hash)
+	}
+	return verifyState(prevState, prev, b)
+}
+
+// verifyState computes state of the block and checks its root.
+func verifyState(prevState util.NodeDB, prev, b *block.Block) (
+	util.NodeDB, error) {
+
+	state, root, err := computeState(prevState, prev, b)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(b.ClientStateHash, root) {
+		return nil, common.NewErrorf("invalid_block",
+			"state mismatch of block %s", b.Hash)
+	}
+	return state, nil
+}
+
+// genesisState creates state of the genesis block. All nodes create the
+// same one.
+func genesisState() (util.NodeDB, util.Key, error) {
+	var (
+		state = util.NewMemoryNodeDB()
+		mpt   = util.NewMerklePatriciaTrie(state, 0)
+	)
+	_, err := mpt.Insert(util.Path(encryption.Hash("genesis")),
+		&util.SecureSerializableValue{Buffer: []byte("genesis")})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = mpt.SaveChanges(context.Background(), state, false); err != nil {
+		return nil, nil, err
+	}
+	return state, mpt.GetRoot(), nil
+}
+
+// computeState of the block on top of the previous block state, in a new
+// level like the state of a real block. The simulated block records its
+// generator in the state.
+func computeState(prevState util.NodeDB, prev, b *block.Block) (
+	util.NodeDB, util.Key, error) {
+
+	var (
+		state = util.NewLevelNodeDB(util.NewMemoryNodeDB(), prevState, false)
+		mpt   = util.NewMerklePatriciaTrie(state, util.Sequence(b.Round))
+	)
+	mpt.SetRoot(prev.ClientStateHash)
+	_, err := mpt.Insert(util.Path(encryption.Hash(strconv.FormatInt(b.Round, 10))),
+		&util.SecureSerializableValue{Buffer: []byte(b.MinerID)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = mpt.SaveChanges(context.Background(), state, false); err != nil {
+		return nil, nil, err
+	}
+	return state, mpt.GetRoot(), nil
+}
+
+func encodeBlock(b *block.Block) []byte {
+	data, err := json.Marshal(b)
+	if err != nil {
+		panic(err) // a block is always encoded
+	}
+	return data
+}
+
+// copyBlock returns copy of the block, as a node receives it.
+func copyBlock(b *block.Block) *block.Block {
+	var cb = new(block.Block)
+	if err := json.Unmarshal(encodeBlock(b), cb); err != nil {
+		panic(err) // an encoded block is always decoded
+	}
+	return cb
+}
+
+func decodeBlock(r *http.Request) (*block.Block, error) {
+	var b = new(block.Block)
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func decodeBlockResponse(resp *http.Response) (*block.Block, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, common.NewErrorf("request_failed", "status %d",
+			resp.StatusCode)
+	}
+	var b = new(block.Block)
+	if err := json.NewDecoder(resp.Body).Decode(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// respond writes the handler result.
+func respond(w http.ResponseWriter, data []byte, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if data == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
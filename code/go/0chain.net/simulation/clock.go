@@ -0,0 +1,162 @@
+package simulation
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Clock is source of time of simulated nodes.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls the function in the clock goroutine once the duration
+	// elapsed.
+	AfterFunc(d time.Duration, f func()) *Timer
+	// After returns channel receiving the time once the duration elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer is a pending call of a virtual clock.
+type Timer struct {
+	at    time.Time
+	seq   uint64
+	f     func()
+	index int // in the queue, -1 if not queued
+	clock *VirtualClock
+}
+
+// Stop the timer, it returns false if the timer already fired or stopped.
+func (t *Timer) Stop() bool {
+	var vc = t.clock
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&vc.queue, t.index)
+	return true
+}
+
+type timerQueue []*Timer
+
+func (q timerQueue) Len() int { return len(q) }
+
+func (q timerQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q timerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+
+func (q *timerQueue) Push(x interface{}) {
+	var t = x.(*Timer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *timerQueue) Pop() interface{} {
+	var (
+		old = *q
+		t   = old[len(old)-1]
+	)
+	t.index = -1
+	*q = old[:len(old)-1]
+	return t
+}
+
+// VirtualClock is a clock moved forward explicitly. Timers fire in order of
+// their time, and in order of scheduling for the same time, in the goroutine
+// advancing the clock. Thus, everything driven by the clock is
+// deterministic.
+type VirtualClock struct {
+	mutex sync.Mutex
+	now   time.Time
+	seq   uint64
+	queue timerQueue
+}
+
+// NewVirtualClock returns virtual clock starting at given time.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now returns current virtual time.
+func (vc *VirtualClock) Now() time.Time {
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	return vc.now
+}
+
+// AfterFunc schedules the function call.
+func (vc *VirtualClock) AfterFunc(d time.Duration, f func()) *Timer {
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	vc.seq++
+	var t = &Timer{at: vc.now.Add(d), seq: vc.seq, f: f, clock: vc}
+	heap.Push(&vc.queue, t)
+	return t
+}
+
+// After returns channel receiving the virtual time once the duration
+// elapsed.
+func (vc *VirtualClock) After(d time.Duration) <-chan time.Time {
+	var ch = make(chan time.Time, 1)
+	vc.AfterFunc(d, func() { ch <- vc.Now() })
+	return ch
+}
+
+// next pops the next timer if it fires not after the given time.
+func (vc *VirtualClock) next(until time.Time) *Timer {
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	if len(vc.queue) == 0 || vc.queue[0].at.After(until) {
+		return nil
+	}
+	var t = heap.Pop(&vc.queue).(*Timer)
+	if t.at.After(vc.now) {
+		vc.now = t.at
+	}
+	return t
+}
+
+// NextAt returns time of the next timer, false if there are no timers.
+func (vc *VirtualClock) NextAt() (time.Time, bool) {
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	if len(vc.queue) == 0 {
+		return time.Time{}, false
+	}
+	return vc.queue[0].at, true
+}
+
+// Step fires the next timer if it's not after the given time. It returns
+// false if there is no such timer.
+func (vc *VirtualClock) Step(until time.Time) bool {
+	var t = vc.next(until)
+	if t == nil {
+		return false
+	}
+	t.f()
+	return true
+}
+
+// Advance moves the clock forward by the duration firing all timers on the
+// way, including ones scheduled by the fired timers.
+func (vc *VirtualClock) Advance(d time.Duration) {
+	var until = vc.Now().Add(d)
+	for vc.Step(until) {
+	}
+	vc.mutex.Lock()
+	defer vc.mutex.Unlock()
+	if until.After(vc.now) {
+		vc.now = until
+	}
+}
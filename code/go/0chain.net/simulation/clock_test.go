@@ -0,0 +1,56 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualClock_Advance(t *testing.T) {
+	var (
+		start = time.Unix(1000, 0)
+		vc    = NewVirtualClock(start)
+		fired []int
+	)
+	vc.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	vc.AfterFunc(time.Second, func() {
+		fired = append(fired, 1)
+		require.Equal(t, start.Add(time.Second), vc.Now())
+		// scheduled by a fired timer, after the one of the same time
+		vc.AfterFunc(time.Second, func() { fired = append(fired, 3) })
+	})
+	var stopped = vc.AfterFunc(time.Second, func() { fired = append(fired, 0) })
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+	vc.AfterFunc(5*time.Second, func() { fired = append(fired, 5) })
+
+	vc.Advance(3 * time.Second)
+	require.Equal(t, []int{1, 2, 3}, fired)
+	require.Equal(t, start.Add(3*time.Second), vc.Now())
+
+	at, ok := vc.NextAt()
+	require.True(t, ok)
+	require.Equal(t, start.Add(5*time.Second), at)
+	require.False(t, vc.Step(start.Add(4*time.Second)))
+	require.True(t, vc.Step(start.Add(5*time.Second)))
+	require.Equal(t, []int{1, 2, 3, 5}, fired)
+
+	_, ok = vc.NextAt()
+	require.False(t, ok)
+}
+
+func TestVirtualClock_After(t *testing.T) {
+	var (
+		vc = NewVirtualClock(time.Unix(1000, 0))
+		ch = vc.After(time.Minute)
+	)
+	vc.Advance(time.Second)
+	select {
+	case <-ch:
+		t.Fatal("fired before the time")
+	default:
+	}
+	vc.Advance(time.Minute)
+	require.Equal(t, time.Unix(1060, 0), <-ch)
+}
@@ -0,0 +1,349 @@
+// Package simulation models the block consensus of miners and sharders in
+// one process over a virtual n2n network driven by a virtual clock, so
+// scenarios of finalization, round timeouts, view changes and network
+// partitions run as plain go tests in seconds.
+//
+// It's a model of the protocol, not the production nodes. The miner.Chain
+// and sharder.Chain round workers aren't run: they're bound to process wide
+// node.Self, the server chain and the entity stores, so a process can run
+// one node only. Instead, every simulated node has its own chain.Chain that
+// decides verification tickets, notarization, magic block of a round and
+// finality by the real chain rules, while the round worker is a simplified
+// copy of the miner one:
+//
+//   - a generator per round and round timeout is chosen by the round seed,
+//     there is no VRF share exchange;
+//   - blocks have no transactions and their state records the generator
+//     only, there are no smart contracts nor transaction pools;
+//   - magic blocks are scripted by Config.ViewChanges, there is no DKG nor
+//     miner SC view change;
+//   - sharders store summaries of the finalized blocks in a MemoryStore, an
+//     in-memory datastore.Store, not the block store.
+//
+// So it covers the n2n message flow, notarization thresholds, round timeouts
+// and the finality rules, but doesn't replace the conductor tests of the
+// real nodes. The Network can carry n2n requests of real nodes too, see
+// node.SetN2NRoundTripper.
+package simulation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/client"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/round"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/logging"
+
+	"go.uber.org/zap"
+)
+
+// Defaults of the harness configuration.
+const (
+	DefaultThresholdByCount = 67
+	DefaultRoundTimeout     = time.Second
+	DefaultLatency          = 10 * time.Millisecond
+)
+
+// ViewChange is a scripted view change, it replaces miners of the magic block
+// starting from the round, there is no DKG of the miners. The magic block is carried by the block chain.ViewChangeOffset rounds earlier,
+// thus, the round of a view change, but the initial one, should be greater
+// than twice the offset.
+type ViewChange struct {
+	Round int64
+	// Miners are indices of the harness miners.
+	Miners []int
+}
+
+// Config of a simulation harness.
+type Config struct {
+	ChainID  string
+	Miners   int
+	Sharders int
+	// ThresholdByCount is percent of miners of a magic block notarizing a
+	// block.
+	ThresholdByCount int
+	RoundTimeout     time.Duration
+	// Latency is the default latency of the network links.
+	Latency time.Duration
+	// ViewChanges in order of rounds. A view change of the first round sets
+	// the initial miners, all miners are in the initial magic block
+	// otherwise.
+	ViewChanges []ViewChange
+}
+
+// Harness of simulated miners and sharders.
+type Harness struct {
+	conf     Config
+	clock    *VirtualClock
+	network  *Network
+	protocol *protocol
+	genesis  *block.Block
+	miners   []*Miner
+	sharders []*Sharder
+}
+
+var setupEntitiesOnce sync.Once
+
+// setupEntities registers metadata of entities stored by the simulated
+// nodes; the nodes use own stores instead of the registered one. The state
+// tries and the chains log, so the logger is required. The simulated nodes
+// sign with ed25519 keys.
+func setupEntities() {
+	setupEntitiesOnce.Do(func() {
+		if logging.Logger == nil {
+			logging.Logger = zap.NewNop()
+		}
+		block.SetupBlockSummaryEntity(NewMemoryStore())
+		round.SetupEntity(NewMemoryStore())
+		client.SetClientSignatureScheme("ed25519")
+	})
+}
+
+// NewHarness creates the nodes, the genesis and the magic blocks. The nodes
+// do nothing until the harness is started.
+func NewHarness(conf Config) (*Harness, error) {
+	if conf.Miners <= 0 || conf.Sharders <= 0 {
+		return nil, common.NewError("invalid_config",
+			"at least one miner and one sharder required")
+	}
+	if conf.ChainID == "" {
+		conf.ChainID = "simulation"
+	}
+	if conf.ThresholdByCount == 0 {
+		conf.ThresholdByCount = DefaultThresholdByCount
+	}
+	if conf.RoundTimeout == 0 {
+		conf.RoundTimeout = DefaultRoundTimeout
+	}
+	if conf.Latency == 0 {
+		conf.Latency = DefaultLatency
+	}
+	setupEntities()
+
+	var clock = NewVirtualClock(time.Unix(1600000000, 0))
+	var h = &Harness{
+		conf:    conf,
+		clock:   clock,
+		network: NewNetwork(clock, conf.Latency),
+	}
+
+	var (
+		minerKeys   = make([]encryption.SignatureScheme, conf.Miners)
+		minerNodes  = make([]*node.Node, conf.Miners)
+		sharderKeys = make([]*node.Node, conf.Sharders)
+	)
+	for i := range minerKeys {
+		var ss, n, err = newSimNode(node.NodeTypeMiner, i)
+		if err != nil {
+			return nil, err
+		}
+		minerKeys[i], minerNodes[i] = ss, n
+	}
+	for i := range sharderKeys {
+		var _, n, err = newSimNode(node.NodeTypeSharder, i)
+		if err != nil {
+			return nil, err
+		}
+		sharderKeys[i] = n
+	}
+
+	mbs, err := newMagicBlocks(conf, minerNodes, sharderKeys)
+	if err != nil {
+		return nil, err
+	}
+	var genesis = new(block.Block)
+	genesis.ChainID = conf.ChainID
+	genesis.Round = 0
+	genesis.CreationDate = common.Timestamp(clock.Now().Unix())
+	if _, genesis.ClientStateHash, err = genesisState(); err != nil {
+		return nil, err
+	}
+	genesis.MagicBlock = mbs[0]
+	genesis.HashBlock()
+	h.genesis = genesis
+	h.protocol = newProtocol(conf.ChainID, conf.ThresholdByCount, mbs, genesis)
+
+	for i := range minerKeys {
+		m, err := newMiner(h, i, minerNodes[i].GetKey(), minerKeys[i], genesis)
+		if err != nil {
+			return nil, err
+		}
+		h.miners = append(h.miners, m)
+		h.network.Register(m.id, m)
+	}
+	for i, n := range sharderKeys {
+		s, err := newSharder(h, i, n.GetKey(), genesis)
+		if err != nil {
+			return nil, err
+		}
+		h.sharders = append(h.sharders, s)
+		h.network.Register(s.id, s)
+	}
+	return h, nil
+}
+
+// newSimNode returns node with new ed25519 keys.
+func newSimNode(nodeType int8, index int) (encryption.SignatureScheme,
+	*node.Node, error) {
+
+	var ss = encryption.NewED25519Scheme()
+	if err := ss.GenerateKeys(); err != nil {
+		return nil, nil, err
+	}
+	var n = node.Provider()
+	n.Type = nodeType
+	n.SetIndex = index
+	n.SetPublicKey(ss.GetPublicKey())
+	n.N2NHost = n.GetKey()
+	return ss, n, nil
+}
+
+// newMagicBlocks returns the initial magic block and magic blocks of the
+// view changes.
+func newMagicBlocks(conf Config, miners, sharders []*node.Node) (
+	[]*block.MagicBlock, error) {
+
+	var (
+		changes = conf.ViewChanges
+		initial = make([]int, len(miners))
+	)
+	for i := range initial {
+		initial[i] = i
+	}
+	if len(changes) > 0 && changes[0].Round <= 1 {
+		initial, changes = changes[0].Miners, changes[1:]
+	}
+	changes = append([]ViewChange{{Round: 1, Miners: initial}}, changes...)
+
+	var mbs []*block.MagicBlock
+	for i, vc := range changes {
+		var start int64 // the initial magic block is carried by the genesis
+		if i > 0 {
+			start = vc.Round - chain.ViewChangeOffset
+		}
+		if i > 0 && (vc.Round <= 2*chain.ViewChangeOffset ||
+			start <= mbs[i-1].StartingRound) {
+
+			return nil, common.NewErrorf("invalid_config",
+				"view change rounds should increase and exceed %d, got %d",
+				2*chain.ViewChangeOffset, vc.Round)
+		}
+		if len(vc.Miners) == 0 {
+			return nil, common.NewErrorf("invalid_config",
+				"no miners of view change at round %d", vc.Round)
+		}
+		var mb = block.NewMagicBlock()
+		mb.MagicBlockNumber = int64(i + 1)
+		mb.StartingRound = start
+		mb.Miners = node.NewPool(node.NodeTypeMiner)
+		mb.Sharders = node.NewPool(node.NodeTypeSharder)
+		for _, idx := range vc.Miners {
+			if idx < 0 || idx >= len(miners) {
+				return nil, common.NewErrorf("invalid_config",
+					"no miner %d of view change at round %d", idx, vc.Round)
+			}
+			mb.Miners.AddNode(miners[idx])
+		}
+		for _, n := range sharders {
+			mb.Sharders.AddNode(n)
+		}
+		if i > 0 {
+			mb.PreviousMagicBlockHash = mbs[i-1].Hash
+		}
+		mb.Hash = mb.GetHash()
+		mbs = append(mbs, mb)
+	}
+	return mbs, nil
+}
+
+// Clock of the simulation.
+func (h *Harness) Clock() *VirtualClock { return h.clock }
+
+// Network of the simulated nodes.
+func (h *Harness) Network() *Network { return h.network }
+
+// Genesis block of the simulated chain.
+func (h *Harness) Genesis() *block.Block { return h.genesis }
+
+// Miner of the index.
+func (h *Harness) Miner(i int) *Miner { return h.miners[i] }
+
+// Miners of the harness.
+func (h *Harness) Miners() []*Miner { return h.miners }
+
+// Sharder of the index.
+func (h *Harness) Sharder(i int) *Sharder { return h.sharders[i] }
+
+// Sharders of the harness.
+func (h *Harness) Sharders() []*Sharder { return h.sharders }
+
+// MagicBlock of the round.
+func (h *Harness) MagicBlock(round int64) *block.MagicBlock {
+	return h.protocol.magicBlock(round)
+}
+
+// magicBlockMiners returns sorted IDs of the miners of the round.
+func (h *Harness) magicBlockMiners(round int64) []string {
+	var ids = h.protocol.magicBlock(round).Miners.Keys()
+	sort.Strings(ids)
+	return ids
+}
+
+func (h *Harness) minerIDs() (ids []string) {
+	for _, m := range h.miners {
+		ids = append(ids, m.id)
+	}
+	return
+}
+
+func (h *Harness) sharderIDs() (ids []string) {
+	for _, s := range h.sharders {
+		ids = append(ids, s.id)
+	}
+	return
+}
+
+// Start the first round on all miners.
+func (h *Harness) Start() {
+	for _, m := range h.miners {
+		if !m.stopped {
+			m.startRound(1)
+		}
+	}
+}
+
+// Run the simulation for the virtual duration.
+func (h *Harness) Run(d time.Duration) {
+	h.clock.Advance(d)
+}
+
+// RunUntil runs the simulation until the condition is met or the virtual
+// timeout elapsed. It returns the condition result.
+func (h *Harness) RunUntil(cond func() bool, timeout time.Duration) bool {
+	var until = h.clock.Now().Add(timeout)
+	for !cond() {
+		if !h.clock.Step(until) {
+			h.clock.Advance(until.Sub(h.clock.Now()))
+			return cond()
+		}
+	}
+	return true
+}
+
+// LatestFinalizedRound returns the lowest latest finalized round of the
+// sharders.
+func (h *Harness) LatestFinalizedRound() int64 {
+	var round = h.sharders[0].LatestFinalizedBlock().Round
+	for _, s := range h.sharders[1:] {
+		if r := s.LatestFinalizedBlock().Round; r < round {
+			round = r
+		}
+	}
+	return round
+}
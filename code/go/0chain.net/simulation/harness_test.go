@@ -0,0 +1,126 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHarness(t *testing.T, conf Config) *Harness {
+	t.Helper()
+	h, err := NewHarness(conf)
+	require.NoError(t, err)
+	return h
+}
+
+func lfbAtLeast(h *Harness, round int64) func() bool {
+	return func() bool { return h.LatestFinalizedRound() >= round }
+}
+
+func TestHarness_Finalization(t *testing.T) {
+	var h = newTestHarness(t, Config{Miners: 4, Sharders: 2})
+	h.Start()
+	require.True(t, h.RunUntil(lfbAtLeast(h, 20), time.Minute))
+
+	var ctx = context.Background()
+	for r := int64(1); r <= 20; r++ {
+		var b = h.Sharder(0).FinalizedBlock(r)
+		require.NotNil(t, b)
+		require.Equal(t, b.Hash, h.Sharder(1).FinalizedBlock(r).Hash)
+		require.Equal(t, 0, b.RoundTimeoutCount)
+
+		bs, err := h.Sharder(1).GetBlockSummary(ctx, r)
+		require.NoError(t, err)
+		require.Equal(t, b.Hash, bs.Hash)
+		require.Equal(t, r, bs.Round)
+	}
+	for _, s := range h.Sharders() {
+		require.Zero(t, s.Rejected())
+	}
+}
+
+func TestHarness_RoundTimeout(t *testing.T) {
+	var h = newTestHarness(t, Config{Miners: 4, Sharders: 1})
+	h.Miner(0).Stop()
+	h.Start()
+	require.True(t, h.RunUntil(lfbAtLeast(h, 20), time.Minute))
+
+	var timeouts int
+	for r := int64(1); r <= 20; r++ {
+		var b = h.Sharder(0).FinalizedBlock(r)
+		require.NotEqual(t, h.Miner(0).ID(), b.MinerID)
+		timeouts += b.RoundTimeoutCount
+	}
+	require.NotZero(t, timeouts, "the stopped miner should cause timeouts")
+
+	// 2 of 4 miners can't notarize a block
+	h.Miner(1).Stop()
+	var stalled = h.LatestFinalizedRound()
+	h.Run(30 * time.Second)
+	require.True(t, h.LatestFinalizedRound() <= stalled+1)
+
+	h.Miner(0).Start()
+	require.True(t, h.RunUntil(lfbAtLeast(h, stalled+10), time.Minute))
+}
+
+func TestHarness_ViewChange(t *testing.T) {
+	var h = newTestHarness(t, Config{
+		Miners:   6,
+		Sharders: 2,
+		ViewChanges: []ViewChange{
+			{Round: 1, Miners: []int{0, 1, 2, 3}},
+			{Round: 15, Miners: []int{2, 3, 4, 5}},
+		},
+	})
+	h.Start()
+	require.True(t, h.RunUntil(lfbAtLeast(h, 16), time.Minute))
+
+	// the miners left the view don't matter anymore
+	h.Miner(0).Stop()
+	h.Miner(1).Stop()
+	require.True(t, h.RunUntil(lfbAtLeast(h, 30), time.Minute))
+
+	var mb = h.MagicBlock(30)
+	require.EqualValues(t, 2, mb.MagicBlockNumber)
+	for _, s := range h.Sharders() {
+		require.Equal(t, mb.Hash, s.MagicBlock(30).Hash)
+		require.Equal(t, mb.Hash, s.FinalizedBlock(11).MagicBlock.Hash)
+		for r := int64(15); r <= 30; r++ {
+			var id = s.FinalizedBlock(r).MinerID
+			require.NotNil(t, mb.Miners.GetNode(id))
+		}
+	}
+}
+
+func TestHarness_invalidViewChange(t *testing.T) {
+	// the magic block would be carried by a block before the first round
+	var _, err = NewHarness(Config{
+		Miners:      4,
+		Sharders:    1,
+		ViewChanges: []ViewChange{{Round: 6, Miners: []int{0, 1, 2}}},
+	})
+	require.Error(t, err)
+}
+
+func TestHarness_Partition(t *testing.T) {
+	var h = newTestHarness(t, Config{Miners: 4, Sharders: 1})
+	h.Start()
+	require.True(t, h.RunUntil(lfbAtLeast(h, 5), time.Minute))
+
+	var (
+		ids      = h.minerIDs()
+		isolated = h.Miner(3)
+	)
+	h.Network().Partition(append(ids[:3:3], h.sharderIDs()...), ids[3:])
+	require.True(t, h.RunUntil(lfbAtLeast(h, 20), time.Minute))
+	require.True(t, isolated.LatestFinalizedBlock().Round < 10)
+
+	h.Network().Heal()
+	require.True(t, h.RunUntil(func() bool {
+		return isolated.LatestFinalizedBlock().Round >= 25
+	}, time.Minute))
+	require.Equal(t, h.Sharder(0).FinalizedBlock(20).Hash,
+		isolated.FinalizedBlock(20).Hash)
+}
@@ -0,0 +1,500 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/node"
+	"0chain.net/chaincore/round"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+)
+
+// Miner is a simulated miner. It generates, verifies, notarizes and
+// finalizes blocks on its chain.Chain: tickets and notarization are
+// verified against the magic block of the round by the chain, notarized
+// blocks are added to the chain rounds and the chain computes the finalized
+// block. The generation is reduced to a generator per round and round
+// timeout chosen by the round seed. Blocks have no transactions, the state
+// of a block records its generator. A miner is driven by the clock
+// goroutine only.
+type Miner struct {
+	h      *Harness
+	index  int
+	id     string
+	signer encryption.SignatureScheme
+	chain  *chain.Chain
+
+	mux     *http.ServeMux
+	stopped bool
+
+	round    int64 // current round
+	timeouts int   // timeouts of the current round
+	timer    *Timer
+
+	// state of the verified blocks by hash
+	states map[string]util.NodeDB
+	// first notarized block of a round
+	notarized map[int64]*block.Block
+	// notarized blocks by hash
+	isNotarized map[string]bool
+	// received tickets by block hash and verifier
+	tickets map[string]map[string]*block.VerificationTicket
+	// round -> 1 + round timeouts of the last block signed in the round
+	signed map[int64]int
+	// actions waiting for the block of the hash
+	pending map[string][]func()
+	// time of the last request of a missing block
+	requested map[string]time.Time
+
+	finalized map[int64]*block.Block
+}
+
+func newMiner(h *Harness, index int, id string,
+	signer encryption.SignatureScheme, genesis *block.Block) (*Miner, error) {
+
+	var m = &Miner{
+		h:           h,
+		index:       index,
+		id:          id,
+		signer:      signer,
+		chain:       h.protocol.newChain(h.protocol.magicBlocks, genesis),
+		mux:         http.NewServeMux(),
+		states:      make(map[string]util.NodeDB),
+		notarized:   make(map[int64]*block.Block),
+		isNotarized: make(map[string]bool),
+		tickets:     make(map[string]map[string]*block.VerificationTicket),
+		signed:      make(map[int64]int),
+		pending:     make(map[string][]func()),
+		requested:   make(map[string]time.Time),
+		finalized:   make(map[int64]*block.Block),
+	}
+	var state, _, err = genesisState()
+	if err != nil {
+		return nil, err
+	}
+	genesis = m.chain.GetLatestFinalizedBlock()
+	m.states[genesis.Hash] = state
+	m.notarized[0] = genesis
+	m.isNotarized[genesis.Hash] = true
+	m.finalized[0] = genesis
+
+	m.handle(verifyBlockURI, m.handleVerifyBlock)
+	m.handle(verificationTicketURI, m.handleVerificationTicket)
+	m.handle(notarizationURI, m.handleNotarization)
+	m.handle(getNotarizedBlockURI, m.handleGetNotarizedBlock)
+	m.handle(getFinalizedBlockURI, m.handleGetFinalizedBlock)
+	return m, nil
+}
+
+// handle registers handler ignoring requests while the miner is stopped.
+func (m *Miner) handle(uri string, handler func(from string,
+	r *http.Request) ([]byte, error)) {
+
+	m.mux.HandleFunc(uri, func(w http.ResponseWriter, r *http.Request) {
+		if m.stopped {
+			http.Error(w, "stopped", http.StatusServiceUnavailable)
+			return
+		}
+		data, err := handler(r.Header.Get(node.HeaderNodeID), r)
+		respond(w, data, err)
+	})
+}
+
+// ID of the miner.
+func (m *Miner) ID() string { return m.id }
+
+// Index of the miner in the harness.
+func (m *Miner) Index() int { return m.index }
+
+// ServeHTTP implements http.Handler.
+func (m *Miner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// CurrentRound of the miner.
+func (m *Miner) CurrentRound() int64 { return m.round }
+
+// LatestFinalizedBlock of the miner.
+func (m *Miner) LatestFinalizedBlock() *block.Block {
+	return m.chain.GetLatestFinalizedBlock()
+}
+
+// FinalizedBlock of the round, nil if the round isn't finalized.
+func (m *Miner) FinalizedBlock(round int64) *block.Block {
+	return m.finalized[round]
+}
+
+// NotarizedBlock returns the first block notarized in the round.
+func (m *Miner) NotarizedBlock(round int64) *block.Block {
+	return m.notarized[round]
+}
+
+// IsStopped reports whether the miner is stopped.
+func (m *Miner) IsStopped() bool { return m.stopped }
+
+// Stop the miner, it drops all messages and timers until started again.
+func (m *Miner) Stop() {
+	m.stopped = true
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+// Start stopped miner, it catches up with notarized blocks it receives.
+func (m *Miner) Start() {
+	if !m.stopped {
+		return
+	}
+	m.stopped = false
+	m.resetTimer()
+}
+
+func (m *Miner) resetTimer() {
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+	var round = m.round
+	m.timer = m.h.clock.AfterFunc(m.h.conf.RoundTimeout, func() {
+		m.onRoundTimeout(round)
+	})
+}
+
+// send message to other nodes, and to self after zero latency.
+func (m *Miner) send(to []string, uri string, data []byte,
+	self func()) {
+
+	for _, id := range to {
+		if id == m.id {
+			if self != nil {
+				m.h.clock.AfterFunc(0, func() {
+					if !m.stopped {
+						self()
+					}
+				})
+			}
+			continue
+		}
+		m.h.network.Send(m.id, id, uri, data)
+	}
+}
+
+func (m *Miner) startRound(round int64) {
+	m.round = round
+	m.timeouts = 0
+	m.resetTimer()
+	m.generate()
+}
+
+func (m *Miner) onRoundTimeout(round int64) {
+	if m.stopped || m.round != round || m.notarized[round] != nil {
+		return
+	}
+	m.timeouts++
+	m.resetTimer()
+	m.generate()
+}
+
+// generate block of the current round if the miner is its generator.
+func (m *Miner) generate() {
+	var prev = m.notarized[m.round-1]
+	if prev == nil || m.h.protocol.generator(prev, m.round, m.timeouts) != m.id {
+		return
+	}
+
+	var b = new(block.Block)
+	b.ChainID = m.h.protocol.id
+	b.Round = m.round
+	b.RoundTimeoutCount = m.timeouts
+	b.SetRoundRandomSeed(roundSeed(prev.Hash, m.round, m.timeouts))
+	b.PrevHash = prev.Hash
+	b.PrevBlockVerificationTickets = prev.GetVerificationTickets()
+	b.MinerID = m.id
+	b.CreationDate = common.Timestamp(m.h.clock.Now().Unix())
+	b.LatestFinalizedMagicBlockRound = m.chain.GetMagicBlock(m.round).StartingRound
+	b.MagicBlock = m.h.protocol.magicBlockStarting(m.round)
+	_, root, err := computeState(m.states[prev.Hash], prev, b)
+	if err != nil {
+		return
+	}
+	b.ClientStateHash = root
+	b.HashBlock()
+	if b.Signature, err = m.signer.Sign(b.Hash); err != nil {
+		return
+	}
+
+	var data = encodeBlock(b)
+	m.send(m.h.magicBlockMiners(b.Round), verifyBlockURI, data, func() {
+		m.onProposal(m.id, b)
+	})
+}
+
+func (m *Miner) handleVerifyBlock(from string, r *http.Request) ([]byte,
+	error) {
+
+	b, err := decodeBlock(r)
+	if err != nil {
+		return nil, err
+	}
+	m.onProposal(from, b)
+	return nil, nil
+}
+
+// getBlock returns block of the hash known to the miner, nil if there is no
+// such one.
+func (m *Miner) getBlock(hash string) *block.Block {
+	b, err := m.chain.GetBlock(context.Background(), hash)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// addBlock verifies the block, computes its state and adds it to the chain.
+// A block following unknown one waits for it, and the previous block is
+// requested from the sender.
+func (m *Miner) addBlock(from string, b *block.Block, then func()) bool {
+	if m.getBlock(b.Hash) != nil {
+		return true
+	}
+	var prev = m.getBlock(b.PrevHash)
+	if prev == nil {
+		m.pending[b.PrevHash] = append(m.pending[b.PrevHash], then)
+		m.requestBlock(from, b.PrevHash)
+		return false
+	}
+	state, err := m.h.protocol.verifyBlock(m.states[prev.Hash], prev, b)
+	if err != nil {
+		return false
+	}
+	m.chain.AddBlock(b)
+	m.states[b.Hash] = state
+
+	// resume blocks waiting for this one after the caller handles it
+	for _, f := range m.pending[b.Hash] {
+		var f = f
+		m.h.clock.AfterFunc(0, func() {
+			if !m.stopped {
+				f()
+			}
+		})
+	}
+	delete(m.pending, b.Hash)
+	m.checkNotarization(b)
+	return true
+}
+
+// requestBlock requests notarized block missing for the miner.
+func (m *Miner) requestBlock(from, hash string) {
+	if from == m.id {
+		return
+	}
+	if at, ok := m.requested[hash]; ok &&
+		m.h.clock.Now().Sub(at) < m.h.conf.RoundTimeout {
+		return
+	}
+	m.requested[hash] = m.h.clock.Now()
+	m.h.network.Request(m.id, from, getNotarizedBlockURI,
+		url.Values{"block": {hash}}, func(resp *http.Response) {
+			if m.stopped {
+				return
+			}
+			b, err := decodeBlockResponse(resp)
+			if err != nil {
+				return
+			}
+			delete(m.requested, hash)
+			m.onNotarization(from, b)
+		})
+}
+
+func (m *Miner) onProposal(from string, b *block.Block) {
+	// the proposal notarizes the previous block
+	if prev := m.getBlock(b.PrevHash); prev != nil && !m.isNotarized[prev.Hash] &&
+		m.chain.VerifyNotarization(context.Background(), prev,
+			b.PrevBlockVerificationTickets, prev.Round) == nil {
+
+		prev.MergeVerificationTickets(b.PrevBlockVerificationTickets)
+		m.notarize(prev)
+	}
+	if !m.addBlock(from, b, func() { m.onProposal(from, b) }) {
+		return
+	}
+	if b.Round != m.round || m.signed[b.Round] > b.RoundTimeoutCount {
+		return // old round or a block of the round timeout already signed
+	}
+	m.signed[b.Round] = b.RoundTimeoutCount + 1
+
+	sig, err := m.signer.Sign(b.Hash)
+	if err != nil {
+		return
+	}
+	var bvt = &block.BlockVerificationTicket{Round: b.Round, BlockID: b.Hash}
+	bvt.VerifierID = m.id
+	bvt.Signature = sig
+	data, _ := json.Marshal(bvt)
+	m.send(m.h.magicBlockMiners(b.Round), verificationTicketURI, data,
+		func() { m.onTicket(bvt) })
+}
+
+func (m *Miner) handleVerificationTicket(from string, r *http.Request) (
+	[]byte, error) {
+
+	var bvt block.BlockVerificationTicket
+	if err := json.NewDecoder(r.Body).Decode(&bvt); err != nil {
+		return nil, err
+	}
+	if err := m.chain.VerifyTicket(r.Context(), bvt.BlockID,
+		&bvt.VerificationTicket, bvt.Round); err != nil {
+
+		return nil, err
+	}
+	m.onTicket(&bvt)
+	return nil, nil
+}
+
+func (m *Miner) onTicket(bvt *block.BlockVerificationTicket) {
+	var tickets, ok = m.tickets[bvt.BlockID]
+	if !ok {
+		tickets = make(map[string]*block.VerificationTicket)
+		m.tickets[bvt.BlockID] = tickets
+	}
+	var vt = bvt.VerificationTicket
+	tickets[vt.VerifierID] = &vt
+	if b := m.getBlock(bvt.BlockID); b != nil {
+		m.checkNotarization(b)
+	}
+}
+
+// checkNotarization notarizes the block once its tickets reach the
+// notarization threshold of the chain.
+func (m *Miner) checkNotarization(b *block.Block) {
+	if m.isNotarized[b.Hash] {
+		return
+	}
+	var tickets = m.tickets[b.Hash]
+	if len(tickets) == 0 {
+		return
+	}
+	var vts = make([]*block.VerificationTicket, 0, len(tickets))
+	for _, vt := range tickets {
+		vts = append(vts, vt)
+	}
+	sort.Slice(vts, func(i, j int) bool {
+		return vts[i].VerifierID < vts[j].VerifierID
+	})
+	b.MergeVerificationTickets(vts)
+	if !m.chain.IsBlockNotarized(context.Background(), b) {
+		return
+	}
+	m.notarize(b)
+}
+
+func (m *Miner) handleNotarization(from string, r *http.Request) ([]byte,
+	error) {
+
+	b, err := decodeBlock(r)
+	if err != nil {
+		return nil, err
+	}
+	m.onNotarization(from, b)
+	return nil, nil
+}
+
+// onNotarization handles block with notarizing verification tickets.
+func (m *Miner) onNotarization(from string, b *block.Block) {
+	if m.isNotarized[b.Hash] {
+		return
+	}
+	if err := m.chain.VerifyNotarization(context.Background(), b,
+		b.GetVerificationTickets(), b.Round); err != nil {
+		return
+	}
+	if !m.addBlock(from, b, func() { m.onNotarization(from, b) }) {
+		return
+	}
+	m.notarize(b)
+}
+
+// notarize the verified block with the notarizing tickets. The block is
+// added to the notarized blocks of its round and the chain computes the
+// finalized block of the round.
+func (m *Miner) notarize(b *block.Block) {
+	if m.isNotarized[b.Hash] {
+		return
+	}
+	m.isNotarized[b.Hash] = true
+	b = m.chain.AddBlock(b)
+	if m.notarized[b.Round] == nil {
+		m.notarized[b.Round] = b
+	}
+	m.send(m.h.minerIDs(), notarizationURI, encodeBlock(b), nil)
+
+	var r = m.chain.GetRound(b.Round)
+	if r == nil {
+		r = m.chain.AddRound(round.NewRound(b.Round))
+	}
+	// there is one generator of a round timeout, its block ranks by the
+	// timeout
+	b.RoundRank = b.RoundTimeoutCount
+	if _, _, err := r.AddNotarizedBlock(b); err == nil {
+		if fb := m.chain.ComputeFinalizedBlock(context.Background(), r); fb != nil {
+			m.finalize(fb)
+		}
+	}
+	if b.Round >= m.round {
+		m.startRound(b.Round + 1)
+	}
+}
+
+// finalize the block and its not finalized ancestors, and send them to the
+// sharders.
+func (m *Miner) finalize(b *block.Block) {
+	var (
+		lfb = m.chain.GetLatestFinalizedBlock()
+		fbs []*block.Block
+	)
+	for ; b != nil && b.Round > lfb.Round; b = m.getBlock(b.PrevHash) {
+		fbs = append(fbs, b)
+	}
+	if b == nil || b.Hash != lfb.Hash {
+		return // doesn't extend the latest finalized block
+	}
+	for i := len(fbs) - 1; i >= 0; i-- {
+		var fb = fbs[i]
+		setLatestFinalizedBlock(m.chain, fb)
+		m.finalized[fb.Round] = fb
+		m.send(m.h.sharderIDs(), finalizedBlockURI, encodeBlock(fb), nil)
+	}
+}
+
+func (m *Miner) handleGetNotarizedBlock(from string, r *http.Request) (
+	[]byte, error) {
+
+	var b = m.getBlock(r.FormValue("block"))
+	if b == nil || !m.isNotarized[b.Hash] {
+		return nil, common.NewError("not_found", "block not found")
+	}
+	return encodeBlock(b), nil
+}
+
+func (m *Miner) handleGetFinalizedBlock(from string, r *http.Request) (
+	[]byte, error) {
+
+	var round, err = parseRound(r)
+	if err != nil {
+		return nil, err
+	}
+	var b = m.finalized[round]
+	if b == nil {
+		return nil, common.NewError("not_found", "block not found")
+	}
+	return encodeBlock(b), nil
+}
@@ -0,0 +1,206 @@
+package simulation
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"0chain.net/chaincore/node"
+	"0chain.net/core/common"
+)
+
+// ErrLinkDown is returned for a request over a dropped or partitioned link.
+var ErrLinkDown = common.NewError("link_down", "simulated link is down")
+
+type link struct {
+	from, to string
+}
+
+// Network is virtual n2n transport between nodes registered by their
+// addresses. Messages are delivered to HTTP handlers of the nodes after the
+// link latency of the virtual clock. Links can be delayed, dropped or
+// partitioned.
+type Network struct {
+	clock   Clock
+	latency time.Duration
+
+	mutex    sync.Mutex
+	handlers map[string]http.Handler
+	delays   map[link]time.Duration
+	drops    map[link]bool
+	groups   map[string]int // partition group of a node, if partitioned
+
+	sent    int64
+	dropped int64
+}
+
+// NewNetwork returns network delivering messages with the given default
+// latency of the clock.
+func NewNetwork(clock Clock, latency time.Duration) *Network {
+	return &Network{
+		clock:    clock,
+		latency:  latency,
+		handlers: make(map[string]http.Handler),
+		delays:   make(map[link]time.Duration),
+		drops:    make(map[link]bool),
+	}
+}
+
+// Register handler of node of given address.
+func (nw *Network) Register(address string, handler http.Handler) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	nw.handlers[address] = handler
+}
+
+// SetDelay sets latency of messages from one node to another.
+func (nw *Network) SetDelay(from, to string, delay time.Duration) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	nw.delays[link{from, to}] = delay
+}
+
+// SetDrop drops or restores messages from one node to another.
+func (nw *Network) SetDrop(from, to string, drop bool) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	if drop {
+		nw.drops[link{from, to}] = true
+	} else {
+		delete(nw.drops, link{from, to})
+	}
+}
+
+// Partition the network into groups of node addresses. Messages between
+// different groups are dropped; nodes out of the groups reach everyone.
+func (nw *Network) Partition(groups ...[]string) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	nw.groups = make(map[string]int)
+	for i, group := range groups {
+		for _, address := range group {
+			nw.groups[address] = i
+		}
+	}
+}
+
+// Heal removes partitions, dropped and delayed links.
+func (nw *Network) Heal() {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	nw.groups = nil
+	nw.delays = make(map[link]time.Duration)
+	nw.drops = make(map[link]bool)
+}
+
+// Stats returns number of sent and dropped messages.
+func (nw *Network) Stats() (sent, dropped int64) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	return nw.sent, nw.dropped
+}
+
+// route returns handler of the destination and the link latency, nil
+// handler for a link that's down.
+func (nw *Network) route(from, to string) (http.Handler, time.Duration) {
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+
+	nw.sent++
+	var (
+		l            = link{from, to}
+		handler, ok  = nw.handlers[to]
+		gfrom, pfrom = nw.groups[from]
+		gto, pto     = nw.groups[to]
+	)
+	if !ok || nw.drops[l] || (pfrom && pto && gfrom != gto) {
+		nw.dropped++
+		return nil, 0
+	}
+	if delay, ok := nw.delays[l]; ok {
+		return handler, delay
+	}
+	return handler, nw.latency
+}
+
+func newNetworkRequest(from, to, uri string, body []byte) *http.Request {
+	var req = httptest.NewRequest(http.MethodPost, "http://"+to+uri,
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(node.HeaderNodeID, from)
+	return req
+}
+
+// Send the body to the URI of the destination without waiting for the
+// delivery. The handler is called in the clock goroutine.
+func (nw *Network) Send(from, to, uri string, body []byte) {
+	var handler, latency = nw.route(from, to)
+	if handler == nil {
+		return
+	}
+	nw.clock.AfterFunc(latency, func() {
+		handler.ServeHTTP(httptest.NewRecorder(),
+			newNetworkRequest(from, to, uri, body))
+	})
+}
+
+// Request the URI of the destination with the form parameters. The response
+// is passed to the callback in the clock goroutine after the round trip
+// latency, it's not called if any direction of the link is down.
+func (nw *Network) Request(from, to, uri string, params url.Values,
+	callback func(resp *http.Response)) {
+
+	var handler, latency = nw.route(from, to)
+	if handler == nil {
+		return
+	}
+	nw.clock.AfterFunc(latency, func() {
+		var req = newNetworkRequest(from, to, uri, []byte(params.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var back, latency = nw.route(to, from)
+		if back == nil {
+			return
+		}
+		nw.clock.AfterFunc(latency, func() { callback(rec.Result()) })
+	})
+}
+
+// RoundTrip implements http.RoundTripper to run n2n clients of real nodes
+// over the network, see node.SetN2NRoundTripper. The URL host is address of
+// the destination and the node ID header is address of the source. It
+// blocks until the message is delivered by the virtual clock.
+func (nw *Network) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		from = req.Header.Get(node.HeaderNodeID)
+		to   = req.URL.Host
+	)
+	var handler, latency = nw.route(from, to)
+	if handler == nil {
+		return nil, ErrLinkDown
+	}
+	select {
+	case <-nw.clock.After(latency):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	var (
+		rec  = httptest.NewRecorder()
+		sreq = req.Clone(req.Context())
+	)
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = from
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+	handler.ServeHTTP(rec, sreq)
+	var resp = rec.Result()
+	resp.Request = req
+	return resp, nil
+}
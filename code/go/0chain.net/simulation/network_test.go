@@ -0,0 +1,137 @@
+package simulation
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"0chain.net/chaincore/node"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echoHandler responds with the sender and the form value and records the
+// time of the requests.
+type echoHandler struct {
+	clock    Clock
+	received []time.Time
+}
+
+func (eh *echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	eh.received = append(eh.received, eh.clock.Now())
+	w.Write([]byte(r.Header.Get(node.HeaderNodeID) + ":" + r.FormValue("v")))
+}
+
+func newTestNetwork() (*VirtualClock, *Network, map[string]*echoHandler) {
+	var (
+		vc       = NewVirtualClock(time.Unix(1000, 0))
+		nw       = NewNetwork(vc, 10*time.Millisecond)
+		handlers = make(map[string]*echoHandler)
+	)
+	for _, id := range []string{"a", "b", "c"} {
+		handlers[id] = &echoHandler{clock: vc}
+		nw.Register(id, handlers[id])
+	}
+	return vc, nw, handlers
+}
+
+func TestNetwork_SendAndRequest(t *testing.T) {
+	var vc, nw, handlers = newTestNetwork()
+	var start = vc.Now()
+
+	nw.SetDelay("a", "c", time.Second)
+	nw.Send("a", "b", "/echo", nil)
+	nw.Send("a", "c", "/echo", nil)
+	var response string
+	nw.Request("b", "a", "/echo", url.Values{"v": {"1"}},
+		func(resp *http.Response) {
+			data, _ := ioutil.ReadAll(resp.Body)
+			response = string(data)
+			require.Equal(t, start.Add(20*time.Millisecond), vc.Now())
+		})
+
+	vc.Advance(2 * time.Second)
+	require.Equal(t, []time.Time{start.Add(10 * time.Millisecond)},
+		handlers["b"].received)
+	require.Equal(t, []time.Time{start.Add(time.Second)},
+		handlers["c"].received)
+	require.Equal(t, "b:1", response)
+
+	sent, dropped := nw.Stats()
+	require.EqualValues(t, 4, sent)
+	require.Zero(t, dropped)
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	var vc, nw, handlers = newTestNetwork()
+
+	nw.Partition([]string{"a"}, []string{"b"})
+	nw.SetDrop("c", "a", true)
+	nw.Send("a", "b", "/echo", nil)
+	nw.Send("b", "a", "/echo", nil)
+	nw.Send("c", "a", "/echo", nil)
+	nw.Send("c", "b", "/echo", nil) // c isn't partitioned
+	var called bool
+	nw.Request("a", "c", "/echo", nil, func(*http.Response) { called = true })
+	vc.Advance(time.Second)
+	require.Empty(t, handlers["a"].received)
+	require.Len(t, handlers["b"].received, 1)
+	require.Len(t, handlers["c"].received, 1)
+	require.False(t, called, "the response link is dropped")
+
+	nw.Heal()
+	nw.Send("b", "a", "/echo", nil)
+	nw.Send("c", "a", "/echo", nil)
+	vc.Advance(time.Second)
+	require.Len(t, handlers["a"].received, 2)
+
+	_, dropped := nw.Stats()
+	require.EqualValues(t, 4, dropped)
+}
+
+func TestNetwork_RoundTrip(t *testing.T) {
+	var vc, nw, _ = newTestNetwork()
+	var client = &http.Client{Transport: nw}
+
+	type result struct {
+		body string
+		at   time.Time
+		err  error
+	}
+	var results = make(chan result)
+	go func() {
+		var req, _ = http.NewRequest(http.MethodGet, "http://b/echo?v=2", nil)
+		req.Header.Set(node.HeaderNodeID, "a")
+		resp, err := client.Do(req)
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		results <- result{body: string(data), at: vc.Now(), err: err}
+	}()
+
+	// drive the clock until the request is delivered
+	var r result
+	for done := false; !done; {
+		select {
+		case r = <-results:
+			done = true
+		default:
+			vc.Advance(time.Millisecond)
+			time.Sleep(time.Millisecond)
+		}
+	}
+	require.NoError(t, r.err)
+	require.Equal(t, "a:2", r.body)
+	require.True(t, !r.at.Before(time.Unix(1000, 0).Add(10*time.Millisecond)))
+
+	nw.SetDrop("a", "b", true)
+	var req, _ = http.NewRequest(http.MethodGet, "http://b/echo", nil)
+	req.Header.Set(node.HeaderNodeID, "a")
+	_, err := client.Do(req)
+	require.Error(t, err)
+}
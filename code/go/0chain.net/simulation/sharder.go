@@ -0,0 +1,232 @@
+package simulation
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/chain"
+	"0chain.net/chaincore/node"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+)
+
+// Sharder is a simulated sharder. It stores finalized blocks received from
+// miners in sequence, verifying their hash, notarization and state, and
+// requests missing ones. Its chain.Chain verifies the notarization and
+// learns magic blocks from the finalized blocks, like the real one. A
+// sharder is driven by the clock goroutine only.
+type Sharder struct {
+	h     *Harness
+	index int
+	id    string
+	chain *chain.Chain
+
+	mux     *http.ServeMux
+	store   *MemoryStore
+	state   util.NodeDB // state of the latest finalized block
+	stopped bool
+
+	// finalized blocks by round
+	blocks map[int64]*block.Block
+	// received blocks after a missing one by round
+	pending map[int64]*block.Block
+	// time of the last request of a missing round
+	requested map[int64]time.Time
+	// number of received blocks failed verification
+	rejected int
+}
+
+func newSharder(h *Harness, index int, id string, genesis *block.Block) (
+	*Sharder, error) {
+
+	var s = &Sharder{
+		h:         h,
+		index:     index,
+		id:        id,
+		chain:     h.protocol.newChain(h.protocol.magicBlocks[:1], genesis),
+		mux:       http.NewServeMux(),
+		store:     NewMemoryStore(),
+		pending:   make(map[int64]*block.Block),
+		requested: make(map[int64]time.Time),
+	}
+	genesis = s.chain.GetLatestFinalizedBlock()
+	s.blocks = map[int64]*block.Block{0: genesis}
+	var err error
+	if s.state, _, err = genesisState(); err != nil {
+		return nil, err
+	}
+	if err := s.storeBlock(genesis); err != nil {
+		return nil, err
+	}
+	s.mux.HandleFunc(finalizedBlockURI, func(w http.ResponseWriter,
+		r *http.Request) {
+
+		if s.stopped {
+			http.Error(w, "stopped", http.StatusServiceUnavailable)
+			return
+		}
+		b, err := decodeBlock(r)
+		if err == nil {
+			s.onFinalizedBlock(r.Header.Get(node.HeaderNodeID), b)
+		}
+		respond(w, nil, err)
+	})
+	s.mux.HandleFunc(getFinalizedBlockURI, func(w http.ResponseWriter,
+		r *http.Request) {
+
+		if s.stopped {
+			http.Error(w, "stopped", http.StatusServiceUnavailable)
+			return
+		}
+		round, err := parseRound(r)
+		if err != nil {
+			respond(w, nil, err)
+			return
+		}
+		var b = s.blocks[round]
+		if b == nil {
+			respond(w, nil, common.NewError("not_found", "block not found"))
+			return
+		}
+		respond(w, encodeBlock(b), nil)
+	})
+	return s, nil
+}
+
+// ID of the sharder.
+func (s *Sharder) ID() string { return s.id }
+
+// Index of the sharder in the harness.
+func (s *Sharder) Index() int { return s.index }
+
+// ServeHTTP implements http.Handler.
+func (s *Sharder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Store of the sharder with summaries of the finalized blocks.
+func (s *Sharder) Store() *MemoryStore { return s.store }
+
+// LatestFinalizedBlock stored by the sharder.
+func (s *Sharder) LatestFinalizedBlock() *block.Block {
+	return s.chain.GetLatestFinalizedBlock()
+}
+
+// FinalizedBlock of the round, nil if the round isn't stored.
+func (s *Sharder) FinalizedBlock(round int64) *block.Block {
+	return s.blocks[round]
+}
+
+// MagicBlock of the round known to the sharder.
+func (s *Sharder) MagicBlock(round int64) *block.MagicBlock {
+	return s.chain.GetMagicBlock(round)
+}
+
+// Rejected returns number of received blocks failed verification.
+func (s *Sharder) Rejected() int { return s.rejected }
+
+// GetBlockSummary reads summary of the finalized block of the round from
+// the sharder store.
+func (s *Sharder) GetBlockSummary(ctx context.Context, round int64) (
+	*block.BlockSummary, error) {
+
+	var b = s.blocks[round]
+	if b == nil {
+		return nil, common.NewErrorf("not_found", "round %d not finalized", round)
+	}
+	var bs = new(block.BlockSummary)
+	if err := s.store.Read(ctx, b.Hash, bs); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// Stop the sharder, it drops all messages until started again.
+func (s *Sharder) Stop() { s.stopped = true }
+
+// Start stopped sharder, it requests blocks missed while stopped once it
+// receives next finalized block.
+func (s *Sharder) Start() { s.stopped = false }
+
+func (s *Sharder) storeBlock(b *block.Block) error {
+	return s.store.Write(context.Background(), b.GetSummary())
+}
+
+func (s *Sharder) onFinalizedBlock(from string, b *block.Block) {
+	if b.Round <= s.LatestFinalizedBlock().Round {
+		return
+	}
+	s.pending[b.Round] = b
+	s.process()
+	var next = s.LatestFinalizedBlock().Round + 1
+	if _, ok := s.pending[next]; !ok && len(s.pending) > 0 {
+		s.requestRound(from, next)
+	}
+}
+
+// process pending blocks following the latest finalized one.
+func (s *Sharder) process() {
+	for {
+		var b, ok = s.pending[s.LatestFinalizedBlock().Round+1]
+		if !ok {
+			return
+		}
+		delete(s.pending, b.Round)
+		state, err := s.verify(b)
+		if err != nil {
+			s.rejected++
+			continue
+		}
+		if err := s.storeBlock(b); err != nil {
+			continue
+		}
+		s.chain.AddBlock(b)
+		setLatestFinalizedBlock(s.chain, b)
+		s.blocks[b.Round] = b
+		s.state = state
+		if b.MagicBlock != nil {
+			setMagicBlock(s.chain, b.MagicBlock)
+		}
+	}
+}
+
+func (s *Sharder) verify(b *block.Block) (util.NodeDB, error) {
+	var lfb = s.LatestFinalizedBlock()
+	if b.PrevHash != lfb.Hash || b.Hash != b.ComputeHash() {
+		return nil, common.NewErrorf("invalid_block",
+			"block %s doesn't follow %s", b.Hash, lfb.Hash)
+	}
+	var err = s.chain.VerifyNotarization(context.Background(), b,
+		b.GetVerificationTickets(), b.Round)
+	if err != nil {
+		return nil, err
+	}
+	return verifyState(s.state, lfb, b)
+}
+
+// requestRound requests finalized block of the round missing for the
+// sharder.
+func (s *Sharder) requestRound(from string, round int64) {
+	if at, ok := s.requested[round]; ok &&
+		s.h.clock.Now().Sub(at) < s.h.conf.RoundTimeout {
+		return
+	}
+	s.requested[round] = s.h.clock.Now()
+	s.h.network.Request(s.id, from, getFinalizedBlockURI,
+		url.Values{"round": {strconv.FormatInt(round, 10)}},
+		func(resp *http.Response) {
+			if s.stopped {
+				return
+			}
+			b, err := decodeBlockResponse(resp)
+			if err != nil {
+				return
+			}
+			delete(s.requested, round)
+			s.onFinalizedBlock(from, b)
+		})
+}
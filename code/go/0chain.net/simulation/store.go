@@ -0,0 +1,278 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+)
+
+// MemoryStore is datastore.Store keeping JSON of entities in memory instead
+// of redis or Cassandra. Every simulated node has its own store.
+type MemoryStore struct {
+	mutex sync.RWMutex
+	// entity name -> key -> JSON
+	entities map[string]map[datastore.Key][]byte
+	// collection name -> key -> score
+	collections map[string]map[datastore.Key]int64
+}
+
+// NewMemoryStore returns empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entities:    make(map[string]map[datastore.Key][]byte),
+		collections: make(map[string]map[datastore.Key]int64),
+	}
+}
+
+func (ms *MemoryStore) bucket(name string) map[datastore.Key][]byte {
+	var b, ok = ms.entities[name]
+	if !ok {
+		b = make(map[datastore.Key][]byte)
+		ms.entities[name] = b
+	}
+	return b
+}
+
+func (ms *MemoryStore) read(emd datastore.EntityMetadata, key datastore.Key,
+	entity datastore.Entity) error {
+
+	entity.SetKey(key)
+	data, ok := ms.entities[emd.GetName()][key]
+	if !ok {
+		return common.NewError(datastore.EntityNotFound,
+			fmt.Sprintf("%v not found with id = %v", emd.GetName(), key))
+	}
+	if err := json.Unmarshal(data, entity); err != nil {
+		return err
+	}
+	entity.ComputeProperties()
+	return nil
+}
+
+func (ms *MemoryStore) write(entity datastore.Entity, overwrite bool) error {
+	var (
+		name   = entity.GetEntityMetadata().GetName()
+		bucket = ms.bucket(name)
+		key    = entity.GetKey()
+	)
+	if _, ok := bucket[key]; ok && !overwrite {
+		return common.NewError("duplicate_entity",
+			fmt.Sprintf("%v with key %v already exists", name, key))
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	bucket[key] = data
+	if ce, ok := entity.(datastore.CollectionEntity); ok {
+		if ce.GetCollectionScore() == 0 {
+			if entity.GetScore() != 0 {
+				ce.SetCollectionScore(entity.GetScore())
+			} else {
+				ce.InitCollectionScore()
+			}
+		}
+		ms.addToCollection(ce)
+	}
+	return nil
+}
+
+func (ms *MemoryStore) addToCollection(ce datastore.CollectionEntity) {
+	var c, ok = ms.collections[ce.GetCollectionName()]
+	if !ok {
+		c = make(map[datastore.Key]int64)
+		ms.collections[ce.GetCollectionName()] = c
+	}
+	c[ce.GetKey()] = ce.GetCollectionScore()
+}
+
+func (ms *MemoryStore) delete(entity datastore.Entity) {
+	delete(ms.bucket(entity.GetEntityMetadata().GetName()), entity.GetKey())
+	if ce, ok := entity.(datastore.CollectionEntity); ok {
+		delete(ms.collections[ce.GetCollectionName()], ce.GetKey())
+	}
+}
+
+// Read an entity by the key.
+func (ms *MemoryStore) Read(ctx context.Context, key datastore.Key,
+	entity datastore.Entity) error {
+
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	return ms.read(entity.GetEntityMetadata(), key, entity)
+}
+
+// Write an entity.
+func (ms *MemoryStore) Write(ctx context.Context, entity datastore.Entity) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.write(entity, true)
+}
+
+// InsertIfNE inserts an entity only if it doesn't exist.
+func (ms *MemoryStore) InsertIfNE(ctx context.Context,
+	entity datastore.Entity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	return ms.write(entity, false)
+}
+
+// Delete an entity.
+func (ms *MemoryStore) Delete(ctx context.Context, entity datastore.Entity) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.delete(entity)
+	return nil
+}
+
+// MultiRead reads entities of given keys. Like the memorystore, a missing
+// entity gets empty key.
+func (ms *MemoryStore) MultiRead(ctx context.Context,
+	emd datastore.EntityMetadata, keys []datastore.Key,
+	entities []datastore.Entity) error {
+
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	for i, key := range keys {
+		var err = ms.read(emd, key, entities[i])
+		if cerr, ok := err.(*common.Error); ok &&
+			cerr.Code == datastore.EntityNotFound {
+			entities[i].SetKey(datastore.EmptyKey)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiWrite writes the entities.
+func (ms *MemoryStore) MultiWrite(ctx context.Context,
+	emd datastore.EntityMetadata, entities []datastore.Entity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, entity := range entities {
+		if err := ms.write(entity, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiDelete deletes the entities.
+func (ms *MemoryStore) MultiDelete(ctx context.Context,
+	emd datastore.EntityMetadata, entities []datastore.Entity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, entity := range entities {
+		ms.delete(entity)
+	}
+	return nil
+}
+
+// AddToCollection adds the entity to its collection.
+func (ms *MemoryStore) AddToCollection(ctx context.Context,
+	ce datastore.CollectionEntity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	ms.addToCollection(ce)
+	return nil
+}
+
+// MultiAddToCollection adds the entities to their collections.
+func (ms *MemoryStore) MultiAddToCollection(ctx context.Context,
+	emd datastore.EntityMetadata, entities []datastore.Entity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, entity := range entities {
+		ms.addToCollection(entity.(datastore.CollectionEntity))
+	}
+	return nil
+}
+
+// DeleteFromCollection removes the entity from its collection.
+func (ms *MemoryStore) DeleteFromCollection(ctx context.Context,
+	ce datastore.CollectionEntity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	delete(ms.collections[ce.GetCollectionName()], ce.GetKey())
+	return nil
+}
+
+// MultiDeleteFromCollection removes the entities from their collections.
+func (ms *MemoryStore) MultiDeleteFromCollection(ctx context.Context,
+	emd datastore.EntityMetadata, entities []datastore.Entity) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, entity := range entities {
+		var ce = entity.(datastore.CollectionEntity)
+		delete(ms.collections[ce.GetCollectionName()], ce.GetKey())
+	}
+	return nil
+}
+
+// GetCollectionSize returns number of entities in the collection.
+func (ms *MemoryStore) GetCollectionSize(ctx context.Context,
+	emd datastore.EntityMetadata, collectionName string) int64 {
+
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	return int64(len(ms.collections[collectionName]))
+}
+
+// IterateCollection calls the handler for entities of the collection in
+// descending order of their scores until it returns false. Entities are
+// read before the iteration, so the handler can modify the store.
+func (ms *MemoryStore) IterateCollection(ctx context.Context,
+	emd datastore.EntityMetadata, collectionName string,
+	handler datastore.CollectionIteratorHandler) error {
+
+	ms.mutex.RLock()
+	var (
+		scores   = ms.collections[collectionName]
+		keys     = make([]datastore.Key, 0, len(scores))
+		entities = make([]datastore.CollectionEntity, 0, len(scores))
+	)
+	for key := range scores {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if scores[keys[i]] == scores[keys[j]] {
+			return keys[i] < keys[j]
+		}
+		return scores[keys[i]] > scores[keys[j]]
+	})
+	for _, key := range keys {
+		var entity = emd.Instance().(datastore.CollectionEntity)
+		if err := ms.read(emd, key, entity); err != nil {
+			continue // in the collection only
+		}
+		entities = append(entities, entity)
+	}
+	ms.mutex.RUnlock()
+
+	for _, entity := range entities {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if !handler(ctx, entity) {
+			break
+		}
+	}
+	return nil
+}
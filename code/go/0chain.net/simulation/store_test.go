@@ -0,0 +1,47 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/core/common"
+	"0chain.net/core/datastore"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlockSummary(hash string, round int64) *block.BlockSummary {
+	var bs = datastore.GetEntityMetadata("block_summary").Instance().(*block.BlockSummary)
+	bs.Hash = hash
+	bs.Round = round
+	return bs
+}
+
+func TestMemoryStore(t *testing.T) {
+	setupEntities()
+	var (
+		ctx = context.Background()
+		ms  = NewMemoryStore()
+		emd = datastore.GetEntityMetadata("block_summary")
+	)
+	require.NoError(t, ms.Write(ctx, newTestBlockSummary("a", 1)))
+	require.NoError(t, ms.InsertIfNE(ctx, newTestBlockSummary("b", 2)))
+	require.Error(t, ms.InsertIfNE(ctx, newTestBlockSummary("b", 3)))
+
+	var bs = new(block.BlockSummary)
+	require.NoError(t, ms.Read(ctx, "b", bs))
+	require.EqualValues(t, 2, bs.Round)
+
+	var entities = []datastore.Entity{emd.Instance(), emd.Instance(),
+		emd.Instance()}
+	require.NoError(t, ms.MultiRead(ctx, emd, []datastore.Key{"a", "x", "b"},
+		entities))
+	require.EqualValues(t, 1, entities[0].(*block.BlockSummary).Round)
+	require.EqualValues(t, 2, entities[2].(*block.BlockSummary).Round)
+
+	require.NoError(t, ms.Delete(ctx, newTestBlockSummary("a", 1)))
+	var err = ms.Read(ctx, "a", new(block.BlockSummary))
+	require.Error(t, err)
+	require.Equal(t, datastore.EntityNotFound, err.(*common.Error).Code)
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 
 	c_state "0chain.net/chaincore/chain/state"
+	"0chain.net/core/common"
 )
 
 const (
@@ -17,12 +18,21 @@ const (
 	noClient        = "can't get client"
 )
 
+// clientParams are query parameters of a client handler
+type clientParams struct {
+	ClientID string `param:"client_id"`
+}
+
 func (fc *FaucetSmartContract) personalPeriodicLimit(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
+	var p clientParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 	gn, err := fc.getGlobalNode(balances)
 	if err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, noLimitsMsg, noGlobalNodeMsg)
 	}
-	un, err := fc.getUserNode(params.Get("client_id"), gn.ID, balances)
+	un, err := fc.getUserNode(p.ClientID, gn.ID, balances)
 	if err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, noLimitsMsg, noClient)
 	}
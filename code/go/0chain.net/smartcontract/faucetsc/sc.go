@@ -59,10 +59,14 @@ func (fc *FaucetSmartContract) GetRestPoints() map[string]smartcontractinterface
 
 func (fc *FaucetSmartContract) setSC(sc *smartcontractinterface.SmartContract, _ smartcontractinterface.BCContextI) {
 	fc.SmartContract = sc
-	fc.SmartContract.RestHandlers["/personalPeriodicLimit"] = fc.personalPeriodicLimit
-	fc.SmartContract.RestHandlers["/globalPerodicLimit"] = fc.globalPerodicLimit
-	fc.SmartContract.RestHandlers["/pourAmount"] = fc.pourAmount
-	fc.SmartContract.RestHandlers["/getConfig"] = fc.getConfigHandler
+	fc.SetRestHandler("/personalPeriodicLimit", fc.personalPeriodicLimit, &smartcontractinterface.RestEndpoint{
+		Summary: "tokens poured to the client in the current period", Params: clientParams{}, Response: periodicResponse{}})
+	fc.SetRestHandler("/globalPerodicLimit", fc.globalPerodicLimit, &smartcontractinterface.RestEndpoint{
+		Summary: "tokens poured in the current global period", Response: periodicResponse{}})
+	fc.SetRestHandler("/pourAmount", fc.pourAmount, &smartcontractinterface.RestEndpoint{
+		Summary: "tokens poured per request", Response: ""})
+	fc.SetRestHandler("/getConfig", fc.getConfigHandler, &smartcontractinterface.RestEndpoint{
+		Summary: "faucet configurations", Response: faucetConfig{}})
 	fc.SmartContractExecutionStats["updateLimits"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "updateLimits"), nil)
 	fc.SmartContractExecutionStats["pour"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "pour"), nil)
 	fc.SmartContractExecutionStats["refill"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", fc.ID, "refill"), nil)
@@ -10,8 +10,17 @@ import (
 	c_state "0chain.net/chaincore/chain/state"
 )
 
+// clientParams are query parameters of a client handler
+type clientParams struct {
+	ClientID string `param:"client_id"`
+}
+
 func (ip *InterestPoolSmartContract) getPoolsStats(ctx context.Context, params url.Values, balances c_state.StateContextI) (interface{}, error) {
-	un := ip.getUserNode(params.Get("client_id"), balances)
+	var p clientParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	un := ip.getUserNode(p.ClientID, balances)
 	if len(un.Pools) == 0 {
 		return nil, common.NewErrNoResource("can't find user node")
 	}
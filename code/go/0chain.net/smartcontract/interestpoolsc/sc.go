@@ -61,8 +61,10 @@ func (ipsc *InterestPoolSmartContract) GetRestPoints() map[string]smartcontracti
 
 func (ipsc *InterestPoolSmartContract) setSC(sc *smartcontractinterface.SmartContract, bcContext smartcontractinterface.BCContextI) {
 	ipsc.SmartContract = sc
-	ipsc.SmartContract.RestHandlers["/getPoolsStats"] = ipsc.getPoolsStats
-	ipsc.SmartContract.RestHandlers["/getLockConfig"] = ipsc.getLockConfig
+	ipsc.SetRestHandler("/getPoolsStats", ipsc.getPoolsStats, &smartcontractinterface.RestEndpoint{
+		Summary: "interest pools of the client", Params: clientParams{}, Response: poolStats{}})
+	ipsc.SetRestHandler("/getLockConfig", ipsc.getLockConfig, &smartcontractinterface.RestEndpoint{
+		Summary: "interest pool configurations", Response: GlobalNode{}})
 	ipsc.SmartContractExecutionStats["lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "lock"), nil)
 	ipsc.SmartContractExecutionStats["unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "unlock"), nil)
 	ipsc.SmartContractExecutionStats["updateVariables"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ipsc.ID, "updateVariables"), nil)
@@ -23,6 +23,8 @@ var moveFunctions = make(map[Phase]movePhaseFunctions)
 - Start      : moveToContribute
 - Contribute : moveToShareOrPublish
 - Share      : moveToShareOrPublish
+- Complain   : moveToShareOrPublish
+- Justify    : moveToShareOrPublish
 - Publish    : moveToWait
 - Wait       : moveToStart
 */
@@ -265,11 +267,10 @@ func (msc *MinerSmartContract) setPhaseNode(balances cstate.StateContextI,
 				}
 			}
 			if err == nil {
-				if pn.Phase >= Phase(len(PhaseRounds)-1) {
-					pn.Phase = 0
+				pn.Phase = NextPhase(pn.Phase,
+					dkgComplaintsActive(balances, pn.CurrentRound))
+				if pn.Phase == Start {
 					pn.Restarts = 0
-				} else {
-					pn.Phase++
 				}
 				pn.StartRound = pn.CurrentRound
 				Logger.Debug("setPhaseNode", zap.String("next_phase", pn.Phase.String()))
@@ -331,6 +332,10 @@ func (msc *MinerSmartContract) createDKGMinersForContribute(
 		return err
 	}
 
+	if err := updateDKGComplaints(balances, NewDKGComplaints()); err != nil {
+		return err
+	}
+
 	// sharders
 	allSharderKeepList := new(MinerNodes)
 	return updateShardersKeepList(balances, allSharderKeepList)
@@ -455,6 +460,18 @@ func (msc *MinerSmartContract) createMagicBlockForWait(
 		}
 	}
 
+	complaints, err := getDKGComplaints(balances)
+	if err != nil {
+		return common.NewError("create_magic_block_failed", err.Error())
+	}
+	for _, key := range complaints.Disqualified() {
+		Logger.Info("create magic block for wait: dealer disqualified",
+			zap.String("dealer", key))
+		delete(dkgMinersList.SimpleNodes, key)
+		delete(gsos.Shares, key)
+		delete(mpks.Mpks, key)
+	}
+
 	// sharders
 	sharders, err := getShardersKeepList(balances)
 	if err != nil {
@@ -739,6 +756,7 @@ func (msc *MinerSmartContract) createMagicBlock(
 	magicBlock.T = dkgMinersList.T
 	magicBlock.K = dkgMinersList.K
 	magicBlock.N = dkgMinersList.N
+	magicBlock.MinersStake = make(map[string]int64, len(dkgMinersList.SimpleNodes))
 
 	for _, v := range dkgMinersList.SimpleNodes {
 		n := &node.Node{}
@@ -754,6 +772,13 @@ func (msc *MinerSmartContract) createMagicBlock(
 		n.Status = node.NodeStatusActive
 		n.InPrevMB = pmb.Miners.HasNode(v.ID)
 		magicBlock.Miners.AddNode(n)
+
+		// the DKG list keeps stakes of the start phase, use actual one
+		var stake = v.TotalStaked
+		if mn, err := getMinerNode(v.ID, balances); err == nil {
+			stake = mn.TotalStaked
+		}
+		magicBlock.MinersStake[v.ID] = stake
 	}
 
 	for _, v := range sharders.Nodes {
@@ -772,6 +797,15 @@ func (msc *MinerSmartContract) createMagicBlock(
 		magicBlock.Sharders.AddNode(n)
 	}
 
+	pfs, err := getProtocolFeatures(balances)
+	if err != nil {
+		return nil, common.NewErrorf("create_magic_block_failed",
+			"can't get protocol features: %v", err)
+	}
+	if pfs.Size() > 0 {
+		magicBlock.Features = pfs
+	}
+
 	magicBlock.MagicBlockNumber = pmb.MagicBlock.MagicBlockNumber + 1
 	magicBlock.PreviousMagicBlockHash = pmb.MagicBlock.Hash
 	magicBlock.StartingRound = pn.CurrentRound + PhaseRounds[Wait]
@@ -805,6 +839,9 @@ func (msc *MinerSmartContract) RestartDKG(pn *PhaseNode,
 	if err := updateShardersKeepList(balances, sharderKeepList); err != nil {
 		Logger.Error("failed to restart dkg", zap.Any("error", err))
 	}
+	if err := updateDKGComplaints(balances, NewDKGComplaints()); err != nil {
+		Logger.Error("failed to restart dkg", zap.Any("error", err))
+	}
 	pn.Phase = Start
 	pn.Restarts++
 	pn.StartRound = pn.CurrentRound
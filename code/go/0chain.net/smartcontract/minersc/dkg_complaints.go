@@ -0,0 +1,373 @@
+package minersc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/threshold/bls"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/encryption"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract"
+
+	. "0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// DKGComplaintsKey is key of the DKG complaints of current view change.
+//
+// During the complain phase a miner accuses dealers it hasn't received a
+// valid secret share from. During the justify phase an accused dealer
+// reveals the disputed shares on chain and the SC validates them against
+// the dealer's MPK. A dealer with at least one complaint not justified is
+// disqualified and is excluded from the next magic block, thus from the
+// qualified set of the DKG.
+var DKGComplaintsKey = globalKeyHash("dkg_complaints")
+
+// DealersComplaint is input of the 'complainDealers' SC function.
+type DealersComplaint struct {
+	Dealers []string `json:"dealers"`
+}
+
+// Encode implements util.Serializable interface.
+func (dc *DealersComplaint) Encode() []byte {
+	buff, _ := json.Marshal(dc)
+	return buff
+}
+
+// Decode implements util.Serializable interface.
+func (dc *DealersComplaint) Decode(input []byte) error {
+	return json.Unmarshal(input, dc)
+}
+
+// SharesJustification is input of the 'justifyShares' SC function. It
+// contains secret shares of a dealer for all miners complained about it.
+type SharesJustification struct {
+	// Shares maps complainer to its secret share (hex) from the dealer.
+	Shares map[string]string `json:"shares"`
+}
+
+// Encode implements util.Serializable interface.
+func (sj *SharesJustification) Encode() []byte {
+	buff, _ := json.Marshal(sj)
+	return buff
+}
+
+// Decode implements util.Serializable interface.
+func (sj *SharesJustification) Decode(input []byte) error {
+	return json.Unmarshal(input, sj)
+}
+
+// DKGComplaints is state node of the complain and justify phases.
+type DKGComplaints struct {
+	// Complaints maps complainer to dealers accused by it.
+	Complaints map[string][]string `json:"complaints"`
+	// Justifications maps dealer to shares revealed by it for complainers.
+	Justifications map[string]map[string]string `json:"justifications"`
+}
+
+// NewDKGComplaints returns new empty DKG complaints.
+func NewDKGComplaints() *DKGComplaints {
+	return &DKGComplaints{
+		Complaints:     make(map[string][]string),
+		Justifications: make(map[string]map[string]string),
+	}
+}
+
+func (dc *DKGComplaints) Encode() []byte {
+	buff, _ := json.Marshal(dc)
+	return buff
+}
+
+func (dc *DKGComplaints) Decode(input []byte) error {
+	return json.Unmarshal(input, dc)
+}
+
+func (dc *DKGComplaints) GetHash() string {
+	return util.ToHex(dc.GetHashBytes())
+}
+
+func (dc *DKGComplaints) GetHashBytes() []byte {
+	return encryption.RawHash(dc.Encode())
+}
+
+// Complainers returns sorted list of miners complained about given dealer.
+func (dc *DKGComplaints) Complainers(dealer string) (complainers []string) {
+	for complainer, dealers := range dc.Complaints {
+		for _, d := range dealers {
+			if d == dealer {
+				complainers = append(complainers, complainer)
+				break
+			}
+		}
+	}
+	sort.Strings(complainers)
+	return
+}
+
+// JustifiedShare returns share revealed by given dealer for given complainer.
+func (dc *DKGComplaints) JustifiedShare(dealer, complainer string) (
+	share string, ok bool) {
+
+	share, ok = dc.Justifications[dealer][complainer]
+	return
+}
+
+// Disqualified returns sorted list of dealers which have complaints not
+// justified.
+func (dc *DKGComplaints) Disqualified() (dealers []string) {
+	var dq = make(map[string]struct{})
+	for complainer, accused := range dc.Complaints {
+		for _, dealer := range accused {
+			if _, ok := dc.JustifiedShare(dealer, complainer); !ok {
+				dq[dealer] = struct{}{}
+			}
+		}
+	}
+	dealers = make([]string, 0, len(dq))
+	for dealer := range dq {
+		dealers = append(dealers, dealer)
+	}
+	sort.Strings(dealers)
+	return
+}
+
+func getDKGComplaints(balances cstate.StateContextI) (
+	dc *DKGComplaints, err error) {
+
+	dc = NewDKGComplaints()
+
+	var val util.Serializable
+	if val, err = balances.GetTrieNode(DKGComplaintsKey); err != nil {
+		if err != util.ErrValueNotPresent {
+			return nil, err
+		}
+		return dc, nil
+	}
+
+	if err = dc.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return dc, nil
+}
+
+func updateDKGComplaints(balances cstate.StateContextI,
+	dc *DKGComplaints) (err error) {
+
+	_, err = balances.InsertTrieNode(DKGComplaintsKey, dc)
+	return
+}
+
+// complainDealers accuses dealers the sender hasn't received valid secret
+// shares from. A miner can complain only once per view change.
+func (msc *MinerSmartContract) complainDealers(t *transaction.Transaction,
+	inputData []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var pn *PhaseNode
+	if pn, err = GetPhaseNode(balances); err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"can't get phase node: %v", err)
+	}
+
+	if pn.Phase != Complain {
+		return "", common.NewErrorf("complain_dealers", "this is not the"+
+			" correct phase to complain: %s", pn.Phase)
+	}
+
+	var dmn *DKGMinerNodes
+	if dmn, err = getDKGMinersList(balances); err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"getting miners DKG list: %v", err)
+	}
+
+	if _, ok := dmn.SimpleNodes[t.ClientID]; !ok {
+		return "", common.NewError("complain_dealers",
+			"miner not part of dkg set")
+	}
+
+	var dc = new(DealersComplaint)
+	if err = dc.Decode(inputData); err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"decoding request: %v", err)
+	}
+
+	if len(dc.Dealers) == 0 {
+		return "", common.NewError("complain_dealers", "empty dealers list")
+	}
+
+	msc.mutexMinerMPK.Lock()
+	defer msc.mutexMinerMPK.Unlock()
+
+	mpks, err := getMinersMPKs(balances)
+	if err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"getting miners MPKs: %v", err)
+	}
+
+	var seen = make(map[string]struct{}, len(dc.Dealers))
+	for _, dealer := range dc.Dealers {
+		if dealer == t.ClientID {
+			return "", common.NewError("complain_dealers",
+				"can't complain about self")
+		}
+		if _, ok := mpks.Mpks[dealer]; !ok {
+			return "", common.NewErrorf("complain_dealers",
+				"dealer %s has not contributed MPK", dealer)
+		}
+		if _, ok := seen[dealer]; ok {
+			return "", common.NewErrorf("complain_dealers",
+				"duplicate dealer %s", dealer)
+		}
+		seen[dealer] = struct{}{}
+	}
+
+	var complaints *DKGComplaints
+	if complaints, err = getDKGComplaints(balances); err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"getting DKG complaints: %v", err)
+	}
+
+	if _, ok := complaints.Complaints[t.ClientID]; ok {
+		return "", common.NewErrorf("complain_dealers",
+			"already have complaints of miner %s", t.ClientID)
+	}
+
+	complaints.Complaints[t.ClientID] = dc.Dealers
+	if err = updateDKGComplaints(balances, complaints); err != nil {
+		return "", common.NewErrorf("complain_dealers",
+			"saving DKG complaints: %v", err)
+	}
+
+	Logger.Info("miner sc: dealers complaint",
+		zap.String("complainer", t.ClientID),
+		zap.Strings("dealers", dc.Dealers))
+
+	return string(dc.Encode()), nil
+}
+
+// justifyShares reveals secret shares of the sender (dealer) for all miners
+// complained about it. All the shares must be given at once and must be
+// valid for the sender's MPK.
+func (msc *MinerSmartContract) justifyShares(t *transaction.Transaction,
+	inputData []byte, gn *GlobalNode, balances cstate.StateContextI) (
+	resp string, err error) {
+
+	var pn *PhaseNode
+	if pn, err = GetPhaseNode(balances); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"can't get phase node: %v", err)
+	}
+
+	if pn.Phase != Justify {
+		return "", common.NewErrorf("justify_shares", "this is not the"+
+			" correct phase to justify shares: %s", pn.Phase)
+	}
+
+	var sj = new(SharesJustification)
+	if err = sj.Decode(inputData); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"decoding request: %v", err)
+	}
+
+	var complaints *DKGComplaints
+	if complaints, err = getDKGComplaints(balances); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"getting DKG complaints: %v", err)
+	}
+
+	if _, ok := complaints.Justifications[t.ClientID]; ok {
+		return "", common.NewErrorf("justify_shares",
+			"already have justification of miner %s", t.ClientID)
+	}
+
+	var complainers = complaints.Complainers(t.ClientID)
+	if len(complainers) == 0 {
+		return "", common.NewErrorf("justify_shares",
+			"no complaints about miner %s", t.ClientID)
+	}
+
+	// don't reveal more shares than required
+	if len(sj.Shares) != len(complainers) {
+		return "", common.NewErrorf("justify_shares",
+			"shares given for %d miners, but %d complained",
+			len(sj.Shares), len(complainers))
+	}
+
+	var dmn *DKGMinerNodes
+	if dmn, err = getDKGMinersList(balances); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"getting miners DKG list: %v", err)
+	}
+
+	msc.mutexMinerMPK.Lock()
+	defer msc.mutexMinerMPK.Unlock()
+
+	mpks, err := getMinersMPKs(balances)
+	if err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"getting miners MPKs: %v", err)
+	}
+
+	mpk, ok := mpks.Mpks[t.ClientID]
+	if !ok {
+		return "", common.NewErrorf("justify_shares",
+			"missing MPK of miner %s", t.ClientID)
+	}
+
+	var jpk = bls.ConvertStringToMpk(mpk.Mpk)
+	for _, complainer := range complainers {
+		var share, ok = sj.Shares[complainer]
+		if !ok {
+			return "", common.NewErrorf("justify_shares",
+				"missing share for %s", complainer)
+		}
+		var sij bls.Key
+		if err = sij.SetHexString(share); err != nil {
+			return "", common.NewErrorf("justify_shares",
+				"invalid share for %s: %v", complainer, err)
+		}
+		if !bls.ValidateShare(jpk, sij, bls.ComputeIDdkg(complainer)) {
+			return "", common.NewErrorf("justify_shares",
+				"share for %s failed validation", complainer)
+		}
+	}
+
+	// the shares are public now
+	for _, complainer := range complainers {
+		dmn.RevealedShares[complainer]++
+	}
+
+	complaints.Justifications[t.ClientID] = sj.Shares
+	if err = updateDKGComplaints(balances, complaints); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"saving DKG complaints: %v", err)
+	}
+
+	if err = updateDKGMinersList(balances, dmn); err != nil {
+		return "", common.NewErrorf("justify_shares",
+			"saving DKG miners: %v", err)
+	}
+
+	Logger.Info("miner sc: shares justified",
+		zap.String("dealer", t.ClientID),
+		zap.Strings("complainers", complainers))
+
+	return string(sj.Encode()), nil
+}
+
+// GetDKGComplaintsHandler returns DKG complaints of current view change.
+func (msc *MinerSmartContract) GetDKGComplaintsHandler(ctx context.Context,
+	params url.Values, balances cstate.StateContextI) (interface{}, error) {
+
+	dc, err := getDKGComplaints(balances)
+	if err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true)
+	}
+	return dc, nil
+}
@@ -0,0 +1,152 @@
+package minersc
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/threshold/bls"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/encryption"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setTestPhase(t *testing.T, balances *testBalances, phase Phase) {
+	var pn = &PhaseNode{Phase: phase}
+	_, err := balances.InsertTrieNode(pn.GetKey(), pn)
+	require.NoError(t, err)
+}
+
+func TestNextPhase(t *testing.T) {
+	var walk = func(complaints bool) (phases []Phase) {
+		for p := NextPhase(Unknown, complaints); len(phases) < 8; p = NextPhase(p, complaints) {
+			phases = append(phases, p)
+		}
+		return
+	}
+	require.Equal(t, []Phase{Start, Contribute, Share, Publish, Wait,
+		Start, Contribute, Share}, walk(false))
+	require.Equal(t, []Phase{Start, Contribute, Share, Complain, Justify,
+		Publish, Wait, Start}, walk(true))
+	require.Equal(t, Phase(3), Publish, "stored phase values are stable")
+	require.Equal(t, Phase(4), Wait, "stored phase values are stable")
+}
+
+func TestDKGComplaints_Disqualified(t *testing.T) {
+	var dc = NewDKGComplaints()
+	require.Empty(t, dc.Disqualified())
+
+	dc.Complaints["m1"] = []string{"d1", "d2"}
+	dc.Complaints["m2"] = []string{"d1"}
+	require.Equal(t, []string{"m1", "m2"}, dc.Complainers("d1"))
+	require.Equal(t, []string{"m1"}, dc.Complainers("d2"))
+	require.Empty(t, dc.Complainers("d3"))
+	require.Equal(t, []string{"d1", "d2"}, dc.Disqualified())
+
+	dc.Justifications["d1"] = map[string]string{"m1": "s1", "m2": "s2"}
+	require.Equal(t, []string{"d2"}, dc.Disqualified())
+
+	share, ok := dc.JustifiedShare("d1", "m2")
+	require.True(t, ok)
+	require.Equal(t, "s2", share)
+	_, ok = dc.JustifiedShare("d2", "m1")
+	require.False(t, ok)
+}
+
+func TestComplainDealersAndJustifyShares(t *testing.T) {
+	var (
+		msc       = newTestMinerSC()
+		balances  = newTestBalances()
+		gn        = new(GlobalNode)
+		d, m1, m2 = encryption.Hash("dealer"), encryption.Hash("miner1"),
+			encryption.Hash("miner2")
+
+		ids    = []string{d, m1, m2}
+		dealer = bls.MakeDKG(2, len(ids), d)
+		dmn    = NewDKGMinerNodes()
+		mpks   = block.NewMpks()
+	)
+	balances.block = block.Provider().(*block.Block)
+
+	for _, id := range ids {
+		dmn.SimpleNodes[id] = &SimpleNode{ID: id}
+		var mpk = &block.MPK{ID: id}
+		for _, pk := range dealer.GetMPKs() {
+			mpk.Mpk = append(mpk.Mpk, pk.GetHexString())
+		}
+		mpks.Mpks[id] = mpk
+	}
+	require.NoError(t, updateDKGMinersList(balances, dmn))
+	require.NoError(t, updateMinersMPKs(balances, mpks))
+
+	var complain = func(from string, dealers ...string) error {
+		var dc = &DealersComplaint{Dealers: dealers}
+		_, err := msc.complainDealers(&transaction.Transaction{ClientID: from},
+			dc.Encode(), gn, balances)
+		return err
+	}
+
+	var justify = func(from string, shares map[string]string) error {
+		var sj = &SharesJustification{Shares: shares}
+		_, err := msc.justifyShares(&transaction.Transaction{ClientID: from},
+			sj.Encode(), gn, balances)
+		return err
+	}
+
+	var shareFor = func(id string) string {
+		share, err := dealer.ComputeDKGKeyShare(bls.ComputeIDdkg(id))
+		require.NoError(t, err)
+		return share.GetHexString()
+	}
+
+	setTestPhase(t, balances, Share)
+	require.Error(t, complain(m1, d), "wrong phase")
+
+	setTestPhase(t, balances, Complain)
+	require.Error(t, complain("unknown", d), "not in DKG")
+	require.Error(t, complain(m1), "empty list")
+	require.Error(t, complain(m1, m1), "self")
+	require.Error(t, complain(m1, "unknown"), "unknown dealer")
+	require.Error(t, complain(m1, d, d), "duplicate")
+	require.NoError(t, complain(m1, d))
+	require.NoError(t, complain(m2, d))
+	require.Error(t, complain(m1, d), "already complained")
+
+	complaints, err := getDKGComplaints(balances)
+	require.NoError(t, err)
+	require.Equal(t, []string{d}, complaints.Disqualified())
+
+	require.Error(t, justify(d, map[string]string{
+		m1: shareFor(m1),
+		m2: shareFor(m2),
+	}), "wrong phase")
+
+	setTestPhase(t, balances, Justify)
+	require.Error(t, justify(m1, map[string]string{
+		d: shareFor(d),
+	}), "no complaints")
+	require.Error(t, justify(d, map[string]string{
+		m1: shareFor(m1),
+	}), "not all complainers")
+	require.Error(t, justify(d, map[string]string{
+		m1: shareFor(m1),
+		m2: shareFor(m1),
+	}), "invalid share")
+	require.NoError(t, justify(d, map[string]string{
+		m1: shareFor(m1),
+		m2: shareFor(m2),
+	}))
+	require.Error(t, justify(d, map[string]string{
+		m1: shareFor(m1),
+		m2: shareFor(m2),
+	}), "already justified")
+
+	complaints, err = getDKGComplaints(balances)
+	require.NoError(t, err)
+	require.Empty(t, complaints.Disqualified())
+
+	dmn, err = getDKGMinersList(balances)
+	require.NoError(t, err)
+	require.Equal(t, 1, dmn.RevealedShares[m1])
+	require.Equal(t, 1, dmn.RevealedShares[m2])
+}
@@ -19,16 +19,41 @@ const (
 	cantGetMinerNodeMsg = "can't get miner node"
 )
 
+// clientParams are query parameters of a client handler
+type clientParams struct {
+	ClientID string `param:"client_id"`
+}
+
+// nodepoolParams are query parameters of the nodepool handler
+type nodepoolParams struct {
+	ID      string `param:"id,required"`
+	N2NHost string `param:"n2n_host,required"`
+}
+
+// nodeParams are query parameters of a node handler
+type nodeParams struct {
+	ID string `param:"id"`
+}
+
+// nodePoolParams are query parameters of a delegate pool handler
+type nodePoolParams struct {
+	ID     string `param:"id"`
+	PoolID string `param:"pool_id"`
+}
+
 // user oriented pools requests handler
 func (msc *MinerSmartContract) GetUserPoolsHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (
 	resp interface{}, err error) {
 
 	var (
-		clientID = params.Get("client_id")
-		un       *UserNode
+		p  clientParams
+		un *UserNode
 	)
-	if un, err = msc.getUserNode(clientID, balances); err != nil {
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if un, err = msc.getUserNode(p.ClientID, balances); err != nil {
 		return nil, common.NewErrInternal("can't get user node", err.Error())
 	}
 
@@ -156,11 +181,14 @@ func (msc *MinerSmartContract) nodeStatHandler(ctx context.Context,
 	resp interface{}, err error) {
 
 	var (
-		id = params.Get("id")
+		p  nodeParams
 		sn *MinerNode
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
-	if sn, err = getMinerNode(id, balances); err != nil {
+	if sn, err = getMinerNode(p.ID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetMinerNodeMsg)
 	}
 
@@ -172,20 +200,22 @@ func (msc *MinerSmartContract) nodePoolStatHandler(ctx context.Context,
 	resp interface{}, err error) {
 
 	var (
-		id     = params.Get("id")
-		poolID = params.Get("pool_id")
-		sn     *MinerNode
+		p  nodePoolParams
+		sn *MinerNode
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
-	if sn, err = getMinerNode(id, balances); err != nil {
+	if sn, err = getMinerNode(p.ID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetMinerNodeMsg)
 	}
 
-	if pool, ok := sn.Pending[poolID]; ok {
+	if pool, ok := sn.Pending[p.PoolID]; ok {
 		return pool, nil
-	} else if pool, ok = sn.Active[poolID]; ok {
+	} else if pool, ok = sn.Active[p.PoolID]; ok {
 		return pool, nil
-	} else if pool, ok = sn.Deleting[poolID]; ok {
+	} else if pool, ok = sn.Deleting[p.PoolID]; ok {
 		return pool, nil
 	}
 
@@ -211,6 +241,8 @@ func (msc *MinerSmartContract) configsHandler(ctx context.Context,
 	conf.StartRounds = scc.GetInt64(pfx + "start_rounds")
 	conf.ContributeRounds = scc.GetInt64(pfx + "contribute_rounds")
 	conf.ShareRounds = scc.GetInt64(pfx + "share_rounds")
+	conf.ComplainRounds = scc.GetInt64(pfx + "complain_rounds")
+	conf.JustifyRounds = scc.GetInt64(pfx + "justify_rounds")
 	conf.PublishRounds = scc.GetInt64(pfx + "publish_rounds")
 	conf.WaitRounds = scc.GetInt64(pfx + "wait_rounds")
 
@@ -60,7 +60,10 @@ func (sc *mockStateContext) GetBlock() *block.Block {
 func (sc *mockStateContext) SetStateContext(_ *state.State) error { return nil }
 
 func (sc *mockStateContext) GetTrieNode(key datastore.Key) (util.Serializable, error) {
-	return sc.store[key], nil
+	if val, ok := sc.store[key]; ok {
+		return val, nil
+	}
+	return nil, util.ErrValueNotPresent
 }
 
 func (sc *mockStateContext) InsertTrieNode(key datastore.Key, node util.Serializable) (datastore.Key, error) {
@@ -48,6 +48,8 @@ func init() {
 	moveFunctions[Start] = moveTrue
 	moveFunctions[Contribute] = moveTrue
 	moveFunctions[Share] = moveTrue
+	moveFunctions[Complain] = moveTrue
+	moveFunctions[Justify] = moveTrue
 	moveFunctions[Publish] = moveTrue
 	moveFunctions[Wait] = moveTrue
 }
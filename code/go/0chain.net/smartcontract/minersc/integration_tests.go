@@ -25,12 +25,16 @@ func (msc *MinerSmartContract) initSC() {
 	PhaseRounds[Start] = scc.GetInt64(pfx + "start_rounds")
 	PhaseRounds[Contribute] = scc.GetInt64(pfx + "contribute_rounds")
 	PhaseRounds[Share] = scc.GetInt64(pfx + "share_rounds")
+	PhaseRounds[Complain] = scc.GetInt64(pfx + "complain_rounds")
+	PhaseRounds[Justify] = scc.GetInt64(pfx + "justify_rounds")
 	PhaseRounds[Publish] = scc.GetInt64(pfx + "publish_rounds")
 	PhaseRounds[Wait] = scc.GetInt64(pfx + "wait_rounds")
 
 	moveFunctions[Start] = msc.moveToContribute
 	moveFunctions[Contribute] = msc.moveToShareOrPublish
 	moveFunctions[Share] = msc.moveToShareOrPublish
+	moveFunctions[Complain] = msc.moveToShareOrPublish
+	moveFunctions[Justify] = msc.moveToShareOrPublish
 	moveFunctions[Publish] = msc.moveToWait
 	moveFunctions[Wait] = msc.moveToStart
 
@@ -42,10 +46,13 @@ func (msc *MinerSmartContract) initSC() {
 	msc.smartContractFunctions["shareSignsOrShares"] = msc.shareSignsOrSharesIntegrationTests
 	msc.smartContractFunctions["sharder_keep"] = msc.sharderKeepIntegrationTests
 	// as is
+	msc.smartContractFunctions["complainDealers"] = msc.complainDealers
+	msc.smartContractFunctions["justifyShares"] = msc.justifyShares
 	msc.smartContractFunctions["wait"] = msc.wait
 	msc.smartContractFunctions["update_settings"] = msc.UpdateSettings
 	msc.smartContractFunctions["addToDelegatePool"] = msc.addToDelegatePool
 	msc.smartContractFunctions["deleteFromDelegatePool"] = msc.deleteFromDelegatePool
+	msc.smartContractFunctions["schedule_protocol_feature"] = msc.scheduleProtocolFeature
 }
 
 func (msc *MinerSmartContract) AddMinerIntegrationTests(
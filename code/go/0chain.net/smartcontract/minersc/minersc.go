@@ -19,12 +19,16 @@ func (msc *MinerSmartContract) initSC() {
 	PhaseRounds[Start] = scc.GetInt64(pfx + "start_rounds")
 	PhaseRounds[Contribute] = scc.GetInt64(pfx + "contribute_rounds")
 	PhaseRounds[Share] = scc.GetInt64(pfx + "share_rounds")
+	PhaseRounds[Complain] = scc.GetInt64(pfx + "complain_rounds")
+	PhaseRounds[Justify] = scc.GetInt64(pfx + "justify_rounds")
 	PhaseRounds[Publish] = scc.GetInt64(pfx + "publish_rounds")
 	PhaseRounds[Wait] = scc.GetInt64(pfx + "wait_rounds")
 
 	moveFunctions[Start] = msc.moveToContribute
 	moveFunctions[Contribute] = msc.moveToShareOrPublish
 	moveFunctions[Share] = msc.moveToShareOrPublish
+	moveFunctions[Complain] = msc.moveToShareOrPublish
+	moveFunctions[Justify] = msc.moveToShareOrPublish
 	moveFunctions[Publish] = msc.moveToWait
 	moveFunctions[Wait] = msc.moveToStart
 }
@@ -42,6 +46,8 @@ func (msc *MinerSmartContract) InitSmartContractFunctions() {
 	msc.smartContractFunctions["payFees"] = msc.payFees
 
 	msc.smartContractFunctions["contributeMpk"] = msc.contributeMpk
+	msc.smartContractFunctions["complainDealers"] = msc.complainDealers
+	msc.smartContractFunctions["justifyShares"] = msc.justifyShares
 	msc.smartContractFunctions["shareSignsOrShares"] = msc.shareSignsOrShares
 	msc.smartContractFunctions["wait"] = msc.wait
 
@@ -51,4 +57,6 @@ func (msc *MinerSmartContract) InitSmartContractFunctions() {
 	msc.smartContractFunctions["deleteFromDelegatePool"] = msc.deleteFromDelegatePool
 
 	msc.smartContractFunctions["sharder_keep"] = msc.sharderKeep
+
+	msc.smartContractFunctions["schedule_protocol_feature"] = msc.scheduleProtocolFeature
 }
@@ -38,7 +38,8 @@ func init() {
 // Phase number.
 type Phase int
 
-// known phases
+// known phases, the values are stored in state and sent to the conductor;
+// new phases are appended to keep them stable
 const (
 	Unknown Phase = iota - 1
 	Start
@@ -46,8 +47,37 @@ const (
 	Share
 	Publish
 	Wait
+	Complain
+	Justify
 )
 
+// DKGComplaintsFeature is the protocol feature enabling the Complain and
+// Justify phases of the view change.
+const DKGComplaintsFeature = "dkg_complaints"
+
+// NextPhase returns phase following the given one. The Complain and Justify
+// phases follow the Share phase only if the DKG complaints are enabled.
+func NextPhase(p Phase, complaints bool) Phase {
+	switch p {
+	case Start:
+		return Contribute
+	case Contribute:
+		return Share
+	case Share:
+		if complaints {
+			return Complain
+		}
+		return Publish
+	case Complain:
+		return Justify
+	case Justify:
+		return Publish
+	case Publish:
+		return Wait
+	}
+	return Start
+}
+
 func (p Phase) String() string {
 	switch p {
 	case Unknown:
@@ -58,6 +88,10 @@ func (p Phase) String() string {
 		return "contribute"
 	case Share:
 		return "share"
+	case Complain:
+		return "complain"
+	case Justify:
+		return "justify"
 	case Publish:
 		return "publish"
 	case Wait:
@@ -205,6 +239,8 @@ type Config struct {
 	StartRounds      int64 `json:"start_rounds"`
 	ContributeRounds int64 `json:"contribute_rounds"`
 	ShareRounds      int64 `json:"share_rounds"`
+	ComplainRounds   int64 `json:"complain_rounds"`
+	JustifyRounds    int64 `json:"justify_rounds"`
 	PublishRounds    int64 `json:"publish_rounds"`
 	WaitRounds       int64 `json:"wait_rounds"`
 }
@@ -0,0 +1,208 @@
+package minersc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"0chain.net/chaincore/block"
+	cstate "0chain.net/chaincore/chain/state"
+	"0chain.net/chaincore/transaction"
+	"0chain.net/core/common"
+	"0chain.net/core/util"
+	"0chain.net/smartcontract"
+
+	. "0chain.net/core/logging"
+	"go.uber.org/zap"
+)
+
+// ProtocolFeaturesKey is key of the protocol features schedule. The schedule
+// is copied to every magic block created by the SC and nodes take the
+// features from the magic block related to a round. Thus, a feature becomes
+// active at its activation round only if the magic block of the round
+// carries it. The SC requires the activation round to be not before the
+// starting round of the next magic block, the first one the schedule gets
+// into.
+var ProtocolFeaturesKey = globalKeyHash("protocol_features")
+
+func init() {
+	block.RegisterProtocolFeature(DKGComplaintsFeature)
+}
+
+func getProtocolFeatures(balances cstate.StateContextI) (
+	pfs *block.ProtocolFeatures, err error) {
+
+	pfs = block.NewProtocolFeatures()
+
+	var val util.Serializable
+	if val, err = balances.GetTrieNode(ProtocolFeaturesKey); err != nil {
+		if err != util.ErrValueNotPresent {
+			return nil, err
+		}
+		return pfs, nil
+	}
+
+	if err = pfs.Decode(val.Encode()); err != nil {
+		return nil, fmt.Errorf("%w: %s", common.ErrDecoding, err)
+	}
+	return pfs, nil
+}
+
+func updateProtocolFeatures(balances cstate.StateContextI,
+	pfs *block.ProtocolFeatures) (err error) {
+
+	_, err = balances.InsertTrieNode(ProtocolFeaturesKey, pfs)
+	return
+}
+
+// dkgComplaintsActive reports whether the DKG Complain and Justify phases
+// are enabled at given round.
+func dkgComplaintsActive(balances cstate.StateContextI, round int64) bool {
+	var pfs, err = getProtocolFeatures(balances)
+	if err != nil {
+		Logger.Error("get protocol features", zap.Error(err))
+		return false
+	}
+	return pfs.IsActive(DKGComplaintsFeature, round)
+}
+
+// nextViewChangeRound returns starting round of the first magic block the
+// SC creates after the given phase node state. The magic block is created
+// at the end of the Publish phase and starts after the Wait phase. If the
+// Publish phase of the current cycle is over, it's the next cycle's magic
+// block. The Complain and Justify phases are always counted, that gives the
+// latest possible round if the DKG doesn't restart.
+func nextViewChangeRound(pn *PhaseNode) (round int64) {
+	round = pn.StartRound + PhaseRounds[pn.Phase]
+	if round < pn.CurrentRound {
+		round = pn.CurrentRound
+	}
+	for phase := pn.Phase; phase != Publish; {
+		phase = NextPhase(phase, true)
+		round += PhaseRounds[phase]
+	}
+	return round + PhaseRounds[Wait]
+}
+
+// nextMagicBlock returns the magic block created by the SC that doesn't
+// start at given round yet, if any.
+func nextMagicBlock(balances cstate.StateContextI, round int64) (
+	*block.MagicBlock, error) {
+
+	var mb, err = getMagicBlock(balances)
+	if err == util.ErrValueNotPresent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mb.StartingRound <= round {
+		return nil, nil
+	}
+	return mb, nil
+}
+
+// scheduleProtocolFeature adds, moves or cancels (zero activation round)
+// a protocol feature activation. Only SC owner can do it and an already
+// active feature or a feature of the created magic block can't be changed.
+func (msc *MinerSmartContract) scheduleProtocolFeature(
+	t *transaction.Transaction, inputData []byte, gn *GlobalNode,
+	balances cstate.StateContextI) (resp string, err error) {
+
+	if t.ClientID != owner {
+		return "", common.NewError("schedule_protocol_feature",
+			"unauthorized access - only the owner can schedule features")
+	}
+
+	var pf block.ProtocolFeature
+	if err = pf.Decode(inputData); err != nil {
+		return "", common.NewErrorf("schedule_protocol_feature",
+			"decoding request: %v", err)
+	}
+
+	if pf.Name == "" {
+		return "", common.NewError("schedule_protocol_feature",
+			"missing feature name")
+	}
+
+	var pfs *block.ProtocolFeatures
+	if pfs, err = getProtocolFeatures(balances); err != nil {
+		return "", common.NewErrorf("schedule_protocol_feature",
+			"can't get protocol features: %v", err)
+	}
+
+	var round = balances.GetBlock().Round
+	if pfs.IsActive(pf.Name, round) {
+		return "", common.NewErrorf("schedule_protocol_feature",
+			"feature %q is already active", pf.Name)
+	}
+
+	var pn *PhaseNode
+	if pn, err = GetPhaseNode(balances); err != nil {
+		return "", common.NewErrorf("schedule_protocol_feature",
+			"can't get phase node: %v", err)
+	}
+
+	// a scheduled feature is in the magic blocks up to the next view change,
+	// so it can be changed only if it's not in the created magic block and
+	// activates after the next view change
+	var min = nextViewChangeRound(pn)
+	if prev, ok := pfs.Features[pf.Name]; ok {
+		var mb *block.MagicBlock
+		if mb, err = nextMagicBlock(balances, round); err != nil {
+			return "", common.NewErrorf("schedule_protocol_feature",
+				"can't get magic block: %v", err)
+		}
+		if mb != nil && mb.Features.Size() > 0 {
+			if _, ok := mb.Features.Features[pf.Name]; ok {
+				return "", common.NewErrorf("schedule_protocol_feature",
+					"feature %q is in the magic block starting at %d",
+					pf.Name, mb.StartingRound)
+			}
+		}
+		if prev.ActivationRound < min {
+			return "", common.NewErrorf("schedule_protocol_feature",
+				"feature %q activates before next view change: %d < %d",
+				pf.Name, prev.ActivationRound, min)
+		}
+	}
+
+	if pf.ActivationRound == 0 {
+		if _, ok := pfs.Features[pf.Name]; !ok {
+			return "", common.NewErrorf("schedule_protocol_feature",
+				"feature %q is not scheduled", pf.Name)
+		}
+		delete(pfs.Features, pf.Name)
+	} else {
+		if pf.ActivationRound < min {
+			return "", common.NewErrorf("schedule_protocol_feature",
+				"activation round is before next view change: %d < %d",
+				pf.ActivationRound, min)
+		}
+		pfs.Features[pf.Name] = &pf
+	}
+
+	if err = updateProtocolFeatures(balances, pfs); err != nil {
+		return "", common.NewErrorf("schedule_protocol_feature",
+			"saving protocol features: %v", err)
+	}
+
+	Logger.Info("miner sc: protocol feature scheduled",
+		zap.String("name", pf.Name),
+		zap.Int64("activation_round", pf.ActivationRound),
+		zap.Int64("round", round))
+
+	return string(pfs.Encode()), nil
+}
+
+// GetProtocolFeaturesHandler returns current protocol features schedule.
+func (msc *MinerSmartContract) GetProtocolFeaturesHandler(
+	ctx context.Context, params url.Values,
+	balances cstate.StateContextI) (interface{}, error) {
+
+	pfs, err := getProtocolFeatures(balances)
+	if err != nil {
+		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true)
+	}
+	return pfs, nil
+}
@@ -0,0 +1,133 @@
+package minersc
+
+import (
+	"testing"
+
+	"0chain.net/chaincore/block"
+	"0chain.net/chaincore/transaction"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setTestPhaseRounds(t *testing.T) {
+	var prev = PhaseRounds
+	PhaseRounds = make(map[Phase]int64)
+	for i, phase := range []Phase{Start, Contribute, Share, Complain,
+		Justify, Publish, Wait} {
+		PhaseRounds[phase] = int64(i+1) * 10
+	}
+	t.Cleanup(func() { PhaseRounds = prev })
+}
+
+func TestNextViewChangeRound(t *testing.T) {
+	setTestPhaseRounds(t)
+
+	// Start 10, Contribute 20, Share 30, Complain 40, Justify 50,
+	// Publish 60, Wait 70
+	for _, tt := range []struct {
+		pn   PhaseNode
+		want int64
+	}{
+		{PhaseNode{Phase: Start, StartRound: 100, CurrentRound: 100}, 100 + 280},
+		{PhaseNode{Phase: Start, StartRound: 100, CurrentRound: 115}, 115 + 270},
+		{PhaseNode{Phase: Share, StartRound: 100, CurrentRound: 105}, 100 + 250},
+		{PhaseNode{Phase: Publish, StartRound: 100, CurrentRound: 105}, 100 + 130},
+		{PhaseNode{Phase: Wait, StartRound: 100, CurrentRound: 105}, 100 + 350},
+	} {
+		var pn = tt.pn
+		require.Equal(t, tt.want, nextViewChangeRound(&pn), pn.Phase.String())
+	}
+}
+
+func TestScheduleProtocolFeature(t *testing.T) {
+	setTestPhaseRounds(t)
+
+	var (
+		msc      = newTestMinerSC()
+		balances = newTestBalances()
+		tx       = &transaction.Transaction{ClientID: owner}
+		gn       = new(GlobalNode)
+	)
+	balances.block = block.Provider().(*block.Block)
+	balances.block.Round = 100
+
+	var schedule = func(name string, round int64) error {
+		var pf = &block.ProtocolFeature{Name: name, ActivationRound: round}
+		_, err := msc.scheduleProtocolFeature(tx, pf.Encode(), gn, balances)
+		return err
+	}
+
+	// Wait phase started at round 90, the next magic block is created at
+	// the end of the next Publish phase
+	var pn = &PhaseNode{Phase: Wait, StartRound: 90}
+	_, err := balances.InsertTrieNode(pn.GetKey(), pn)
+	require.NoError(t, err)
+
+	var min int64 = 90 + 70 + 10 + 20 + 30 + 40 + 50 + 60 + 70
+	require.Error(t, schedule("", min+1), "missing name")
+	require.Error(t, schedule("f1", min-1), "before next view change")
+	require.NoError(t, schedule("f1", min))
+	require.NoError(t, schedule("f1", min+1))
+	require.NoError(t, schedule("f1", min+10), "moving")
+	require.NoError(t, schedule("f2", min+1))
+
+	pfs, err := getProtocolFeatures(balances)
+	require.NoError(t, err)
+	require.Equal(t, []string{"f1", "f2"}, pfs.Names())
+	require.EqualValues(t, min+10, pfs.Features["f1"].ActivationRound)
+
+	require.NoError(t, schedule("f2", 0), "canceling")
+	require.Error(t, schedule("f2", 0), "not scheduled")
+
+	// activated
+	balances.block.Round = min + 10
+	require.Error(t, schedule("f1", 0), "already active")
+
+	// not owner
+	tx.ClientID = "not_owner"
+	require.Error(t, schedule("f3", min+100))
+}
+
+func TestScheduleProtocolFeature_nextMagicBlock(t *testing.T) {
+	setTestPhaseRounds(t)
+
+	var (
+		msc      = newTestMinerSC()
+		balances = newTestBalances()
+		tx       = &transaction.Transaction{ClientID: owner}
+		gn       = new(GlobalNode)
+	)
+	balances.block = block.Provider().(*block.Block)
+	balances.block.Round = 100
+
+	var schedule = func(name string, round int64) error {
+		var pf = &block.ProtocolFeature{Name: name, ActivationRound: round}
+		_, err := msc.scheduleProtocolFeature(tx, pf.Encode(), gn, balances)
+		return err
+	}
+
+	var pn = &PhaseNode{Phase: Wait, StartRound: 90}
+	_, err := balances.InsertTrieNode(pn.GetKey(), pn)
+	require.NoError(t, err)
+
+	var min int64 = 90 + 70 + 10 + 20 + 30 + 40 + 50 + 60 + 70
+	require.NoError(t, schedule("f1", min+10))
+	pfs, err := getProtocolFeatures(balances)
+	require.NoError(t, err)
+
+	// the magic block created with f1 starts after the Wait phase
+	var mb = block.NewMagicBlock()
+	mb.StartingRound = 90 + 70
+	mb.Features = pfs
+	require.NoError(t, updateMagicBlock(balances, mb))
+
+	require.Error(t, schedule("f1", 0), "canceling")
+	require.Error(t, schedule("f1", min+20), "moving")
+	require.NoError(t, schedule("f2", min+10))
+	require.NoError(t, schedule("f2", 0), "not in the magic block")
+
+	// the magic block started
+	balances.block.Round = mb.StartingRound
+	require.NoError(t, schedule("f1", min+20))
+	require.NoError(t, schedule("f1", 0))
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"sync"
 
+	"0chain.net/chaincore/block"
 	cstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/config"
 	sci "0chain.net/chaincore/smartcontractinterface"
@@ -45,7 +46,11 @@ var (
 		"add_sharder":        {},
 		"sharder_keep":       {},
 		"contributeMpk":      {},
+		"complainDealers":    {},
+		"justifyShares":      {},
 		"shareSignsOrShares": {},
+
+		"schedule_protocol_feature": {},
 	}
 )
 
@@ -91,20 +96,37 @@ func (msc *MinerSmartContract) GetRestPoints() map[string]sci.SmartContractRestH
 //setSC setting up smartcontract. implementing the interface
 func (msc *MinerSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCContextI) {
 	msc.SmartContract = sc
-	msc.SmartContract.RestHandlers["/getNodepool"] = msc.GetNodepoolHandler
-	msc.SmartContract.RestHandlers["/getUserPools"] = msc.GetUserPoolsHandler
-	msc.SmartContract.RestHandlers["/getMinerList"] = msc.GetMinerListHandler
-	msc.SmartContract.RestHandlers["/getSharderList"] = msc.GetSharderListHandler
-	msc.SmartContract.RestHandlers["/getSharderKeepList"] = msc.GetSharderKeepListHandler
-	msc.SmartContract.RestHandlers["/getPhase"] = msc.GetPhaseHandler
-	msc.SmartContract.RestHandlers["/getDkgList"] = msc.GetDKGMinerListHandler
-	msc.SmartContract.RestHandlers["/getMpksList"] = msc.GetMinersMpksListHandler
-	msc.SmartContract.RestHandlers["/getGroupShareOrSigns"] = msc.GetGroupShareOrSignsHandler
-	msc.SmartContract.RestHandlers["/getMagicBlock"] = msc.GetMagicBlockHandler
-
-	msc.SmartContract.RestHandlers["/nodeStat"] = msc.nodeStatHandler
-	msc.SmartContract.RestHandlers["/nodePoolStat"] = msc.nodePoolStatHandler
-	msc.SmartContract.RestHandlers["/configs"] = msc.configsHandler
+	msc.SetRestHandler("/getNodepool", msc.GetNodepoolHandler, &sci.RestEndpoint{
+		Summary: "nodepool information of a registered miner", Params: nodepoolParams{}})
+	msc.SetRestHandler("/getUserPools", msc.GetUserPoolsHandler, &sci.RestEndpoint{
+		Summary: "delegate pools of the client", Params: clientParams{}, Response: userPools{}})
+	msc.SetRestHandler("/getMinerList", msc.GetMinerListHandler, &sci.RestEndpoint{
+		Summary: "all registered miners", Response: MinerNodes{}})
+	msc.SetRestHandler("/getSharderList", msc.GetSharderListHandler, &sci.RestEndpoint{
+		Summary: "all registered sharders", Response: MinerNodes{}})
+	msc.SetRestHandler("/getSharderKeepList", msc.GetSharderKeepListHandler, &sci.RestEndpoint{
+		Summary: "sharders keep list of the view change", Response: MinerNodes{}})
+	msc.SetRestHandler("/getPhase", msc.GetPhaseHandler, &sci.RestEndpoint{
+		Summary: "current view change phase", Response: PhaseNode{}})
+	msc.SetRestHandler("/getDkgList", msc.GetDKGMinerListHandler, &sci.RestEndpoint{
+		Summary: "DKG miners of the view change", Response: DKGMinerNodes{}})
+	msc.SetRestHandler("/getMpksList", msc.GetMinersMpksListHandler, &sci.RestEndpoint{
+		Summary: "MPKs of the DKG miners", Response: block.Mpks{}})
+	msc.SetRestHandler("/getDkgComplaints", msc.GetDKGComplaintsHandler, &sci.RestEndpoint{
+		Summary: "DKG complaints of the view change", Response: DKGComplaints{}})
+	msc.SetRestHandler("/getGroupShareOrSigns", msc.GetGroupShareOrSignsHandler, &sci.RestEndpoint{
+		Summary: "group shares or signatures of the view change", Response: block.GroupSharesOrSigns{}})
+	msc.SetRestHandler("/getMagicBlock", msc.GetMagicBlockHandler, &sci.RestEndpoint{
+		Summary: "magic block of the view change", Response: block.MagicBlock{}})
+	msc.SetRestHandler("/getProtocolFeatures", msc.GetProtocolFeaturesHandler, &sci.RestEndpoint{
+		Summary: "protocol features schedule", Response: block.ProtocolFeatures{}})
+
+	msc.SetRestHandler("/nodeStat", msc.nodeStatHandler, &sci.RestEndpoint{
+		Summary: "miner or sharder node", Params: nodeParams{}, Response: MinerNode{}})
+	msc.SetRestHandler("/nodePoolStat", msc.nodePoolStatHandler, &sci.RestEndpoint{
+		Summary: "delegate pool of the node", Params: nodePoolParams{}, Response: sci.DelegatePool{}})
+	msc.SetRestHandler("/configs", msc.configsHandler, &sci.RestEndpoint{
+		Summary: "miner SC configurations", Response: Config{}})
 
 	msc.bcContext = bcContext
 	msc.SmartContractExecutionStats["add_miner"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", msc.ID, "add_miner"), nil)
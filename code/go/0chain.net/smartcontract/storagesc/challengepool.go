@@ -4,7 +4,6 @@ import (
 	"0chain.net/smartcontract"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 
@@ -252,15 +251,14 @@ func (ssc *StorageSmartContract) getChallengePoolStatHandler(
 	resp interface{}, err error) {
 
 	var (
-		allocationID = datastore.Key(params.Get("allocation_id"))
-		alloc        *StorageAllocation
-		cp           *challengePool
+		p     allocationParams
+		alloc *StorageAllocation
+		cp    *challengePool
 	)
-
-	if allocationID == "" {
-		err := errors.New("missing allocation_id URL query parameter")
-		return nil, common.NewErrBadRequest(err.Error())
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
 	}
+	var allocationID = p.AllocationID
 
 	if alloc, err = ssc.getAllocation(allocationID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetAllocation)
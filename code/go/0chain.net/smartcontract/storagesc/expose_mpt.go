@@ -7,6 +7,11 @@ import (
 	"net/url"
 )
 
+// mptKeyParams are query parameters of the MPT key handler
+type mptKeyParams struct {
+	Key string `param:"key"`
+}
+
 func (ssc *StorageSmartContract) GetMptKey(
 	_ context.Context,
 	params url.Values,
@@ -23,11 +28,14 @@ func (ssc *StorageSmartContract) GetMptKey(
 			"exposed mpt not enabled")
 	}
 
-	var key = params.Get("key")
-	val, err := balances.GetTrieNode(key)
+	var p mptKeyParams
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	val, err := balances.GetTrieNode(p.Key)
 	if err != nil {
 		return nil, common.NewErrorf("get_mpt_key",
-			"get trie node %s failed: %v", key, err)
+			"get trie node %s failed: %v", p.Key, err)
 	}
 	return string(val.Encode()), nil
 }
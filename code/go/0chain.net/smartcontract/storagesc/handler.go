@@ -17,17 +17,73 @@ import (
 
 const cantGetBlobberMsg = "can't get blobber"
 
+// clientParams are query parameters of a client handler
+type clientParams struct {
+	ClientID string `param:"client_id"`
+}
+
+// blobberParams are query parameters of a blobber handler
+type blobberParams struct {
+	BlobberID string `param:"blobber_id,required"`
+}
+
+// allocationParams are query parameters of an allocation handler
+type allocationParams struct {
+	AllocationID string `param:"allocation_id,required"`
+}
+
+// allocBlobberParams are query parameters of the allocation/blobber
+// statistic of the read and write pools
+type allocBlobberParams struct {
+	ClientID     string `param:"client_id"`
+	AllocationID string `param:"allocation_id"`
+	BlobberID    string `param:"blobber_id"`
+}
+
+// allocationsParams are query parameters of the allocations handler
+type allocationsParams struct {
+	Client string `param:"client"`
+}
+
+// allocationStatsParams are query parameters of the allocation handler
+type allocationStatsParams struct {
+	Allocation string `param:"allocation"`
+}
+
+// allocationMinLockParams are query parameters of the allocation min lock
+// handler, the allocation data is JSON encoded new allocation request
+type allocationMinLockParams struct {
+	AllocationData string `param:"allocation_data"`
+}
+
+// readMarkerParams are query parameters of the latest read marker handler
+type readMarkerParams struct {
+	Client  string `param:"client"`
+	Blobber string `param:"blobber"`
+}
+
+// challengesParams are query parameters of the open challenges handler
+type challengesParams struct {
+	Blobber string `param:"blobber"`
+}
+
+// challengeParams are query parameters of the challenge handler
+type challengeParams struct {
+	Blobber   string `param:"blobber"`
+	Challenge string `param:"challenge"`
+}
+
 // GetBlobberHandler returns Blobber object from its individual stored value.
 func (ssc *StorageSmartContract) GetBlobberHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (
 	resp interface{}, err error) {
 
-	var blobberID = params.Get("blobber_id")
-	if blobberID == "" {
-		return nil, common.NewErrBadRequest("missing 'blobber_id' URL query parameter")
+	var p blobberParams
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
 	}
 
-	bl, err := ssc.getBlobber(blobberID, balances)
+	bl, err := ssc.getBlobber(p.BlobberID, balances)
 	if err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get blobber")
 	}
@@ -50,8 +106,11 @@ func (ssc *StorageSmartContract) GetBlobbersHandler(ctx context.Context,
 func (ssc *StorageSmartContract) GetAllocationsHandler(ctx context.Context,
 	params url.Values, balances cstate.StateContextI) (interface{}, error) {
 
-	clientID := params.Get("client")
-	allocations, err := ssc.getAllocationsList(clientID, balances)
+	var p allocationsParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	allocations, err := ssc.getAllocationsList(p.Client, balances)
 	if err != nil {
 		return nil, common.NewErrInternal("can't get allocation list", err.Error())
 	}
@@ -79,9 +138,12 @@ func (ssc *StorageSmartContract) GetAllocationMinLockHandler(ctx context.Context
 	var err error
 	var creationDate = common.Timestamp(time.Now().Unix())
 
-	allocData := params.Get("allocation_data")
+	var p allocationMinLockParams
+	if err = common.DecodeParams(params, &p); err != nil {
+		return "", err
+	}
 	var request newAllocationRequest
-	if err = request.decode([]byte(allocData)); err != nil {
+	if err = request.decode([]byte(p.AllocationData)); err != nil {
 		return "", common.NewErrInternal("can't decode allocation request", err.Error())
 	}
 
@@ -120,9 +182,12 @@ func (ssc *StorageSmartContract) GetAllocationMinLockHandler(ctx context.Context
 const cantGetAllocation = "can't get allocation"
 
 func (ssc *StorageSmartContract) AllocationStatsHandler(ctx context.Context, params url.Values, balances cstate.StateContextI) (interface{}, error) {
-	allocationID := params.Get("allocation")
+	var p allocationStatsParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 	allocationObj := &StorageAllocation{}
-	allocationObj.ID = allocationID
+	allocationObj.ID = p.Allocation
 
 	allocationBytes, err := balances.GetTrieNode(allocationObj.GetKey(ssc.ID))
 	if err != nil {
@@ -140,15 +205,16 @@ func (ssc *StorageSmartContract) LatestReadMarkerHandler(ctx context.Context,
 	resp interface{}, err error) {
 
 	var (
-		clientID  = params.Get("client")
-		blobberID = params.Get("blobber")
-
+		p          readMarkerParams
 		commitRead = &ReadConnection{}
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
 	commitRead.ReadMarker = &ReadMarker{
-		BlobberID: blobberID,
-		ClientID:  clientID,
+		BlobberID: p.Blobber,
+		ClientID:  p.Client,
 	}
 
 	var commitReadBytes util.Serializable
@@ -170,7 +236,11 @@ func (ssc *StorageSmartContract) LatestReadMarkerHandler(ctx context.Context,
 }
 
 func (ssc *StorageSmartContract) OpenChallengeHandler(ctx context.Context, params url.Values, balances cstate.StateContextI) (interface{}, error) {
-	blobberID := params.Get("blobber")
+	var p challengesParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	blobberID := p.Blobber
 
 	// return "404", if blobber not registered
 	blobber := StorageNode{ID: blobberID}
@@ -205,9 +275,12 @@ func (ssc *StorageSmartContract) GetChallengeHandler(ctx context.Context, params
 			logging.Logger.Error("/getchallenge failed with error - " + retErr.Error())
 		}
 	}()
-	blobberID := params.Get("blobber")
+	var p challengeParams
+	if err := common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 	blobberChallengeObj := &BlobberChallenge{}
-	blobberChallengeObj.BlobberID = blobberID
+	blobberChallengeObj.BlobberID = p.Blobber
 	blobberChallengeObj.Challenges = make([]*StorageChallenge, 0)
 
 	blobberChallengeBytes, err := balances.GetTrieNode(blobberChallengeObj.GetKey(ssc.ID))
@@ -218,10 +291,9 @@ func (ssc *StorageSmartContract) GetChallengeHandler(ctx context.Context, params
 		return "", common.NewErrInternal("can't decode blobber challenge", err.Error())
 	}
 
-	challengeID := params.Get("challenge")
-	if _, ok := blobberChallengeObj.ChallengeMap[challengeID]; !ok {
+	if _, ok := blobberChallengeObj.ChallengeMap[p.Challenge]; !ok {
 		return nil, common.NewErrBadRequest("can't find challenge with provided 'challenge' param")
 	}
 
-	return blobberChallengeObj.ChallengeMap[challengeID], nil
+	return blobberChallengeObj.ChallengeMap[p.Challenge], nil
 }
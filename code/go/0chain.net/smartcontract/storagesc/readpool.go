@@ -511,10 +511,16 @@ func (ssc *StorageSmartContract) getReadPoolAllocBlobberStatHandler(
 	resp interface{}, err error) {
 
 	var (
-		clientID  = params.Get("client_id")
-		allocID   = params.Get("allocation_id")
-		blobberID = params.Get("blobber_id")
-		rp        *readPool
+		p  allocBlobberParams
+		rp *readPool
+	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	var (
+		clientID  = p.ClientID
+		allocID   = p.AllocationID
+		blobberID = p.BlobberID
 	)
 
 	if rp, err = ssc.getReadPool(clientID, balances); err != nil {
@@ -549,11 +555,14 @@ func (ssc *StorageSmartContract) getReadPoolStatHandler(ctx context.Context,
 	resp interface{}, err error) {
 
 	var (
-		clientID = datastore.Key(params.Get("client_id"))
-		rp       *readPool
+		p  clientParams
+		rp *readPool
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
-	if rp, err = ssc.getReadPool(clientID, balances); err != nil {
+	if rp, err = ssc.getReadPool(p.ClientID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get read pool")
 	}
 
@@ -9,6 +9,7 @@ import (
 	chainstate "0chain.net/chaincore/chain/state"
 	"0chain.net/chaincore/config"
 	sci "0chain.net/chaincore/smartcontractinterface"
+	"0chain.net/chaincore/state"
 	"0chain.net/chaincore/transaction"
 	"0chain.net/core/common"
 	metrics "github.com/rcrowley/go-metrics"
@@ -46,18 +47,24 @@ func (ipsc *StorageSmartContract) GetExecutionStats() map[string]interface{} {
 
 func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCContextI) {
 	ssc.SmartContract = sc
-	ssc.SmartContract.RestHandlers["/get_mpt_key"] = ssc.GetMptKey
+	ssc.SetRestHandler("/get_mpt_key", ssc.GetMptKey, &sci.RestEndpoint{
+		Summary: "value of the MPT key, if exposed", Params: mptKeyParams{}, Response: ""})
 	// sc configurations
-	ssc.SmartContract.RestHandlers["/getConfig"] = ssc.getConfigHandler
+	ssc.SetRestHandler("/getConfig", ssc.getConfigHandler, &sci.RestEndpoint{
+		Summary: "storage SC configurations", Response: scConfig{}})
 	ssc.SmartContractExecutionStats["update_config"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_config"), nil)
 	// reading / writing
-	ssc.SmartContract.RestHandlers["/latestreadmarker"] = ssc.LatestReadMarkerHandler
+	ssc.SetRestHandler("/latestreadmarker", ssc.LatestReadMarkerHandler, &sci.RestEndpoint{
+		Summary: "latest read marker of the client and blobber", Params: readMarkerParams{}, Response: ReadMarker{}})
 	ssc.SmartContractExecutionStats["read_redeem"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "read_redeem"), nil)
 	ssc.SmartContractExecutionStats["commit_connection"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "commit_connection"), nil)
 	// allocation
-	ssc.SmartContract.RestHandlers["/allocation"] = ssc.AllocationStatsHandler
-	ssc.SmartContract.RestHandlers["/allocations"] = ssc.GetAllocationsHandler
-	ssc.SmartContract.RestHandlers["/allocation_min_lock"] = ssc.GetAllocationMinLockHandler
+	ssc.SetRestHandler("/allocation", ssc.AllocationStatsHandler, &sci.RestEndpoint{
+		Summary: "allocation", Params: allocationStatsParams{}, Response: StorageAllocation{}})
+	ssc.SetRestHandler("/allocations", ssc.GetAllocationsHandler, &sci.RestEndpoint{
+		Summary: "allocations of the client", Params: allocationsParams{}, Response: []*StorageAllocation{}})
+	ssc.SetRestHandler("/allocation_min_lock", ssc.GetAllocationMinLockHandler, &sci.RestEndpoint{
+		Summary: "min lock demand of a new allocation", Params: allocationMinLockParams{}, Response: map[string]state.Balance{}})
 	ssc.SmartContractExecutionStats["new_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "new_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["update_allocation_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_allocation_request"), nil)
 	ssc.SmartContractExecutionStats["finalize_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "finalize_allocation"), nil)
@@ -67,8 +74,10 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContractExecutionStats["add_curator"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_curator"), nil)
 	ssc.SmartContractExecutionStats["curator_transfer_allocation"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "curator_transfer_allocation"), nil)
 	// challenge
-	ssc.SmartContract.RestHandlers["/openchallenges"] = ssc.OpenChallengeHandler
-	ssc.SmartContract.RestHandlers["/getchallenge"] = ssc.GetChallengeHandler
+	ssc.SetRestHandler("/openchallenges", ssc.OpenChallengeHandler, &sci.RestEndpoint{
+		Summary: "open challenges of the blobber", Params: challengesParams{}, Response: BlobberChallenge{}})
+	ssc.SetRestHandler("/getchallenge", ssc.GetChallengeHandler, &sci.RestEndpoint{
+		Summary: "challenge of the blobber", Params: challengeParams{}, Response: StorageChallenge{}})
 	ssc.SmartContractExecutionStats["challenge_request"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_request"), nil)
 	ssc.SmartContractExecutionStats["challenge_response"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "challenge_response"), nil)
 	ssc.SmartContractExecutionStats["generate_challenges"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "generate_challenges"), nil)
@@ -79,8 +88,10 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContractExecutionStats[statUpdateValidator] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_validator"), nil)
 	ssc.SmartContractExecutionStats[statNumberOfValidators] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "number of validators"), nil)
 	// blobber
-	ssc.SmartContract.RestHandlers["/getblobbers"] = ssc.GetBlobbersHandler
-	ssc.SmartContract.RestHandlers["/getBlobber"] = ssc.GetBlobberHandler
+	ssc.SetRestHandler("/getblobbers", ssc.GetBlobbersHandler, &sci.RestEndpoint{
+		Summary: "all blobbers alive", Response: StorageNodes{}})
+	ssc.SetRestHandler("/getBlobber", ssc.GetBlobberHandler, &sci.RestEndpoint{
+		Summary: "blobber", Params: blobberParams{}, Response: StorageNode{}})
 	ssc.SmartContractExecutionStats["add_blobber"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "add_blobber (add/update/remove SC function)"), nil)
 	ssc.SmartContractExecutionStats["update_blobber_settings"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "update_blobber_settings"), nil)
 	ssc.SmartContractExecutionStats["pay_blobber_block_rewards"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "pay_blobber_block_rewards"), nil)
@@ -90,24 +101,31 @@ func (ssc *StorageSmartContract) setSC(sc *sci.SmartContract, bcContext sci.BCCo
 	ssc.SmartContractExecutionStats[statUpdateBlobber] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stat: update blobber"), nil)
 	ssc.SmartContractExecutionStats[statRemoveBlobber] = metrics.GetOrRegisterCounter(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stat: remove blobber"), nil)
 	// read pool
-	ssc.SmartContract.RestHandlers["/getReadPoolStat"] = ssc.getReadPoolStatHandler
-	ssc.SmartContract.RestHandlers["/getReadPoolAllocBlobberStat"] = ssc.getReadPoolAllocBlobberStatHandler
+	ssc.SetRestHandler("/getReadPoolStat", ssc.getReadPoolStatHandler, &sci.RestEndpoint{
+		Summary: "read pool of the client", Params: clientParams{}, Response: allocationPoolsStat{}})
+	ssc.SetRestHandler("/getReadPoolAllocBlobberStat", ssc.getReadPoolAllocBlobberStatHandler, &sci.RestEndpoint{
+		Summary: "read pool tokens of the allocation and blobber", Params: allocBlobberParams{}, Response: []untilStat{}})
 	ssc.SmartContractExecutionStats["new_read_pool"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "new_read_pool"), nil)
 	ssc.SmartContractExecutionStats["read_pool_lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "read_pool_lock"), nil)
 	ssc.SmartContractExecutionStats["read_pool_unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "read_pool_unlock"), nil)
 	// write pool
-	ssc.SmartContract.RestHandlers["/getWritePoolStat"] = ssc.getWritePoolStatHandler
-	ssc.SmartContract.RestHandlers["/getWritePoolAllocBlobberStat"] = ssc.getWritePoolAllocBlobberStatHandler
+	ssc.SetRestHandler("/getWritePoolStat", ssc.getWritePoolStatHandler, &sci.RestEndpoint{
+		Summary: "write pool of the client", Params: clientParams{}, Response: allocationPoolsStat{}})
+	ssc.SetRestHandler("/getWritePoolAllocBlobberStat", ssc.getWritePoolAllocBlobberStatHandler, &sci.RestEndpoint{
+		Summary: "write pool tokens of the allocation and blobber", Params: allocBlobberParams{}, Response: []untilStat{}})
 	ssc.SmartContractExecutionStats["write_pool_lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "write_pool_lock"), nil)
 	ssc.SmartContractExecutionStats["write_pool_unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "write_pool_unlock"), nil)
 	// stake pool
-	ssc.SmartContract.RestHandlers["/getStakePoolStat"] = ssc.getStakePoolStatHandler
-	ssc.SmartContract.RestHandlers["/getUserStakePoolStat"] = ssc.getUserStakePoolStatHandler
+	ssc.SetRestHandler("/getStakePoolStat", ssc.getStakePoolStatHandler, &sci.RestEndpoint{
+		Summary: "stake pool of the blobber", Params: stakePoolParams{}, Response: stakePoolStat{}})
+	ssc.SetRestHandler("/getUserStakePoolStat", ssc.getUserStakePoolStatHandler, &sci.RestEndpoint{
+		Summary: "stake pools of the client", Params: clientParams{}, Response: userPoolStat{}})
 	ssc.SmartContractExecutionStats["stake_pool_lock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stake_pool_lock"), nil)
 	ssc.SmartContractExecutionStats["stake_pool_unlock"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stake_pool_unlock"), nil)
 	ssc.SmartContractExecutionStats["stake_pool_pay_interests"] = metrics.GetOrRegisterTimer(fmt.Sprintf("sc:%v:func:%v", ssc.ID, "stake_pool_pay_interests"), nil)
 	// challenge pool
-	ssc.SmartContract.RestHandlers["/getChallengePoolStat"] = ssc.getChallengePoolStatHandler
+	ssc.SetRestHandler("/getChallengePoolStat", ssc.getChallengePoolStatHandler, &sci.RestEndpoint{
+		Summary: "challenge pool of the allocation", Params: allocationParams{}, Response: challengePoolStat{}})
 }
 
 func (ssc *StorageSmartContract) GetName() string {
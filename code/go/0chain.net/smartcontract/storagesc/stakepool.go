@@ -1138,17 +1138,26 @@ func (ssc *StorageSmartContract) stakePoolPayInterests(
 
 const cantGetStakePoolMsg = "can't get related stake pool"
 
+// stakePoolParams are query parameters of the stake pool handler
+type stakePoolParams struct {
+	BlobberID string `param:"blobber_id"`
+}
+
 // statistic for all locked tokens of a stake pool
 func (ssc *StorageSmartContract) getStakePoolStatHandler(ctx context.Context,
 	params url.Values, balances chainstate.StateContextI) (
 	resp interface{}, err error) {
 
 	var (
-		blobberID = datastore.Key(params.Get("blobber_id"))
-		conf      *scConfig
-		blobber   *StorageNode
-		sp        *stakePool
+		p       stakePoolParams
+		conf    *scConfig
+		blobber *StorageNode
+		sp      *stakePool
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	var blobberID = p.BlobberID
 
 	if conf, err = ssc.getConfig(balances, false); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
@@ -1175,11 +1184,15 @@ func (ssc *StorageSmartContract) getUserStakePoolStatHandler(ctx context.Context
 	resp interface{}, err error) {
 
 	var (
-		clientID = datastore.Key(params.Get("client_id"))
-		now      = common.Now()
-		conf     *scConfig
-		usp      *userStakePools
+		p    clientParams
+		now  = common.Now()
+		conf *scConfig
+		usp  *userStakePools
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	var clientID = p.ClientID
 
 	if conf, err = ssc.getConfig(balances, false); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetConfigErrMsg)
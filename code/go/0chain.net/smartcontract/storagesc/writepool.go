@@ -459,10 +459,16 @@ func (ssc *StorageSmartContract) getWritePoolAllocBlobberStatHandler(
 	resp interface{}, err error) {
 
 	var (
-		clientID  = params.Get("client_id")
-		allocID   = params.Get("allocation_id")
-		blobberID = params.Get("blobber_id")
-		wp        *writePool
+		p  allocBlobberParams
+		wp *writePool
+	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	var (
+		clientID  = p.ClientID
+		allocID   = p.AllocationID
+		blobberID = p.BlobberID
 	)
 
 	if wp, err = ssc.getWritePool(clientID, balances); err != nil {
@@ -497,11 +503,14 @@ func (ssc *StorageSmartContract) getWritePoolStatHandler(ctx context.Context,
 	resp interface{}, err error) {
 
 	var (
-		clientID = params.Get("client_id")
-		wp       *writePool
+		p  clientParams
+		wp *writePool
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
-	if wp, err = ssc.getWritePool(clientID, balances); err != nil {
+	if wp, err = ssc.getWritePool(p.ClientID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, cantGetWritePoolMsg)
 	}
 
@@ -145,17 +145,25 @@ func (vsc *VestingSmartContract) getOrCreateClientPools(clientID datastore.Key,
 // REST-handlers
 //
 
+// clientParams are query parameters of the client pools handler
+type clientParams struct {
+	ClientID string `param:"client_id"`
+}
+
 func (vsc *VestingSmartContract) getClientPoolsHandler(ctx context.Context,
 	params url.Values, balances chainstate.StateContextI) (
 	resp interface{}, err error) {
 
 	var (
-		clientID = params.Get("client_id")
-		cp       *clientPools
+		p  clientParams
+		cp *clientPools
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
 	// just return empty list if not found
-	if cp, err = vsc.getOrCreateClientPools(clientID, balances); err != nil {
+	if cp, err = vsc.getOrCreateClientPools(p.ClientID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get or create client pools")
 	}
 
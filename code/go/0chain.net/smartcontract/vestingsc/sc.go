@@ -57,9 +57,16 @@ func (vsc *VestingSmartContract) setSC(sc *smartcontractinterface.SmartContract,
 	vsc.SmartContract = sc
 
 	// information (statistics) and configurations
-	vsc.SmartContract.RestHandlers["/getConfig"] = vsc.getConfigHandler
-	vsc.SmartContract.RestHandlers["/getPoolInfo"] = vsc.getPoolInfoHandler
-	vsc.SmartContract.RestHandlers["/getClientPools"] = vsc.getClientPoolsHandler
+	vsc.SetRestHandler("/getConfig", vsc.getConfigHandler,
+		&smartcontractinterface.RestEndpoint{
+			Summary: "vesting SC configurations", Response: config{}})
+	vsc.SetRestHandler("/getPoolInfo", vsc.getPoolInfoHandler,
+		&smartcontractinterface.RestEndpoint{
+			Summary: "vesting pool", Params: poolParams{}, Response: info{}})
+	vsc.SetRestHandler("/getClientPools", vsc.getClientPoolsHandler,
+		&smartcontractinterface.RestEndpoint{
+			Summary: "vesting pools of the client", Params: clientParams{},
+			Response: clientPools{}})
 
 	// add/delete {start,duration,lock_tokens,[destinations]}
 	vsc.SmartContractExecutionStats["add"] = metrics.GetOrRegisterTimer(
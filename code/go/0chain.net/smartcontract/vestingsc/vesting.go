@@ -831,16 +831,24 @@ func (vsc *VestingSmartContract) trigger(t *transaction.Transaction,
 // REST handlers
 //
 
+// poolParams are query parameters of the pool info handler
+type poolParams struct {
+	PoolID string `param:"pool_id"`
+}
+
 func (vsc *VestingSmartContract) getPoolInfoHandler(ctx context.Context,
 	params url.Values, balances chainstate.StateContextI) (
 	resp interface{}, err error) {
 
 	var (
-		poolID = datastore.Key(params.Get("pool_id"))
-		vp     *vestingPool
+		p  poolParams
+		vp *vestingPool
 	)
+	if err = common.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
 
-	if vp, err = vsc.getPool(poolID, balances); err != nil {
+	if vp, err = vsc.getPool(p.PoolID, balances); err != nil {
 		return nil, smartcontract.NewErrNoResourceOrErrInternal(err, true, "can't get pool")
 	}
 
@@ -11,6 +11,7 @@ do
   rm -rf docker.local/miner"$i"/data/rocksdb/config*
   rm -rf docker.local/miner"$i"/data/rocksdb/mb*
   rm -rf docker.local/miner"$i"/data/rocksdb/state*
+  rm -rf docker.local/miner"$i"/data/rocksdb/journal*
 done
 
 for i in $(seq 1 3)
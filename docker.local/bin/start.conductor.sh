@@ -7,9 +7,15 @@ do
     docker stop "$running"
 done
 
+tests="${1:-view-change.fault-tolerance}"
+if [ $# -gt 0 ]; then
+    shift
+fi
+
 # go caches all build by default
 (cd ./code/go/0chain.net/conductor/conductor/ && go build)
 # start the conductor
 ./code/go/0chain.net/conductor/conductor/conductor                     \
     -config "./docker.local/config/conductor.config.yaml"              \
-    -tests "./docker.local/config/conductor.${1:-view-change.fault-tolerance}.yaml"
+    -tests "./docker.local/config/conductor.${tests}.yaml"            \
+    "$@"
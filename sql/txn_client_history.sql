@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS zerochain.txn_client_history (
+client_id text,
+round bigint,
+hash text,
+roles set<text>,
+partial boolean,
+PRIMARY KEY (client_id, round, hash)
+) WITH CLUSTERING ORDER BY (round ASC, hash ASC);
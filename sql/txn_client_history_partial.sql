@@ -0,0 +1,3 @@
+-- adds the partial column to txn_client_history tables created before it,
+-- fails with "conflicts with an existing column" if the column exists
+ALTER TABLE zerochain.txn_client_history ADD partial boolean;